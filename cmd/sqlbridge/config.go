@@ -0,0 +1,185 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/siyul-park/sqlbridge/schema"
+)
+
+// Config describes the catalogs of a registry. Relative paths are relative to the directory of the config file, and
+// environment variables are expanded in DSNs, URLs and headers so that secrets stay out of the file.
+type Config struct {
+	Database string                   `json:"database,omitempty"`
+	Catalogs map[string]CatalogConfig `json:"catalogs"`
+}
+
+// CatalogConfig describes a catalog: a directory of CSV files if Type is "csv", a database behind database/sql if it
+// is "sql", and otherwise the Tables listed.
+type CatalogConfig struct {
+	Type   string                 `json:"type,omitempty"`
+	Path   string                 `json:"path,omitempty"`
+	Driver string                 `json:"driver,omitempty"`
+	DSN    string                 `json:"dsn,omitempty"`
+	Tables map[string]TableConfig `json:"tables,omitempty"`
+}
+
+// TableConfig describes a table of Type "csv", "json" or "parquet" read from Path, or "http" read from URL.
+type TableConfig struct {
+	Type      string            `json:"type"`
+	Path      string            `json:"path,omitempty"`
+	Delimiter string            `json:"delimiter,omitempty"`
+	Header    *bool             `json:"header,omitempty"`
+	Null      *string           `json:"null,omitempty"`
+	URL       string            `json:"url,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Records   string            `json:"records,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+}
+
+var ErrConfig = errors.New("invalid config")
+
+// LoadConfig reads the config file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrConfig, path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for name, catalog := range config.Catalogs {
+		catalog.Path = resolvePath(dir, catalog.Path)
+		for table, cfg := range catalog.Tables {
+			cfg.Path = resolvePath(dir, cfg.Path)
+			catalog.Tables[table] = cfg
+		}
+		config.Catalogs[name] = catalog
+	}
+	return &config, nil
+}
+
+// Open returns the registry the config describes, and a function closing the databases it connected to. Every
+// catalog can hold views.
+func (c *Config) Open() (schema.Registry, func() error, error) {
+	var dbs []*sql.DB
+	closer := func() error {
+		var errs []error
+		for _, db := range dbs {
+			errs = append(errs, db.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	catalogs := make(map[string]schema.Catalog, len(c.Catalogs))
+	for name, cfg := range c.Catalogs {
+		var catalog schema.Catalog
+		switch cfg.Type {
+		case "csv":
+			var err error
+			if catalog, err = schema.NewCSVCatalog(cfg.Path); err != nil {
+				_ = closer()
+				return nil, nil, err
+			}
+		case "sql":
+			dialect, err := sqlDialect(cfg.Driver)
+			if err != nil {
+				_ = closer()
+				return nil, nil, fmt.Errorf("catalog %q: %w", name, err)
+			}
+			db, err := sql.Open(cfg.Driver, os.ExpandEnv(cfg.DSN))
+			if err != nil {
+				_ = closer()
+				return nil, nil, err
+			}
+			dbs = append(dbs, db)
+			catalog = schema.NewSQLCatalog(db, dialect)
+		case "":
+			tables := make(map[string]schema.Table, len(cfg.Tables))
+			for table, t := range cfg.Tables {
+				var err error
+				if tables[table], err = t.open(); err != nil {
+					_ = closer()
+					return nil, nil, fmt.Errorf("table %q.%q: %w", name, table, err)
+				}
+			}
+			catalog = schema.NewInMemoryCatalog(tables)
+		default:
+			_ = closer()
+			return nil, nil, fmt.Errorf("%w: catalog %q has unknown type %q", ErrConfig, name, cfg.Type)
+		}
+		catalogs[name] = schema.NewViewCatalog(catalog)
+	}
+	return schema.NewInMemoryRegistry(catalogs), closer, nil
+}
+
+func (t TableConfig) open() (schema.Table, error) {
+	switch t.Type {
+	case "csv":
+		var opts []schema.CSVOption
+		if t.Delimiter != "" {
+			r, size := utf8.DecodeRuneInString(t.Delimiter)
+			if size != len(t.Delimiter) {
+				return nil, fmt.Errorf("%w: delimiter %q is not a single character", ErrConfig, t.Delimiter)
+			}
+			opts = append(opts, schema.WithDelimiter(r))
+		}
+		if t.Header != nil {
+			opts = append(opts, schema.WithHeader(*t.Header))
+		}
+		if t.Null != nil {
+			opts = append(opts, schema.WithNullToken(*t.Null))
+		}
+		return schema.NewCSVTable(t.Path, opts...), nil
+	case "json":
+		return schema.NewJSONFileTable(t.Path), nil
+	case "parquet":
+		return schema.NewParquetTable(t.Path), nil
+	case "http":
+		var opts []schema.HTTPOption
+		if t.Method != "" {
+			opts = append(opts, schema.WithMethod(t.Method))
+		}
+		for key, value := range t.Headers {
+			opts = append(opts, schema.WithRequestHeader(key, os.ExpandEnv(value)))
+		}
+		if t.Records != "" {
+			opts = append(opts, schema.WithRecordsPath(t.Records))
+		}
+		for column, param := range t.Params {
+			opts = append(opts, schema.WithQueryParam(column, param))
+		}
+		return schema.NewHTTPTable(os.ExpandEnv(t.URL), opts...), nil
+	}
+	return nil, fmt.Errorf("%w: unknown table type %q", ErrConfig, t.Type)
+}
+
+// sqlDialect returns the dialect of the databases the database/sql driver named driver connects to.
+func sqlDialect(driver string) (schema.Dialect, error) {
+	switch driver {
+	case "mysql":
+		return schema.MySQLDialect{}, nil
+	case "pgx", "pgx/v5":
+		return schema.PostgresDialect{}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown driver %q", ErrConfig, driver)
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, config string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "data"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "users.csv"), []byte("id,name\n1,foo\n2,bar\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "orders.csv"), []byte("id;user_id;amount\n1;1;2.5\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "events.json"), []byte(`{"id": 1, "kind": "click"}`+"\n"), 0o644))
+
+	path := filepath.Join(dir, "sqlbridge.json")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o644))
+	return path
+}
+
+const testConfig = `{
+	"database": "app",
+	"catalogs": {
+		"app": {
+			"tables": {
+				"users": {"type": "csv", "path": "data/users.csv"},
+				"orders": {"type": "csv", "path": "data/orders.csv", "delimiter": ";"},
+				"events": {"type": "json", "path": "events.json"}
+			}
+		},
+		"files": {"type": "csv", "path": "data"}
+	}
+}`
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, testConfig)
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, "app", config.Database)
+	require.Equal(t, filepath.Join(filepath.Dir(path), "data", "users.csv"), config.Catalogs["app"].Tables["users"].Path)
+	require.Equal(t, filepath.Join(filepath.Dir(path), "data"), config.Catalogs["files"].Path)
+
+	_, err = LoadConfig(writeConfig(t, `{"catalogs": [}`))
+	require.ErrorIs(t, err, ErrConfig)
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestConfig_Open(t *testing.T) {
+	config, err := LoadConfig(writeConfig(t, testConfig))
+	require.NoError(t, err)
+
+	registry, closer, err := config.Open()
+	require.NoError(t, err)
+	defer closer()
+
+	names, err := registry.(schema.CatalogLister).Catalogs()
+	require.NoError(t, err)
+	require.Equal(t, []string{"app", "files"}, names)
+
+	for catalog, tables := range map[string][]string{"app": {"events", "orders", "users"}, "files": {"orders", "users"}} {
+		c, err := registry.Catalog(catalog)
+		require.NoError(t, err)
+
+		names, err := c.(schema.TableLister).Tables()
+		require.NoError(t, err)
+		require.Equal(t, tables, names)
+	}
+
+	app, err := registry.Catalog("app")
+	require.NoError(t, err)
+	orders, err := app.Table("orders")
+	require.NoError(t, err)
+	columns, err := orders.(schema.Describer).Columns(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, columns, 3)
+
+	tests := []string{
+		`{"catalogs": {"app": {"type": "xml"}}}`,
+		`{"catalogs": {"app": {"tables": {"users": {"type": "xml"}}}}}`,
+		`{"catalogs": {"app": {"tables": {"users": {"type": "csv", "delimiter": "ab"}}}}}`,
+		`{"catalogs": {"db": {"type": "sql", "driver": "sqlite3"}}}`,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			config, err := LoadConfig(writeConfig(t, tt))
+			require.NoError(t, err)
+
+			_, _, err = config.Open()
+			require.ErrorIs(t, err, ErrConfig)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Command sqlbridge queries the catalogs of a config file with SQL, either interactively or from scripts.
+//
+// Usage:
+//
+//	sqlbridge [-c config] [-d database] [-o format] [-t] [-e statements | -f file | file]
+//
+// Without -e or a file, it reads statements from standard input, as a REPL if that is a terminal.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/siyul-park/sqlbridge/driver"
+	"github.com/siyul-park/sqlbridge/schema"
+	"golang.org/x/term"
+)
+
+const defaultConfig = "sqlbridge.json"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with args, returning its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("sqlbridge", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("c", os.Getenv("SQLBRIDGE_CONFIG"), "config file describing the catalogs, "+defaultConfig+" if it exists by default")
+	database := flags.String("d", "", "database to connect to")
+	execute := flags.String("e", "", "run the statements and exit")
+	file := flags.String("f", "", "run the statements of the file and exit, - for standard input")
+	format := flags.String("o", string(FormatTable), "output format: table, csv, json or vertical")
+	timing := flags.Bool("t", false, "print how long each statement takes")
+	historyPath := flags.String("history", defaultHistory(), "file keeping the history of the REPL, none if empty")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: sqlbridge [flags] [file]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() > 1 || (flags.NArg() == 1 && (*file != "" || *execute != "")) {
+		flags.Usage()
+		return 2
+	}
+	if flags.NArg() == 1 {
+		*file = flags.Arg(0)
+	}
+
+	f, err := ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(stderr, "ERROR: %v\n", err)
+		return 2
+	}
+
+	config := &Config{}
+	if *configPath == "" {
+		if _, err := os.Stat(defaultConfig); err == nil {
+			*configPath = defaultConfig
+		}
+	}
+	if *configPath != "" {
+		if config, err = LoadConfig(*configPath); err != nil {
+			fmt.Fprintf(stderr, "ERROR: %v\n", err)
+			return 1
+		}
+	}
+	if *database == "" {
+		*database = config.Database
+	}
+	if *database == "" {
+		*database = schema.InformationSchemaName
+	}
+
+	registry, closer, err := config.Open()
+	if err != nil {
+		fmt.Fprintf(stderr, "ERROR: %v\n", err)
+		return 1
+	}
+	defer func() { _ = closer() }()
+
+	ctx := context.Background()
+	connector, err := driver.New(driver.WithRegistry(registry)).OpenConnector(*database)
+	if err != nil {
+		fmt.Fprintf(stderr, "ERROR: %v\n", err)
+		return 1
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	// A single connection keeps the database a USE switches to.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "ERROR: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+
+	shell := &Shell{conn: conn, stdout: stdout, stderr: stderr, format: f, timing: *timing}
+
+	var script io.Reader
+	switch {
+	case *execute != "":
+		script = strings.NewReader(*execute)
+	case *file == "-":
+		script = stdin
+	case *file != "":
+		r, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(stderr, "ERROR: %v\n", err)
+			return 1
+		}
+		defer r.Close()
+		script = r
+	default:
+		if in, ok := stdin.(*os.File); ok && term.IsTerminal(int(in.Fd())) {
+			shell.interactive = true
+			fmt.Fprintf(stderr, "Connected to %s. Type \\? for help.\n", *database)
+			if err := shell.REPL(ctx, newTerminal(int(in.Fd()), *historyPath)); err != nil {
+				fmt.Fprintf(stderr, "ERROR: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+		script = stdin
+	}
+
+	if err := shell.Run(ctx, script); err != nil && !errors.Is(err, errQuit) {
+		fmt.Fprintf(stderr, "ERROR: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func defaultHistory() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".sqlbridge_history")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	config := writeConfig(t, testConfig)
+	script := filepath.Join(t.TempDir(), "script.sql")
+	require.NoError(t, os.WriteFile(script, []byte("SELECT COUNT(*) AS n FROM users;\n"), 0o644))
+
+	tests := []struct {
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			args:   []string{"-c", config, "-o", "csv", "-e", "SELECT id, name FROM users ORDER BY id DESC"},
+			stdout: "id,name\n2,bar\n1,foo\n",
+		},
+		{
+			args:   []string{"-c", config, "-d", "files", "-o", "json", "-e", "SELECT name FROM users WHERE id = 1"},
+			stdout: "[\n{\"name\":\"foo\"}\n]\n",
+		},
+		{
+			args:   []string{"-c", config, "-o", "csv", script},
+			stdout: "n\n2\n",
+		},
+		{
+			args:   []string{"-c", config, "-o", "csv", "-f", "-"},
+			stdin:  "SELECT name FROM users WHERE id = 2;",
+			stdout: "name\nbar\n",
+		},
+		{
+			args:   []string{"-c", config, "-o", "csv"},
+			stdin:  "SELECT name FROM users WHERE id = 1",
+			stdout: "name\nfoo\n",
+		},
+		{
+			args:   []string{"-c", config, "-e", "SELECT * FROM missing"},
+			code:   1,
+			stderr: "ERROR: ",
+		},
+		{
+			args:   []string{"-c", config, "-d", "missing", "-e", "SELECT 1"},
+			code:   1,
+			stderr: "ERROR: ",
+		},
+		{
+			args:   []string{"-c", filepath.Join(t.TempDir(), "missing.json"), "-e", "SELECT 1"},
+			code:   1,
+			stderr: "ERROR: ",
+		},
+		{
+			args:   []string{"-o", "xml", "-e", "SELECT 1"},
+			code:   2,
+			stderr: "ERROR: ",
+		},
+		{
+			args:   []string{"-unknown"},
+			code:   2,
+			stderr: "Usage: sqlbridge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			require.Equal(t, tt.code, code, stderr.String())
+			require.Equal(t, tt.stdout, stdout.String())
+			require.Contains(t, stderr.String(), tt.stderr)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format is how the rows of a query are printed.
+type Format string
+
+// Result is the columns and rows a statement returned.
+type Result struct {
+	Columns []string
+	Numeric []bool
+	Rows    [][]any
+}
+
+const (
+	FormatTable    Format = "table"
+	FormatCSV      Format = "csv"
+	FormatJSON     Format = "json"
+	FormatVertical Format = "vertical"
+)
+
+// ParseFormat returns the format named name.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatTable, FormatCSV, FormatJSON, FormatVertical:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected table, csv, json or vertical", name)
+}
+
+// Print writes res to w in format.
+func (f Format) Print(w io.Writer, res *Result) error {
+	switch f {
+	case FormatCSV:
+		return printCSV(w, res)
+	case FormatJSON:
+		return printJSON(w, res)
+	case FormatVertical:
+		return printVertical(w, res)
+	}
+	return printTable(w, res)
+}
+
+func printTable(w io.Writer, res *Result) error {
+	widths := make([]int, len(res.Columns))
+	for i, col := range res.Columns {
+		widths[i] = utf8.RuneCountInString(col)
+	}
+	cells := make([][]string, len(res.Rows))
+	for i, row := range res.Rows {
+		cells[i] = make([]string, len(row))
+		for j, value := range row {
+			cells[i][j] = formatValue(value, "NULL")
+			widths[j] = max(widths[j], utf8.RuneCountInString(cells[i][j]))
+		}
+	}
+
+	var b strings.Builder
+	border := func() {
+		for _, width := range widths {
+			b.WriteString("+" + strings.Repeat("-", width+2))
+		}
+		b.WriteString("+\n")
+	}
+	line := func(values []string, numeric func(int) bool) {
+		for i, value := range values {
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value))
+			if numeric(i) {
+				b.WriteString("| " + padding + value + " ")
+			} else {
+				b.WriteString("| " + value + padding + " ")
+			}
+		}
+		b.WriteString("|\n")
+	}
+
+	border()
+	line(res.Columns, func(int) bool { return false })
+	border()
+	for _, row := range cells {
+		line(row, func(i int) bool { return i < len(res.Numeric) && res.Numeric[i] })
+	}
+	if len(cells) > 0 {
+		border()
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func printCSV(w io.Writer, res *Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(res.Columns); err != nil {
+		return err
+	}
+	for _, row := range res.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = formatValue(value, "")
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// printJSON writes the rows as an array of objects, one per line.
+func printJSON(w io.Writer, res *Result) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, row := range res.Rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n{")
+		for j, value := range row {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			key, err := json.Marshal(res.Columns[j])
+			if err != nil {
+				return err
+			}
+			if s, ok := value.([]byte); ok {
+				value = string(s)
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			b.Write(key)
+			b.WriteString(":")
+			b.Write(data)
+		}
+		b.WriteString("}")
+	}
+	if len(res.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func printVertical(w io.Writer, res *Result) error {
+	width := 0
+	for _, col := range res.Columns {
+		width = max(width, utf8.RuneCountInString(col))
+	}
+
+	var b strings.Builder
+	for i, row := range res.Rows {
+		fmt.Fprintf(&b, "%s %d. row %s\n", strings.Repeat("*", 27), i+1, strings.Repeat("*", 27))
+		for j, value := range row {
+			col := res.Columns[j]
+			fmt.Fprintf(&b, "%s%s: %s\n", strings.Repeat(" ", width-utf8.RuneCountInString(col)), col, formatValue(value, "NULL"))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatValue returns the text of a value scanned from a row, or null if it is NULL.
+func formatValue(value any, null string) string {
+	switch v := value.(type) {
+	case nil:
+		return null
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999999")
+	}
+	if data, err := json.Marshal(value); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_Print(t *testing.T) {
+	res := &Result{
+		Columns: []string{"id", "name", "tags"},
+		Numeric: []bool{true, false, false},
+		Rows: [][]any{
+			{int64(1), "foo", map[string]any{"a": true}},
+			{int64(10), nil, []byte("x,y")},
+		},
+	}
+
+	tests := []struct {
+		format Format
+		output string
+	}{
+		{
+			format: FormatTable,
+			output: "" +
+				"+----+------+------------+\n" +
+				"| id | name | tags       |\n" +
+				"+----+------+------------+\n" +
+				"|  1 | foo  | {\"a\":true} |\n" +
+				"| 10 | NULL | x,y        |\n" +
+				"+----+------+------------+\n",
+		},
+		{
+			format: FormatCSV,
+			output: "id,name,tags\n1,foo,\"{\"\"a\"\":true}\"\n10,,\"x,y\"\n",
+		},
+		{
+			format: FormatJSON,
+			output: "[\n{\"id\":1,\"name\":\"foo\",\"tags\":{\"a\":true}},\n{\"id\":10,\"name\":null,\"tags\":\"x,y\"}\n]\n",
+		},
+		{
+			format: FormatVertical,
+			output: "" +
+				"*************************** 1. row ***************************\n" +
+				"  id: 1\n" +
+				"name: foo\n" +
+				"tags: {\"a\":true}\n" +
+				"*************************** 2. row ***************************\n" +
+				"  id: 10\n" +
+				"name: NULL\n" +
+				"tags: x,y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b strings.Builder
+			require.NoError(t, tt.format.Print(&b, res))
+			require.Equal(t, tt.output, b.String())
+		})
+	}
+}
+
+func TestFormat_PrintEmpty(t *testing.T) {
+	res := &Result{Columns: []string{"id"}}
+
+	var b strings.Builder
+	require.NoError(t, FormatTable.Print(&b, res))
+	require.Equal(t, "+----+\n| id |\n+----+\n", b.String())
+
+	b.Reset()
+	require.NoError(t, FormatJSON.Print(&b, res))
+	require.Equal(t, "[]\n", b.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("CSV")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	_, err = ParseFormat("xml")
+	require.Error(t, err)
+}
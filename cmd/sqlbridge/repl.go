@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+	"golang.org/x/term"
+)
+
+// Shell runs statements and meta commands on a connection, printing what they return.
+type Shell struct {
+	conn        *sql.Conn
+	stdout      io.Writer
+	stderr      io.Writer
+	format      Format
+	timing      bool
+	interactive bool
+}
+
+// LineReader reads the lines of input of a REPL.
+type LineReader interface {
+	ReadLine() (string, error)
+	SetPrompt(prompt string)
+}
+
+// splitter accumulates lines of input into statements, which end with a semicolon outside of quotes and comments,
+// and meta commands, which take a line starting with a backslash.
+type splitter struct {
+	buf strings.Builder
+}
+
+// terminal reads lines from a terminal with line editing and history, switching it to raw mode only while reading
+// so that an interrupt still reaches a running statement.
+type terminal struct {
+	fd   int
+	term *term.Terminal
+}
+
+// history keeps the lines read by a terminal, appending them to a file so that they outlive the session.
+type history struct {
+	entries []string
+	path    string
+}
+
+const (
+	prompt      = "sqlbridge> "
+	promptMore  = "        -> "
+	historySize = 1000
+)
+
+var errQuit = errors.New("quit")
+
+const help = `\q               quit
+\?               show this help
+\l               list databases
+\d [table]       list tables, or describe a table
+\c database      connect to another database
+\timing [on|off] toggle printing how long statements take
+\x               toggle vertical output
+\format [name]   show or set the output format: table, csv, json or vertical
+`
+
+// Run runs the statements and meta commands of a script, stopping at the first that fails.
+func (s *Shell) Run(ctx context.Context, script io.Reader) error {
+	var sp splitter
+	scanner := bufio.NewScanner(script)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		for _, stmt := range sp.feed(scanner.Text()) {
+			if err := s.Execute(ctx, stmt); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if stmt := sp.flush(); stmt != "" {
+		return s.Execute(ctx, stmt)
+	}
+	return nil
+}
+
+// REPL reads statements from reader until it ends or \q, printing the errors of those that fail. An interrupt
+// cancels the running statement.
+func (s *Shell) REPL(ctx context.Context, reader LineReader) error {
+	var sp splitter
+	for {
+		if sp.pending() {
+			reader.SetPrompt(promptMore)
+		} else {
+			reader.SetPrompt(prompt)
+		}
+
+		line, err := reader.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, stmt := range sp.feed(line) {
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+			err := s.Execute(ctx, stmt)
+			stop()
+			if errors.Is(err, errQuit) {
+				return nil
+			}
+			if err != nil {
+				fmt.Fprintf(s.stderr, "ERROR: %v\n", err)
+			}
+		}
+	}
+}
+
+// Execute runs a statement or a meta command.
+func (s *Shell) Execute(ctx context.Context, stmt string) error {
+	if !strings.HasPrefix(stmt, `\`) {
+		return s.query(ctx, stmt)
+	}
+
+	fields := strings.Fields(stmt)
+	args := fields[1:]
+	switch fields[0] {
+	case `\q`, `\quit`:
+		return errQuit
+	case `\?`, `\h`, `\help`:
+		_, err := io.WriteString(s.stdout, help)
+		return err
+	case `\l`:
+		return s.query(ctx, "SHOW DATABASES")
+	case `\d`:
+		if len(args) > 0 {
+			return s.query(ctx, "SHOW COLUMNS FROM "+args[0])
+		}
+		return s.query(ctx, "SHOW TABLES")
+	case `\c`, `\connect`:
+		if len(args) == 0 {
+			return errors.New(`\c needs a database`)
+		}
+		return s.query(ctx, "USE "+args[0])
+	case `\timing`:
+		switch {
+		case len(args) == 0:
+			s.timing = !s.timing
+		case args[0] == "on" || args[0] == "off":
+			s.timing = args[0] == "on"
+		default:
+			return fmt.Errorf(`\timing takes on or off, not %q`, args[0])
+		}
+		if s.timing {
+			fmt.Fprintln(s.stderr, "Timing is on.")
+		} else {
+			fmt.Fprintln(s.stderr, "Timing is off.")
+		}
+		return nil
+	case `\x`:
+		if s.format == FormatVertical {
+			s.format = FormatTable
+		} else {
+			s.format = FormatVertical
+		}
+		fmt.Fprintf(s.stderr, "Output format is %s.\n", s.format)
+		return nil
+	case `\format`:
+		if len(args) > 0 {
+			format, err := ParseFormat(args[0])
+			if err != nil {
+				return err
+			}
+			s.format = format
+		}
+		fmt.Fprintf(s.stderr, "Output format is %s.\n", s.format)
+		return nil
+	}
+	return fmt.Errorf(`unknown command %s, try \?`, fields[0])
+}
+
+func (s *Shell) query(ctx context.Context, query string) error {
+	start := time.Now()
+	res, err := s.fetch(ctx, query)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	switch {
+	case len(res.Columns) > 0:
+		if err := s.format.Print(s.stdout, res); err != nil {
+			return err
+		}
+		if s.interactive {
+			fmt.Fprintf(s.stdout, "(%d %s)\n", len(res.Rows), plural(len(res.Rows), "row", "rows"))
+		}
+	case s.interactive:
+		fmt.Fprintln(s.stdout, "OK")
+	}
+	if s.timing {
+		fmt.Fprintf(s.stderr, "Time: %.3f ms\n", float64(elapsed.Microseconds())/1000)
+	}
+	return nil
+}
+
+// fetch runs query, reading all of its rows. Statements the engine does not support succeed without effect, as they
+// do on the servers.
+func (s *Shell) fetch(ctx context.Context, query string) (*Result, error) {
+	rows, err := s.conn.QueryContext(ctx, query)
+	if errors.Is(err, driver.ErrSkip) {
+		return &Result{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Columns: make([]string, len(types)), Numeric: make([]bool, len(types))}
+	for i, typ := range types {
+		res.Columns[i] = typ.Name()
+		t, ok := schema.ParseTypeName(typ.DatabaseTypeName())
+		res.Numeric[i] = ok && (sqltypes.IsIntegral(t) || sqltypes.IsFloat(t) || t == querypb.Type_DECIMAL)
+	}
+
+	for rows.Next() {
+		values := make([]any, len(types))
+		dest := make([]any, len(types))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		res.Rows = append(res.Rows, values)
+	}
+	return res, rows.Err()
+}
+
+// feed adds a line of input, returning the statements and meta commands it completes.
+func (s *splitter) feed(line string) []string {
+	if !s.pending() && strings.HasPrefix(strings.TrimSpace(line), `\`) {
+		return []string{strings.TrimSpace(line)}
+	}
+
+	s.buf.WriteString(line)
+	s.buf.WriteByte('\n')
+	text := s.buf.String()
+
+	var stmts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' && quote != '`' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '-' && strings.HasPrefix(text[i:], "--"), ch == '#':
+			if j := strings.IndexByte(text[i:], '\n'); j >= 0 {
+				i += j
+			}
+		case ch == '/' && strings.HasPrefix(text[i:], "/*"):
+			j := strings.Index(text[i+2:], "*/")
+			if j < 0 {
+				i = len(text)
+			} else {
+				i += j + 3
+			}
+		case ch == ';':
+			if stmt := strings.TrimSpace(text[start:i]); stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+			start = i + 1
+		}
+	}
+
+	s.buf.Reset()
+	if rest := text[start:]; stripComments(rest) != "" {
+		s.buf.WriteString(rest)
+	}
+	return stmts
+}
+
+// pending reports whether a statement was started but not ended.
+func (s *splitter) pending() bool {
+	return s.buf.Len() > 0
+}
+
+// flush returns the statement started but not ended, if any.
+func (s *splitter) flush() string {
+	stmt := strings.TrimSpace(s.buf.String())
+	s.buf.Reset()
+	return stmt
+}
+
+// stripComments removes the whitespace and comments leading text.
+func stripComments(text string) string {
+	for {
+		text = strings.TrimSpace(text)
+		switch {
+		case strings.HasPrefix(text, "--"), strings.HasPrefix(text, "#"):
+			_, text, _ = strings.Cut(text, "\n")
+		case strings.HasPrefix(text, "/*") && strings.Contains(text, "*/"):
+			_, text, _ = strings.Cut(text, "*/")
+		default:
+			return text
+		}
+	}
+}
+
+func newTerminal(fd int, path string) *terminal {
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, prompt)
+	if path != "" {
+		t.History = loadHistory(path)
+	}
+	return &terminal{fd: fd, term: t}
+}
+
+func (t *terminal) ReadLine() (string, error) {
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = term.Restore(t.fd, state) }()
+
+	if width, height, err := term.GetSize(t.fd); err == nil && width > 0 {
+		_ = t.term.SetSize(width, height)
+	}
+	return t.term.ReadLine()
+}
+
+func (t *terminal) SetPrompt(prompt string) {
+	t.term.SetPrompt(prompt)
+}
+
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				h.entries = append(h.entries, line)
+			}
+		}
+		h.entries = h.entries[max(0, len(h.entries)-historySize):]
+	}
+	return h
+}
+
+func (h *history) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > historySize {
+		h.entries = h.entries[1:]
+	}
+
+	if f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err == nil {
+		_, _ = f.WriteString(entry + "\n")
+		_ = f.Close()
+	}
+}
+
+func (h *history) Len() int {
+	return len(h.entries)
+}
+
+func (h *history) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
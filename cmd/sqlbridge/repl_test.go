@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/siyul-park/sqlbridge/driver"
+	"github.com/stretchr/testify/require"
+)
+
+type lines struct {
+	lines   []string
+	prompts []string
+}
+
+var _ LineReader = (*lines)(nil)
+
+func (l *lines) ReadLine() (string, error) {
+	if len(l.lines) == 0 {
+		return "", io.EOF
+	}
+	line := l.lines[0]
+	l.lines = l.lines[1:]
+	return line, nil
+}
+
+func (l *lines) SetPrompt(prompt string) {
+	l.prompts = append(l.prompts, prompt)
+}
+
+func newTestShell(t *testing.T) (*Shell, *strings.Builder, *strings.Builder) {
+	config, err := LoadConfig(writeConfig(t, testConfig))
+	require.NoError(t, err)
+	registry, closer, err := config.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = closer() })
+
+	connector, err := driver.New(driver.WithRegistry(registry)).OpenConnector("app")
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { _ = db.Close() })
+
+	conn, err := db.Conn(context.TODO())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	return &Shell{conn: conn, stdout: stdout, stderr: stderr, format: FormatTable}, stdout, stderr
+}
+
+func TestShell_REPL(t *testing.T) {
+	shell, stdout, stderr := newTestShell(t)
+	shell.interactive = true
+
+	reader := &lines{lines: []string{
+		"SELECT id, name",
+		"FROM users WHERE name = 'a;b'; SELECT COUNT(*)",
+		"FROM users;",
+		`\x`,
+		"SELECT name FROM users WHERE id = 2;",
+		"SELECT missing FROM users;",
+		`\d`,
+		`\q`,
+		"SELECT 1 FROM users;",
+	}}
+	require.NoError(t, shell.REPL(context.TODO(), reader))
+
+	require.Equal(t, []string{prompt, promptMore, promptMore, prompt, prompt, prompt, prompt, prompt}, reader.prompts)
+	require.Equal(t, ""+
+		"+----+------+\n"+
+		"| id | name |\n"+
+		"+----+------+\n"+
+		"(0 rows)\n"+
+		"+----------+\n"+
+		"| COUNT(*) |\n"+
+		"+----------+\n"+
+		"|        2 |\n"+
+		"+----------+\n"+
+		"(1 row)\n"+
+		"*************************** 1. row ***************************\n"+
+		"name: bar\n"+
+		"(1 row)\n"+
+		"*************************** 1. row ***************************\n"+
+		"Tables_in_app: events\n"+
+		"*************************** 2. row ***************************\n"+
+		"Tables_in_app: orders\n"+
+		"*************************** 3. row ***************************\n"+
+		"Tables_in_app: users\n"+
+		"(3 rows)\n", stdout.String())
+	require.Contains(t, stderr.String(), "Output format is vertical.")
+	require.Contains(t, stderr.String(), "ERROR:")
+}
+
+func TestShell_Run(t *testing.T) {
+	tests := []struct {
+		script string
+		output string
+		err    bool
+	}{
+		{
+			script: "SELECT name FROM users ORDER BY id;\nSELECT amount FROM orders",
+			output: "name\nfoo\nbar\namount\n2.5\n",
+		},
+		{
+			script: "-- switch databases\n\\c files\nSELECT name FROM users WHERE id = 1;",
+			output: "name\nfoo\n",
+		},
+		{
+			script: "SET NAMES utf8; SELECT id FROM users WHERE id = 1 /* ; */;",
+			output: "id\n1\n",
+		},
+		{
+			script: "SELECT id FROM users WHERE id = 1; SELECT missing FROM users; SELECT id FROM users WHERE id = 2;",
+			output: "id\n1\n",
+			err:    true,
+		},
+		{
+			script: `\unknown`,
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			shell, stdout, _ := newTestShell(t)
+			shell.format = FormatCSV
+
+			err := shell.Run(context.TODO(), strings.NewReader(tt.script))
+			if tt.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.output, stdout.String())
+		})
+	}
+}
+
+func TestShell_Timing(t *testing.T) {
+	shell, _, stderr := newTestShell(t)
+
+	require.NoError(t, shell.Run(context.TODO(), strings.NewReader("\\timing on\nSELECT id FROM users;")))
+	require.Contains(t, stderr.String(), "Time: ")
+}
+
+func TestSplitter_Feed(t *testing.T) {
+	var sp splitter
+	require.Empty(t, sp.feed("SELECT 'it''s;' AS a, \"b;\" AS `c;`"))
+	require.True(t, sp.pending())
+	require.Equal(t, []string{"SELECT 'it''s;' AS a, \"b;\" AS `c;`\n-- not; the end\nFROM t", "SELECT 2"}, sp.feed("-- not; the end\nFROM t; SELECT 2; SELECT"))
+	require.Equal(t, "SELECT", sp.flush())
+	require.False(t, sp.pending())
+
+	require.Empty(t, sp.feed("-- only a comment"))
+	require.False(t, sp.pending())
+	require.Equal(t, []string{`\d users`}, sp.feed(`\d users`))
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h := loadHistory(path)
+	h.Add("SELECT 1;")
+	h.Add("SELECT 1;")
+	h.Add(" ")
+	h.Add("SELECT 2;")
+
+	h = loadHistory(path)
+	require.Equal(t, 2, h.Len())
+	require.Equal(t, "SELECT 2;", h.At(0))
+	require.Equal(t, "SELECT 1;", h.At(1))
+}
@@ -3,11 +3,8 @@ package driver
 import (
 	"context"
 	"database/sql/driver"
-	"fmt"
-	"strings"
 
 	"github.com/siyul-park/sqlbridge/engine"
-	"github.com/xwb1989/sqlparser"
 )
 
 type connection struct {
@@ -68,29 +65,21 @@ func (c *connection) PrepareContext(ctx context.Context, query string) (driver.S
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		var idx int
-		var b strings.Builder
-		for i := 0; i < len(query); i++ {
-			if query[i] == '?' {
-				idx++
-				b.WriteString(fmt.Sprintf(":v%d", idx))
-			} else {
-				b.WriteByte(query[i])
-			}
-		}
+		query, _ := engine.BindQuestionMarks(query)
 
-		stmt, err := sqlparser.Parse(b.String())
+		stmt, err := engine.Parse(query)
 		if err != nil {
 			return nil, err
 		}
 
+		binds := engine.Bindvars(stmt)
+
 		p, err := c.planner.Plan(stmt)
 		if err != nil {
 			return nil, err
 		}
 
-		binds := sqlparser.GetBindvars(stmt)
-		return &statement{plan: p, binds: binds}, nil
+		return &statement{planner: c.planner, plan: p, binds: binds}, nil
 	}
 }
 
@@ -1,11 +1,14 @@
 package driver
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/go-faker/faker/v4"
 	"github.com/siyul-park/sqlbridge/schema"
 	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
 func TestConnection_Prepare(t *testing.T) {
@@ -28,3 +31,44 @@ func TestConnection_Prepare(t *testing.T) {
 
 	require.NoError(t, stmt.Close())
 }
+
+func TestConnection_PrepareLiteral(t *testing.T) {
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{
+			"t": schema.NewInMemoryTable([]schema.Row{{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1)}}}),
+		}),
+	})
+
+	connector, err := New(WithRegistry(registry)).OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		args     []any
+		expected []string
+	}{
+		{query: "SELECT '?' , ? FROM t", args: []any{"foo"}, expected: []string{"?", "foo"}},
+		{query: "SELECT \"?\", ? /* ? */ FROM t -- ?", args: []any{"foo"}, expected: []string{"?", "foo"}},
+		{query: "SELECT 'a?b' FROM t WHERE id = ?", args: []any{1}, expected: []string{"a?b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			stmt, err := db.Prepare(tt.query)
+			require.NoError(t, err)
+			defer stmt.Close()
+
+			values := make([]string, len(tt.expected))
+			dest := make([]any, len(values))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			require.NoError(t, stmt.QueryRow(tt.args...).Scan(dest...))
+			require.Equal(t, tt.expected, values)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package driver
 
 import (
 	"database/sql/driver"
+	"time"
 
 	"github.com/siyul-park/sqlbridge/engine"
 	"github.com/siyul-park/sqlbridge/schema"
@@ -10,6 +11,9 @@ import (
 type Driver struct {
 	registry   schema.Registry
 	dispatcher *engine.Dispatcher
+	location   *time.Location
+	maxDepth   int
+	parallel   int
 }
 
 type Option func(*Driver)
@@ -25,14 +29,31 @@ func WithDispatcher(dispatcher *engine.Dispatcher) Option {
 	return func(d *Driver) { d.dispatcher = dispatcher }
 }
 
+func WithLocation(loc *time.Location) Option {
+	return func(d *Driver) { d.location = loc }
+}
+
+func WithMaxRecursionDepth(depth int) Option {
+	return func(d *Driver) { d.maxDepth = depth }
+}
+
+func WithParallelism(workers int) Option {
+	return func(d *Driver) { d.parallel = workers }
+}
+
 func New(opts ...Option) *Driver {
 	d := &Driver{
 		registry:   schema.NewInMemoryRegistry(nil),
 		dispatcher: engine.NewDispatcher(engine.WithBuiltIn()),
+		location:   time.UTC,
+		maxDepth:   engine.DefaultMaxRecursionDepth,
 	}
 	for _, opt := range opts {
 		opt(d)
 	}
+	d.registry = schema.NewCompositeRegistry(d.registry, schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		schema.InformationSchemaName: schema.NewInformationSchema(d.registry),
+	}))
 	return d
 }
 
@@ -41,7 +62,7 @@ func (d *Driver) Open(name string) (driver.Conn, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &connection{planner: engine.NewPlanner(catalog, d.dispatcher)}, nil
+	return &connection{planner: engine.NewPlanner(catalog, d.dispatcher, engine.WithRegistry(d.registry), engine.WithDatabase(name), engine.WithLocation(d.location), engine.WithMaxRecursionDepth(d.maxDepth), engine.WithParallelism(d.parallel))}, nil
 }
 
 func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
@@ -1,20 +1,95 @@
 package driver
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"io"
+	"reflect"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
 type rows struct {
-	columns []string
+	columns []schema.Column
 	values  [][]driver.Value
 	offset  int
 }
 
 var _ driver.Rows = (*rows)(nil)
+var _ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+var _ driver.RowsColumnTypeNullable = (*rows)(nil)
+var _ driver.RowsColumnTypeScanType = (*rows)(nil)
+var _ driver.RowsColumnTypeLength = (*rows)(nil)
+var _ driver.RowsColumnTypePrecisionScale = (*rows)(nil)
 
 func (r *rows) Columns() []string {
-	return r.columns
+	columns := make([]string, 0, len(r.columns))
+	for _, col := range r.columns {
+		columns = append(columns, col.Name.Name.String())
+	}
+	return columns
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return schema.TypeName(r.columns[index].Type)
+}
+
+func (r *rows) ColumnTypeNullable(index int) (bool, bool) {
+	return r.columns[index].Nullable, true
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	col := r.columns[index]
+	switch {
+	case sqltypes.IsSigned(col.Type):
+		if col.Nullable {
+			return reflect.TypeOf(sql.NullInt64{})
+		}
+		return reflect.TypeOf(int64(0))
+	case sqltypes.IsUnsigned(col.Type):
+		if col.Nullable {
+			return reflect.TypeOf(sql.Null[uint64]{})
+		}
+		return reflect.TypeOf(uint64(0))
+	case col.Type == querypb.Type_DATETIME, col.Type == querypb.Type_TIMESTAMP:
+		if col.Nullable {
+			return reflect.TypeOf(sql.NullTime{})
+		}
+		return reflect.TypeOf(time.Time{})
+	case sqltypes.IsFloat(col.Type), col.Type == querypb.Type_DECIMAL:
+		if col.Nullable {
+			return reflect.TypeOf(sql.NullFloat64{})
+		}
+		return reflect.TypeOf(float64(0))
+	case col.Type == querypb.Type_BLOB, col.Type == querypb.Type_VARBINARY:
+		return reflect.TypeOf([]byte(nil))
+	case col.Type == querypb.Type_JSON, col.Type == querypb.Type_NULL_TYPE:
+		return reflect.TypeOf((*any)(nil)).Elem()
+	default:
+		if col.Nullable {
+			return reflect.TypeOf(sql.NullString{})
+		}
+		return reflect.TypeOf("")
+	}
+}
+
+func (r *rows) ColumnTypeLength(index int) (int64, bool) {
+	col := r.columns[index]
+	if col.Length > 0 && (sqltypes.IsText(col.Type) || sqltypes.IsBinary(col.Type)) {
+		return col.Length, true
+	}
+	return 0, false
+}
+
+func (r *rows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	col := r.columns[index]
+	if col.Precision > 0 && (sqltypes.IsFloat(col.Type) || col.Type == querypb.Type_DECIMAL) {
+		return col.Precision, col.Scale, true
+	}
+	return 0, 0, false
 }
 
 func (r *rows) Next(dest []driver.Value) error {
@@ -0,0 +1,95 @@
+package driver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestRows_Columns(t *testing.T) {
+	r := &rows{
+		columns: []schema.Column{
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true, Length: 255},
+		},
+	}
+	require.Equal(t, []string{"id", "name"}, r.Columns())
+}
+
+func TestRows_ColumnType(t *testing.T) {
+	r := &rows{
+		columns: []schema.Column{
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true, Length: 255},
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("price")}, Type: sqltypes.Decimal, Precision: 10, Scale: 2},
+		},
+	}
+
+	require.Equal(t, "BIGINT", r.ColumnTypeDatabaseTypeName(0))
+	require.Equal(t, "VARCHAR", r.ColumnTypeDatabaseTypeName(1))
+	require.Equal(t, "DECIMAL", r.ColumnTypeDatabaseTypeName(2))
+
+	nullable, ok := r.ColumnTypeNullable(1)
+	require.True(t, ok)
+	require.True(t, nullable)
+
+	require.Equal(t, reflect.TypeOf(int64(0)), r.ColumnTypeScanType(0))
+	require.Equal(t, reflect.TypeOf(sql.NullString{}), r.ColumnTypeScanType(1))
+
+	length, ok := r.ColumnTypeLength(1)
+	require.True(t, ok)
+	require.Equal(t, int64(255), length)
+
+	_, ok = r.ColumnTypeLength(0)
+	require.False(t, ok)
+
+	precision, scale, ok := r.ColumnTypePrecisionScale(2)
+	require.True(t, ok)
+	require.Equal(t, int64(10), precision)
+	require.Equal(t, int64(2), scale)
+}
+
+func TestRows_ColumnTypeScanType(t *testing.T) {
+	tests := []struct {
+		column   schema.Column
+		expected reflect.Type
+	}{
+		{column: schema.Column{Type: sqltypes.Int64}, expected: reflect.TypeOf(int64(0))},
+		{column: schema.Column{Type: sqltypes.Int64, Nullable: true}, expected: reflect.TypeOf(sql.NullInt64{})},
+		{column: schema.Column{Type: sqltypes.Uint64}, expected: reflect.TypeOf(uint64(0))},
+		{column: schema.Column{Type: sqltypes.Uint64, Nullable: true}, expected: reflect.TypeOf(sql.Null[uint64]{})},
+		{column: schema.Column{Type: sqltypes.Datetime}, expected: reflect.TypeOf(time.Time{})},
+		{column: schema.Column{Type: sqltypes.Timestamp, Nullable: true}, expected: reflect.TypeOf(sql.NullTime{})},
+		{column: schema.Column{Type: sqltypes.Date}, expected: reflect.TypeOf("")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected.String(), func(t *testing.T) {
+			tt.column.Name = &sqlparser.ColName{Name: sqlparser.NewColIdent("c")}
+			r := &rows{columns: []schema.Column{tt.column}}
+			require.Equal(t, tt.expected, r.ColumnTypeScanType(0))
+		})
+	}
+}
+
+func TestRows_Next(t *testing.T) {
+	r := &rows{
+		columns: []schema.Column{
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+		},
+		values: [][]driver.Value{{int64(1)}},
+	}
+
+	dest := make([]driver.Value, 1)
+	require.NoError(t, r.Next(dest))
+	require.Equal(t, []driver.Value{int64(1)}, dest)
+	require.ErrorIs(t, r.Next(dest), io.EOF)
+}
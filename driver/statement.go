@@ -5,16 +5,19 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/siyul-park/sqlbridge/engine"
 	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
 	"github.com/xwb1989/sqlparser/dependency/querypb"
 	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
 type statement struct {
-	plan  engine.Plan
-	binds map[string]struct{}
+	planner *engine.Planner
+	plan    engine.Plan
+	binds   map[string]struct{}
 }
 
 var _ driver.Stmt = (*statement)(nil)
@@ -39,6 +42,7 @@ func (s *statement) ExecContext(ctx context.Context, args []driver.NamedValue) (
 		return nil, err
 	}
 
+	ctx = engine.ContextWithLocation(ctx, s.planner.Location())
 	cursor, err := s.plan.Run(ctx, binds)
 	if err != nil {
 		return nil, err
@@ -58,6 +62,7 @@ func (s *statement) QueryContext(ctx context.Context, args []driver.NamedValue)
 		return nil, err
 	}
 
+	ctx = engine.ContextWithLocation(ctx, s.planner.Location())
 	cursor, err := s.plan.Run(ctx, binds)
 	if err != nil {
 		return nil, err
@@ -68,34 +73,93 @@ func (s *statement) QueryContext(ctx context.Context, args []driver.NamedValue)
 		return nil, err
 	}
 
-	var columns []string
-	var values [][]driver.Value
-	for _, row := range records {
-		idx := map[string]int{}
-		for i, col := range row.Columns {
-			idx[col.Name.String()] = i
-			if !slices.Contains(columns, col.Name.String()) {
-				columns = append(columns, col.Name.String())
+	columns, err := s.plan.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	columns = slices.Clone(columns)
+	names := make([]*sqlparser.ColName, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	if columns == nil {
+		for _, row := range records {
+			for i, col := range row.Columns {
+				if count(names, col.Name) <= count(row.Columns[:i], col.Name) {
+					name := &sqlparser.ColName{Name: col.Name}
+					names = append(names, name)
+					columns = append(columns, schema.Column{
+						Name:     name,
+						Type:     querypb.Type_NULL_TYPE,
+						Nullable: true,
+					})
+				}
 			}
 		}
+	}
 
+	var values [][]driver.Value
+	for _, row := range records {
 		var vals []driver.Value
-		for _, col := range columns {
-			if i, ok := idx[col]; !ok {
+		for j, col := range columns {
+			i := j
+			if len(row.Columns) != len(columns) {
+				i = nth(row.Columns, col.Name.Name, count(names[:j], col.Name.Name))
+			}
+			if i < 0 {
 				vals = append(vals, nil)
-			} else {
-				val, err := schema.Unmarshal(row.Values[i])
-				if err != nil {
-					return nil, err
-				}
-				vals = append(vals, val)
+				continue
 			}
+			if col.Type == querypb.Type_NULL_TYPE && !row.Values[i].IsNull() {
+				columns[j].Type = row.Values[i].Type()
+			}
+			val, err := unmarshal(row.Values[i], s.planner.Location())
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
 		}
 		values = append(values, vals)
 	}
 	return &rows{columns: columns, values: values}, nil
 }
 
+// unmarshal converts value into a driver value, parsing DATETIME and TIMESTAMP values into times in loc.
+func unmarshal(value sqltypes.Value, loc *time.Location) (driver.Value, error) {
+	if value.Type() == sqltypes.Datetime || value.Type() == sqltypes.Timestamp {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999"} {
+			if t, err := time.ParseInLocation(layout, value.ToString(), loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return schema.Unmarshal(value)
+}
+
+// count returns how many of cols are named name.
+func count(cols []*sqlparser.ColName, name sqlparser.ColIdent) int {
+	n := 0
+	for _, col := range cols {
+		if col.Name.Equal(name) {
+			n++
+		}
+	}
+	return n
+}
+
+// nth returns the position of the n-th of cols named name, counting from 0, or -1 if there are not that many.
+func nth(cols []*sqlparser.ColName, name sqlparser.ColIdent, n int) int {
+	for i, col := range cols {
+		if col.Name.Equal(name) {
+			if n == 0 {
+				return i
+			}
+			n--
+		}
+	}
+	return -1
+}
+
 func (s *statement) Close() error {
 	return nil
 }
@@ -103,7 +167,7 @@ func (s *statement) Close() error {
 func (s *statement) named(args []driver.Value) []driver.NamedValue {
 	value := make([]driver.NamedValue, 0, len(args))
 	for i, arg := range args {
-		value = append(value, driver.NamedValue{Name: fmt.Sprintf("v%d", i), Value: arg})
+		value = append(value, driver.NamedValue{Ordinal: i + 1, Value: arg})
 	}
 	return value
 }
@@ -111,7 +175,11 @@ func (s *statement) named(args []driver.Value) []driver.NamedValue {
 func (s *statement) bind(args []driver.NamedValue) (map[string]*querypb.BindVariable, error) {
 	binds := make(map[string]any, len(args))
 	for _, arg := range args {
-		binds[arg.Name] = arg.Value
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("v%d", arg.Ordinal)
+		}
+		binds[name] = arg.Value
 	}
 	return sqltypes.BuildBindVariables(binds)
 }
@@ -1,13 +1,26 @@
 package driver
 
 import (
+	"context"
+	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-faker/faker/v4"
+	"github.com/siyul-park/sqlbridge/engine"
 	"github.com/siyul-park/sqlbridge/schema"
 	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
 func TestStatement_NumInput(t *testing.T) {
@@ -87,3 +100,1156 @@ func TestStatement_Query(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, rows)
 }
+
+func TestStatement_QueryColumnTypes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	name := faker.Word()
+	table := faker.Word()
+
+	tbl := schema.NewInMemoryTable(nil)
+	_ = tbl.SetColumns(ctx, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		table: tbl,
+	})
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		name: catalog,
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector(name)
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM `%s`", table))
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name"}, columns)
+
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	require.Equal(t, "BIGINT", types[0].DatabaseTypeName())
+	require.Equal(t, "VARCHAR", types[1].DatabaseTypeName())
+
+	nullable, ok := types[1].Nullable()
+	require.True(t, ok)
+	require.True(t, nullable)
+}
+
+func TestStatement_QueryScanType(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("created_at")}}
+	events := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NULL, sqltypes.MakeTrusted(sqltypes.Datetime, []byte("2024-01-31 14:00:00"))}},
+	})
+	_ = events.SetColumns(ctx, []schema.Column{
+		{Name: columns[0], Type: sqltypes.Uint64, Nullable: true},
+		{Name: columns[1], Type: sqltypes.Datetime},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"events": events}),
+	})
+
+	connector, err := New(WithRegistry(registry)).OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "SELECT id, created_at FROM events")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+
+	dest := make([]any, len(types))
+	for i, typ := range types {
+		dest[i] = reflect.New(typ.ScanType()).Interface()
+	}
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(dest...))
+	require.Equal(t, &sql.Null[uint64]{}, dest[0])
+	require.Equal(t, time.Date(2024, 1, 31, 14, 0, 0, 0, time.UTC), *dest[1].(*time.Time))
+}
+
+func TestStatement_QueryShow(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	tbl := schema.NewInMemoryTable(nil)
+	_ = tbl.SetColumns(ctx, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true, Length: 32},
+	})
+	_ = tbl.SetIndex(ctx, schema.Index{Name: "id", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{
+			"users":  tbl,
+			"events": schema.NewInMemoryTable(nil),
+		}),
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query   string
+		columns []string
+		values  [][]any
+	}{
+		{
+			query:   "SHOW DATABASES",
+			columns: []string{"Database"},
+			values:  [][]any{{"app"}, {"information_schema"}},
+		},
+		{
+			query:   "SHOW TABLES",
+			columns: []string{"Tables_in_app"},
+			values:  [][]any{{"events"}, {"users"}},
+		},
+		{
+			query:   "SHOW FULL TABLES LIKE 'us%'",
+			columns: []string{"Tables_in_app", "Table_type"},
+			values:  [][]any{{"users", "BASE TABLE"}},
+		},
+		{
+			query:   "SHOW COLUMNS FROM users",
+			columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+			values: [][]any{
+				{"id", "bigint", "NO", "", nil, ""},
+				{"name", "varchar(32)", "YES", "", nil, ""},
+			},
+		},
+		{
+			query:   "SHOW FIELDS IN users WHERE Field = 'name'",
+			columns: []string{"Field", "Type", "Null", "Key", "Default", "Extra"},
+			values: [][]any{
+				{"name", "varchar(32)", "YES", "", nil, ""},
+			},
+		},
+		{
+			query:   "SHOW INDEX FROM users",
+			columns: []string{"Table", "Non_unique", "Key_name", "Seq_in_index", "Column_name"},
+			values:  [][]any{{"users", int64(1), "id", uint64(1), "id"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			columns, err := rows.Columns()
+			require.NoError(t, err)
+			require.Equal(t, tt.columns, columns)
+
+			var values [][]any
+			for rows.Next() {
+				vals := make([]any, len(columns))
+				ptrs := make([]any, len(columns))
+				for i := range vals {
+					ptrs[i] = &vals[i]
+				}
+				require.NoError(t, rows.Scan(ptrs...))
+				values = append(values, vals)
+			}
+			require.Equal(t, tt.values, values)
+		})
+	}
+}
+
+func TestStatement_QueryInformationSchema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{
+			"users":  schema.NewInMemoryTable(nil),
+			"events": schema.NewInMemoryTable(nil),
+		}),
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector(schema.InformationSchemaName)
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "SELECT TABLE_NAME FROM `TABLES` WHERE TABLE_SCHEMA = 'app'")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.Equal(t, []string{"events", "users"}, names)
+}
+
+func TestStatement_QueryCrossCatalog(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	users := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("bar")}},
+	})
+	events := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("user_id")}, {Name: sqlparser.NewColIdent("type")}}, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("login")}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"pg":    schema.NewInMemoryCatalog(map[string]schema.Table{"users": users}),
+		"mongo": schema.NewInMemoryCatalog(map[string]schema.Table{"events": events}),
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector("pg")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	var name, typ string
+	err = db.QueryRowContext(ctx, "SELECT pg.users.name, e.type FROM users JOIN mongo.events AS e ON pg.users.id = e.user_id").Scan(&name, &typ)
+	require.NoError(t, err)
+	require.Equal(t, "bar", name)
+	require.Equal(t, "login", typ)
+
+	_, err = db.ExecContext(ctx, "USE mongo")
+	require.NoError(t, err)
+
+	err = db.QueryRowContext(ctx, "SELECT type FROM events").Scan(&typ)
+	require.NoError(t, err)
+	require.Equal(t, "login", typ)
+
+	_, err = db.ExecContext(ctx, "USE unknown")
+	require.ErrorIs(t, err, schema.ErrCatalogNotFound)
+}
+
+func TestStatement_QueryDuplicateColumns(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}, {Name: sqlparser.NewColIdent("team")}}
+	users := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo"), sqltypes.NewInt64(10)}},
+	})
+	columns = []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+	teams := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(10), sqltypes.NewVarChar("core")}},
+	})
+	described := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(10), sqltypes.NewVarChar("core")}},
+	})
+	_ = described.SetColumns(ctx, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"users": users, "teams": teams, "described": described}),
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		expected []any
+	}{
+		{query: "SELECT u.id, t.id FROM users AS u JOIN teams AS t ON u.team = t.id", expected: []any{int64(1), int64(10)}},
+		{query: "SELECT u.id, d.id FROM users AS u JOIN described AS d ON u.team = d.id", expected: []any{int64(1), int64(10)}},
+		{query: "SELECT u.*, t.name FROM users AS u JOIN teams AS t ON u.team = t.id", expected: []any{int64(1), "foo", int64(10), "core"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			require.True(t, rows.Next())
+			actual := make([]any, len(tt.expected))
+			ptrs := make([]any, len(actual))
+			for i := range actual {
+				ptrs[i] = &actual[i]
+			}
+			require.NoError(t, rows.Scan(ptrs...))
+			for i, v := range actual {
+				if b, ok := v.([]byte); ok {
+					actual[i] = string(b)
+				}
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestStatement_QueryBind(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+	users := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("bar")}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"users": users}),
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query string
+		args  []any
+	}{
+		{query: "SELECT name FROM users WHERE id = ? AND name = ?", args: []any{2, "bar"}},
+		{query: "SELECT name FROM users WHERE id = :id AND name = :name", args: []any{sql.Named("name", "bar"), sql.Named("id", 2)}},
+		{query: "SELECT name FROM users WHERE id = :v1 AND name = :v2", args: []any{2, "bar"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var name string
+			require.NoError(t, db.QueryRowContext(ctx, tt.query, tt.args...).Scan(&name))
+			require.Equal(t, "bar", name)
+		})
+	}
+
+	t.Run("driver.Value", func(t *testing.T) {
+		conn, err := drv.Open("app")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		stmt, err := conn.Prepare("SELECT name FROM users WHERE id = ? AND name = ?")
+		require.NoError(t, err)
+		defer stmt.Close()
+
+		rows, err := stmt.Query([]driver.Value{int64(2), "bar"})
+		require.NoError(t, err)
+		defer rows.Close()
+
+		dest := make([]driver.Value, 1)
+		require.NoError(t, rows.Next(dest))
+		require.Equal(t, io.EOF, rows.Next(dest))
+	})
+}
+
+func TestStatement_QueryView(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	users := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("bar")}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewViewCatalog(schema.NewInMemoryCatalog(map[string]schema.Table{"users": users})),
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "CREATE VIEW named (user_id, user_name) AS SELECT id, name FROM users")
+	require.NoError(t, err)
+
+	var name string
+	err = db.QueryRowContext(ctx, "SELECT user_name FROM named WHERE user_id = ?", 2).Scan(&name)
+	require.NoError(t, err)
+	require.Equal(t, "bar", name)
+
+	var typ string
+	err = db.QueryRowContext(ctx, "SELECT TABLE_TYPE FROM information_schema.`TABLES` WHERE TABLE_NAME = 'named'").Scan(&typ)
+	require.NoError(t, err)
+	require.Equal(t, "VIEW", typ)
+
+	_, err = db.ExecContext(ctx, "DROP VIEW named")
+	require.NoError(t, err)
+
+	_, err = db.QueryContext(ctx, "SELECT * FROM named")
+	require.ErrorIs(t, err, schema.ErrTableNotFound)
+}
+
+func TestStatement_QueryNull(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+	users := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NULL}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"users": users}),
+	})
+
+	drv := New(WithRegistry(registry))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		expected []int64
+	}{
+		{query: "SELECT id FROM users WHERE NOT (name = NULL)", expected: nil},
+		{query: "SELECT id FROM users WHERE name <=> NULL", expected: []int64{2}},
+		{query: "SELECT id FROM users WHERE name <> 'foo'", expected: nil},
+		{query: "SELECT id FROM users WHERE name IS NULL", expected: []int64{2}},
+		{query: "SELECT id FROM users WHERE name NOT IN ('bar', NULL)", expected: nil},
+		{query: "SELECT id FROM users WHERE name = 'foo' OR name IS NULL ORDER BY id", expected: []int64{1, 2}},
+		{query: "SELECT id FROM users WHERE (name = 'bar') IS NOT TRUE ORDER BY id", expected: []int64{1, 2}},
+		{query: "SELECT id FROM users WHERE (name = 'bar') IS FALSE", expected: []int64{1}},
+		{query: "SELECT id FROM users WHERE TRUE ORDER BY id", expected: []int64{1, 2}},
+		{query: "SELECT id FROM users WHERE FALSE", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			var ids []int64
+			for rows.Next() {
+				var id int64
+				require.NoError(t, rows.Scan(&id))
+				ids = append(ids, id)
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestStatement_QueryString(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+	users := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("héllo")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("world")}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"users": users}),
+	})
+
+	drv := New(WithRegistry(registry), WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn(), engine.WithString())))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		expected []sql.NullString
+	}{
+		{query: "SELECT SUBSTR(name, 2) FROM users ORDER BY id", expected: []sql.NullString{{String: "éllo", Valid: true}, {String: "orld", Valid: true}}},
+		{query: "SELECT SUBSTRING(name FROM 2 FOR 2) FROM users ORDER BY id", expected: []sql.NullString{{String: "él", Valid: true}, {String: "or", Valid: true}}},
+		{query: "SELECT MID(name, 2, 9223372036854775807) FROM users WHERE id = 1", expected: []sql.NullString{{String: "éllo", Valid: true}}},
+		{query: "SELECT REPEAT(name, 9223372036854775807) FROM users WHERE id = 1", expected: []sql.NullString{{}}},
+		{query: "SELECT LPAD(name, 3000000000, 'x') FROM users WHERE id = 1", expected: []sql.NullString{{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			var actual []sql.NullString
+			for rows.Next() {
+				var v sql.NullString
+				require.NoError(t, rows.Scan(&v))
+				actual = append(actual, v)
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestStatement_QueryTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("created_at")}}
+	events := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.Datetime, []byte("2024-01-31 14:00:00"))}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.MakeTrusted(sqltypes.Datetime, []byte("2024-01-31 16:00:00"))}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"events": events}),
+	})
+
+	drv := New(WithRegistry(registry), WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn(), engine.WithTime())))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "SELECT DATE_FORMAT(created_at, '%Y-%m-%d') FROM events ORDER BY id", expected: []string{"2024-01-31", "2024-01-31"}},
+		{query: "SELECT DATE_FORMAT(created_at + INTERVAL 1 DAY, '%Y-%m-%d %H') FROM events WHERE id = 1", expected: []string{"2024-02-01 14"}},
+		{query: "SELECT TIMESTAMPDIFF(HOUR, '2024-01-31 00:00:00', created_at) FROM events ORDER BY id", expected: []string{"14", "16"}},
+		{query: "SELECT EXTRACT(YEAR FROM created_at) FROM events WHERE id = 1", expected: []string{"2024"}},
+		{query: "SET time_zone = '+09:00'", expected: nil},
+		{query: "SELECT DATE_FORMAT(created_at, '%Y-%m-%d') FROM events ORDER BY id", expected: []string{"2024-01-31", "2024-02-01"}},
+	}
+
+	for _, tt := range tests {
+		rows, err := conn.QueryContext(ctx, tt.query)
+		require.NoError(t, err, tt.query)
+
+		var actual []string
+		for rows.Next() {
+			var v string
+			require.NoError(t, rows.Scan(&v))
+			actual = append(actual, v)
+		}
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+		require.Equal(t, tt.expected, actual, tt.query)
+	}
+}
+
+func TestStatement_QueryJSON(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("customer")}, {Name: sqlparser.NewColIdent("body")}}
+	orders := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo"), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"items": [{"sku": "a", "qty": 1}, {"sku": "b", "qty": 2}]}`))}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("foo"), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"items": [{"sku": "c", "qty": 3}]}`))}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"orders": orders}),
+	})
+
+	drv := New(WithRegistry(registry), WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn(), engine.WithJSON())))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "SELECT JSON_EXTRACT(body, '$.items[*].sku') FROM orders ORDER BY id", expected: []string{`["a","b"]`, `["c"]`}},
+		{query: "SELECT body->>'$.items[0].sku' FROM orders ORDER BY id", expected: []string{`"a"`, `"c"`}},
+		{query: "SELECT JSON_LENGTH(body, '$.items') FROM orders ORDER BY id", expected: []string{"2", "1"}},
+		{query: "SELECT id FROM orders WHERE JSON_CONTAINS(body, '{\"sku\": \"c\"}', '$.items')", expected: []string{"2"}},
+		{query: "SELECT JSON_OBJECT('id', id, 'skus', JSON_EXTRACT(body, '$.items[*].sku')) FROM orders WHERE id = 2", expected: []string{`{"id":2,"skus":["c"]}`}},
+		{query: "SELECT JSON_ARRAYAGG(id) FROM orders GROUP BY customer", expected: []string{`[1,2]`}},
+		{query: "SELECT JSON_OBJECTAGG(id, JSON_LENGTH(body, '$.items')) FROM orders GROUP BY customer", expected: []string{`{"1":2,"2":1}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			var actual []string
+			for rows.Next() {
+				var v any
+				require.NoError(t, rows.Scan(&v))
+				if b, ok := v.([]byte); ok {
+					v = string(b)
+				}
+				b, err := json.Marshal(v)
+				require.NoError(t, err)
+				actual = append(actual, string(b))
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestStatement_QueryJSONTable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("body")}}
+	orders := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"items": [{"sku": "a", "qty": 1, "tags": ["x", "y"]}, {"sku": "b", "qty": 2}]}`))}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"items": [{"sku": "c", "qty": "many"}]}`))}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NULL}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"orders": orders}),
+	})
+
+	drv := New(WithRegistry(registry), WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn(), engine.WithJSON())))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		expected [][]any
+	}{
+		{
+			query:    "SELECT o.id, jt.sku, jt.qty FROM orders AS o, JSON_TABLE(o.body, '$.items[*]' COLUMNS (sku VARCHAR(10) PATH '$.sku', qty INT PATH '$.qty')) AS jt ORDER BY o.id, jt.sku",
+			expected: [][]any{{int64(1), "a", int64(1)}, {int64(1), "b", int64(2)}, {int64(2), "c", nil}},
+		},
+		{
+			query:    "SELECT o.id, jt.sku FROM orders AS o JOIN JSON_TABLE(o.body, '$.items[*]' COLUMNS (sku VARCHAR(10) PATH '$.sku', qty INT PATH '$.qty' DEFAULT '0' ON ERROR)) AS jt ON jt.qty > 1 ORDER BY o.id",
+			expected: [][]any{{int64(1), "b"}},
+		},
+		{
+			query:    "SELECT o.id, jt.sku FROM orders AS o LEFT JOIN JSON_TABLE(o.body, '$.items[*]' COLUMNS (sku VARCHAR(10) PATH '$.sku')) AS jt ON TRUE ORDER BY o.id, jt.sku",
+			expected: [][]any{{int64(1), "a"}, {int64(1), "b"}, {int64(2), "c"}, {int64(3), nil}},
+		},
+		{
+			query:    "SELECT o.id, jt.sku FROM orders AS o LEFT JOIN JSON_TABLE(o.body, '$.items[*]' COLUMNS (sku VARCHAR(10) PATH '$.sku', qty INT PATH '$.qty' DEFAULT '0' ON ERROR)) AS jt ON jt.qty > 1 ORDER BY o.id",
+			expected: [][]any{{int64(1), "b"}, {int64(2), nil}, {int64(3), nil}},
+		},
+		{
+			query:    "SELECT jt.n, jt.sku, jt.tag FROM orders AS o, JSON_TABLE(o.body, '$.items[*]' COLUMNS (n FOR ORDINALITY, sku VARCHAR(10) PATH '$.sku', NESTED PATH '$.tags[*]' COLUMNS (tag VARCHAR(10) PATH '$'))) AS jt WHERE o.id = 1",
+			expected: [][]any{{uint64(1), "a", "x"}, {uint64(1), "a", "y"}, {uint64(2), "b", nil}},
+		},
+		{
+			query:    "SELECT jt.v FROM JSON_TABLE('[1, 2, 3]', '$[*]' COLUMNS (v INT PATH '$')) AS jt WHERE jt.v >= 2",
+			expected: [][]any{{int64(2)}, {int64(3)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			cols, err := rows.Columns()
+			require.NoError(t, err)
+
+			var actual [][]any
+			for rows.Next() {
+				row := make([]any, len(cols))
+				ptrs := make([]any, len(cols))
+				for i := range row {
+					ptrs[i] = &row[i]
+				}
+				require.NoError(t, rows.Scan(ptrs...))
+				for i, v := range row {
+					if b, ok := v.([]byte); ok {
+						row[i] = string(b)
+					}
+				}
+				actual = append(actual, row)
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("right join", func(t *testing.T) {
+		_, err := db.QueryContext(ctx, "SELECT jt.sku FROM orders AS o RIGHT JOIN JSON_TABLE(o.body, '$.items[*]' COLUMNS (sku VARCHAR(10) PATH '$.sku')) AS jt ON TRUE")
+		require.ErrorIs(t, err, engine.ErrJoinNotSupported)
+	})
+}
+
+func TestStatement_QueryWindow(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("user_id")}, {Name: sqlparser.NewColIdent("amount")}}
+	orders := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(1), sqltypes.NewInt64(10)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewInt64(2), sqltypes.NewInt64(30)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewInt64(1), sqltypes.NewInt64(20)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(4), sqltypes.NewInt64(1), sqltypes.NewInt64(20)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(5), sqltypes.NewInt64(2), sqltypes.NewInt64(5)}},
+	})
+
+	columns = []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("grp")}, {Name: sqlparser.NewColIdent("note")}}
+	notes := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(1), sqltypes.NULL}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewInt64(2), sqltypes.NULL}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(4), sqltypes.NewInt64(2), sqltypes.NULL}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"orders": orders, "notes": notes}),
+	})
+
+	drv := New(WithRegistry(registry), WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn())))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		expected [][]any
+	}{
+		{
+			query:    "SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id) AS rn FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), int64(1)}, {int64(2), int64(1)}, {int64(3), int64(2)}, {int64(4), int64(3)}, {int64(5), int64(2)}},
+		},
+		{
+			query:    "SELECT id, RANK() OVER (ORDER BY amount DESC), DENSE_RANK() OVER (ORDER BY amount DESC) FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), int64(4), int64(3)}, {int64(2), int64(1), int64(1)}, {int64(3), int64(2), int64(2)}, {int64(4), int64(2), int64(2)}, {int64(5), int64(5), int64(4)}},
+		},
+		{
+			query:    "SELECT id, LAG(amount) OVER (PARTITION BY user_id ORDER BY id), LEAD(amount, 1, 0) OVER (PARTITION BY user_id ORDER BY id) FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), nil, int64(20)}, {int64(2), nil, int64(5)}, {int64(3), int64(10), int64(20)}, {int64(4), int64(20), int64(0)}, {int64(5), int64(30), int64(0)}},
+		},
+		{
+			query:    "SELECT id, SUM(amount) OVER (PARTITION BY user_id ORDER BY id) AS running FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), int64(10)}, {int64(2), int64(30)}, {int64(3), int64(30)}, {int64(4), int64(50)}, {int64(5), int64(35)}},
+		},
+		{
+			query:    "SELECT id, SUM(amount) OVER (ORDER BY id ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), int64(10)}, {int64(2), int64(40)}, {int64(3), int64(50)}, {int64(4), int64(40)}, {int64(5), int64(25)}},
+		},
+		{
+			query:    "SELECT id, COUNT(id) OVER (ORDER BY amount RANGE BETWEEN 5 PRECEDING AND 5 FOLLOWING) FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), int64(2)}, {int64(2), int64(1)}, {int64(3), int64(2)}, {int64(4), int64(2)}, {int64(5), int64(2)}},
+		},
+		{
+			query:    "SELECT id, FIRST_VALUE(id) OVER (PARTITION BY user_id ORDER BY amount DESC) FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), int64(3)}, {int64(2), int64(2)}, {int64(3), int64(3)}, {int64(4), int64(3)}, {int64(5), int64(2)}},
+		},
+		{
+			query:    "SELECT user_id, SUM(amount), RANK() OVER (ORDER BY SUM(amount) DESC) FROM orders GROUP BY user_id ORDER BY user_id",
+			expected: [][]any{{int64(1), int64(50), int64(1)}, {int64(2), int64(35), int64(2)}},
+		},
+		{
+			query:    "SELECT id, SUM(amount) OVER (ORDER BY id ROWS BETWEEN CURRENT ROW AND 9223372036854775807 FOLLOWING) FROM orders ORDER BY id",
+			expected: [][]any{{int64(1), int64(85)}, {int64(2), int64(75)}, {int64(3), int64(45)}, {int64(4), int64(25)}, {int64(5), int64(5)}},
+		},
+		{
+			query:    "SELECT id, COUNT(*) OVER (), COUNT(note) OVER () FROM notes ORDER BY id",
+			expected: [][]any{{int64(1), int64(4), int64(1)}, {int64(2), int64(4), int64(1)}, {int64(3), int64(4), int64(1)}, {int64(4), int64(4), int64(1)}},
+		},
+		{
+			query:    "SELECT id, COUNT(*) OVER (PARTITION BY grp), COUNT(*) OVER (PARTITION BY grp ORDER BY id) FROM notes ORDER BY id",
+			expected: [][]any{{int64(1), int64(2), int64(1)}, {int64(2), int64(2), int64(2)}, {int64(3), int64(2), int64(1)}, {int64(4), int64(2), int64(2)}},
+		},
+		{
+			query:    "SELECT *, ROW_NUMBER() OVER (ORDER BY id DESC) FROM orders ORDER BY ROW_NUMBER() OVER (ORDER BY id DESC) LIMIT 2",
+			expected: [][]any{{int64(5), int64(2), int64(5), int64(1)}, {int64(4), int64(1), int64(20), int64(2)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			cols, err := rows.Columns()
+			require.NoError(t, err)
+
+			var actual [][]any
+			for rows.Next() {
+				row := make([]any, len(cols))
+				ptrs := make([]any, len(cols))
+				for i := range row {
+					ptrs[i] = &row[i]
+				}
+				require.NoError(t, rows.Scan(ptrs...))
+				actual = append(actual, row)
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("negative offset", func(t *testing.T) {
+		_, err := db.QueryContext(ctx, "SELECT LAG(amount, -1) OVER (ORDER BY id) FROM orders")
+		require.ErrorContains(t, err, "invalid argument for LAG: -1")
+	})
+}
+
+func TestStatement_QueryAggregate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("user_id")}, {Name: sqlparser.NewColIdent("amount")}}
+	orders := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(1), sqltypes.NewInt64(10)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewInt64(2), sqltypes.NewInt64(30)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewInt64(1), sqltypes.NewInt64(20)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(4), sqltypes.NewInt64(1), sqltypes.NewInt64(20)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(5), sqltypes.NewInt64(2), sqltypes.NewInt64(5)}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"orders": orders}),
+	})
+
+	dispatcher := engine.NewDispatcher(engine.WithBuiltIn(), engine.WithAggregate("product", func() engine.AggregateState {
+		return &product{value: 1}
+	}))
+
+	tests := []struct {
+		query    string
+		expected [][]any
+	}{
+		{
+			query:    "SELECT user_id, COUNT(*), SUM(amount), MAX(amount) FROM orders GROUP BY user_id ORDER BY user_id",
+			expected: [][]any{{int64(1), int64(3), int64(50), int64(20)}, {int64(2), int64(2), int64(35), int64(30)}},
+		},
+		{
+			query:    "SELECT COUNT(*), AVG(amount) FROM orders",
+			expected: [][]any{{int64(5), float64(17)}},
+		},
+		{
+			query:    "SELECT COUNT(*), SUM(amount) FROM orders WHERE amount > 100",
+			expected: [][]any{{int64(0), nil}},
+		},
+		{
+			query:    "SELECT COUNT(DISTINCT amount), SUM(amount) FILTER (WHERE amount > 10) FROM orders",
+			expected: [][]any{{int64(4), int64(70)}},
+		},
+		{
+			query:    "SELECT user_id, GROUP_CONCAT(DISTINCT amount ORDER BY amount DESC SEPARATOR '/') FROM orders GROUP BY user_id ORDER BY user_id",
+			expected: [][]any{{int64(1), "20/10"}, {int64(2), "30/5"}},
+		},
+		{
+			query:    "SELECT user_id, COUNT(*) AS n FROM orders GROUP BY user_id HAVING SUM(amount) > 40",
+			expected: [][]any{{int64(1), int64(3)}},
+		},
+		{
+			query:    "SELECT user_id FROM orders GROUP BY user_id ORDER BY SUM(amount)",
+			expected: [][]any{{int64(2)}, {int64(1)}},
+		},
+		{
+			query:    "SELECT user_id, PRODUCT(amount) FROM orders GROUP BY user_id ORDER BY user_id",
+			expected: [][]any{{int64(1), int64(4000)}, {int64(2), int64(150)}},
+		},
+	}
+
+	for _, parallelism := range []int{0, 4} {
+		drv := New(WithRegistry(registry), WithDispatcher(dispatcher), WithParallelism(parallelism))
+
+		connector, err := drv.OpenConnector("app")
+		require.NoError(t, err)
+
+		db := sql.OpenDB(connector)
+		defer db.Close()
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/%d", tt.query, parallelism), func(t *testing.T) {
+				rows, err := db.QueryContext(ctx, tt.query)
+				require.NoError(t, err)
+				defer rows.Close()
+
+				cols, err := rows.Columns()
+				require.NoError(t, err)
+
+				var actual [][]any
+				for rows.Next() {
+					row := make([]any, len(cols))
+					ptrs := make([]any, len(cols))
+					for i := range row {
+						ptrs[i] = &row[i]
+					}
+					require.NoError(t, rows.Scan(ptrs...))
+					actual = append(actual, row)
+				}
+				require.NoError(t, rows.Err())
+				require.Equal(t, tt.expected, actual)
+			})
+		}
+	}
+}
+
+func TestStatement_QueryWith(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("manager_id")}, {Name: sqlparser.NewColIdent("name")}}
+	employees := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NULL, sqltypes.NewVarChar("ceo")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewInt64(1), sqltypes.NewVarChar("cto")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewInt64(2), sqltypes.NewVarChar("dev")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(4), sqltypes.NewInt64(1), sqltypes.NewVarChar("cfo")}},
+	})
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"employees": employees}),
+	})
+
+	drv := New(WithRegistry(registry), WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn())))
+
+	connector, err := drv.OpenConnector("app")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		args     []any
+		expected [][]any
+	}{
+		{
+			query:    "WITH managers AS (SELECT * FROM employees WHERE manager_id = 1) SELECT name FROM managers ORDER BY id",
+			expected: [][]any{{"cto"}, {"cfo"}},
+		},
+		{
+			query:    "WITH m(mid) AS (SELECT id FROM employees WHERE id < ?) SELECT a.mid AS lo, b.mid AS hi FROM m AS a JOIN m AS b ON a.mid < b.mid",
+			args:     []any{3},
+			expected: [][]any{{int64(1), int64(2)}},
+		},
+		{
+			query:    "WITH a AS (SELECT id AS n FROM employees WHERE id = 1), b AS (SELECT n + 1 AS n FROM a) SELECT * FROM a UNION ALL SELECT * FROM b",
+			expected: [][]any{{int64(1)}, {int64(2)}},
+		},
+		{
+			query:    "WITH RECURSIVE seq(n) AS (SELECT id FROM employees WHERE id = 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 5) SELECT n FROM seq",
+			expected: [][]any{{int64(1)}, {int64(2)}, {int64(3)}, {int64(4)}, {int64(5)}},
+		},
+		{
+			query:    "WITH RECURSIVE chain AS (SELECT id, name, 0 AS depth FROM employees WHERE manager_id IS NULL UNION ALL SELECT e.id, e.name, c.depth + 1 FROM employees AS e JOIN chain AS c ON e.manager_id = c.id) SELECT name, depth FROM chain ORDER BY id",
+			expected: [][]any{{"ceo", int64(0)}, {"cto", int64(1)}, {"dev", int64(2)}, {"cfo", int64(1)}},
+		},
+		{
+			query:    "WITH RECURSIVE r(n) AS (SELECT id FROM employees WHERE id = 1 UNION SELECT n FROM r) SELECT n FROM r",
+			expected: [][]any{{int64(1)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query, tt.args...)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			cols, err := rows.Columns()
+			require.NoError(t, err)
+
+			var actual [][]any
+			for rows.Next() {
+				row := make([]any, len(cols))
+				ptrs := make([]any, len(cols))
+				for i := range row {
+					ptrs[i] = &row[i]
+				}
+				require.NoError(t, rows.Scan(ptrs...))
+				actual = append(actual, row)
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	connector, err = New(WithRegistry(registry), WithMaxRecursionDepth(10)).OpenConnector("app")
+	require.NoError(t, err)
+
+	db = sql.OpenDB(connector)
+	defer db.Close()
+
+	_, err = db.QueryContext(ctx, "WITH RECURSIVE seq(n) AS (SELECT id FROM employees WHERE id = 1 UNION ALL SELECT n + 1 FROM seq) SELECT n FROM seq")
+	require.ErrorIs(t, err, engine.ErrRecursionDepth)
+}
+
+func TestStatement_QueryCSV(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name,joined_at\n1,foo,2024-01-02\n2,bar,2024-03-04\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.tsv"), []byte("id\tuser_id\tamount\n1\t1\t10.5\n2\t1\t\n3\t2\t4\n"), 0o644))
+
+	catalog, err := schema.NewCSVCatalog(dir)
+	require.NoError(t, err)
+
+	drv := New(WithRegistry(schema.NewInMemoryRegistry(map[string]schema.Catalog{"files": catalog})))
+
+	connector, err := drv.OpenConnector("files")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tests := []struct {
+		query    string
+		expected [][]any
+	}{
+		{
+			query:    "SELECT name FROM users WHERE id > 1",
+			expected: [][]any{{"bar"}},
+		},
+		{
+			query:    "SELECT u.name, SUM(o.amount) FROM users AS u JOIN orders AS o ON o.user_id = u.id GROUP BY u.name ORDER BY u.name",
+			expected: [][]any{{"bar", float64(4)}, {"foo", float64(10.5)}},
+		},
+		{
+			query:    "SELECT COUNT(*) FROM orders WHERE amount IS NULL",
+			expected: [][]any{{int64(1)}},
+		},
+		{
+			query:    "SELECT COUNT(*) FROM users",
+			expected: [][]any{{int64(2)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rows, err := db.QueryContext(ctx, tt.query)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			cols, err := rows.Columns()
+			require.NoError(t, err)
+
+			var actual [][]any
+			for rows.Next() {
+				row := make([]any, len(cols))
+				ptrs := make([]any, len(cols))
+				for i := range row {
+					ptrs[i] = &row[i]
+				}
+				require.NoError(t, rows.Scan(ptrs...))
+				actual = append(actual, row)
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestStatement_QueryJSONRecords(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	events := schema.NewJSONTable(func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(`[{"id": 1, "type": "click"}, {"id": 2, "meta": {"x": 1}}]`)), nil
+	})
+
+	drv := New(WithRegistry(schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"api": schema.NewInMemoryCatalog(map[string]schema.Table{"events": events}),
+	})))
+
+	connector, err := drv.OpenConnector("api")
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "SELECT * FROM events ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "type", "meta"}, cols)
+
+	var actual [][]any
+	for rows.Next() {
+		row := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		require.NoError(t, rows.Scan(ptrs...))
+		actual = append(actual, row)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, [][]any{
+		{int64(1), "click", nil},
+		{int64(2), nil, map[string]any{"x": float64(1)}},
+	}, actual)
+}
+
+type product struct {
+	value int64
+}
+
+func (p *product) Accumulate(args []engine.Value) error {
+	v, err := engine.ToInt(args[0])
+	p.value *= v
+	return err
+}
+
+func (p *product) Merge(other engine.AggregateState) error {
+	p.value *= other.(*product).value
+	return nil
+}
+
+func (p *product) Finalize() (engine.Value, error) {
+	return engine.NewInt64(p.value), nil
+}
@@ -10,8 +10,9 @@ import (
 )
 
 type AliasPlan struct {
-	Input Plan
-	As    sqlparser.TableIdent
+	Input     Plan
+	As        sqlparser.TableIdent
+	Qualifier sqlparser.TableIdent
 }
 
 var _ Plan = (*AliasPlan)(nil)
@@ -27,7 +28,7 @@ func (p *AliasPlan) Run(ctx context.Context, binds map[string]*querypb.BindVaria
 			columns = append(columns, &sqlparser.ColName{
 				Metadata:  col.Metadata,
 				Name:      col.Name,
-				Qualifier: sqlparser.TableName{Qualifier: col.Qualifier.Qualifier, Name: p.As},
+				Qualifier: p.qualify(col.Qualifier),
 			})
 		}
 		row.Columns = columns
@@ -35,6 +36,23 @@ func (p *AliasPlan) Run(ctx context.Context, binds map[string]*querypb.BindVaria
 	}), nil
 }
 
+func (p *AliasPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	columns, err := p.Input.Schema(ctx)
+	if err != nil || columns == nil {
+		return nil, err
+	}
+	aliases := make([]schema.Column, 0, len(columns))
+	for _, col := range columns {
+		col.Name = &sqlparser.ColName{
+			Metadata:  col.Name.Metadata,
+			Name:      col.Name.Name,
+			Qualifier: p.qualify(col.Name.Qualifier),
+		}
+		aliases = append(aliases, col)
+	}
+	return aliases, nil
+}
+
 func (p *AliasPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	if cont, err := f(p); !cont || err != nil {
 		return cont, err
@@ -42,6 +60,13 @@ func (p *AliasPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	return p.Input.Walk(f)
 }
 
+func (p *AliasPlan) qualify(table sqlparser.TableName) sqlparser.TableName {
+	if p.Qualifier.IsEmpty() {
+		return sqlparser.TableName{Qualifier: table.Qualifier, Name: p.As}
+	}
+	return sqlparser.TableName{Qualifier: p.Qualifier, Name: p.As}
+}
+
 func (p *AliasPlan) String() string {
 	return fmt.Sprintf("AliasPlan(%s, %s)", p.Input.String(), sqlparser.String(p.As))
 }
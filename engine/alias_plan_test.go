@@ -60,6 +60,23 @@ func TestAliasPlan_Run(t *testing.T) {
 				},
 			}),
 		},
+		{
+			plan: &AliasPlan{
+				Input:     &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t2")}},
+				As:        sqlparser.NewTableIdent("t2"),
+				Qualifier: sqlparser.NewTableIdent("c1"),
+			},
+			cursor: schema.NewInMemoryCursor([]schema.Row{
+				{
+					Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Qualifier: sqlparser.NewTableIdent("c1"), Name: sqlparser.NewTableIdent("t2")}}, {Name: sqlparser.NewColIdent("name"), Qualifier: sqlparser.TableName{Qualifier: sqlparser.NewTableIdent("c1"), Name: sqlparser.NewTableIdent("t2")}}},
+					Values:  []sqltypes.Value{sqltypes.NewInt64(0), sqltypes.MakeTrusted(sqltypes.VarChar, []byte("bar"))},
+				},
+				{
+					Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Qualifier: sqlparser.NewTableIdent("c1"), Name: sqlparser.NewTableIdent("t2")}}, {Name: sqlparser.NewColIdent("name"), Qualifier: sqlparser.TableName{Qualifier: sqlparser.NewTableIdent("c1"), Name: sqlparser.NewTableIdent("t2")}}},
+					Values:  []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.VarChar, []byte("bar"))},
+				},
+			}),
+		},
 	}
 
 	for _, tt := range tests {
@@ -79,3 +96,28 @@ func TestAliasPlan_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestAliasPlan_Schema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(ctx, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		"t1": t1,
+	})
+
+	plan := &AliasPlan{
+		Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+		As:    sqlparser.NewTableIdent("t"),
+	}
+
+	cols, err := plan.Schema(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}}, Type: sqltypes.Int64},
+	}, cols)
+}
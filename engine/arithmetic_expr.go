@@ -26,6 +26,16 @@ func (e *AddExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	if l, ok := left.(*Interval); ok {
+		return addInterval(ctx, right, l)
+	}
+	if r, ok := right.(*Interval); ok {
+		return addInterval(ctx, left, r)
+	}
+
 	left, right, err = Promote(left, right)
 	if err != nil {
 		return nil, err
@@ -99,6 +109,13 @@ func (e *SubExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	if r, ok := right.(*Interval); ok {
+		return addInterval(ctx, left, r.Scale(-1))
+	}
+
 	left, right, err = Promote(left, right)
 	if err != nil {
 		return nil, err
@@ -166,6 +183,10 @@ func (e *MulExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
 	left, right, err = Promote(left, right)
 	if err != nil {
 		return nil, err
@@ -216,6 +237,7 @@ func (e *MulExpr) String() string {
 	return e.Left.String() + " * " + e.Right.String()
 }
 
+// DivExpr divides Left by Right, yielding NULL if Right is zero, as MySQL does.
 type DivExpr struct {
 	Left  Expr
 	Right Expr
@@ -233,6 +255,10 @@ func (e *DivExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
 	left, right, err = Promote(left, right)
 	if err != nil {
 		return nil, err
@@ -245,7 +271,7 @@ func (e *DivExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 			return nil, fmt.Errorf("cannot divide Int64 by %T", right)
 		}
 		if r.Int() == 0 {
-			return nil, fmt.Errorf("division by zero")
+			return nil, nil
 		}
 		return NewInt64(l.Int() / r.Int()), nil
 	case *Uint64:
@@ -254,7 +280,7 @@ func (e *DivExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 			return nil, fmt.Errorf("cannot divide Uint64 by %T", right)
 		}
 		if r.Uint() == 0 {
-			return nil, fmt.Errorf("division by zero")
+			return nil, nil
 		}
 		return NewUint64(l.Uint() / r.Uint()), nil
 	case *Float64:
@@ -263,7 +289,7 @@ func (e *DivExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 			return nil, fmt.Errorf("cannot divide Float64 by %T", right)
 		}
 		if r.Float() == 0 {
-			return nil, fmt.Errorf("division by zero")
+			return nil, nil
 		}
 		return NewFloat64(l.Float() / r.Float()), nil
 	default:
@@ -308,34 +334,7 @@ func (e *ModExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 	if err != nil {
 		return nil, err
 	}
-
-	left, right, err = Promote(left, right)
-	if err != nil {
-		return nil, err
-	}
-
-	switch l := left.(type) {
-	case *Int64:
-		r, ok := right.(*Int64)
-		if !ok {
-			return nil, fmt.Errorf("cannot mod Int64 with %T", right)
-		}
-		return NewInt64(l.Int() % r.Int()), nil
-	case *Uint64:
-		r, ok := right.(*Uint64)
-		if !ok {
-			return nil, fmt.Errorf("cannot mod Uint64 with %T", right)
-		}
-		return NewUint64(l.Uint() % r.Uint()), nil
-	case *Float64:
-		r, ok := right.(*Float64)
-		if !ok {
-			return nil, fmt.Errorf("cannot mod Float64 with %T", right)
-		}
-		return NewFloat64(math.Mod(l.Float(), r.Float())), nil
-	default:
-		return nil, fmt.Errorf("cannot mod %T with %T", left, right)
-	}
+	return mod(left, right)
 }
 
 func (e *ModExpr) Walk(f func(Expr) (bool, error)) (bool, error) {
@@ -515,3 +514,47 @@ func (e *BitNotExpr) Copy() Expr {
 func (e *BitNotExpr) String() string {
 	return fmt.Sprintf("BitNot(%s)", e.Input)
 }
+
+// mod returns the remainder of left divided by right, or NULL if right is zero, as MySQL does.
+func mod(left, right Value) (Value, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	left, right, err := Promote(left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := left.(type) {
+	case *Int64:
+		r, ok := right.(*Int64)
+		if !ok {
+			return nil, fmt.Errorf("cannot mod Int64 with %T", right)
+		}
+		if r.Int() == 0 {
+			return nil, nil
+		}
+		return NewInt64(l.Int() % r.Int()), nil
+	case *Uint64:
+		r, ok := right.(*Uint64)
+		if !ok {
+			return nil, fmt.Errorf("cannot mod Uint64 with %T", right)
+		}
+		if r.Uint() == 0 {
+			return nil, nil
+		}
+		return NewUint64(l.Uint() % r.Uint()), nil
+	case *Float64:
+		r, ok := right.(*Float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot mod Float64 with %T", right)
+		}
+		if r.Float() == 0 {
+			return nil, nil
+		}
+		return NewFloat64(math.Mod(l.Float(), r.Float())), nil
+	default:
+		return nil, fmt.Errorf("cannot mod %T with %T", left, right)
+	}
+}
@@ -54,6 +54,16 @@ func TestAddExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewVarChar(" apples")},
 			expected: NewVarChar("7 apples"),
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewVarChar("2024-01-31")},
+			right:    &IntervalExpr{Input: &LiteralExpr{Value: sqltypes.NewInt64(1)}, Unit: "month"},
+			expected: &DateTime{data: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), date: true},
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(1)},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,6 +115,11 @@ func TestSubExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewFloat64(0.5)},
 			expected: NewFloat64(-2.0),
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewVarChar("2024-03-01 12:00:00")},
+			right:    &IntervalExpr{Input: &LiteralExpr{Value: sqltypes.NewInt64(1)}, Unit: "day"},
+			expected: NewDateTime(time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)),
+		},
 	}
 
 	for _, test := range tests {
@@ -156,6 +171,11 @@ func TestMulExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewFloat64(3.5)},
 			expected: NewFloat64(-7.0),
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(2)},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -197,6 +217,26 @@ func TestDivExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewInt64(3)},
 			expected: NewFloat64(3.0),
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewInt64(10)},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewUint64(10)},
+			right:    &LiteralExpr{Value: sqltypes.NewUint64(0)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewFloat64(10)},
+			right:    &LiteralExpr{Value: sqltypes.NewFloat64(0)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(2)},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -233,6 +273,21 @@ func TestModExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewInt64(2)},
 			expected: NewInt64(1),
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewInt64(5)},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewFloat64(5.5)},
+			right:    &LiteralExpr{Value: sqltypes.NewFloat64(0)},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+)
+
+// Binder resolves column references against the columns a plan produces.
+type Binder struct {
+	columns []schema.Column
+}
+
+var (
+	ErrUnknownColumn   = errors.New("unknown column")
+	ErrAmbiguousColumn = errors.New("ambiguous column")
+	ErrNotGrouped      = errors.New("column is not in group by")
+)
+
+func NewBinder(columns []schema.Column) *Binder {
+	return &Binder{columns: columns}
+}
+
+// Bind qualifies every column reference in expr with the table it resolves to, and checks the arguments of each
+// call against the types of the columns. Nothing is checked when the columns are unknown.
+func (b *Binder) Bind(expr Expr) error {
+	if b.columns == nil || expr == nil {
+		return nil
+	}
+	_, err := expr.Walk(func(expr Expr) (bool, error) {
+		switch e := expr.(type) {
+		case *IdenticalExpr:
+			return false, nil
+		case *CallExpr:
+			if err := e.check(b.columns); err != nil {
+				return false, err
+			}
+		case *ColumnExpr:
+			col, err := b.Resolve(e.Value)
+			if err != nil {
+				return false, err
+			}
+			e.Value = &sqlparser.ColName{
+				Metadata:  e.Value.Metadata,
+				Name:      e.Value.Name,
+				Qualifier: col.Name.Qualifier,
+			}
+		}
+		return true, nil
+	})
+	return err
+}
+
+// Resolve returns the single column name refers to.
+func (b *Binder) Resolve(name *sqlparser.ColName) (schema.Column, error) {
+	var matches []schema.Column
+	for _, col := range b.columns {
+		if schema.Match(col.Name, name) {
+			matches = append(matches, col)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return schema.Column{}, fmt.Errorf("%w: %s", ErrUnknownColumn, sqlparser.String(name))
+	case 1:
+		return matches[0], nil
+	default:
+		return schema.Column{}, fmt.Errorf("%w: %s", ErrAmbiguousColumn, sqlparser.String(name))
+	}
+}
+
+// Grouped reports an error if expr references a column outside of an aggregate that is not one of the group keys.
+func (b *Binder) Grouped(expr Expr, keys []Expr) error {
+	for _, key := range keys {
+		if key.String() == expr.String() {
+			return nil
+		}
+	}
+
+	_, err := expr.Walk(func(expr Expr) (bool, error) {
+		switch e := expr.(type) {
+		case *CallExpr:
+			return !e.Aggregate, nil
+		case *ColumnExpr:
+			for _, key := range keys {
+				grouped := false
+				_, _ = key.Walk(func(expr Expr) (bool, error) {
+					if k, ok := expr.(*ColumnExpr); ok && (schema.Match(k.Value, e.Value) || schema.Match(e.Value, k.Value)) {
+						grouped = true
+					}
+					return !grouped, nil
+				})
+				if grouped {
+					return true, nil
+				}
+			}
+			return false, fmt.Errorf("%w: %s", ErrNotGrouped, sqlparser.String(e.Value))
+		}
+		return true, nil
+	})
+	return err
+}
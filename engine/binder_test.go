@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+func TestBinder_Bind(t *testing.T) {
+	columns := []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}}, Type: querypb.Type_VARCHAR},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("t2")}}, Type: querypb.Type_INT64},
+	}
+
+	tests := []struct {
+		columns []schema.Column
+		expr    Expr
+		bound   Expr
+		err     error
+	}{
+		{
+			columns: columns,
+			expr:    &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}},
+			bound:   &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("name"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}}},
+		},
+		{
+			columns: columns,
+			expr:    &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("t2")}}},
+			bound:   &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("t2")}}},
+		},
+		{
+			columns: columns,
+			expr:    &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}},
+			err:     ErrAmbiguousColumn,
+		},
+		{
+			columns: columns,
+			expr:    &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("age")}},
+			err:     ErrUnknownColumn,
+		},
+		{
+			columns: nil,
+			expr:    &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("age")}},
+			bound:   &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("age")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr.String(), func(t *testing.T) {
+			err := NewBinder(tt.columns).Bind(tt.expr)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.bound, tt.expr)
+			}
+		})
+	}
+}
+
+func TestBinder_Grouped(t *testing.T) {
+	id := &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}
+	name := &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}}
+
+	tests := []struct {
+		expr Expr
+		keys []Expr
+		err  error
+	}{
+		{
+			expr: id,
+			keys: []Expr{id},
+		},
+		{
+			expr: &CallExpr{Name: sqlparser.NewColIdent("count"), Input: name, Aggregate: true},
+			keys: []Expr{id},
+		},
+		{
+			expr: &AddExpr{Left: id, Right: &CallExpr{Name: sqlparser.NewColIdent("max"), Input: name, Aggregate: true}},
+			keys: []Expr{id},
+		},
+		{
+			expr: name,
+			keys: []Expr{id},
+			err:  ErrNotGrouped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr.String(), func(t *testing.T) {
+			err := NewBinder(nil).Grouped(tt.expr, tt.keys)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
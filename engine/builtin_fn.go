@@ -2,6 +2,7 @@ package engine
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"strings"
 
@@ -9,144 +10,98 @@ import (
 )
 
 var (
-	BitAnd     = sqlparser.NewColIdent("bit_and")
-	BitOr      = sqlparser.NewColIdent("bit_or")
-	BitXor     = sqlparser.NewColIdent("bit_xor")
-	Substr     = sqlparser.NewColIdent("substr")
-	ConcatWs   = sqlparser.NewColIdent("concat_ws")
-	NVL        = sqlparser.NewColIdent("nvl")
-	NVL2       = sqlparser.NewColIdent("nvl2")
-	Count      = sqlparser.NewColIdent("count")
-	Avg        = sqlparser.NewColIdent("avg")
-	Max        = sqlparser.NewColIdent("max")
-	Min        = sqlparser.NewColIdent("min")
-	Sum        = sqlparser.NewColIdent("sum")
-	Std        = sqlparser.NewColIdent("std")
-	Stddev     = sqlparser.NewColIdent("stddev")
-	StddevSamp = sqlparser.NewColIdent("stddev_samp")
-	StddevPop  = sqlparser.NewColIdent("stddev_pop")
-	Variance   = sqlparser.NewColIdent("variance")
-	VarSamp    = sqlparser.NewColIdent("var_samp")
-	VarPop     = sqlparser.NewColIdent("var_pop")
+	BitAnd      = sqlparser.NewColIdent("bit_and")
+	BitOr       = sqlparser.NewColIdent("bit_or")
+	BitXor      = sqlparser.NewColIdent("bit_xor")
+	Substr      = sqlparser.NewColIdent("substr")
+	ConcatWs    = sqlparser.NewColIdent("concat_ws")
+	NVL         = sqlparser.NewColIdent("nvl")
+	NVL2        = sqlparser.NewColIdent("nvl2")
+	Count       = sqlparser.NewColIdent("count")
+	Avg         = sqlparser.NewColIdent("avg")
+	Max         = sqlparser.NewColIdent("max")
+	Min         = sqlparser.NewColIdent("min")
+	Sum         = sqlparser.NewColIdent("sum")
+	Std         = sqlparser.NewColIdent("std")
+	Stddev      = sqlparser.NewColIdent("stddev")
+	StddevSamp  = sqlparser.NewColIdent("stddev_samp")
+	StddevPop   = sqlparser.NewColIdent("stddev_pop")
+	Variance    = sqlparser.NewColIdent("variance")
+	VarSamp     = sqlparser.NewColIdent("var_samp")
+	VarPop      = sqlparser.NewColIdent("var_pop")
+	GroupConcat = sqlparser.NewColIdent("group_concat")
 )
 
+var ErrAggregateState = errors.New("cannot merge states of different aggregates")
+
 func WithBuiltIn() DispatchOption {
 	return func(d *Dispatcher) {
-		d.fns[BitAnd.String()] = NewBitAnd()
-		d.fns[BitOr.String()] = NewBitOr()
-		d.fns[BitXor.String()] = NewBitXor()
-		d.fns[Substr.String()] = NewSubstr()
-		d.fns[ConcatWs.String()] = NewConcatWs()
-		d.fns[NVL.String()] = NewNVL()
-		d.fns[NVL2.String()] = NewNVL2()
-		d.fns[Count.String()] = NewCount()
-		d.fns[Avg.String()] = NewAvg()
-		d.fns[Max.String()] = NewMax()
-		d.fns[Min.String()] = NewMin()
-		d.fns[Sum.String()] = NewSum()
-		d.fns[Std.String()] = NewStdDevSamp()
-		d.fns[Stddev.String()] = NewStdDevSamp()
-		d.fns[StddevSamp.String()] = NewStdDevSamp()
-		d.fns[StddevPop.String()] = NewStddevPop()
-		d.fns[Variance.String()] = NewVarSamp()
-		d.fns[VarSamp.String()] = NewVarSamp()
-		d.fns[VarPop.String()] = NewVarPop()
-	}
-}
-
-func NewBitAnd() Function {
-	return func(args []Value) (Value, error) {
-		var value uint64
-		for i, arg := range args {
-			val, err := ToUint(arg)
-			if err != nil {
-				return nil, err
-			}
-			if i == 0 {
-				value = val
-			} else {
-				value &= val
-			}
-		}
-		return NewUint64(value), nil
+		d.specs[Substr.String()] = builtin(2, 3, withoutContext(NewSubstr()))
+		d.specs[ConcatWs.String()] = builtin(2, -1, withoutContext(NewConcatWs()))
+		d.specs[NVL.String()] = builtin(2, 2, withoutContext(NewNVL()))
+		d.specs[NVL2.String()] = builtin(3, 3, withoutContext(NewNVL2()))
+		d.aggFns[BitAnd.String()] = NewBitAnd()
+		d.aggFns[BitOr.String()] = NewBitOr()
+		d.aggFns[BitXor.String()] = NewBitXor()
+		d.aggFns[Count.String()] = NewCount()
+		d.aggFns[Avg.String()] = NewAvg()
+		d.aggFns[Max.String()] = NewMax()
+		d.aggFns[Min.String()] = NewMin()
+		d.aggFns[Sum.String()] = NewSum()
+		d.aggFns[Std.String()] = NewStdDevSamp()
+		d.aggFns[Stddev.String()] = NewStdDevSamp()
+		d.aggFns[StddevSamp.String()] = NewStdDevSamp()
+		d.aggFns[StddevPop.String()] = NewStddevPop()
+		d.aggFns[Variance.String()] = NewVarSamp()
+		d.aggFns[VarSamp.String()] = NewVarSamp()
+		d.aggFns[VarPop.String()] = NewVarPop()
+		d.aggFns[GroupConcat.String()] = NewGroupConcat()
 	}
 }
 
-func NewBitOr() Function {
+func NewSubstr() Function {
 	return func(args []Value) (Value, error) {
-		var value uint64
-		for i, arg := range args {
-			val, err := ToUint(arg)
-			if err != nil {
-				return nil, err
-			}
-			if i == 0 {
-				value = val
-			} else {
-				value |= val
-			}
+		if len(args) < 2 || len(args) > 3 {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
 		}
-		return NewUint64(value), nil
-	}
-}
-
-func NewBitXor() Function {
-	return func(args []Value) (Value, error) {
-		var value uint64
-		for i, arg := range args {
-			val, err := ToUint(arg)
-			if err != nil {
-				return nil, err
-			}
-			if i == 0 {
-				value = val
-			} else {
-				value ^= val
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
 			}
 		}
-		return NewUint64(value), nil
-	}
-}
-
-func NewSubstr() Function {
-	return func(args []Value) (Value, error) {
-		if len(args) == 0 {
-			return NewVarChar(""), nil
-		}
 
 		str, err := ToString(args[0])
 		if err != nil {
 			return nil, err
 		}
+		runes := []rune(str)
 
-		offset, length := int64(0), int64(len(str))
-		if len(args) > 1 {
-			if offset, err = ToInt(args[1]); err != nil {
-				return nil, err
-			}
+		pos, err := ToInt(args[1])
+		if err != nil {
+			return nil, err
 		}
+		length := int64(len(runes))
 		if len(args) > 2 {
 			if length, err = ToInt(args[2]); err != nil {
 				return nil, err
 			}
 		}
 
-		offset = (offset + int64(len(str))) % int64(len(str))
-		if offset < 0 {
-			offset += int64(len(str))
+		if pos < 0 {
+			pos += int64(len(runes)) + 1
 		}
-		length = max(0, length)
-		if offset+length > int64(len(str)) {
-			length = int64(len(str)) - offset
+		if pos < 1 || pos > int64(len(runes)) || length < 1 {
+			return NewVarChar(""), nil
 		}
-		return NewVarChar(str[offset : offset+length]), nil
+		length = min(length, int64(len(runes))-pos+1)
+		return NewVarChar(string(runes[pos-1 : pos-1+length])), nil
 	}
 }
 
 func NewConcatWs() Function {
 	return func(args []Value) (Value, error) {
-		if len(args) == 0 {
-			return NewVarChar(""), nil
+		if len(args) == 0 || args[0] == nil {
+			return nil, nil
 		}
 
 		sep, err := ToString(args[0])
@@ -156,6 +111,9 @@ func NewConcatWs() Function {
 
 		var elems []string
 		for _, arg := range args[1:] {
+			if arg == nil {
+				continue
+			}
 			elem, err := ToString(arg)
 			if err != nil {
 				return nil, err
@@ -184,199 +142,345 @@ func NewNVL2() Function {
 	})
 }
 
-func NewCount() Function {
-	return func(args []Value) (Value, error) {
-		return NewInt64(int64(len(args))), nil
+func NewBitAnd() AggregateFunction {
+	return func() AggregateState {
+		return &bitState{value: math.MaxUint64, op: func(a, b uint64) uint64 { return a & b }}
 	}
 }
 
-func NewAvg() Function {
-	return func(args []Value) (Value, error) {
-		var sum float64
-		var count int64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sum += val
-			count++
-		}
-		if count == 0 {
-			return nil, nil
-		}
-		return NewFloat64(sum / float64(count)), nil
+func NewBitOr() AggregateFunction {
+	return func() AggregateState {
+		return &bitState{op: func(a, b uint64) uint64 { return a | b }}
 	}
 }
 
-func NewMax() Function {
-	return func(args []Value) (Value, error) {
-		if len(args) == 0 {
-			return NewInt64(0), nil
-		}
-		var value int64
-		for i, arg := range args {
-			val, err := ToInt(arg)
-			if err != nil {
-				return nil, err
+func NewBitXor() AggregateFunction {
+	return func() AggregateState {
+		return &bitState{op: func(a, b uint64) uint64 { return a ^ b }}
+	}
+}
+
+// NewCount counts the rows whose arguments are all non-NULL, which is every row when there are none.
+func NewCount() AggregateFunction {
+	return func() AggregateState { return &countState{} }
+}
+
+func NewAvg() AggregateFunction {
+	return func() AggregateState {
+		return &varianceState{result: func(s *varianceState) Value { return NewFloat64(s.mean) }}
+	}
+}
+
+func NewMax() AggregateFunction {
+	return func() AggregateState { return &extremeState{sign: 1} }
+}
+
+func NewMin() AggregateFunction {
+	return func() AggregateState { return &extremeState{sign: -1} }
+}
+
+// NewSum adds up integers as an integer and any other numbers as a float.
+func NewSum() AggregateFunction {
+	return func() AggregateState { return &sumState{} }
+}
+
+func NewStdDevSamp() AggregateFunction {
+	return NewVariance(1, math.Sqrt)
+}
+
+func NewStddevPop() AggregateFunction {
+	return NewVariance(0, math.Sqrt)
+}
+
+func NewVarSamp() AggregateFunction {
+	return NewVariance(1, nil)
+}
+
+func NewVarPop() AggregateFunction {
+	return NewVariance(0, nil)
+}
+
+// NewVariance returns the variance of the values with ddof delta degrees of freedom, mapped by fn if not nil.
+// It is NULL for at most ddof values.
+func NewVariance(ddof int64, fn func(float64) float64) AggregateFunction {
+	return func() AggregateState {
+		return &varianceState{result: func(s *varianceState) Value {
+			if s.count <= ddof {
+				return nil
 			}
-			if i == 0 || val > value {
-				value = val
+			v := s.m2 / float64(s.count-ddof)
+			if fn != nil {
+				v = fn(v)
 			}
+			return NewFloat64(v)
+		}}
+	}
+}
+
+// NewGroupConcat joins the concatenated arguments of the rows with its first argument as the separator,
+// skipping rows with a NULL argument.
+func NewGroupConcat() AggregateFunction {
+	return func() AggregateState { return &groupConcatState{} }
+}
+
+type bitState struct {
+	value uint64
+	op    func(a, b uint64) uint64
+}
+
+func (s *bitState) Accumulate(args []Value) error {
+	arg, err := singleArg(args)
+	if err != nil || arg == nil {
+		return err
+	}
+	val, err := ToUint(arg)
+	if err != nil {
+		return err
+	}
+	s.value = s.op(s.value, val)
+	return nil
+}
+
+func (s *bitState) Merge(other AggregateState) error {
+	o, ok := other.(*bitState)
+	if !ok {
+		return ErrAggregateState
+	}
+	s.value = s.op(s.value, o.value)
+	return nil
+}
+
+func (s *bitState) Finalize() (Value, error) {
+	return NewUint64(s.value), nil
+}
+
+type countState struct {
+	count int64
+}
+
+func (s *countState) Accumulate(args []Value) error {
+	for _, arg := range args {
+		if arg == nil {
+			return nil
 		}
-		return NewInt64(value), nil
 	}
+	s.count++
+	return nil
 }
 
-func NewMin() Function {
-	return func(args []Value) (Value, error) {
-		if len(args) == 0 {
-			return NewInt64(0), nil
+func (s *countState) Merge(other AggregateState) error {
+	o, ok := other.(*countState)
+	if !ok {
+		return ErrAggregateState
+	}
+	s.count += o.count
+	return nil
+}
+
+func (s *countState) Finalize() (Value, error) {
+	return NewInt64(s.count), nil
+}
+
+type sumState struct {
+	count int64
+	ints  int64
+	float float64
+	real  bool
+}
+
+func (s *sumState) Accumulate(args []Value) error {
+	arg, err := singleArg(args)
+	if err != nil || arg == nil {
+		return err
+	}
+	switch arg.(type) {
+	case *Int64, *Uint64:
+		val, err := ToInt(arg)
+		if err != nil {
+			return err
 		}
-		var value int64
-		for i, arg := range args {
-			val, err := ToInt(arg)
-			if err != nil {
-				return nil, err
-			}
-			if i == 0 || val < value {
-				value = val
-			}
+		s.ints += val
+	default:
+		val, err := ToFloat(arg)
+		if err != nil {
+			return err
 		}
-		return NewInt64(value), nil
+		s.float += val
+		s.real = true
 	}
+	s.count++
+	return nil
 }
 
-func NewSum() Function {
-	return func(args []Value) (Value, error) {
-		var value int64
-		for _, arg := range args {
-			val, err := ToInt(arg)
-			if err != nil {
-				return nil, err
-			}
-			value += val
-		}
-		return NewInt64(value), nil
+func (s *sumState) Merge(other AggregateState) error {
+	o, ok := other.(*sumState)
+	if !ok {
+		return ErrAggregateState
 	}
+	s.count += o.count
+	s.ints += o.ints
+	s.float += o.float
+	s.real = s.real || o.real
+	return nil
 }
 
-func NewStdDevSamp() Function {
-	return func(args []Value) (Value, error) {
-		var sum float64
-		var count int64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sum += val
-			count++
-		}
+func (s *sumState) Finalize() (Value, error) {
+	switch {
+	case s.count == 0:
+		return nil, nil
+	case s.real:
+		return NewFloat64(float64(s.ints) + s.float), nil
+	default:
+		return NewInt64(s.ints), nil
+	}
+}
 
-		if count < 2 {
-			return nil, errors.New("at least two values are required")
-		}
+type extremeState struct {
+	value Value
+	sign  int
+}
 
-		mean := sum / float64(count)
-		var sds float64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sds += (val - mean) * (val - mean)
-		}
-		return NewFloat64(math.Sqrt(sds / float64(count-1))), nil
+func (s *extremeState) Accumulate(args []Value) error {
+	arg, err := singleArg(args)
+	if err != nil || arg == nil {
+		return err
 	}
+	return s.fold(arg)
 }
 
-func NewStddevPop() Function {
-	return func(args []Value) (Value, error) {
-		var sum float64
-		var count int64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sum += val
-			count++
-		}
+func (s *extremeState) Merge(other AggregateState) error {
+	o, ok := other.(*extremeState)
+	if !ok {
+		return ErrAggregateState
+	}
+	if o.value == nil {
+		return nil
+	}
+	return s.fold(o.value)
+}
 
-		if count == 0 {
-			return nil, errors.New("no values provided")
-		}
+func (s *extremeState) Finalize() (Value, error) {
+	return s.value, nil
+}
 
-		mean := sum / float64(count)
-		var sds float64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sds += (val - mean) * (val - mean)
-		}
-		return NewFloat64(math.Sqrt(sds / float64(count))), nil
+func (s *extremeState) fold(val Value) error {
+	if s.value == nil {
+		s.value = val
+		return nil
+	}
+	cmp, err := Compare(val, s.value)
+	if err != nil {
+		return err
 	}
+	if cmp*s.sign > 0 {
+		s.value = val
+	}
+	return nil
 }
 
-func NewVarPop() Function {
-	return func(args []Value) (Value, error) {
-		var sum float64
-		var count int64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sum += val
-			count++
-		}
+// varianceState keeps the running mean and sum of squared deviations of the values, which merge by the
+// parallel algorithm of Chan et al.
+type varianceState struct {
+	count  int64
+	mean   float64
+	m2     float64
+	result func(*varianceState) Value
+}
 
-		if count == 0 {
-			return nil, errors.New("no values provided")
-		}
+func (s *varianceState) Accumulate(args []Value) error {
+	arg, err := singleArg(args)
+	if err != nil || arg == nil {
+		return err
+	}
+	val, err := ToFloat(arg)
+	if err != nil {
+		return err
+	}
+	s.count++
+	delta := val - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (val - s.mean)
+	return nil
+}
 
-		mean := sum / float64(count)
-		var sds float64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sds += (val - mean) * (val - mean)
-		}
-		return NewFloat64(sds / float64(count)), nil
+func (s *varianceState) Merge(other AggregateState) error {
+	o, ok := other.(*varianceState)
+	if !ok {
+		return ErrAggregateState
+	}
+	if o.count == 0 {
+		return nil
 	}
+	count := s.count + o.count
+	delta := o.mean - s.mean
+	s.m2 += o.m2 + delta*delta*float64(s.count)*float64(o.count)/float64(count)
+	s.mean += delta * float64(o.count) / float64(count)
+	s.count = count
+	return nil
 }
 
-func NewVarSamp() Function {
-	return func(args []Value) (Value, error) {
-		var sum float64
-		var count int64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sum += val
-			count++
-		}
+func (s *varianceState) Finalize() (Value, error) {
+	if s.count == 0 {
+		return nil, nil
+	}
+	return s.result(s), nil
+}
 
-		if count < 2 {
-			return nil, errors.New("at least two values are required")
-		}
+type groupConcatState struct {
+	sep   *string
+	elems []string
+}
 
-		mean := sum / float64(count)
-		var sds float64
-		for _, arg := range args {
-			val, err := ToFloat(arg)
-			if err != nil {
-				return nil, err
-			}
-			sds += (val - mean) * (val - mean)
+func (s *groupConcatState) Accumulate(args []Value) error {
+	if len(args) < 2 {
+		return fmt.Errorf("incorrect number of arguments: %d", len(args))
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil
 		}
-		return NewFloat64(sds / float64(count-1)), nil
 	}
+	if s.sep == nil {
+		sep, err := ToString(args[0])
+		if err != nil {
+			return err
+		}
+		s.sep = &sep
+	}
+	var elem strings.Builder
+	for _, arg := range args[1:] {
+		str, err := ToString(arg)
+		if err != nil {
+			return err
+		}
+		elem.WriteString(str)
+	}
+	s.elems = append(s.elems, elem.String())
+	return nil
+}
+
+func (s *groupConcatState) Merge(other AggregateState) error {
+	o, ok := other.(*groupConcatState)
+	if !ok {
+		return ErrAggregateState
+	}
+	if s.sep == nil {
+		s.sep = o.sep
+	}
+	s.elems = append(s.elems, o.elems...)
+	return nil
+}
+
+func (s *groupConcatState) Finalize() (Value, error) {
+	if s.sep == nil {
+		return nil, nil
+	}
+	return NewVarChar(strings.Join(s.elems, *s.sep)), nil
+}
+
+func singleArg(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+	}
+	return args[0], nil
 }
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/siyul-park/sqlbridge/schema"
 	"github.com/xwb1989/sqlparser"
@@ -15,37 +16,65 @@ type CallExpr struct {
 	Name       sqlparser.ColIdent
 	Input      Expr
 	Aggregate  bool
+	call       ContextFunction
+	once       sync.Once
 }
 
 var _ Expr = (*CallExpr)(nil)
 
 func (e *CallExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*querypb.BindVariable) (Value, error) {
-	name := e.Name.String()
-	if !e.Qualifier.IsEmpty() {
-		name = fmt.Sprintf("%s.%s", e.Qualifier.String(), name)
+	name := e.FuncName()
+	if e.Aggregate {
+		rows := row.Children
+		if len(rows) == 0 {
+			rows = []schema.Row{row}
+		}
+		args := make([][]Value, 0, len(rows))
+		for _, r := range rows {
+			vals, err := e.args(ctx, r, binds)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, vals)
+		}
+		return e.Dispatcher.DispatchAggregate(name, args)
 	}
 
-	var rows []schema.Row
-	if e.Aggregate && len(row.Children) > 0 {
-		rows = row.Children
-	} else {
-		rows = []schema.Row{row}
+	args, err := e.args(ctx, row, binds)
+	if err != nil {
+		return nil, err
 	}
+	if spec, ok := e.Dispatcher.Function(name); ok && spec.New != nil {
+		e.once.Do(func() { e.call = spec.New() })
+		spec.Call = e.call
+		return spec.dispatch(ctx, name, args)
+	}
+	return e.Dispatcher.DispatchContext(ctx, name, args)
+}
 
-	args := make([]Value, 0, len(rows))
-	for _, r := range rows {
-		val, err := e.Input.Eval(ctx, r, binds)
-		if err != nil {
-			return nil, err
-		}
-		switch val := val.(type) {
-		case *Tuple:
-			args = append(args, val.Values()...)
-		default:
-			args = append(args, val)
-		}
+// check reports an error if the arguments of the call do not fit the spec of its function, reading the types
+// of the columns they reference from columns.
+func (e *CallExpr) check(columns []schema.Column) error {
+	if e.Aggregate || e.Dispatcher == nil {
+		return nil
+	}
+	spread, ok := e.Input.(*SpreadExpr)
+	if !ok {
+		return nil
 	}
-	return e.Dispatcher.Dispatch(name, args)
+	spec, ok := e.Dispatcher.Function(e.FuncName())
+	if !ok {
+		return nil
+	}
+
+	args := make([]querypb.Type, 0, len(spread.Exprs))
+	for _, arg := range spread.Exprs {
+		args = append(args, TypeOf(arg, columns).Type)
+	}
+	if _, err := spec.Bind(args); err != nil {
+		return fmt.Errorf("%w in the call to function %s", err, e.FuncName())
+	}
+	return nil
 }
 
 func (e *CallExpr) Walk(f func(Expr) (bool, error)) (bool, error) {
@@ -66,9 +95,25 @@ func (e *CallExpr) Copy() Expr {
 }
 
 func (e *CallExpr) String() string {
+	return fmt.Sprintf("Call(%s, %s)", e.FuncName(), e.Input.String())
+}
+
+// FuncName returns the name the function is dispatched by, qualified if the call is.
+func (e *CallExpr) FuncName() string {
 	name := e.Name.String()
 	if !e.Qualifier.IsEmpty() {
 		name = fmt.Sprintf("%s.%s", e.Qualifier.String(), name)
 	}
-	return fmt.Sprintf("Call(%s, %s)", name, e.Input.String())
+	return name
+}
+
+func (e *CallExpr) args(ctx context.Context, row schema.Row, binds map[string]*querypb.BindVariable) ([]Value, error) {
+	val, err := e.Input.Eval(ctx, row, binds)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := val.(*Tuple); ok {
+		return t.Values(), nil
+	}
+	return []Value{val}, nil
 }
@@ -27,6 +27,9 @@ func (e *EqualExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
 	cmp, err := Compare(left, right)
 	if err != nil {
 		return nil, err
@@ -55,6 +58,54 @@ func (e *EqualExpr) String() string {
 	return fmt.Sprintf("Equal(%s, %s)", e.Left.String(), e.Right.String())
 }
 
+type NullSafeEqualExpr struct {
+	Left  Expr
+	Right Expr
+}
+
+var _ Expr = (*NullSafeEqualExpr)(nil)
+
+func (e *NullSafeEqualExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*querypb.BindVariable) (Value, error) {
+	left, err := e.Left.Eval(ctx, row, binds)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Right.Eval(ctx, row, binds)
+	if err != nil {
+		return nil, err
+	}
+
+	if left == nil || right == nil {
+		return NewBool(left == nil && right == nil), nil
+	}
+	cmp, err := Compare(left, right)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(cmp == 0), nil
+}
+
+func (e *NullSafeEqualExpr) Walk(f func(Expr) (bool, error)) (bool, error) {
+	if cont, err := f(e); !cont || err != nil {
+		return cont, err
+	}
+	if cont, err := e.Left.Walk(f); !cont || err != nil {
+		return cont, err
+	}
+	return e.Right.Walk(f)
+}
+
+func (e *NullSafeEqualExpr) Copy() Expr {
+	return &NullSafeEqualExpr{
+		Left:  e.Left.Copy(),
+		Right: e.Right.Copy(),
+	}
+}
+
+func (e *NullSafeEqualExpr) String() string {
+	return fmt.Sprintf("NullSafeEqual(%s, %s)", e.Left.String(), e.Right.String())
+}
+
 type GreaterThanExpr struct {
 	Left  Expr
 	Right Expr
@@ -72,6 +123,9 @@ func (e *GreaterThanExpr) Eval(ctx context.Context, row schema.Row, binds map[st
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
 	cmp, err := Compare(left, right)
 	if err != nil {
 		return nil, err
@@ -117,6 +171,9 @@ func (e *GreaterThanOrEqualExpr) Eval(ctx context.Context, row schema.Row, binds
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
 	cmp, err := Compare(left, right)
 	if err != nil {
 		return nil, err
@@ -162,6 +219,9 @@ func (e *LessThanExpr) Eval(ctx context.Context, row schema.Row, binds map[strin
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
 	cmp, err := Compare(left, right)
 	if err != nil {
 		return nil, err
@@ -207,6 +267,9 @@ func (e *LessThanOrEqualExpr) Eval(ctx context.Context, row schema.Row, binds ma
 		return nil, err
 	}
 
+	if left == nil || right == nil {
+		return nil, nil
+	}
 	cmp, err := Compare(left, right)
 	if err != nil {
 		return nil, err
@@ -252,21 +315,26 @@ func (e *InExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*que
 		return nil, err
 	}
 
+	values := []Value{right}
 	if r, ok := right.(*Tuple); ok {
-		for _, val := range r.Values() {
-			if cmp, err := Compare(left, val); err != nil {
-				return nil, err
-			} else if cmp == 0 {
-				return True, nil
-			}
+		values = r.Values()
+	}
+
+	unknown := left == nil
+	for _, val := range values {
+		if left == nil || val == nil {
+			unknown = true
+			continue
 		}
-	} else {
-		if cmp, err := Compare(left, right); err != nil {
+		if cmp, err := Compare(left, val); err != nil {
 			return nil, err
 		} else if cmp == 0 {
 			return True, nil
 		}
 	}
+	if unknown {
+		return nil, nil
+	}
 	return False, nil
 }
 
@@ -307,6 +375,9 @@ func (e *MatchExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*
 	if err != nil {
 		return nil, err
 	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
 
 	var columns []string
 	switch left := left.(type) {
@@ -376,6 +447,9 @@ func (e *LikeExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*q
 	if err != nil {
 		return nil, err
 	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
 
 	lhs, err := ToString(left)
 	if err != nil {
@@ -441,6 +515,9 @@ func (e *RegexpExpr) Eval(ctx context.Context, row schema.Row, binds map[string]
 	if err != nil {
 		return nil, err
 	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
 
 	lhs, err := ToString(left)
 	if err != nil {
@@ -495,6 +572,9 @@ func (e *IdenticalExpr) Eval(ctx context.Context, row schema.Row, binds map[stri
 		values := val.Values()
 		for i := 0; i < len(values); i++ {
 			for j := i + 1; j < len(values); j++ {
+				if values[i] == nil || values[j] == nil {
+					return nil, nil
+				}
 				if cmp, err := Compare(values[i], values[j]); err != nil {
 					return nil, err
 				} else if cmp != 0 {
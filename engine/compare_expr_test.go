@@ -39,6 +39,16 @@ func TestEqualExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewVarChar("bar")},
 			expected: False,
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(1)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -51,6 +61,52 @@ func TestEqualExpr_Eval(t *testing.T) {
 	}
 }
 
+func TestNullSafeEqualExpr_Eval(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	tests := []struct {
+		left     Expr
+		right    Expr
+		expected Value
+	}{
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewInt64(42)},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(42)},
+			expected: True,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewInt64(42)},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(7)},
+			expected: False,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(42)},
+			expected: False,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewInt64(42)},
+			right:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: False,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: True,
+		},
+	}
+
+	for _, tt := range tests {
+		expr := &NullSafeEqualExpr{Left: tt.left, Right: tt.right}
+		t.Run(expr.String(), func(t *testing.T) {
+			actual, err := expr.Eval(ctx, schema.Row{}, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
 func TestGreaterThanExpr_Eval(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
 	defer cancel()
@@ -396,6 +452,35 @@ func TestInExpr_Eval(t *testing.T) {
 			right:    &TupleExpr{},
 			expected: False,
 		},
+		{
+			left: &LiteralExpr{Value: sqltypes.NewInt64(3)},
+			right: &TupleExpr{
+				Exprs: []Expr{
+					&LiteralExpr{Value: sqltypes.NULL},
+					&LiteralExpr{Value: sqltypes.NewInt64(3)},
+				},
+			},
+			expected: True,
+		},
+		{
+			left: &LiteralExpr{Value: sqltypes.NewInt64(2)},
+			right: &TupleExpr{
+				Exprs: []Expr{
+					&LiteralExpr{Value: sqltypes.NULL},
+					&LiteralExpr{Value: sqltypes.NewInt64(3)},
+				},
+			},
+			expected: nil,
+		},
+		{
+			left: &LiteralExpr{Value: sqltypes.NULL},
+			right: &TupleExpr{
+				Exprs: []Expr{
+					&LiteralExpr{Value: sqltypes.NewInt64(3)},
+				},
+			},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -525,6 +610,11 @@ func TestLikeExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewVarChar("_")},
 			expected: False,
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewVarChar("%")},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -22,13 +22,25 @@ func (e *ConvertExpr) Eval(ctx context.Context, row schema.Row, binds map[string
 	if err != nil {
 		return nil, err
 	}
-	val, err := ToSQL(input, querypb.Type(querypb.Type_value[strings.ToUpper(e.Type.Type)]))
+	val, err := ToSQL(input, convertType(e.Type))
 	if err != nil {
 		return nil, err
 	}
 	return FromSQL(val)
 }
 
+// convertType returns the type a CAST or CONVERT to typ results in. SIGNED and UNSIGNED are the 64-bit integers.
+func convertType(typ *sqlparser.ConvertType) querypb.Type {
+	switch name := strings.ToUpper(typ.Type); name {
+	case "SIGNED":
+		return querypb.Type_INT64
+	case "UNSIGNED":
+		return querypb.Type_UINT64
+	default:
+		return querypb.Type(querypb.Type_value[name])
+	}
+}
+
 func (e *ConvertExpr) Walk(f func(Expr) (bool, error)) (bool, error) {
 	if cont, err := f(e); !cont || err != nil {
 		return cont, err
@@ -8,6 +8,7 @@ import (
 	"github.com/siyul-park/sqlbridge/schema"
 	"github.com/stretchr/testify/require"
 	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
 	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
@@ -40,6 +41,16 @@ func TestConvertExpr_Eval(t *testing.T) {
 			typ:      &sqlparser.ConvertType{Type: "VARBINARY"},
 			expected: NewVarBinary([]byte("true")),
 		},
+		{
+			input:    &LiteralExpr{Value: sqltypes.NewVarChar("-7")},
+			typ:      &sqlparser.ConvertType{Type: "signed"},
+			expected: NewInt64(-7),
+		},
+		{
+			input:    &LiteralExpr{Value: sqltypes.NewInt64(7)},
+			typ:      &sqlparser.ConvertType{Type: "unsigned"},
+			expected: NewUint64(7),
+		},
 	}
 
 	for _, tt := range tests {
@@ -54,3 +65,22 @@ func TestConvertExpr_Eval(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertExpr_TypeOf(t *testing.T) {
+	tests := []struct {
+		typ      *sqlparser.ConvertType
+		expected querypb.Type
+	}{
+		{typ: &sqlparser.ConvertType{Type: "signed"}, expected: querypb.Type_INT64},
+		{typ: &sqlparser.ConvertType{Type: "UNSIGNED"}, expected: querypb.Type_UINT64},
+		{typ: &sqlparser.ConvertType{Type: "datetime"}, expected: querypb.Type_DATETIME},
+		{typ: &sqlparser.ConvertType{Type: "VARCHAR"}, expected: querypb.Type_VARCHAR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ.Type, func(t *testing.T) {
+			col := TypeOf(&ConvertExpr{Input: &LiteralExpr{Value: sqltypes.NewVarChar("1")}, Type: tt.typ}, nil)
+			require.Equal(t, tt.expected, col.Type)
+		})
+	}
+}
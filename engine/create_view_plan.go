@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+type CreateViewPlan struct {
+	Catalog   schema.ViewStore
+	Name      string
+	Query     string
+	OrReplace bool
+}
+
+var _ Plan = (*CreateViewPlan)(nil)
+
+func (p *CreateViewPlan) Run(_ context.Context, _ map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	if !p.OrReplace {
+		if _, err := p.Catalog.View(p.Name); err == nil {
+			return nil, fmt.Errorf("%w: %s", schema.ErrTableExists, p.Name)
+		}
+	}
+	if err := p.Catalog.SetView(p.Name, p.Query); err != nil {
+		return nil, err
+	}
+	return schema.NewInMemoryCursor(nil), nil
+}
+
+func (p *CreateViewPlan) Schema(_ context.Context) ([]schema.Column, error) {
+	return []schema.Column{}, nil
+}
+
+func (p *CreateViewPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	return f(p)
+}
+
+func (p *CreateViewPlan) String() string {
+	return fmt.Sprintf("CreateViewPlan(%s, %s)", p.Name, p.Query)
+}
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateViewPlan_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	catalog := schema.NewViewCatalog(nil)
+
+	plan := &CreateViewPlan{Catalog: catalog, Name: "v", Query: "select 1"}
+
+	cursor, err := plan.Run(ctx, nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	query, err := catalog.View("v")
+	require.NoError(t, err)
+	require.Equal(t, "select 1", query)
+
+	_, err = plan.Run(ctx, nil)
+	require.ErrorIs(t, err, schema.ErrTableExists)
+
+	plan = &CreateViewPlan{Catalog: catalog, Name: "v", Query: "select 2", OrReplace: true}
+
+	_, err = plan.Run(ctx, nil)
+	require.NoError(t, err)
+
+	query, err = catalog.View("v")
+	require.NoError(t, err)
+	require.Equal(t, "select 2", query)
+}
@@ -1,21 +1,121 @@
 package engine
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
 type Dispatcher struct {
-	fns map[string]Function
+	aggFns map[string]AggregateFunction
+	specs  map[string]FunctionSpec
 }
 
 type DispatchOption func(*Dispatcher)
 
 type Function func(args []Value) (Value, error)
 
+// ContextFunction is a Function that also receives the context of the running query.
+type ContextFunction func(ctx context.Context, args []Value) (Value, error)
+
+// FunctionSpec declares a scalar function together with what the planner may assume of its calls, so that calls
+// are checked and folded when planned rather than failing when run.
+type FunctionSpec struct {
+	// Call computes the function with the context of the running query and the arguments cast to Params.
+	Call ContextFunction
+	// New, if set, returns a fresh Call for each call of the function in a query, which keeps its state from one
+	// row to the next.
+	New func() ContextFunction
+	// Params are the types of the parameters. NULL_TYPE accepts an argument of any type.
+	Params []querypb.Type
+	// Optional is how many of the last Params may be left out.
+	Optional int
+	// Variadic lets the last parameter repeat any number of times, including none.
+	Variadic bool
+	// Return is the type of the result, unless Infer derives it from the types of the arguments.
+	Return querypb.Type
+	Infer  func(args []querypb.Type) querypb.Type
+	// Deterministic functions always return the same result for the same arguments, so calls over constants are
+	// computed once when planned. Other functions are volatile and called on every evaluation.
+	Deterministic bool
+	// NullOnNull functions return NULL without being called when any argument is NULL.
+	NullOnNull bool
+}
+
+// AggregateFunction returns the initial state of an aggregate, one for each group it is computed over.
+type AggregateFunction func() AggregateState
+
+// AggregateState accumulates the arguments of the rows of a group one row at a time. Merge folds in the state
+// of another part of the same group, so that the parts can be aggregated apart and finalized once.
+type AggregateState interface {
+	Accumulate(args []Value) error
+	Merge(other AggregateState) error
+	Finalize() (Value, error)
+}
+
+var (
+	ErrArgumentCount = errors.New("incorrect parameter count")
+	ErrArgumentType  = errors.New("incorrect argument type")
+)
+
+// WithFunction registers a volatile function that takes any number of arguments of any type.
 func WithFunction(name string, f Function) DispatchOption {
-	return func(d *Dispatcher) { d.fns[strings.ToLower(name)] = f }
+	return WithContextFunction(name, withoutContext(f))
+}
+
+// WithContextFunction registers a volatile function that takes any number of arguments of any type.
+func WithContextFunction(name string, f ContextFunction) DispatchOption {
+	return WithFunctionSpec(name, FunctionSpec{
+		Call:     f,
+		Params:   []querypb.Type{querypb.Type_NULL_TYPE},
+		Variadic: true,
+	})
+}
+
+// WithFunctionSpec registers a scalar function declared by spec.
+func WithFunctionSpec(name string, spec FunctionSpec) DispatchOption {
+	return func(d *Dispatcher) {
+		d.unregister(name)
+		d.specs[strings.ToLower(name)] = spec
+	}
+}
+
+// builtin declares a deterministic function taking between minArgs and maxArgs arguments of any type, or minArgs
+// or more when maxArgs is negative.
+func builtin(minArgs, maxArgs int, call ContextFunction) FunctionSpec {
+	spec := FunctionSpec{Call: call, Deterministic: true}
+	if maxArgs < 0 {
+		spec.Params = make([]querypb.Type, minArgs+1)
+		spec.Variadic = true
+	} else {
+		spec.Params = make([]querypb.Type, maxArgs)
+		spec.Optional = maxArgs - minArgs
+	}
+	return spec
+}
+
+// volatile marks spec as computing a new result on every call, such as the current time.
+func volatile(spec FunctionSpec) FunctionSpec {
+	spec.Deterministic = false
+	return spec
+}
+
+func withoutContext(f Function) ContextFunction {
+	return func(_ context.Context, args []Value) (Value, error) {
+		return f(args)
+	}
+}
+
+// WithAggregate registers an aggregate function, which GROUP BY and window functions compute over groups of rows.
+func WithAggregate(name string, f AggregateFunction) DispatchOption {
+	return func(d *Dispatcher) {
+		d.unregister(name)
+		d.aggFns[strings.ToLower(name)] = f
+	}
 }
 
 func NewTernaryFunction(fn func(a, b, c Value) (Value, error)) Function {
@@ -37,7 +137,10 @@ func NewBinaryFunction(fn func(lhs, rhs Value) (Value, error)) Function {
 }
 
 func NewDispatcher(opts ...DispatchOption) *Dispatcher {
-	d := &Dispatcher{fns: make(map[string]Function)}
+	d := &Dispatcher{
+		aggFns: make(map[string]AggregateFunction),
+		specs:  make(map[string]FunctionSpec),
+	}
 	for _, opt := range opts {
 		opt(d)
 	}
@@ -45,9 +148,172 @@ func NewDispatcher(opts ...DispatchOption) *Dispatcher {
 }
 
 func (d *Dispatcher) Dispatch(name string, args []Value) (Value, error) {
-	fn, ok := d.fns[strings.ToLower(name)]
+	return d.DispatchContext(context.Background(), name, args)
+}
+
+// DispatchContext calls the function name with args. An aggregate is computed over a group with a row for each
+// of args.
+func (d *Dispatcher) DispatchContext(ctx context.Context, name string, args []Value) (Value, error) {
+	if spec, ok := d.specs[strings.ToLower(name)]; ok {
+		if spec.New != nil {
+			spec.Call = spec.New()
+		}
+		return spec.dispatch(ctx, name, args)
+	}
+	if _, ok := d.aggFns[strings.ToLower(name)]; ok {
+		rows := make([][]Value, 0, len(args))
+		for _, arg := range args {
+			rows = append(rows, []Value{arg})
+		}
+		return d.DispatchAggregate(name, rows)
+	}
+	return nil, errors.New("function not found: " + name)
+}
+
+// Function returns the spec of the scalar function registered as name.
+func (d *Dispatcher) Function(name string) (FunctionSpec, bool) {
+	spec, ok := d.specs[strings.ToLower(name)]
+	return spec, ok
+}
+
+// Aggregate returns the aggregate function registered as name.
+func (d *Dispatcher) Aggregate(name string) (AggregateFunction, bool) {
+	fn, ok := d.aggFns[strings.ToLower(name)]
+	return fn, ok
+}
+
+// DispatchAggregate computes the aggregate name over a group with the arguments of each of its rows.
+func (d *Dispatcher) DispatchAggregate(name string, rows [][]Value) (Value, error) {
+	fn, ok := d.Aggregate(name)
 	if !ok {
-		return nil, errors.New("function not found: " + name)
+		return nil, errors.New("aggregate function not found: " + name)
+	}
+	state := fn()
+	for _, args := range rows {
+		if err := state.Accumulate(args); err != nil {
+			return nil, err
+		}
+	}
+	return state.Finalize()
+}
+
+func (d *Dispatcher) unregister(name string) {
+	delete(d.aggFns, strings.ToLower(name))
+	delete(d.specs, strings.ToLower(name))
+}
+
+// Bind checks arguments of the given types against the parameters and returns the type of the result. An argument
+// of NULL_TYPE is of a type not known until run.
+func (s FunctionSpec) Bind(args []querypb.Type) (querypb.Type, error) {
+	if err := s.arity(len(args)); err != nil {
+		return querypb.Type_NULL_TYPE, err
+	}
+	for i, arg := range args {
+		if param := s.param(i); !assignable(param, arg) {
+			return querypb.Type_NULL_TYPE, fmt.Errorf("%w: argument %d is %v, expected %v", ErrArgumentType, i+1, arg, param)
+		}
+	}
+	if s.Infer != nil {
+		return s.Infer(args), nil
+	}
+	return s.Return, nil
+}
+
+func (s FunctionSpec) dispatch(ctx context.Context, name string, args []Value) (Value, error) {
+	val, err := s.call(ctx, args)
+	if err != nil && (errors.Is(err, ErrArgumentCount) || errors.Is(err, ErrArgumentType)) {
+		return nil, fmt.Errorf("%w in the call to function %s", err, name)
+	}
+	return val, err
+}
+
+func (s FunctionSpec) call(ctx context.Context, args []Value) (Value, error) {
+	if err := s.arity(len(args)); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vals := make([]Value, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			if s.NullOnNull {
+				return nil, nil
+			}
+			continue
+		}
+		if param := s.param(i); param != querypb.Type_NULL_TYPE && param != arg.Type() {
+			if !assignable(param, arg.Type()) {
+				return nil, fmt.Errorf("%w: argument %d is %v, expected %v", ErrArgumentType, i+1, arg.Type(), param)
+			}
+			v, err := Cast(arg, param)
+			if err != nil {
+				return nil, err
+			}
+			arg = v
+		}
+		vals[i] = arg
+	}
+	return s.Call(ctx, vals)
+}
+
+func (s FunctionSpec) arity(n int) error {
+	minArgs, maxArgs := len(s.Params)-s.Optional, len(s.Params)
+	if s.Variadic {
+		minArgs--
+	}
+	switch {
+	case s.Variadic && n < minArgs:
+		return fmt.Errorf("%w: expected at least %d arguments, got %d", ErrArgumentCount, minArgs, n)
+	case !s.Variadic && minArgs == maxArgs && n != maxArgs:
+		return fmt.Errorf("%w: expected %d arguments, got %d", ErrArgumentCount, maxArgs, n)
+	case !s.Variadic && (n < minArgs || n > maxArgs):
+		return fmt.Errorf("%w: expected %d to %d arguments, got %d", ErrArgumentCount, minArgs, maxArgs, n)
+	}
+	return nil
+}
+
+func (s FunctionSpec) param(i int) querypb.Type {
+	if i < len(s.Params) {
+		return s.Params[i]
+	}
+	if s.Variadic && len(s.Params) > 0 {
+		return s.Params[len(s.Params)-1]
+	}
+	return querypb.Type_NULL_TYPE
+}
+
+// assignable reports whether an argument of type arg can be cast to a parameter of type param. Strings convert
+// to any scalar, as do all scalars to strings and JSON.
+func assignable(param, arg querypb.Type) bool {
+	switch {
+	case param == querypb.Type_NULL_TYPE, arg == querypb.Type_NULL_TYPE, param == arg:
+		return true
+	case param == querypb.Type_TUPLE, arg == querypb.Type_TUPLE:
+		return false
+	case isString(arg), isString(param), param == querypb.Type_JSON:
+		return true
+	case isNumeric(param):
+		return isNumeric(arg)
+	case isTemporal(param):
+		return isTemporal(arg)
+	}
+	return false
+}
+
+func isString(typ querypb.Type) bool {
+	return sqltypes.IsText(typ) || sqltypes.IsBinary(typ)
+}
+
+func isNumeric(typ querypb.Type) bool {
+	return sqltypes.IsIntegral(typ) || sqltypes.IsFloat(typ) || typ == querypb.Type_DECIMAL
+}
+
+func isTemporal(typ querypb.Type) bool {
+	switch typ {
+	case querypb.Type_DATE, querypb.Type_TIME, querypb.Type_DATETIME, querypb.Type_TIMESTAMP, querypb.Type_YEAR:
+		return true
 	}
-	return fn(args)
+	return false
 }
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+func TestDispatcher_DispatchContext(t *testing.T) {
+	d := NewDispatcher(
+		WithFunction("first", func(args []Value) (Value, error) {
+			if len(args) == 0 {
+				return nil, nil
+			}
+			return args[0], nil
+		}),
+		WithFunctionSpec("concat2", FunctionSpec{
+			Call: func(_ context.Context, args []Value) (Value, error) {
+				return NewVarChar(args[0].(*VarChar).String() + args[1].(*VarChar).String()), nil
+			},
+			Params:     []querypb.Type{querypb.Type_VARCHAR, querypb.Type_VARCHAR},
+			Return:     querypb.Type_VARCHAR,
+			NullOnNull: true,
+		}),
+		WithFunctionSpec("total", FunctionSpec{
+			Call: func(_ context.Context, args []Value) (Value, error) {
+				var sum float64
+				for _, arg := range args {
+					if arg != nil {
+						sum += arg.(*Float64).Float()
+					}
+				}
+				return NewFloat64(sum), nil
+			},
+			Params:   []querypb.Type{querypb.Type_FLOAT64},
+			Variadic: true,
+			Return:   querypb.Type_FLOAT64,
+		}),
+		WithFunctionSpec("count2", FunctionSpec{
+			Call: func(_ context.Context, args []Value) (Value, error) {
+				return NewInt64(int64(len(args))), nil
+			},
+			Params:   []querypb.Type{querypb.Type_NULL_TYPE, querypb.Type_NULL_TYPE},
+			Optional: 1,
+			Return:   querypb.Type_INT64,
+		}),
+	)
+
+	tests := []struct {
+		name   string
+		args   []Value
+		expect Value
+		err    error
+	}{
+		{name: "first", args: []Value{NewInt64(1), NewVarChar("a")}, expect: NewInt64(1)},
+		{name: "first", args: nil, expect: nil},
+		{name: "concat2", args: []Value{NewVarChar("a"), NewInt64(1)}, expect: NewVarChar("a1")},
+		{name: "concat2", args: []Value{NewVarChar("a"), nil}, expect: nil},
+		{name: "concat2", args: []Value{NewVarChar("a")}, err: ErrArgumentCount},
+		{name: "total", args: nil, expect: NewFloat64(0)},
+		{name: "total", args: []Value{NewInt64(1), nil, NewVarChar("2.5")}, expect: NewFloat64(3.5)},
+		{name: "total", args: []Value{NewJSON(map[string]any{})}, err: ErrArgumentType},
+		{name: "count2", args: []Value{NewInt64(1)}, expect: NewInt64(1)},
+		{name: "count2", args: []Value{NewInt64(1), NewInt64(2)}, expect: NewInt64(2)},
+		{name: "count2", args: nil, err: ErrArgumentCount},
+		{name: "count2", args: []Value{NewInt64(1), NewInt64(2), NewInt64(3)}, err: ErrArgumentCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := d.DispatchContext(context.TODO(), tt.name, tt.args)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, actual)
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		_, err := d.DispatchContext(ctx, "total", nil)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestFunctionSpec_Bind(t *testing.T) {
+	spec := FunctionSpec{
+		Params:   []querypb.Type{querypb.Type_DATETIME, querypb.Type_INT64},
+		Variadic: true,
+		Infer: func(args []querypb.Type) querypb.Type {
+			return args[0]
+		},
+	}
+
+	tests := []struct {
+		args   []querypb.Type
+		expect querypb.Type
+		err    error
+	}{
+		{args: []querypb.Type{querypb.Type_DATETIME}, expect: querypb.Type_DATETIME},
+		{args: []querypb.Type{querypb.Type_VARCHAR, querypb.Type_FLOAT64, querypb.Type_NULL_TYPE}, expect: querypb.Type_VARCHAR},
+		{args: nil, err: ErrArgumentCount},
+		{args: []querypb.Type{querypb.Type_INT64}, err: ErrArgumentType},
+		{args: []querypb.Type{querypb.Type_DATE, querypb.Type_DATETIME}, err: ErrArgumentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(querypb.Type_name[int32(tt.expect)], func(t *testing.T) {
+			actual, err := spec.Bind(tt.args)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, actual)
+		})
+	}
+}
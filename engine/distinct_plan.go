@@ -23,35 +23,9 @@ func (p *DistinctPlan) Run(ctx context.Context, binds map[string]*querypb.BindVa
 	var rows []schema.Row
 	return schema.NewMappedCursor(cursor, func(row schema.Row) (schema.Row, error) {
 		for _, r := range rows {
-			if len(r.Columns) != len(row.Columns) {
-				continue
-			}
-
-			duplicate := true
-			for _, col := range r.Columns {
-				val1, ok1 := r.Get(col)
-				val2, ok2 := row.Get(col)
-				if !ok1 || !ok2 {
-					duplicate = false
-					break
-				}
-
-				v1, err := FromSQL(val1)
-				if err != nil {
-					return schema.Row{}, err
-				}
-				v2, err := FromSQL(val2)
-				if err != nil {
-					return schema.Row{}, err
-				}
-
-				cmp, err := Compare(v1, v2)
-				if err != nil || cmp != 0 {
-					duplicate = false
-					break
-				}
-			}
-			if duplicate {
+			if duplicate, err := equalRows(r, row); err != nil {
+				return schema.Row{}, err
+			} else if duplicate {
 				return schema.Row{}, nil
 			}
 		}
@@ -61,6 +35,10 @@ func (p *DistinctPlan) Run(ctx context.Context, binds map[string]*querypb.BindVa
 	}), nil
 }
 
+func (p *DistinctPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Input.Schema(ctx)
+}
+
 func (p *DistinctPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	if cont, err := f(p); !cont || err != nil {
 		return cont, err
@@ -71,3 +49,32 @@ func (p *DistinctPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 func (p *DistinctPlan) String() string {
 	return fmt.Sprintf("DistinctPlan(%s)", p.Input.String())
 }
+
+// equalRows reports whether r1 and r2 have the same columns with values that compare equal.
+func equalRows(r1, r2 schema.Row) (bool, error) {
+	if len(r1.Columns) != len(r2.Columns) {
+		return false, nil
+	}
+	for _, col := range r1.Columns {
+		val1, ok1 := r1.Get(col)
+		val2, ok2 := r2.Get(col)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+
+		v1, err := FromSQL(val1)
+		if err != nil {
+			return false, err
+		}
+		v2, err := FromSQL(val2)
+		if err != nil {
+			return false, err
+		}
+
+		cmp, err := Compare(v1, v2)
+		if err != nil || cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+type DropViewPlan struct {
+	Catalog  schema.ViewStore
+	Name     string
+	IfExists bool
+}
+
+var _ Plan = (*DropViewPlan)(nil)
+
+func (p *DropViewPlan) Run(_ context.Context, _ map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	if err := p.Catalog.DeleteView(p.Name); err != nil && !(p.IfExists && errors.Is(err, schema.ErrViewNotFound)) {
+		return nil, err
+	}
+	return schema.NewInMemoryCursor(nil), nil
+}
+
+func (p *DropViewPlan) Schema(_ context.Context) ([]schema.Column, error) {
+	return []schema.Column{}, nil
+}
+
+func (p *DropViewPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	return f(p)
+}
+
+func (p *DropViewPlan) String() string {
+	return fmt.Sprintf("DropViewPlan(%s)", p.Name)
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropViewPlan_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	catalog := schema.NewViewCatalog(nil)
+	_ = catalog.SetView("v", "select 1")
+
+	plan := &DropViewPlan{Catalog: catalog, Name: "v"}
+
+	cursor, err := plan.Run(ctx, nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	_, err = catalog.View("v")
+	require.ErrorIs(t, err, schema.ErrViewNotFound)
+
+	_, err = plan.Run(ctx, nil)
+	require.ErrorIs(t, err, schema.ErrViewNotFound)
+
+	plan = &DropViewPlan{Catalog: catalog, Name: "v", IfExists: true}
+
+	_, err = plan.Run(ctx, nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// DualPlan produces a single row without columns, which a select without a FROM clause, or from DUAL, projects.
+type DualPlan struct {
+}
+
+var _ Plan = (*DualPlan)(nil)
+
+func (p *DualPlan) Run(_ context.Context, _ map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	return schema.NewInMemoryCursor([]schema.Row{{Columns: []*sqlparser.ColName{}, Values: []sqltypes.Value{}}}), nil
+}
+
+func (p *DualPlan) Schema(_ context.Context) ([]schema.Column, error) {
+	return []schema.Column{}, nil
+}
+
+func (p *DualPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	return f(p)
+}
+
+func (p *DualPlan) String() string {
+	return "DualPlan()"
+}
@@ -0,0 +1,24 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestDualPlan_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	cursor, err := (&DualPlan{}).Run(ctx, nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Row{{Columns: []*sqlparser.ColName{}, Values: []sqltypes.Value{}}}, rows)
+}
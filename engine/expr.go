@@ -13,3 +13,85 @@ type Expr interface {
 	Copy() Expr
 	String() string
 }
+
+// TypeOf infers the column an expression produces when evaluated against rows of the given columns.
+func TypeOf(expr Expr, columns []schema.Column) schema.Column {
+	switch e := expr.(type) {
+	case *LiteralExpr:
+		return schema.Column{Type: e.Value.Type(), Nullable: e.Value.IsNull()}
+	case *IndexExpr:
+		if left, ok := e.Left.(*ColumnExpr); ok {
+			return TypeOf(left, columns)
+		}
+	case *ColumnExpr:
+		for _, col := range columns {
+			if schema.Match(col.Name, e.Value) {
+				return col
+			}
+		}
+	case *EqualExpr, *GreaterThanExpr, *GreaterThanOrEqualExpr, *LessThanExpr, *LessThanOrEqualExpr,
+		*InExpr, *LikeExpr, *RegexpExpr, *MatchExpr, *IdenticalExpr, *AndExpr, *OrExpr, *NotExpr:
+		col := schema.Column{Type: querypb.Type_INT64}
+		_, _ = expr.Walk(func(child Expr) (bool, error) {
+			if child == expr {
+				return true, nil
+			}
+			col.Nullable = col.Nullable || TypeOf(child, columns).Nullable
+			return false, nil
+		})
+		return col
+	case *NullSafeEqualExpr:
+		return schema.Column{Type: querypb.Type_INT64}
+	case *AddExpr:
+		return promoteType(TypeOf(e.Left, columns), TypeOf(e.Right, columns))
+	case *SubExpr:
+		return promoteType(TypeOf(e.Left, columns), TypeOf(e.Right, columns))
+	case *MulExpr:
+		return promoteType(TypeOf(e.Left, columns), TypeOf(e.Right, columns))
+	case *DivExpr:
+		return promoteType(TypeOf(e.Left, columns), TypeOf(e.Right, columns))
+	case *ModExpr:
+		return promoteType(TypeOf(e.Left, columns), TypeOf(e.Right, columns))
+	case *ConvertExpr:
+		col := TypeOf(e.Input, columns)
+		return schema.Column{Type: convertType(e.Type), Nullable: col.Nullable}
+	case *IfExpr:
+		return TypeOf(e.Then, columns)
+	case *CallExpr:
+		spread, ok := e.Input.(*SpreadExpr)
+		if spec, found := e.Dispatcher.Function(e.FuncName()); found && ok && !e.Aggregate {
+			args := make([]querypb.Type, 0, len(spread.Exprs))
+			for _, arg := range spread.Exprs {
+				args = append(args, TypeOf(arg, columns).Type)
+			}
+			if typ, err := spec.Bind(args); err == nil {
+				return schema.Column{Type: typ, Nullable: true}
+			}
+		}
+	case *JSONExtractExpr:
+		return schema.Column{Type: querypb.Type_JSON, Nullable: true}
+	case *IntervalExpr:
+		return schema.Column{Type: querypb.Type_VARCHAR}
+	}
+	return schema.Column{Type: querypb.Type_NULL_TYPE, Nullable: true}
+}
+
+func promoteType(lhs, rhs schema.Column) schema.Column {
+	priority := map[querypb.Type]int{
+		querypb.Type_INT64:     1,
+		querypb.Type_UINT64:    2,
+		querypb.Type_FLOAT64:   3,
+		querypb.Type_VARCHAR:   4,
+		querypb.Type_VARBINARY: 5,
+		querypb.Type_DATETIME:  6,
+		querypb.Type_JSON:      7,
+	}
+
+	col := schema.Column{Type: lhs.Type, Nullable: lhs.Nullable || rhs.Nullable}
+	if lhs.Type == querypb.Type_NULL_TYPE || rhs.Type == querypb.Type_NULL_TYPE {
+		col.Type = querypb.Type_NULL_TYPE
+	} else if priority[rhs.Type] > priority[lhs.Type] {
+		col.Type = rhs.Type
+	}
+	return col
+}
@@ -32,6 +32,10 @@ func (p *FilterPlan) Run(ctx context.Context, binds map[string]*querypb.BindVari
 	}), nil
 }
 
+func (p *FilterPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Input.Schema(ctx)
+}
+
 func (p *FilterPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	if cont, err := f(p); !cont || err != nil {
 		return cont, err
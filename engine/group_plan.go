@@ -2,8 +2,13 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/siyul-park/sqlbridge/schema"
 	"github.com/xwb1989/sqlparser"
@@ -11,9 +16,55 @@ import (
 	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
+// GroupPlan groups the rows of Input by Exprs and computes Aggregates over each group, keeping only their
+// states rather than the rows. A group has the columns its rows agree on followed by the result of each
+// aggregate. Without Exprs, all rows form a single group, even when there are none. With a Parallelism above
+// one, that many workers aggregate the rows into partial groups, which are then merged.
 type GroupPlan struct {
-	Input Plan
-	Exprs []Expr
+	Input       Plan
+	Exprs       []Expr
+	Aggregates  []*AggregateFunc
+	Parallelism int
+}
+
+// AggregateFunc is an aggregate over the rows of a group for which Filter holds. With Distinct, rows with the
+// same arguments are aggregated once, and with an Order, rows are aggregated in that order.
+type AggregateFunc struct {
+	Dispatcher *Dispatcher
+	Name       sqlparser.ColIdent
+	Args       []Expr
+	Distinct   bool
+	Filter     Expr
+	Order      []WindowOrder
+	As         *sqlparser.ColName
+}
+
+// grouping is the groups of the rows aggregated so far, which may be only part of the rows of Input.
+type grouping struct {
+	plan   *GroupPlan
+	fns    []AggregateFunction
+	groups []*group
+}
+
+type group struct {
+	key     *Tuple
+	first   int
+	columns []*sqlparser.ColName
+	values  []sqltypes.Value
+	aggs    []*aggregate
+}
+
+// aggregate is the state of an aggregate of a group. The rows of a Distinct or ordered aggregate are buffered
+// until the group is finalized.
+type aggregate struct {
+	state AggregateState
+	rows  []aggregateRow
+}
+
+type aggregateRow struct {
+	seq  int
+	args []Value
+	keys []Value
 }
 
 var _ Plan = (*GroupPlan)(nil)
@@ -23,90 +74,384 @@ func (p *GroupPlan) Run(ctx context.Context, binds map[string]*querypb.BindVaria
 	if err != nil {
 		return nil, err
 	}
+	defer input.Close()
 
-	rows, err := schema.ReadAll(input)
+	var g *grouping
+	if p.Parallelism > 1 {
+		g, err = p.parallel(ctx, input, binds)
+	} else {
+		g, err = p.serial(ctx, input, binds)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var keys []*Tuple
-	var children [][]schema.Row
-	for _, row := range rows {
-		var vals []Value
-		for _, expr := range p.Exprs {
-			val, err := expr.Eval(ctx, row, binds)
+	rows, err := g.rows()
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewInMemoryCursor(rows), nil
+}
+
+func (p *GroupPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	input, err := p.Input.Schema(ctx)
+	if err != nil || input == nil || len(p.Aggregates) == 0 {
+		return input, err
+	}
+
+	columns := slices.Clone(input)
+	for _, fn := range p.Aggregates {
+		col := schema.Column{Name: fn.As, Type: querypb.Type_NULL_TYPE, Nullable: true}
+		if fn.Name.Equal(Count) {
+			col = schema.Column{Name: fn.As, Type: querypb.Type_INT64}
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (p *GroupPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	if cont, err := f(p); !cont || err != nil {
+		return cont, err
+	}
+	return p.Input.Walk(f)
+}
+
+func (p *GroupPlan) String() string {
+	var exprs []string
+	for _, expr := range p.Exprs {
+		exprs = append(exprs, expr.String())
+	}
+	for _, fn := range p.Aggregates {
+		exprs = append(exprs, fn.String())
+	}
+	return fmt.Sprintf("GroupPlan(%s)", strings.Join(exprs, ", "))
+}
+
+func (p *GroupPlan) serial(ctx context.Context, input schema.Cursor, binds map[string]*querypb.BindVariable) (*grouping, error) {
+	g, err := p.grouping()
+	if err != nil {
+		return nil, err
+	}
+	for seq := 0; ; seq++ {
+		row, err := input.Next()
+		if errors.Is(err, io.EOF) {
+			return g, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := g.add(ctx, seq, row, binds); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parallel hands the rows of input out to Parallelism workers, each aggregating its own partial groups, and
+// merges their groups once every row is aggregated.
+func (p *GroupPlan) parallel(ctx context.Context, input schema.Cursor, binds map[string]*querypb.BindVariable) (*grouping, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type item struct {
+		seq int
+		row schema.Row
+	}
+
+	partials := make([]*grouping, p.Parallelism)
+	for i := range partials {
+		g, err := p.grouping()
+		if err != nil {
+			return nil, err
+		}
+		partials[i] = g
+	}
+
+	items := make(chan item)
+	errs := make([]error, len(partials)+1)
+
+	var wg sync.WaitGroup
+	for i, g := range partials {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for it := range items {
+				if err := g.add(ctx, it.seq, it.row, binds); err != nil {
+					errs[i] = err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+read:
+	for seq := 0; ; seq++ {
+		row, err := input.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			errs[len(partials)] = err
+			break
+		}
+		select {
+		case items <- item{seq: seq, row: row}:
+		case <-ctx.Done():
+			break read
+		}
+	}
+	close(items)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g := partials[0]
+	for _, other := range partials[1:] {
+		if err := g.merge(other); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (p *GroupPlan) grouping() (*grouping, error) {
+	fns := make([]AggregateFunction, 0, len(p.Aggregates))
+	for _, fn := range p.Aggregates {
+		f, ok := fn.Dispatcher.Aggregate(fn.Name.String())
+		if !ok {
+			return nil, errors.New("aggregate function not found: " + fn.Name.String())
+		}
+		fns = append(fns, f)
+	}
+	return &grouping{plan: p, fns: fns}, nil
+}
+
+func (g *grouping) add(ctx context.Context, seq int, row schema.Row, binds map[string]*querypb.BindVariable) error {
+	var vals []Value
+	for _, expr := range g.plan.Exprs {
+		val, err := expr.Eval(ctx, row, binds)
+		if err != nil {
+			return err
+		}
+		vals = append(vals, val)
+	}
+	key := NewTuple(vals)
+
+	grp := g.find(key)
+	if grp == nil {
+		grp = g.group(key, seq, slices.Clone(row.Columns), slices.Clone(row.Values))
+	} else if err := grp.agree(row); err != nil {
+		return err
+	}
+
+	for i, fn := range g.plan.Aggregates {
+		if fn.Filter != nil {
+			val, err := fn.Filter.Eval(ctx, row, binds)
 			if err != nil {
-				return nil, err
+				return err
+			}
+			if !ToBool(val) {
+				continue
 			}
-			vals = append(vals, val)
 		}
-		key := NewTuple(vals)
 
-		duplicate := false
-		for i, k := range keys {
-			cmp, err := Compare(k, key)
-			if cmp == 0 && err == nil {
-				children[i] = append(children[i], row)
-				duplicate = true
-				break
+		args, err := fn.args(ctx, row, binds)
+		if err != nil {
+			return err
+		}
+
+		agg := grp.aggs[i]
+		if !fn.Distinct && len(fn.Order) == 0 {
+			if err := agg.state.Accumulate(args); err != nil {
+				return err
 			}
+			continue
 		}
-		if !duplicate {
-			keys = append(keys, key)
-			children = append(children, []schema.Row{row})
+
+		var keys []Value
+		for _, order := range fn.Order {
+			val, err := order.Expr.Eval(ctx, row, binds)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, val)
 		}
+		agg.rows = append(agg.rows, aggregateRow{seq: seq, args: args, keys: keys})
 	}
+	return nil
+}
 
-	var group []schema.Row
-	for _, rows := range children {
-		columns := make([]*sqlparser.ColName, len(rows[0].Columns))
-		copy(columns, rows[0].Columns)
-
-		values := make([]sqltypes.Value, len(rows[0].Values))
-		copy(values, rows[0].Values)
+// merge folds the groups of other into those of g.
+func (g *grouping) merge(other *grouping) error {
+	for _, o := range other.groups {
+		grp := g.find(o.key)
+		if grp == nil {
+			g.groups = append(g.groups, o)
+			continue
+		}
+		if o.first < grp.first {
+			grp.first, o.first = o.first, grp.first
+			grp.columns, o.columns = o.columns, grp.columns
+			grp.values, o.values = o.values, grp.values
+		}
+		if err := grp.agree(schema.Row{Columns: o.columns, Values: o.values}); err != nil {
+			return err
+		}
+		for i, agg := range grp.aggs {
+			if err := agg.state.Merge(o.aggs[i].state); err != nil {
+				return err
+			}
+			agg.rows = append(agg.rows, o.aggs[i].rows...)
+		}
+	}
+	sort.SliceStable(g.groups, func(i, j int) bool { return g.groups[i].first < g.groups[j].first })
+	return nil
+}
 
-		for _, row := range rows[1:] {
-			for i := 0; i < len(columns); i++ {
-				val, _ := row.Get(columns[i])
+func (g *grouping) rows() ([]schema.Row, error) {
+	if len(g.plan.Exprs) == 0 && len(g.groups) == 0 {
+		g.group(NewTuple(nil), 0, nil, nil)
+	}
 
-				v1, err := FromSQL(values[i])
-				if err != nil {
-					return nil, err
-				}
-				v2, err := FromSQL(val)
-				if err != nil {
+	rows := make([]schema.Row, 0, len(g.groups))
+	for _, grp := range g.groups {
+		columns := grp.columns
+		values := grp.values
+		for i, fn := range g.plan.Aggregates {
+			val, err := grp.aggs[i].finalize(fn)
+			if err != nil {
+				return nil, err
+			}
+			v := sqltypes.NULL
+			if val != nil {
+				if v, err = ToSQL(val, val.Type()); err != nil {
 					return nil, err
 				}
+			}
+			columns = append(columns, fn.As)
+			values = append(values, v)
+		}
+		rows = append(rows, schema.Row{Columns: columns, Values: values})
+	}
+	return rows, nil
+}
 
-				cmp, err := Compare(v1, v2)
-				if cmp != 0 || err != nil {
-					columns = append(columns[:i], columns[i+1:]...)
-					values = append(values[:i], values[i+1:]...)
-					i--
-				}
+func (g *grouping) find(key *Tuple) *group {
+	for _, grp := range g.groups {
+		if cmp, err := Compare(grp.key, key); cmp == 0 && err == nil {
+			return grp
+		}
+	}
+	return nil
+}
+
+func (g *grouping) group(key *Tuple, seq int, columns []*sqlparser.ColName, values []sqltypes.Value) *group {
+	grp := &group{key: key, first: seq, columns: columns, values: values}
+	for _, fn := range g.fns {
+		grp.aggs = append(grp.aggs, &aggregate{state: fn()})
+	}
+	g.groups = append(g.groups, grp)
+	return grp
+}
+
+// agree drops the columns of grp whose values row does not share.
+func (grp *group) agree(row schema.Row) error {
+	for i := 0; i < len(grp.columns); i++ {
+		val, _ := row.Get(grp.columns[i])
+
+		v1, err := FromSQL(grp.values[i])
+		if err != nil {
+			return err
+		}
+		v2, err := FromSQL(val)
+		if err != nil {
+			return err
+		}
+
+		cmp, err := Compare(v1, v2)
+		if cmp != 0 || err != nil {
+			grp.columns = append(grp.columns[:i], grp.columns[i+1:]...)
+			grp.values = append(grp.values[:i], grp.values[i+1:]...)
+			i--
+		}
+	}
+	return nil
+}
+
+// finalize accumulates the buffered rows, if any, in order and without duplicates, and returns the result.
+func (agg *aggregate) finalize(fn *AggregateFunc) (Value, error) {
+	rows := agg.rows
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].seq < rows[j].seq })
+	sort.SliceStable(rows, func(i, j int) bool {
+		for k, order := range fn.Order {
+			cmp, err := Compare(rows[i].keys[k], rows[j].keys[k])
+			if err != nil || cmp == 0 {
+				continue
 			}
+			if order.Direction == sqlparser.DescScr {
+				cmp = -cmp
+			}
+			return cmp < 0
 		}
+		return false
+	})
 
-		group = append(group, schema.Row{
-			Columns:  columns,
-			Values:   values,
-			Children: rows,
-		})
+	var seen []*Tuple
+	for _, row := range rows {
+		if fn.Distinct {
+			args := NewTuple(row.args)
+			if slices.ContainsFunc(seen, func(t *Tuple) bool {
+				cmp, err := Compare(t, args)
+				return cmp == 0 && err == nil
+			}) {
+				continue
+			}
+			seen = append(seen, args)
+		}
+		if err := agg.state.Accumulate(row.args); err != nil {
+			return nil, err
+		}
 	}
-	return schema.NewInMemoryCursor(group), nil
+	return agg.state.Finalize()
 }
 
-func (p *GroupPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
-	if cont, err := f(p); !cont || err != nil {
-		return cont, err
+func (f *AggregateFunc) args(ctx context.Context, row schema.Row, binds map[string]*querypb.BindVariable) ([]Value, error) {
+	args := make([]Value, 0, len(f.Args))
+	for _, expr := range f.Args {
+		val, err := expr.Eval(ctx, row, binds)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := val.(*Tuple); ok {
+			args = append(args, t.Values()...)
+		} else {
+			args = append(args, val)
+		}
 	}
-	return p.Input.Walk(f)
+	return args, nil
 }
 
-func (p *GroupPlan) String() string {
-	var exprs []string
-	for _, expr := range p.Exprs {
-		exprs = append(exprs, expr.String())
+func (f *AggregateFunc) String() string {
+	var args []string
+	for _, expr := range f.Args {
+		args = append(args, expr.String())
 	}
-	return fmt.Sprintf("GroupPlan(%s)", strings.Join(exprs, ", "))
+	if f.Distinct {
+		args = append([]string{"DISTINCT"}, args...)
+	}
+	var order []string
+	for _, o := range f.Order {
+		order = append(order, o.Expr.String()+" "+o.Direction)
+	}
+	filter := ""
+	if f.Filter != nil {
+		filter = f.Filter.String()
+	}
+	return fmt.Sprintf("Aggregate(%s, [%s], [%s], %s)", f.Name.String(), strings.Join(args, ", "), strings.Join(order, ", "), filter)
 }
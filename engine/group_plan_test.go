@@ -34,11 +34,58 @@ func TestGroupPlan_Run(t *testing.T) {
 		},
 	})
 
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+	t3 := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("bar")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewVarChar("foo")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewVarChar("foo")}},
+	})
+	t4 := schema.NewInMemoryTable(nil)
+
 	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
 		"t1": t1,
 		"t2": t2,
+		"t3": t3,
+		"t4": t4,
 	})
 
+	dispatcher := NewDispatcher(WithBuiltIn())
+	id := &IndexExpr{Left: &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}
+	name := &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}}
+	aggregates := []*AggregateFunc{
+		{Dispatcher: dispatcher, Name: Count, As: &sqlparser.ColName{Name: sqlparser.NewColIdent("count(*)")}},
+		{Dispatcher: dispatcher, Name: Count, Args: []Expr{id}, Distinct: true, As: &sqlparser.ColName{Name: sqlparser.NewColIdent("count(distinct id)")}},
+		{
+			Dispatcher: dispatcher,
+			Name:       Sum,
+			Args:       []Expr{id},
+			Filter:     &GreaterThanExpr{Left: id, Right: &LiteralExpr{Value: sqltypes.NewInt64(1)}},
+			As:         &sqlparser.ColName{Name: sqlparser.NewColIdent("sum(id) filter (where id > 1)")},
+		},
+		{
+			Dispatcher: dispatcher,
+			Name:       GroupConcat,
+			Args:       []Expr{&LiteralExpr{Value: sqltypes.NewVarChar("-")}, id},
+			Order:      []WindowOrder{{Expr: id, Direction: sqlparser.DescScr}},
+			As:         &sqlparser.ColName{Name: sqlparser.NewColIdent("group_concat(id order by id desc separator '-')")},
+		},
+	}
+	as := make([]*sqlparser.ColName, 0, len(aggregates))
+	for _, fn := range aggregates {
+		as = append(as, fn.As)
+	}
+	expected := []schema.Row{
+		{
+			Columns: append([]*sqlparser.ColName{columns[1]}, as...),
+			Values:  []sqltypes.Value{sqltypes.NewVarChar("foo"), sqltypes.NewInt64(3), sqltypes.NewInt64(2), sqltypes.NewInt64(6), sqltypes.NewVarChar("3-3-1")},
+		},
+		{
+			Columns: append([]*sqlparser.ColName{columns[0], columns[1]}, as...),
+			Values:  []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("bar"), sqltypes.NewInt64(1), sqltypes.NewInt64(1), sqltypes.NewInt64(2), sqltypes.NewVarChar("2")},
+		},
+	}
+
 	tests := []struct {
 		plan   Plan
 		binds  map[string]*querypb.BindVariable
@@ -55,16 +102,35 @@ func TestGroupPlan_Run(t *testing.T) {
 				{
 					Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("name")}},
 					Values:  []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.VarChar, []byte("foo"))},
-					Children: []schema.Row{
-						{
-							Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}},
-							Values:  []sqltypes.Value{sqltypes.NewInt64(0), sqltypes.MakeTrusted(sqltypes.VarChar, []byte("foo"))},
-						},
-						{
-							Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}},
-							Values:  []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.VarChar, []byte("foo"))},
-						},
-					},
+				},
+			}),
+		},
+		{
+			plan: &GroupPlan{
+				Input:      &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t3")}},
+				Exprs:      []Expr{name},
+				Aggregates: aggregates,
+			},
+			cursor: schema.NewInMemoryCursor(expected),
+		},
+		{
+			plan: &GroupPlan{
+				Input:       &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t3")}},
+				Exprs:       []Expr{name},
+				Aggregates:  aggregates,
+				Parallelism: 4,
+			},
+			cursor: schema.NewInMemoryCursor(expected),
+		},
+		{
+			plan: &GroupPlan{
+				Input:      &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t4")}},
+				Aggregates: aggregates[:2],
+			},
+			cursor: schema.NewInMemoryCursor([]schema.Row{
+				{
+					Columns: []*sqlparser.ColName{aggregates[0].As, aggregates[1].As},
+					Values:  []sqltypes.Value{sqltypes.NewInt64(0), sqltypes.NewInt64(0)},
 				},
 			}),
 		},
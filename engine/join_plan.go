@@ -47,6 +47,18 @@ func (p *JoinPlan) Run(ctx context.Context, binds map[string]*querypb.BindVariab
 	return schema.NewInMemoryCursor(joins), nil
 }
 
+func (p *JoinPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	left, err := p.Left.Schema(ctx)
+	if err != nil || left == nil {
+		return nil, err
+	}
+	right, err := p.Right.Schema(ctx)
+	if err != nil || right == nil {
+		return nil, err
+	}
+	return append(append([]schema.Column{}, left...), right...), nil
+}
+
 func (p *JoinPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	if cont, err := f(p); !cont || err != nil {
 		return cont, err
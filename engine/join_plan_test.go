@@ -93,3 +93,34 @@ func TestJoinPlan_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestJoinPlan_Schema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(ctx, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+	})
+	t2 := schema.NewInMemoryTable(nil)
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		"t1": t1,
+		"t2": t2,
+	})
+
+	plan := &JoinPlan{
+		Left:  &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+		Right: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+	}
+
+	cols, err := plan.Schema(ctx)
+	require.NoError(t, err)
+	require.Len(t, cols, 2)
+
+	plan.Right = &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t2")}}
+
+	cols, err = plan.Schema(ctx)
+	require.NoError(t, err)
+	require.Nil(t, cols)
+}
@@ -39,6 +39,16 @@ func TestJSONExtractExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewVarChar("$.arr[1].x")},
 			expected: NewValue(float64(2)),
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewVarChar(`{"arr": [{"x": 1}, {"x": 2}]}`)},
+			right:    &LiteralExpr{Value: sqltypes.NewVarChar("$.arr[*].x")},
+			expected: NewJSON([]any{float64(1), float64(2)}),
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewVarChar("$.foo")},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,607 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+var (
+	JSONExtract      = sqlparser.NewColIdent("json_extract")
+	JSONUnquote      = sqlparser.NewColIdent("json_unquote")
+	JSONContains     = sqlparser.NewColIdent("json_contains")
+	JSONContainsPath = sqlparser.NewColIdent("json_contains_path")
+	JSONKeys         = sqlparser.NewColIdent("json_keys")
+	JSONLength       = sqlparser.NewColIdent("json_length")
+	JSONType         = sqlparser.NewColIdent("json_type")
+	JSONObject       = sqlparser.NewColIdent("json_object")
+	JSONArray        = sqlparser.NewColIdent("json_array")
+	JSONSet          = sqlparser.NewColIdent("json_set")
+	JSONInsert       = sqlparser.NewColIdent("json_insert")
+	JSONReplace      = sqlparser.NewColIdent("json_replace")
+	JSONRemove       = sqlparser.NewColIdent("json_remove")
+	JSONArrayAgg     = sqlparser.NewColIdent("json_arrayagg")
+	JSONObjectAgg    = sqlparser.NewColIdent("json_objectagg")
+)
+
+func init() {
+	sqlparser.Aggregates[JSONArrayAgg.String()] = true
+	sqlparser.Aggregates[JSONObjectAgg.String()] = true
+}
+
+// WithJSON registers the MySQL JSON functions. Document arguments may be JSON values or JSON text, and scalars
+// selected from a document are returned as SQL values.
+func WithJSON() DispatchOption {
+	return func(d *Dispatcher) {
+		d.specs[JSONExtract.String()] = builtin(2, -1, withoutContext(NewJSONExtract()))
+		d.specs[JSONUnquote.String()] = builtin(1, 1, withoutContext(NewJSONUnquote()))
+		d.specs[JSONContains.String()] = builtin(2, 3, withoutContext(NewJSONContains()))
+		d.specs[JSONContainsPath.String()] = builtin(3, -1, withoutContext(NewJSONContainsPath()))
+		d.specs[JSONKeys.String()] = builtin(1, 2, withoutContext(NewJSONKeys()))
+		d.specs[JSONLength.String()] = builtin(1, 2, withoutContext(NewJSONLength()))
+		d.specs[JSONType.String()] = builtin(1, 1, withoutContext(NewJSONType()))
+		d.specs[JSONObject.String()] = builtin(0, -1, withoutContext(NewJSONObject()))
+		d.specs[JSONArray.String()] = builtin(0, -1, withoutContext(NewJSONArray()))
+		d.specs[JSONSet.String()] = builtin(3, -1, withoutContext(NewJSONSet()))
+		d.specs[JSONInsert.String()] = builtin(3, -1, withoutContext(NewJSONInsert()))
+		d.specs[JSONReplace.String()] = builtin(3, -1, withoutContext(NewJSONReplace()))
+		d.specs[JSONRemove.String()] = builtin(2, -1, withoutContext(NewJSONRemove()))
+		d.aggFns[JSONArrayAgg.String()] = NewJSONArrayAgg()
+		d.aggFns[JSONObjectAgg.String()] = NewJSONObjectAgg()
+	}
+}
+
+func NewJSONExtract() Function {
+	return NewJSONFunction(2, -1, func(doc any, args []Value) (Value, error) {
+		paths, err := toJSONPaths(args)
+		if err != nil {
+			return nil, err
+		}
+		return extractJSON(doc, paths...), nil
+	})
+}
+
+func NewJSONUnquote() Function {
+	return func(args []Value) (Value, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		switch v := args[0].(type) {
+		case nil:
+			return nil, nil
+		case *JSON:
+			if s, ok := v.Interface().(string); ok {
+				return NewVarChar(s), nil
+			}
+			b, err := v.Bytes()
+			if err != nil {
+				return nil, err
+			}
+			return NewVarChar(string(b)), nil
+		default:
+			s, err := ToString(v)
+			if err != nil {
+				return nil, err
+			}
+			if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+				var unquoted string
+				if err := json.Unmarshal([]byte(s), &unquoted); err != nil {
+					return nil, fmt.Errorf("invalid JSON text: %w", err)
+				}
+				return NewVarChar(unquoted), nil
+			}
+			return NewVarChar(s), nil
+		}
+	}
+}
+
+func NewJSONContains() Function {
+	return NewJSONFunction(2, 3, func(doc any, args []Value) (Value, error) {
+		candidate, err := toJSONDoc(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(args) > 1 {
+			paths, err := toJSONPaths(args[1:])
+			if err != nil {
+				return nil, err
+			}
+			if paths[0].Wildcard() {
+				return nil, fmt.Errorf("%w: wildcards are not allowed in %s", ErrInvalidJSONPath, paths[0])
+			}
+			matches := paths[0].Find(doc)
+			if len(matches) == 0 {
+				return nil, nil
+			}
+			doc = matches[0]
+		}
+		return NewBool(containsJSON(doc, candidate)), nil
+	})
+}
+
+func NewJSONContainsPath() Function {
+	return NewJSONFunction(3, -1, func(doc any, args []Value) (Value, error) {
+		mode, err := ToString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		mode = strings.ToLower(mode)
+		if mode != "one" && mode != "all" {
+			return nil, fmt.Errorf("the second argument must be 'one' or 'all': %s", mode)
+		}
+		paths, err := toJSONPaths(args[1:])
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			found := len(path.Find(doc)) > 0
+			if found && mode == "one" {
+				return NewBool(true), nil
+			}
+			if !found && mode == "all" {
+				return NewBool(false), nil
+			}
+		}
+		return NewBool(mode == "all"), nil
+	})
+}
+
+func NewJSONKeys() Function {
+	return NewJSONFunction(1, 2, func(doc any, args []Value) (Value, error) {
+		doc, ok, err := selectJSON(doc, args)
+		if !ok || err != nil {
+			return nil, err
+		}
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		keys := make([]any, 0, len(m))
+		for _, key := range jsonKeys(m) {
+			keys = append(keys, key)
+		}
+		return NewJSON(keys), nil
+	})
+}
+
+func NewJSONLength() Function {
+	return NewJSONFunction(1, 2, func(doc any, args []Value) (Value, error) {
+		doc, ok, err := selectJSON(doc, args)
+		if !ok || err != nil {
+			return nil, err
+		}
+		switch v := doc.(type) {
+		case map[string]any:
+			return NewInt64(int64(len(v))), nil
+		case []any:
+			return NewInt64(int64(len(v))), nil
+		default:
+			return NewInt64(1), nil
+		}
+	})
+}
+
+func NewJSONType() Function {
+	return NewJSONFunction(1, 1, func(doc any, _ []Value) (Value, error) {
+		switch v := doc.(type) {
+		case map[string]any:
+			return NewVarChar("OBJECT"), nil
+		case []any:
+			return NewVarChar("ARRAY"), nil
+		case string:
+			return NewVarChar("STRING"), nil
+		case bool:
+			return NewVarChar("BOOLEAN"), nil
+		case nil:
+			return NewVarChar("NULL"), nil
+		case int64:
+			return NewVarChar("INTEGER"), nil
+		case uint64:
+			return NewVarChar("UNSIGNED INTEGER"), nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				return NewVarChar("INTEGER"), nil
+			}
+			return NewVarChar("DOUBLE"), nil
+		default:
+			return nil, fmt.Errorf("unsupported JSON value: %T", doc)
+		}
+	})
+}
+
+func NewJSONObject() Function {
+	return func(args []Value) (Value, error) {
+		return objectJSON(args)
+	}
+}
+
+func NewJSONArray() Function {
+	return func(args []Value) (Value, error) {
+		arr := make([]any, 0, len(args))
+		for _, arg := range args {
+			v, err := jsonOf(arg)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return NewJSON(arr), nil
+	}
+}
+
+func NewJSONSet() Function {
+	return modifyJSON(true, true)
+}
+
+func NewJSONInsert() Function {
+	return modifyJSON(true, false)
+}
+
+func NewJSONReplace() Function {
+	return modifyJSON(false, true)
+}
+
+func NewJSONRemove() Function {
+	return NewJSONFunction(2, -1, func(doc any, args []Value) (Value, error) {
+		paths, err := toJSONPaths(args)
+		if err != nil {
+			return nil, err
+		}
+		doc = cloneJSON(doc)
+		for _, path := range paths {
+			if path.Wildcard() {
+				return nil, fmt.Errorf("%w: wildcards are not allowed in %s", ErrInvalidJSONPath, path)
+			}
+			if len(path.legs) == 0 {
+				return nil, fmt.Errorf("%w: cannot remove %s", ErrInvalidJSONPath, path)
+			}
+			doc = removeJSONPath(doc, path.legs)
+		}
+		return NewJSON(doc), nil
+	})
+}
+
+func NewJSONArrayAgg() AggregateFunction {
+	return func() AggregateState { return &jsonArrayAggState{} }
+}
+
+// NewJSONObjectAgg builds an object of key-value argument pairs, later keys overwriting earlier ones.
+func NewJSONObjectAgg() AggregateFunction {
+	return func() AggregateState { return &jsonObjectAggState{} }
+}
+
+// NewJSONFunction returns a function whose first argument is a JSON document followed by between minArgs-1 and
+// maxArgs-1 other arguments, or any number when maxArgs is negative. A NULL argument yields NULL.
+func NewJSONFunction(minArgs, maxArgs int, fn func(doc any, args []Value) (Value, error)) Function {
+	return func(args []Value) (Value, error) {
+		if len(args) < minArgs || (maxArgs >= 0 && len(args) > maxArgs) {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+		}
+		doc, err := toJSONDoc(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(doc, args[1:])
+	}
+}
+
+func modifyJSON(insert, replace bool) Function {
+	return func(args []Value) (Value, error) {
+		if len(args) < 3 || len(args)%2 == 0 {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		if args[0] == nil {
+			return nil, nil
+		}
+		doc, err := toJSONDoc(args[0])
+		if err != nil {
+			return nil, err
+		}
+		doc = cloneJSON(doc)
+
+		for i := 1; i < len(args); i += 2 {
+			if args[i] == nil {
+				return nil, nil
+			}
+			paths, err := toJSONPaths(args[i : i+1])
+			if err != nil {
+				return nil, err
+			}
+			if paths[0].Wildcard() {
+				return nil, fmt.Errorf("%w: wildcards are not allowed in %s", ErrInvalidJSONPath, paths[0])
+			}
+			val, err := jsonOf(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			doc = setJSONPath(doc, paths[0].legs, val, insert, replace)
+		}
+		return NewJSON(doc), nil
+	}
+}
+
+func objectJSON(args []Value) (Value, error) {
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+	}
+	obj := make(map[string]any, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		if args[i] == nil {
+			return nil, fmt.Errorf("JSON documents may not contain NULL member names")
+		}
+		key, err := ToString(args[i])
+		if err != nil {
+			return nil, err
+		}
+		val, err := jsonOf(args[i+1])
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = val
+	}
+	return NewJSON(obj), nil
+}
+
+type jsonArrayAggState struct {
+	arr []any
+}
+
+func (s *jsonArrayAggState) Accumulate(args []Value) error {
+	arg, err := singleArg(args)
+	if err != nil {
+		return err
+	}
+	v, err := jsonOf(arg)
+	if err != nil {
+		return err
+	}
+	s.arr = append(s.arr, v)
+	return nil
+}
+
+func (s *jsonArrayAggState) Merge(other AggregateState) error {
+	o, ok := other.(*jsonArrayAggState)
+	if !ok {
+		return ErrAggregateState
+	}
+	s.arr = append(s.arr, o.arr...)
+	return nil
+}
+
+func (s *jsonArrayAggState) Finalize() (Value, error) {
+	if s.arr == nil {
+		return nil, nil
+	}
+	return NewJSON(s.arr), nil
+}
+
+type jsonObjectAggState struct {
+	args []Value
+}
+
+func (s *jsonObjectAggState) Accumulate(args []Value) error {
+	if len(args) != 2 {
+		return fmt.Errorf("incorrect number of arguments: %d", len(args))
+	}
+	if args[0] == nil {
+		return fmt.Errorf("JSON documents may not contain NULL member names")
+	}
+	s.args = append(s.args, args...)
+	return nil
+}
+
+func (s *jsonObjectAggState) Merge(other AggregateState) error {
+	o, ok := other.(*jsonObjectAggState)
+	if !ok {
+		return ErrAggregateState
+	}
+	s.args = append(s.args, o.args...)
+	return nil
+}
+
+func (s *jsonObjectAggState) Finalize() (Value, error) {
+	if s.args == nil {
+		return nil, nil
+	}
+	return objectJSON(s.args)
+}
+
+func extractJSON(doc any, paths ...*JSONPath) Value {
+	if len(paths) == 1 && !paths[0].Wildcard() {
+		matches := paths[0].Find(doc)
+		if len(matches) == 0 || matches[0] == nil {
+			return nil
+		}
+		return NewValue(matches[0])
+	}
+
+	var matches []any
+	for _, path := range paths {
+		matches = append(matches, path.Find(doc)...)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return NewJSON(matches)
+}
+
+func selectJSON(doc any, args []Value) (any, bool, error) {
+	if len(args) == 0 {
+		return doc, true, nil
+	}
+	paths, err := toJSONPaths(args)
+	if err != nil {
+		return nil, false, err
+	}
+	if paths[0].Wildcard() {
+		return nil, false, fmt.Errorf("%w: wildcards are not allowed in %s", ErrInvalidJSONPath, paths[0])
+	}
+	matches := paths[0].Find(doc)
+	if len(matches) == 0 {
+		return nil, false, nil
+	}
+	return matches[0], true, nil
+}
+
+func containsJSON(target, candidate any) bool {
+	switch t := target.(type) {
+	case map[string]any:
+		c, ok := candidate.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, val := range c {
+			v, ok := t[key]
+			if !ok || !containsJSON(v, val) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		if c, ok := candidate.([]any); ok {
+			for _, val := range c {
+				if !containsJSON(t, val) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, v := range t {
+			if containsJSON(v, candidate) {
+				return true
+			}
+		}
+		return false
+	default:
+		return equalJSON(target, candidate)
+	}
+}
+
+func equalJSON(lhs, rhs any) bool {
+	if l, ok := numberJSON(lhs); ok {
+		r, ok := numberJSON(rhs)
+		return ok && l == r
+	}
+	switch l := lhs.(type) {
+	case string, bool, nil:
+		return lhs == rhs
+	case []any:
+		r, ok := rhs.([]any)
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !equalJSON(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		r, ok := rhs.(map[string]any)
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for key, val := range l {
+			if !equalJSON(val, r[key]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func numberJSON(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			m[key] = cloneJSON(val)
+		}
+		return m
+	case []any:
+		arr := make([]any, len(v))
+		for i, val := range v {
+			arr[i] = cloneJSON(val)
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+func toJSONPaths(args []Value) ([]*JSONPath, error) {
+	paths := make([]*JSONPath, 0, len(args))
+	for _, arg := range args {
+		s, err := ToString(arg)
+		if err != nil {
+			return nil, err
+		}
+		path, err := ParseJSONPath(s)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// toJSONDoc reads a document argument, parsing strings as JSON text.
+func toJSONDoc(val Value) (any, error) {
+	switch v := val.(type) {
+	case *VarChar, *VarBinary:
+		b, err := ToBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		var doc any
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON text: %w", err)
+		}
+		return doc, nil
+	default:
+		return jsonOf(val)
+	}
+}
+
+// jsonOf converts a SQL value into a JSON value, keeping strings as JSON strings.
+func jsonOf(val Value) (any, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case *JSON:
+		return v.Interface(), nil
+	case *Int64:
+		return v.Int(), nil
+	case *Uint64:
+		return v.Uint(), nil
+	case *Float64:
+		return v.Float(), nil
+	case *DateTime:
+		return v.Time().Format("2006-01-02 15:04:05.999999"), nil
+	default:
+		return ToString(v)
+	}
+}
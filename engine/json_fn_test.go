@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithJSON(t *testing.T) {
+	d := NewDispatcher(WithJSON())
+
+	doc := NewVarChar(`{"id": 1, "name": "foo", "tags": ["a", "b"], "items": [{"id": 1, "qty": 2}, {"id": 2, "qty": 5}]}`)
+
+	tests := []struct {
+		name     string
+		args     []Value
+		expected Value
+	}{
+		{name: "json_extract", args: []Value{doc, NewVarChar("$.name")}, expected: NewVarChar("foo")},
+		{name: "json_extract", args: []Value{doc, NewVarChar("$.items[*].id")}, expected: NewJSON([]any{float64(1), float64(2)})},
+		{name: "json_extract", args: []Value{doc, NewVarChar("$.id"), NewVarChar("$.name")}, expected: NewJSON([]any{float64(1), "foo"})},
+		{name: "json_extract", args: []Value{doc, NewVarChar("$.missing")}, expected: nil},
+		{name: "json_extract", args: []Value{nil, NewVarChar("$.id")}, expected: nil},
+		{name: "json_unquote", args: []Value{NewVarChar(`"foo\n"`)}, expected: NewVarChar("foo\n")},
+		{name: "json_unquote", args: []Value{NewVarChar("foo")}, expected: NewVarChar("foo")},
+		{name: "json_unquote", args: []Value{NewJSON("foo")}, expected: NewVarChar("foo")},
+		{name: "json_contains", args: []Value{doc, NewVarChar(`{"tags": ["b"]}`)}, expected: NewBool(true)},
+		{name: "json_contains", args: []Value{doc, NewVarChar(`"c"`), NewVarChar("$.tags")}, expected: NewBool(false)},
+		{name: "json_contains", args: []Value{doc, NewVarChar(`{"id": 2}`), NewVarChar("$.items")}, expected: NewBool(true)},
+		{name: "json_contains", args: []Value{doc, NewVarChar("1"), NewVarChar("$.missing")}, expected: nil},
+		{name: "json_contains_path", args: []Value{doc, NewVarChar("one"), NewVarChar("$.id"), NewVarChar("$.missing")}, expected: NewBool(true)},
+		{name: "json_contains_path", args: []Value{doc, NewVarChar("all"), NewVarChar("$.id"), NewVarChar("$.missing")}, expected: NewBool(false)},
+		{name: "json_keys", args: []Value{doc}, expected: NewJSON([]any{"id", "name", "tags", "items"})},
+		{name: "json_keys", args: []Value{doc, NewVarChar("$.items[0]")}, expected: NewJSON([]any{"id", "qty"})},
+		{name: "json_keys", args: []Value{doc, NewVarChar("$.tags")}, expected: nil},
+		{name: "json_length", args: []Value{doc}, expected: NewInt64(4)},
+		{name: "json_length", args: []Value{doc, NewVarChar("$.items")}, expected: NewInt64(2)},
+		{name: "json_length", args: []Value{doc, NewVarChar("$.name")}, expected: NewInt64(1)},
+		{name: "json_type", args: []Value{doc}, expected: NewVarChar("OBJECT")},
+		{name: "json_type", args: []Value{NewVarChar("[1]")}, expected: NewVarChar("ARRAY")},
+		{name: "json_type", args: []Value{NewVarChar("1.5")}, expected: NewVarChar("DOUBLE")},
+		{name: "json_type", args: []Value{NewVarChar(`"x"`)}, expected: NewVarChar("STRING")},
+		{name: "json_type", args: []Value{NewVarChar("null")}, expected: NewVarChar("NULL")},
+		{name: "json_object", args: []Value{NewVarChar("id"), NewInt64(1), NewVarChar("name"), NewVarChar("foo"), NewVarChar("tags"), NewJSON([]any{"a"})}, expected: NewJSON(map[string]any{"id": int64(1), "name": "foo", "tags": []any{"a"}})},
+		{name: "json_object", args: nil, expected: NewJSON(map[string]any{})},
+		{name: "json_array", args: []Value{NewInt64(1), nil, NewVarChar("x")}, expected: NewJSON([]any{int64(1), nil, "x"})},
+		{name: "json_set", args: []Value{NewVarChar(`{"a": 1, "b": [1]}`), NewVarChar("$.a"), NewInt64(2), NewVarChar("$.c"), NewVarChar("x"), NewVarChar("$.b[5]"), NewInt64(2)}, expected: NewJSON(map[string]any{"a": int64(2), "b": []any{float64(1), int64(2)}, "c": "x"})},
+		{name: "json_insert", args: []Value{NewVarChar(`{"a": 1}`), NewVarChar("$.a"), NewInt64(2), NewVarChar("$.b"), NewInt64(3)}, expected: NewJSON(map[string]any{"a": float64(1), "b": int64(3)})},
+		{name: "json_replace", args: []Value{NewVarChar(`{"a": 1}`), NewVarChar("$.a"), NewInt64(2), NewVarChar("$.b"), NewInt64(3)}, expected: NewJSON(map[string]any{"a": int64(2)})},
+		{name: "json_remove", args: []Value{NewVarChar(`{"a": 1, "b": [1, 2, 3]}`), NewVarChar("$.a"), NewVarChar("$.b[last]")}, expected: NewJSON(map[string]any{"b": []any{float64(1), float64(2)}})},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.name, i), func(t *testing.T) {
+			actual, err := d.Dispatch(tt.name, tt.args)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWithJSON_Aggregate(t *testing.T) {
+	d := NewDispatcher(WithJSON())
+
+	tests := []struct {
+		name     string
+		rows     [][]Value
+		expected Value
+	}{
+		{name: "json_arrayagg", rows: [][]Value{{NewInt64(1)}, {nil}, {NewVarChar("x")}}, expected: NewJSON([]any{int64(1), nil, "x"})},
+		{name: "json_arrayagg", rows: nil, expected: nil},
+		{name: "json_objectagg", rows: [][]Value{{NewVarChar("a"), NewInt64(1)}, {NewVarChar("b"), NewInt64(2)}}, expected: NewJSON(map[string]any{"a": int64(1), "b": int64(2)})},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.name, i), func(t *testing.T) {
+			actual, err := d.DispatchAggregate(tt.name, tt.rows)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := d.DispatchAggregate("json_objectagg", [][]Value{{nil, NewInt64(1)}})
+	require.Error(t, err)
+}
+
+func TestWithJSON_Error(t *testing.T) {
+	d := NewDispatcher(WithJSON())
+
+	tests := []struct {
+		name string
+		args []Value
+	}{
+		{name: "json_extract", args: []Value{NewVarChar(`{}`), NewVarChar("a")}},
+		{name: "json_extract", args: []Value{NewVarChar(`{`), NewVarChar("$")}},
+		{name: "json_set", args: []Value{NewVarChar(`{}`), NewVarChar("$[*]"), NewInt64(1)}},
+		{name: "json_remove", args: []Value{NewVarChar(`{}`), NewVarChar("$")}},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.name, i), func(t *testing.T) {
+			_, err := d.Dispatch(tt.name, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
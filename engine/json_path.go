@@ -0,0 +1,426 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// JSONPath is a parsed MySQL JSON path such as `$.items[*].id`, `$."a key"[last-1]` or `$**.id`.
+type JSONPath struct {
+	raw  string
+	legs []jsonPathLeg
+}
+
+type jsonPathLeg struct {
+	kind jsonPathLegKind
+	key  string
+	from jsonPathIndex
+	to   jsonPathIndex
+}
+
+type jsonPathLegKind int
+
+type jsonPathIndex struct {
+	offset int
+	last   bool
+}
+
+const (
+	jsonPathMember jsonPathLegKind = iota
+	jsonPathMemberWildcard
+	jsonPathIndexLeg
+	jsonPathIndexWildcard
+	jsonPathIndexRange
+	jsonPathDoubleWildcard
+)
+
+var ErrInvalidJSONPath = errors.New("invalid JSON path")
+
+func ParseJSONPath(s string) (*JSONPath, error) {
+	p := &jsonPathParser{src: s}
+	legs, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPath{raw: s, legs: legs}, nil
+}
+
+// Find returns every value of doc the path selects, in document order.
+func (p *JSONPath) Find(doc any) []any {
+	var matches []any
+	findJSONPath(doc, p.legs, func(v any) {
+		matches = append(matches, v)
+	})
+	return matches
+}
+
+// Wildcard reports whether the path can select more than one value.
+func (p *JSONPath) Wildcard() bool {
+	for _, leg := range p.legs {
+		switch leg.kind {
+		case jsonPathMemberWildcard, jsonPathIndexWildcard, jsonPathIndexRange, jsonPathDoubleWildcard:
+			return true
+		}
+	}
+	return false
+}
+
+func (p *JSONPath) String() string {
+	return p.raw
+}
+
+func (i jsonPathIndex) resolve(n int) int {
+	if i.last {
+		return n - 1 - i.offset
+	}
+	return i.offset
+}
+
+func findJSONPath(curr any, legs []jsonPathLeg, fn func(any)) {
+	if len(legs) == 0 {
+		fn(curr)
+		return
+	}
+
+	leg, rest := legs[0], legs[1:]
+	switch leg.kind {
+	case jsonPathMember:
+		if m, ok := curr.(map[string]any); ok {
+			if child, ok := m[leg.key]; ok {
+				findJSONPath(child, rest, fn)
+			}
+		}
+	case jsonPathMemberWildcard:
+		if m, ok := curr.(map[string]any); ok {
+			for _, key := range jsonKeys(m) {
+				findJSONPath(m[key], rest, fn)
+			}
+		}
+	case jsonPathIndexLeg:
+		arr, ok := curr.([]any)
+		if !ok {
+			// A non-array behaves as an array holding only itself.
+			if leg.from.resolve(1) == 0 {
+				findJSONPath(curr, rest, fn)
+			}
+			return
+		}
+		if i := leg.from.resolve(len(arr)); i >= 0 && i < len(arr) {
+			findJSONPath(arr[i], rest, fn)
+		}
+	case jsonPathIndexWildcard:
+		if arr, ok := curr.([]any); ok {
+			for _, child := range arr {
+				findJSONPath(child, rest, fn)
+			}
+		}
+	case jsonPathIndexRange:
+		if arr, ok := curr.([]any); ok {
+			from, to := max(leg.from.resolve(len(arr)), 0), min(leg.to.resolve(len(arr)), len(arr)-1)
+			for i := from; i <= to; i++ {
+				findJSONPath(arr[i], rest, fn)
+			}
+		}
+	case jsonPathDoubleWildcard:
+		findJSONPath(curr, rest, fn)
+		switch v := curr.(type) {
+		case map[string]any:
+			for _, key := range jsonKeys(v) {
+				findJSONPath(v[key], legs, fn)
+			}
+		case []any:
+			for _, child := range v {
+				findJSONPath(child, legs, fn)
+			}
+		}
+	}
+}
+
+func setJSONPath(curr any, legs []jsonPathLeg, val any, insert, replace bool) any {
+	if len(legs) == 0 {
+		if replace {
+			return val
+		}
+		return curr
+	}
+
+	leg, rest := legs[0], legs[1:]
+	switch leg.kind {
+	case jsonPathMember:
+		m, ok := curr.(map[string]any)
+		if !ok {
+			return curr
+		}
+		child, exists := m[leg.key]
+		if len(rest) == 0 {
+			if (exists && replace) || (!exists && insert) {
+				m[leg.key] = val
+			}
+		} else if exists {
+			m[leg.key] = setJSONPath(child, rest, val, insert, replace)
+		}
+		return m
+	case jsonPathIndexLeg:
+		arr, ok := curr.([]any)
+		if !ok {
+			i := leg.from.resolve(1)
+			if i == 0 {
+				return setJSONPath(curr, rest, val, insert, replace)
+			}
+			if i > 0 && len(rest) == 0 && insert {
+				return []any{curr, val}
+			}
+			return curr
+		}
+		i := leg.from.resolve(len(arr))
+		switch {
+		case i < 0:
+		case i < len(arr):
+			arr[i] = setJSONPath(arr[i], rest, val, insert, replace)
+		case len(rest) == 0 && insert:
+			arr = append(arr, val)
+		}
+		return arr
+	default:
+		return curr
+	}
+}
+
+func removeJSONPath(curr any, legs []jsonPathLeg) any {
+	leg, rest := legs[0], legs[1:]
+	switch leg.kind {
+	case jsonPathMember:
+		m, ok := curr.(map[string]any)
+		if !ok {
+			return curr
+		}
+		if child, ok := m[leg.key]; ok {
+			if len(rest) == 0 {
+				delete(m, leg.key)
+			} else {
+				m[leg.key] = removeJSONPath(child, rest)
+			}
+		}
+		return m
+	case jsonPathIndexLeg:
+		arr, ok := curr.([]any)
+		if !ok {
+			return curr
+		}
+		i := leg.from.resolve(len(arr))
+		if i < 0 || i >= len(arr) {
+			return arr
+		}
+		if len(rest) == 0 {
+			return append(arr[:i:i], arr[i+1:]...)
+		}
+		arr[i] = removeJSONPath(arr[i], rest)
+		return arr
+	default:
+		return curr
+	}
+}
+
+func jsonKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+type jsonPathParser struct {
+	src string
+	pos int
+}
+
+func (p *jsonPathParser) parse() ([]jsonPathLeg, error) {
+	p.skipSpace()
+	if !p.consume("$") {
+		return nil, p.errorf("must start with $")
+	}
+
+	var legs []jsonPathLeg
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+
+		switch p.src[p.pos] {
+		case '.':
+			p.pos++
+			leg, err := p.parseMember()
+			if err != nil {
+				return nil, err
+			}
+			legs = append(legs, leg)
+		case '[':
+			p.pos++
+			leg, err := p.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			legs = append(legs, leg)
+		case '*':
+			if !p.consume("**") {
+				return nil, p.errorf("expected **")
+			}
+			legs = append(legs, jsonPathLeg{kind: jsonPathDoubleWildcard})
+		default:
+			return nil, p.errorf("unexpected %q", p.src[p.pos])
+		}
+	}
+
+	if len(legs) > 0 && legs[len(legs)-1].kind == jsonPathDoubleWildcard {
+		return nil, p.errorf("** must be followed by a path leg")
+	}
+	return legs, nil
+}
+
+func (p *jsonPathParser) parseMember() (jsonPathLeg, error) {
+	p.skipSpace()
+	if p.consume("*") {
+		return jsonPathLeg{kind: jsonPathMemberWildcard}, nil
+	}
+
+	if p.pos < len(p.src) && p.src[p.pos] == '"' {
+		start := p.pos
+		for p.pos++; p.pos < len(p.src) && p.src[p.pos] != '"'; p.pos++ {
+			if p.src[p.pos] == '\\' {
+				p.pos++
+			}
+		}
+		if p.pos >= len(p.src) {
+			return jsonPathLeg{}, p.errorf("unterminated quoted key")
+		}
+		p.pos++
+		key, err := strconv.Unquote(p.src[start:p.pos])
+		if err != nil {
+			return jsonPathLeg{}, p.errorf("invalid quoted key")
+		}
+		return jsonPathLeg{kind: jsonPathMember, key: key}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := rune(p.src[p.pos])
+		if r != '_' && r != '$' && r < unicode.MaxASCII && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		p.pos++
+	}
+	if start == p.pos {
+		return jsonPathLeg{}, p.errorf("expected key")
+	}
+	return jsonPathLeg{kind: jsonPathMember, key: p.src[start:p.pos]}, nil
+}
+
+func (p *jsonPathParser) parseIndex() (jsonPathLeg, error) {
+	p.skipSpace()
+	if p.consume("*") {
+		p.skipSpace()
+		if !p.consume("]") {
+			return jsonPathLeg{}, p.errorf("expected ]")
+		}
+		return jsonPathLeg{kind: jsonPathIndexWildcard}, nil
+	}
+
+	from, err := p.parseArrayIndex()
+	if err != nil {
+		return jsonPathLeg{}, err
+	}
+	leg := jsonPathLeg{kind: jsonPathIndexLeg, from: from}
+
+	p.skipSpace()
+	if p.consumeWord("to") {
+		to, err := p.parseArrayIndex()
+		if err != nil {
+			return jsonPathLeg{}, err
+		}
+		leg.kind = jsonPathIndexRange
+		leg.to = to
+		p.skipSpace()
+	}
+
+	if !p.consume("]") {
+		return jsonPathLeg{}, p.errorf("expected ]")
+	}
+	return leg, nil
+}
+
+func (p *jsonPathParser) parseArrayIndex() (jsonPathIndex, error) {
+	p.skipSpace()
+	if p.consumeWord("last") {
+		p.skipSpace()
+		if !p.consume("-") {
+			return jsonPathIndex{last: true}, nil
+		}
+		p.skipSpace()
+		n, err := p.parseInt()
+		if err != nil {
+			return jsonPathIndex{}, err
+		}
+		return jsonPathIndex{offset: n, last: true}, nil
+	}
+
+	n, err := p.parseInt()
+	if err != nil {
+		return jsonPathIndex{}, err
+	}
+	return jsonPathIndex{offset: n}, nil
+}
+
+func (p *jsonPathParser) parseInt() (int, error) {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, p.errorf("expected array index")
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, p.errorf("array index out of range")
+	}
+	return n, nil
+}
+
+func (p *jsonPathParser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *jsonPathParser) consumeWord(s string) bool {
+	if len(p.src)-p.pos < len(s) || !strings.EqualFold(p.src[p.pos:p.pos+len(s)], s) {
+		return false
+	}
+	if end := p.pos + len(s); end < len(p.src) && (unicode.IsLetter(rune(p.src[end])) || unicode.IsDigit(rune(p.src[end]))) {
+		return false
+	}
+	p.pos += len(s)
+	return true
+}
+
+func (p *jsonPathParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *jsonPathParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w %q at position %d: %s", ErrInvalidJSONPath, p.src, p.pos, fmt.Sprintf(format, args...))
+}
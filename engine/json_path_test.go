@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		wildcard bool
+		err      bool
+	}{
+		{path: "$"},
+		{path: "$.a.b"},
+		{path: `$."a b".c`},
+		{path: "$.items[0].id"},
+		{path: "$.items[last]"},
+		{path: "$.items[last - 1]"},
+		{path: "$.items[*].id", wildcard: true},
+		{path: "$.*", wildcard: true},
+		{path: "$[1 to 3]", wildcard: true},
+		{path: "$**.id", wildcard: true},
+		{path: "a.b", err: true},
+		{path: "$.", err: true},
+		{path: "$[", err: true},
+		{path: "$[x]", err: true},
+		{path: `$."a`, err: true},
+		{path: "$**", err: true},
+		{path: "$.a b", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := ParseJSONPath(tt.path)
+			if tt.err {
+				require.ErrorIs(t, err, ErrInvalidJSONPath)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wildcard, path.Wildcard())
+			require.Equal(t, tt.path, path.String())
+		})
+	}
+}
+
+func TestJSONPath_Find(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{"a": {"b": 1}, "a b": 2, "items": [{"id": 1}, {"id": 2}, {"id": 3}], "s": "x"}`), &doc))
+
+	tests := []struct {
+		path     string
+		expected []any
+	}{
+		{path: "$.a.b", expected: []any{float64(1)}},
+		{path: `$."a b"`, expected: []any{float64(2)}},
+		{path: "$.items[1].id", expected: []any{float64(2)}},
+		{path: "$.items[last].id", expected: []any{float64(3)}},
+		{path: "$.items[last-2].id", expected: []any{float64(1)}},
+		{path: "$.items[*].id", expected: []any{float64(1), float64(2), float64(3)}},
+		{path: "$.items[1 to last].id", expected: []any{float64(2), float64(3)}},
+		{path: "$**.id", expected: []any{float64(1), float64(2), float64(3)}},
+		{path: "$.s[0]", expected: []any{"x"}},
+		{path: "$.items[5]", expected: nil},
+		{path: "$.missing", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := ParseJSONPath(tt.path)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, path.Find(doc))
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// JSONTablePlan expands the document Expr evaluates to for each row of Input into rows of Columns, appended
+// to that row, keeping those On holds for. Without an Input the document is expanded once. A Left plan keeps a
+// row of Input that expands to none with NULL in Columns, as LEFT JOIN does.
+type JSONTablePlan struct {
+	Input   Plan
+	Expr    Expr
+	Path    string
+	Columns []*JSONTableColumn
+	As      sqlparser.TableIdent
+	On      Expr
+	Left    bool
+}
+
+type jsonTableScanner struct {
+	paths map[string]*JSONPath
+}
+
+var _ Plan = (*JSONTablePlan)(nil)
+
+func (p *JSONTablePlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	scanner, err := newJSONTableScanner(p.Path, p.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	outer := []schema.Row{{}}
+	if p.Input != nil {
+		input, err := p.Input.Run(ctx, binds)
+		if err != nil {
+			return nil, err
+		}
+		if outer, err = schema.ReadAll(input); err != nil {
+			return nil, err
+		}
+	}
+
+	columns := p.columns(p.Columns)
+
+	var rows []schema.Row
+	for _, row := range outer {
+		expanded, err := p.expand(ctx, scanner, row, columns, binds)
+		if err != nil {
+			return nil, err
+		}
+		if len(expanded) == 0 && p.Left {
+			nulls := make([]sqltypes.Value, len(columns))
+			for i := range nulls {
+				nulls[i] = sqltypes.NULL
+			}
+			expanded = append(expanded, schema.Row{
+				Columns: slices.Concat(row.Columns, columns),
+				Values:  slices.Concat(row.Values, nulls),
+			})
+		}
+		rows = append(rows, expanded...)
+	}
+	return schema.NewInMemoryCursor(rows), nil
+}
+
+// expand returns the rows the document of row expands to that On holds for.
+func (p *JSONTablePlan) expand(ctx context.Context, scanner *jsonTableScanner, row schema.Row, columns []*sqlparser.ColName, binds map[string]*querypb.BindVariable) ([]schema.Row, error) {
+	val, err := p.Expr.Eval(ctx, row, binds)
+	if err != nil || val == nil {
+		return nil, err
+	}
+	doc, err := toJSONDoc(val)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []schema.Row
+	for i, item := range scanner.paths[p.Path].Find(doc) {
+		values, err := scanner.scan(item, i+1, p.Columns)
+		if err != nil {
+			return nil, err
+		}
+		for _, vals := range values {
+			r := schema.Row{
+				Columns: slices.Concat(row.Columns, columns),
+				Values:  slices.Concat(row.Values, vals),
+			}
+			if p.On != nil {
+				val, err := p.On.Eval(ctx, r, binds)
+				if err != nil {
+					return nil, err
+				}
+				if !ToBool(val) {
+					continue
+				}
+			}
+			rows = append(rows, r)
+		}
+	}
+	return rows, nil
+}
+
+func (p *JSONTablePlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	var columns []schema.Column
+	if p.Input != nil {
+		input, err := p.Input.Schema(ctx)
+		if err != nil || input == nil {
+			return nil, err
+		}
+		columns = append(columns, input...)
+	}
+	return append(columns, p.schema(p.Columns)...), nil
+}
+
+func (p *JSONTablePlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	if cont, err := f(p); !cont || err != nil || p.Input == nil {
+		return cont, err
+	}
+	return p.Input.Walk(f)
+}
+
+func (p *JSONTablePlan) String() string {
+	var b strings.Builder
+	b.WriteString("JSONTablePlan(")
+	if p.Input != nil {
+		b.WriteString(p.Input.String())
+		b.WriteString(", ")
+	}
+	b.WriteString(p.Expr.String())
+	b.WriteString(", ")
+	b.WriteString(p.Path)
+	b.WriteString(", ")
+	b.WriteString(sqlparser.String(p.As))
+	if p.On != nil {
+		b.WriteString(", ")
+		b.WriteString(p.On.String())
+	}
+	if p.Left {
+		b.WriteString(", LEFT")
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func (p *JSONTablePlan) columns(cols []*JSONTableColumn) []*sqlparser.ColName {
+	var columns []*sqlparser.ColName
+	for _, col := range cols {
+		if col.Nested != nil {
+			columns = append(columns, p.columns(col.Nested)...)
+			continue
+		}
+		columns = append(columns, &sqlparser.ColName{Name: col.Name, Qualifier: sqlparser.TableName{Name: p.As}})
+	}
+	return columns
+}
+
+func (p *JSONTablePlan) schema(cols []*JSONTableColumn) []schema.Column {
+	var columns []schema.Column
+	for _, col := range cols {
+		if col.Nested != nil {
+			columns = append(columns, p.schema(col.Nested)...)
+			continue
+		}
+		columns = append(columns, schema.Column{
+			Name:     &sqlparser.ColName{Name: col.Name, Qualifier: sqlparser.TableName{Name: p.As}},
+			Type:     col.Type,
+			Nullable: !col.Ordinality && !col.Exists,
+		})
+	}
+	return columns
+}
+
+func newJSONTableScanner(path string, columns []*JSONTableColumn) (*jsonTableScanner, error) {
+	s := &jsonTableScanner{paths: make(map[string]*JSONPath)}
+	if err := s.compile(path, columns); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *jsonTableScanner) compile(path string, columns []*JSONTableColumn) error {
+	if _, ok := s.paths[path]; !ok {
+		p, err := ParseJSONPath(path)
+		if err != nil {
+			return err
+		}
+		s.paths[path] = p
+	}
+	for _, col := range columns {
+		if col.Ordinality {
+			continue
+		}
+		if err := s.compile(col.Path, col.Nested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scan returns the rows of columns for item. Sibling NESTED PATH columns are expanded one at a time, leaving the
+// others NULL, and a row is still produced when none of them match.
+func (s *jsonTableScanner) scan(item any, ordinal int, columns []*JSONTableColumn) ([][]sqltypes.Value, error) {
+	type nested struct {
+		offset int
+		rows   [][]sqltypes.Value
+	}
+
+	var row []sqltypes.Value
+	var nests []nested
+	for _, col := range columns {
+		if col.Nested == nil {
+			val, err := s.value(item, ordinal, col)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, val)
+			continue
+		}
+
+		n := nested{offset: len(row)}
+		for i, child := range s.paths[col.Path].Find(item) {
+			rows, err := s.scan(child, i+1, col.Nested)
+			if err != nil {
+				return nil, err
+			}
+			n.rows = append(n.rows, rows...)
+		}
+		nests = append(nests, n)
+		row = append(row, make([]sqltypes.Value, width(col.Nested))...)
+	}
+
+	var rows [][]sqltypes.Value
+	for _, n := range nests {
+		for _, r := range n.rows {
+			clone := slices.Clone(row)
+			copy(clone[n.offset:], r)
+			rows = append(rows, clone)
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (s *jsonTableScanner) value(item any, ordinal int, col *JSONTableColumn) (sqltypes.Value, error) {
+	if col.Ordinality {
+		return ToSQL(NewInt64(int64(ordinal)), col.Type)
+	}
+
+	matches := s.paths[col.Path].Find(item)
+	if col.Exists {
+		return ToSQL(NewBool(len(matches) > 0), col.Type)
+	}
+
+	if len(matches) == 0 {
+		return s.fallback(col, col.OnEmpty, fmt.Errorf("missing value for JSON_TABLE column '%s'", col.Name))
+	}
+	if len(matches) > 1 {
+		return s.fallback(col, col.OnError, fmt.Errorf("more than one value for JSON_TABLE column '%s'", col.Name))
+	}
+	val, err := s.convert(matches[0], col.Type)
+	if err != nil {
+		return s.fallback(col, col.OnError, fmt.Errorf("invalid value for JSON_TABLE column '%s': %w", col.Name, err))
+	}
+	return val, nil
+}
+
+func (s *jsonTableScanner) fallback(col *JSONTableColumn, f JSONTableFallback, cause error) (sqltypes.Value, error) {
+	switch {
+	case f.Error:
+		return sqltypes.NULL, cause
+	case f.Default != nil:
+		var v any = string(f.Default.Val)
+		if err := json.Unmarshal(f.Default.Val, &v); err != nil {
+			v = string(f.Default.Val)
+		}
+		return s.convert(v, col.Type)
+	default:
+		return sqltypes.NULL, nil
+	}
+}
+
+func (s *jsonTableScanner) convert(v any, typ querypb.Type) (sqltypes.Value, error) {
+	if typ == querypb.Type_JSON {
+		return ToSQL(NewJSON(v), typ)
+	}
+	switch v.(type) {
+	case nil:
+		return sqltypes.NULL, nil
+	case map[string]any, []any:
+		return sqltypes.NULL, fmt.Errorf("cannot convert a JSON container to %s", schema.TypeName(typ))
+	}
+	return ToSQL(NewValue(v), typ)
+}
+
+func width(columns []*JSONTableColumn) int {
+	n := 0
+	for _, col := range columns {
+		if col.Nested != nil {
+			n += width(col.Nested)
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestJSONTablePlan_Run(t *testing.T) {
+	id := &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}
+	body := &sqlparser.ColName{Name: sqlparser.NewColIdent("body")}
+	t1 := schema.NewInMemoryTable([]schema.Row{
+		{
+			Columns: []*sqlparser.ColName{id, body},
+			Values:  []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[{"v": 1, "tags": ["a", "b"]}, {"v": "x"}]`))},
+		},
+		{
+			Columns: []*sqlparser.ColName{id, body},
+			Values:  []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NULL},
+		},
+	})
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+
+	as := sqlparser.NewTableIdent("jt")
+	col := func(name string) *sqlparser.ColName {
+		return &sqlparser.ColName{Name: sqlparser.NewColIdent(name), Qualifier: sqlparser.TableName{Name: as}}
+	}
+
+	tests := []struct {
+		plan   Plan
+		binds  map[string]*querypb.BindVariable
+		cursor schema.Cursor
+		err    bool
+	}{
+		{
+			plan: &JSONTablePlan{
+				Expr: &LiteralExpr{Value: sqltypes.NewVarChar(`[1, 2]`)},
+				Path: "$[*]",
+				Columns: []*JSONTableColumn{
+					{Name: sqlparser.NewColIdent("n"), Type: sqltypes.Uint32, Ordinality: true},
+					{Name: sqlparser.NewColIdent("v"), Type: sqltypes.Int64, Path: "$"},
+				},
+				As: as,
+			},
+			cursor: schema.NewInMemoryCursor([]schema.Row{
+				{Columns: []*sqlparser.ColName{col("n"), col("v")}, Values: []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.Uint32, []byte("1")), sqltypes.NewInt64(1)}},
+				{Columns: []*sqlparser.ColName{col("n"), col("v")}, Values: []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.Uint32, []byte("2")), sqltypes.NewInt64(2)}},
+			}),
+		},
+		{
+			plan: &JSONTablePlan{
+				Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+				Expr:  &IndexExpr{Left: &ColumnExpr{Value: body}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}},
+				Path:  "$[*]",
+				Columns: []*JSONTableColumn{
+					{Name: sqlparser.NewColIdent("v"), Type: sqltypes.Int64, Path: "$.v", OnError: JSONTableFallback{Default: sqlparser.NewStrVal([]byte("0"))}},
+					{Name: sqlparser.NewColIdent("e"), Type: sqltypes.Int64, Path: "$.tags", Exists: true},
+					{Path: "$.tags[*]", Nested: []*JSONTableColumn{
+						{Name: sqlparser.NewColIdent("tag"), Type: sqltypes.VarChar, Path: "$"},
+					}},
+				},
+				As: as,
+			},
+			cursor: schema.NewInMemoryCursor([]schema.Row{
+				{Columns: []*sqlparser.ColName{id, body, col("v"), col("e"), col("tag")}, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[{"v": 1, "tags": ["a", "b"]}, {"v": "x"}]`)), sqltypes.NewInt64(1), sqltypes.NewInt64(1), sqltypes.NewVarChar("a")}},
+				{Columns: []*sqlparser.ColName{id, body, col("v"), col("e"), col("tag")}, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[{"v": 1, "tags": ["a", "b"]}, {"v": "x"}]`)), sqltypes.NewInt64(1), sqltypes.NewInt64(1), sqltypes.NewVarChar("b")}},
+				{Columns: []*sqlparser.ColName{id, body, col("v"), col("e"), col("tag")}, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[{"v": 1, "tags": ["a", "b"]}, {"v": "x"}]`)), sqltypes.NewInt64(0), sqltypes.NewInt64(0), sqltypes.NULL}},
+			}),
+		},
+		{
+			plan: &JSONTablePlan{
+				Expr: &LiteralExpr{Value: sqltypes.NewVarChar(`[{}]`)},
+				Path: "$[*]",
+				Columns: []*JSONTableColumn{
+					{Name: sqlparser.NewColIdent("v"), Type: sqltypes.Int64, Path: "$.v", OnEmpty: JSONTableFallback{Error: true}},
+				},
+				As: as,
+			},
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plan.String(), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+			defer cancel()
+
+			cursor, err := tt.plan.Run(ctx, tt.binds)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			expected, err := schema.ReadAll(tt.cursor)
+			require.NoError(t, err)
+
+			actual, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestJSONTablePlan_Schema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	plan := &JSONTablePlan{
+		Input: &NOPPlan{},
+		Expr:  &LiteralExpr{Value: sqltypes.NewVarChar(`[]`)},
+		Path:  "$[*]",
+		Columns: []*JSONTableColumn{
+			{Name: sqlparser.NewColIdent("n"), Type: sqltypes.Uint32, Ordinality: true},
+			{Path: "$.tags[*]", Nested: []*JSONTableColumn{
+				{Name: sqlparser.NewColIdent("tag"), Type: sqltypes.VarChar, Path: "$"},
+			}},
+		},
+		As: sqlparser.NewTableIdent("jt"),
+	}
+
+	cols, err := plan.Schema(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("n"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("jt")}}, Type: sqltypes.Uint32},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("tag"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("jt")}}, Type: sqltypes.VarChar, Nullable: true},
+	}, cols)
+}
@@ -59,6 +59,10 @@ func (p *LimitPlan) Run(ctx context.Context, binds map[string]*querypb.BindVaria
 	}), nil
 }
 
+func (p *LimitPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Input.Schema(ctx)
+}
+
 func (p *LimitPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	if cont, err := f(p); !cont || err != nil {
 		return cont, err
@@ -21,7 +21,7 @@ func (e *AndExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 	if err != nil {
 		return nil, err
 	}
-	if !ToBool(left) {
+	if left != nil && !ToBool(left) {
 		return False, nil
 	}
 
@@ -29,7 +29,13 @@ func (e *AndExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 	if err != nil {
 		return nil, err
 	}
-	return NewBool(ToBool(right)), nil
+	if right != nil && !ToBool(right) {
+		return False, nil
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return True, nil
 }
 
 func (e *AndExpr) Walk(f func(Expr) (bool, error)) (bool, error) {
@@ -73,7 +79,13 @@ func (e *OrExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*que
 	if err != nil {
 		return nil, err
 	}
-	return NewBool(ToBool(right)), nil
+	if ToBool(right) {
+		return True, nil
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return False, nil
 }
 
 func (e *OrExpr) Walk(f func(Expr) (bool, error)) (bool, error) {
@@ -108,6 +120,9 @@ func (e *NotExpr) Eval(ctx context.Context, row schema.Row, binds map[string]*qu
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	return NewBool(!ToBool(val)), nil
 }
 
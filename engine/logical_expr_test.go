@@ -39,6 +39,26 @@ func TestAndExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
 			expected: False,
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(1)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
+			expected: False,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewInt64(0)},
+			right:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: False,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -80,6 +100,26 @@ func TestOrExpr_Eval(t *testing.T) {
 			right:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
 			expected: False,
 		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(1)},
+			expected: True,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NewInt64(1)},
+			right:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: True,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
+			expected: nil,
+		},
+		{
+			left:     &LiteralExpr{Value: sqltypes.NULL},
+			right:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -108,6 +148,14 @@ func TestNotExpr_Eval(t *testing.T) {
 			input:    &LiteralExpr{Value: sqltypes.NewInt64(0)},
 			expected: True,
 		},
+		{
+			input:    &LiteralExpr{Value: sqltypes.NULL},
+			expected: nil,
+		},
+		{
+			input:    &EqualExpr{Left: &LiteralExpr{Value: sqltypes.NewInt64(1)}, Right: &LiteralExpr{Value: sqltypes.NULL}},
+			expected: nil,
+		},
 	}
 
 	for _, tt := range tests {
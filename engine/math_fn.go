@@ -0,0 +1,398 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+var (
+	Abs      = sqlparser.NewColIdent("abs")
+	Round    = sqlparser.NewColIdent("round")
+	Ceil     = sqlparser.NewColIdent("ceil")
+	Ceiling  = sqlparser.NewColIdent("ceiling")
+	Floor    = sqlparser.NewColIdent("floor")
+	Truncate = sqlparser.NewColIdent("truncate")
+	Mod      = sqlparser.NewColIdent("mod")
+	Pow      = sqlparser.NewColIdent("pow")
+	Power    = sqlparser.NewColIdent("power")
+	Sqrt     = sqlparser.NewColIdent("sqrt")
+	Exp      = sqlparser.NewColIdent("exp")
+	Ln       = sqlparser.NewColIdent("ln")
+	Log      = sqlparser.NewColIdent("log")
+	Log2     = sqlparser.NewColIdent("log2")
+	Log10    = sqlparser.NewColIdent("log10")
+	Sign     = sqlparser.NewColIdent("sign")
+	Pi       = sqlparser.NewColIdent("pi")
+	Greatest = sqlparser.NewColIdent("greatest")
+	Least    = sqlparser.NewColIdent("least")
+	Rand     = sqlparser.NewColIdent("rand")
+)
+
+// WithMath registers the MySQL numeric functions. Integer arguments keep an integer result where MySQL does.
+func WithMath() DispatchOption {
+	return func(d *Dispatcher) {
+		d.specs[Abs.String()] = builtin(1, 1, withoutContext(NewAbs()))
+		d.specs[Round.String()] = builtin(1, 2, withoutContext(NewRound()))
+		d.specs[Ceil.String()] = builtin(1, 1, withoutContext(NewCeil()))
+		d.specs[Ceiling.String()] = builtin(1, 1, withoutContext(NewCeil()))
+		d.specs[Floor.String()] = builtin(1, 1, withoutContext(NewFloor()))
+		d.specs[Truncate.String()] = builtin(2, 2, withoutContext(NewTruncate()))
+		d.specs[Mod.String()] = builtin(2, 2, withoutContext(NewMod()))
+		d.specs[Pow.String()] = builtin(2, 2, withoutContext(NewPow()))
+		d.specs[Power.String()] = builtin(2, 2, withoutContext(NewPow()))
+		d.specs[Sqrt.String()] = builtin(1, 1, withoutContext(NewSqrt()))
+		d.specs[Exp.String()] = builtin(1, 1, withoutContext(NewExp()))
+		d.specs[Ln.String()] = builtin(1, 1, withoutContext(NewLn()))
+		d.specs[Log.String()] = builtin(1, 2, withoutContext(NewLog()))
+		d.specs[Log2.String()] = builtin(1, 1, withoutContext(NewLog2()))
+		d.specs[Log10.String()] = builtin(1, 1, withoutContext(NewLog10()))
+		d.specs[Sign.String()] = builtin(1, 1, withoutContext(NewSign()))
+		d.specs[Pi.String()] = builtin(0, 0, withoutContext(NewPi()))
+		d.specs[Greatest.String()] = builtin(2, -1, withoutContext(NewGreatest()))
+		d.specs[Least.String()] = builtin(2, -1, withoutContext(NewLeast()))
+		d.specs[Rand.String()] = FunctionSpec{New: NewRand, Params: []querypb.Type{querypb.Type_NULL_TYPE}, Variadic: true}
+	}
+}
+
+func NewAbs() Function {
+	return NewNumericFunction(1, 1, func(args []Value) (Value, error) {
+		switch v := args[0].(type) {
+		case *Int64:
+			if v.Int() == math.MinInt64 {
+				return nil, fmt.Errorf("value is out of range in abs(%d)", v.Int())
+			}
+			if v.Int() < 0 {
+				return NewInt64(-v.Int()), nil
+			}
+			return v, nil
+		case *Uint64:
+			return v, nil
+		default:
+			return NewFloat64(math.Abs(args[0].(*Float64).Float())), nil
+		}
+	})
+}
+
+func NewRound() Function {
+	return NewNumericFunction(1, 2, func(args []Value) (Value, error) {
+		return scale(args, true)
+	})
+}
+
+func NewTruncate() Function {
+	return NewNumericFunction(2, 2, func(args []Value) (Value, error) {
+		return scale(args, false)
+	})
+}
+
+func NewCeil() Function {
+	return NewNumericFunction(1, 1, func(args []Value) (Value, error) {
+		if v, ok := args[0].(*Float64); ok {
+			return NewFloat64(math.Ceil(v.Float())), nil
+		}
+		return args[0], nil
+	})
+}
+
+func NewFloor() Function {
+	return NewNumericFunction(1, 1, func(args []Value) (Value, error) {
+		if v, ok := args[0].(*Float64); ok {
+			return NewFloat64(math.Floor(v.Float())), nil
+		}
+		return args[0], nil
+	})
+}
+
+func NewMod() Function {
+	return NewNumericFunction(2, 2, func(args []Value) (Value, error) {
+		return mod(args[0], args[1])
+	})
+}
+
+func NewPow() Function {
+	return NewFloatFunction(2, func(args []float64) (Value, error) {
+		v := math.Pow(args[0], args[1])
+		if math.IsNaN(v) {
+			return nil, nil
+		}
+		if math.IsInf(v, 0) {
+			return nil, fmt.Errorf("value is out of range in pow(%v, %v)", args[0], args[1])
+		}
+		return NewFloat64(v), nil
+	})
+}
+
+func NewSqrt() Function {
+	return NewFloatFunction(1, func(args []float64) (Value, error) {
+		if args[0] < 0 {
+			return nil, nil
+		}
+		return NewFloat64(math.Sqrt(args[0])), nil
+	})
+}
+
+func NewExp() Function {
+	return NewFloatFunction(1, func(args []float64) (Value, error) {
+		v := math.Exp(args[0])
+		if math.IsInf(v, 0) {
+			return nil, fmt.Errorf("value is out of range in exp(%v)", args[0])
+		}
+		return NewFloat64(v), nil
+	})
+}
+
+func NewLn() Function {
+	return NewFloatFunction(1, func(args []float64) (Value, error) {
+		if args[0] <= 0 {
+			return nil, nil
+		}
+		return NewFloat64(math.Log(args[0])), nil
+	})
+}
+
+func NewLog() Function {
+	ln := NewLn()
+	log := NewFloatFunction(2, func(args []float64) (Value, error) {
+		base, x := args[0], args[1]
+		if base <= 0 || base == 1 || x <= 0 {
+			return nil, nil
+		}
+		return NewFloat64(math.Log(x) / math.Log(base)), nil
+	})
+	return func(args []Value) (Value, error) {
+		if len(args) == 1 {
+			return ln(args)
+		}
+		return log(args)
+	}
+}
+
+func NewLog2() Function {
+	return NewFloatFunction(1, func(args []float64) (Value, error) {
+		if args[0] <= 0 {
+			return nil, nil
+		}
+		return NewFloat64(math.Log2(args[0])), nil
+	})
+}
+
+func NewLog10() Function {
+	return NewFloatFunction(1, func(args []float64) (Value, error) {
+		if args[0] <= 0 {
+			return nil, nil
+		}
+		return NewFloat64(math.Log10(args[0])), nil
+	})
+}
+
+func NewSign() Function {
+	return NewNumericFunction(1, 1, func(args []Value) (Value, error) {
+		f, err := ToFloat(args[0])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case f > 0:
+			return NewInt64(1), nil
+		case f < 0:
+			return NewInt64(-1), nil
+		default:
+			return NewInt64(0), nil
+		}
+	})
+}
+
+func NewPi() Function {
+	return NewFloatFunction(0, func(_ []float64) (Value, error) {
+		return NewFloat64(math.Pi), nil
+	})
+}
+
+func NewGreatest() Function {
+	return extremum(1)
+}
+
+func NewLeast() Function {
+	return extremum(-1)
+}
+
+// NewRand returns a function computing a random float in [0, 1). With a seed argument it draws the successive
+// values of the sequence the seed starts, and starts over when the seed changes, so the same seed always yields the
+// same values in the same order.
+func NewRand() ContextFunction {
+	var mu sync.Mutex
+	var source *rand.Rand
+	var seed int64
+	return func(_ context.Context, args []Value) (Value, error) {
+		if len(args) > 1 {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		if len(args) == 0 {
+			return NewFloat64(rand.Float64()), nil
+		}
+
+		var n int64
+		if args[0] != nil {
+			var err error
+			if n, err = ToInt(args[0]); err != nil {
+				return nil, err
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if source == nil || n != seed {
+			source = rand.New(rand.NewPCG(uint64(n), 0))
+			seed = n
+		}
+		return NewFloat64(source.Float64()), nil
+	}
+}
+
+// NewNumericFunction returns a function taking between minArgs and maxArgs arguments, each converted to Int64,
+// Uint64 or Float64. Strings and other values become Float64. A NULL argument yields NULL.
+func NewNumericFunction(minArgs, maxArgs int, fn func(args []Value) (Value, error)) Function {
+	return func(args []Value) (Value, error) {
+		if len(args) < minArgs || len(args) > maxArgs {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		nums := make([]Value, 0, len(args))
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+			num, err := toNumber(arg)
+			if err != nil {
+				return nil, err
+			}
+			nums = append(nums, num)
+		}
+		return fn(nums)
+	}
+}
+
+// NewFloatFunction returns a function taking exactly n arguments converted to float64. A NULL argument yields NULL.
+func NewFloatFunction(n int, fn func(args []float64) (Value, error)) Function {
+	return NewNumericFunction(n, n, func(args []Value) (Value, error) {
+		floats := make([]float64, 0, len(args))
+		for _, arg := range args {
+			f, err := ToFloat(arg)
+			if err != nil {
+				return nil, err
+			}
+			floats = append(floats, f)
+		}
+		return fn(floats)
+	})
+}
+
+func toNumber(val Value) (Value, error) {
+	switch v := val.(type) {
+	case *Int64, *Uint64, *Float64:
+		return v, nil
+	default:
+		f, err := ToFloat(v)
+		if err != nil {
+			return nil, err
+		}
+		return NewFloat64(f), nil
+	}
+}
+
+func scale(args []Value, round bool) (Value, error) {
+	var d int64
+	if len(args) > 1 {
+		var err error
+		if d, err = ToInt(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	d = min(max(d, -30), 30)
+
+	switch v := args[0].(type) {
+	case *Int64:
+		if d >= 0 {
+			return v, nil
+		}
+		if d < -18 {
+			return NewInt64(0), nil
+		}
+		p := int64(math.Pow10(int(-d)))
+		q := v.Int() / p * p
+		if rem := v.Int() - q; round && rem*2 >= p {
+			if q > math.MaxInt64-p {
+				return nil, fmt.Errorf("value is out of range in round(%d, %d)", v.Int(), d)
+			}
+			q += p
+		} else if round && rem*2 <= -p {
+			if q < math.MinInt64+p {
+				return nil, fmt.Errorf("value is out of range in round(%d, %d)", v.Int(), d)
+			}
+			q -= p
+		}
+		return NewInt64(q), nil
+	case *Uint64:
+		if d >= 0 {
+			return v, nil
+		}
+		if d < -19 {
+			return NewUint64(0), nil
+		}
+		p := uint64(math.Pow10(int(-d)))
+		q := v.Uint() / p * p
+		if round && (v.Uint()-q) >= p-(v.Uint()-q) {
+			if q > math.MaxUint64-p {
+				return nil, fmt.Errorf("value is out of range in round(%d, %d)", v.Uint(), d)
+			}
+			q += p
+		}
+		return NewUint64(q), nil
+	default:
+		f := args[0].(*Float64).Float()
+		p := math.Pow10(int(d))
+		if math.IsInf(f*p, 0) {
+			return NewFloat64(f), nil
+		}
+		if round {
+			return NewFloat64(math.Round(f*p) / p), nil
+		}
+		return NewFloat64(math.Trunc(f*p) / p), nil
+	}
+}
+
+func extremum(sign int) Function {
+	return func(args []Value) (Value, error) {
+		if len(args) < 2 {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+		}
+
+		result := args[0]
+		for _, arg := range args[1:] {
+			l, r, err := Promote(result, arg)
+			if err != nil {
+				return nil, err
+			}
+			cmp, err := Compare(r, l)
+			if err != nil {
+				return nil, err
+			}
+			if cmp*sign > 0 {
+				result = r
+			} else {
+				result = l
+			}
+		}
+		return result, nil
+	}
+}
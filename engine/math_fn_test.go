@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestWithMath(t *testing.T) {
+	d := NewDispatcher(WithMath())
+
+	tests := []struct {
+		name     string
+		args     []Value
+		expected Value
+	}{
+		{name: "abs", args: []Value{NewInt64(-3)}, expected: NewInt64(3)},
+		{name: "abs", args: []Value{NewFloat64(-1.5)}, expected: NewFloat64(1.5)},
+		{name: "abs", args: []Value{NewVarChar("-2")}, expected: NewFloat64(2)},
+		{name: "abs", args: []Value{nil}, expected: nil},
+		{name: "round", args: []Value{NewFloat64(2.5)}, expected: NewFloat64(3)},
+		{name: "round", args: []Value{NewFloat64(-2.5)}, expected: NewFloat64(-3)},
+		{name: "round", args: []Value{NewFloat64(1.2345), NewInt64(2)}, expected: NewFloat64(1.23)},
+		{name: "round", args: []Value{NewInt64(1250), NewInt64(-2)}, expected: NewInt64(1300)},
+		{name: "round", args: []Value{NewInt64(-1250), NewInt64(-2)}, expected: NewInt64(-1300)},
+		{name: "round", args: []Value{NewInt64(7), NewInt64(2)}, expected: NewInt64(7)},
+		{name: "round", args: []Value{NewFloat64(1.5), nil}, expected: nil},
+		{name: "round", args: []Value{NewInt64(math.MaxInt64), NewInt64(-20)}, expected: NewInt64(0)},
+		{name: "truncate", args: []Value{NewFloat64(1.999), NewInt64(1)}, expected: NewFloat64(1.9)},
+		{name: "truncate", args: []Value{NewFloat64(-1.999), NewInt64(0)}, expected: NewFloat64(-1)},
+		{name: "truncate", args: []Value{NewInt64(1299), NewInt64(-2)}, expected: NewInt64(1200)},
+		{name: "ceil", args: []Value{NewFloat64(1.2)}, expected: NewFloat64(2)},
+		{name: "ceiling", args: []Value{NewInt64(4)}, expected: NewInt64(4)},
+		{name: "floor", args: []Value{NewFloat64(-1.2)}, expected: NewFloat64(-2)},
+		{name: "mod", args: []Value{NewInt64(-7), NewInt64(3)}, expected: NewInt64(-1)},
+		{name: "mod", args: []Value{NewFloat64(7.5), NewInt64(2)}, expected: NewFloat64(1.5)},
+		{name: "mod", args: []Value{NewInt64(7), nil}, expected: nil},
+		{name: "mod", args: []Value{NewInt64(7), NewInt64(0)}, expected: nil},
+		{name: "mod", args: []Value{NewUint64(7), NewUint64(0)}, expected: nil},
+		{name: "pow", args: []Value{NewInt64(2), NewInt64(10)}, expected: NewFloat64(1024)},
+		{name: "power", args: []Value{NewInt64(4), NewFloat64(0.5)}, expected: NewFloat64(2)},
+		{name: "sqrt", args: []Value{NewInt64(16)}, expected: NewFloat64(4)},
+		{name: "sqrt", args: []Value{NewInt64(-1)}, expected: nil},
+		{name: "exp", args: []Value{NewInt64(0)}, expected: NewFloat64(1)},
+		{name: "ln", args: []Value{NewFloat64(math.E)}, expected: NewFloat64(1)},
+		{name: "ln", args: []Value{NewInt64(0)}, expected: nil},
+		{name: "log", args: []Value{NewInt64(1)}, expected: NewFloat64(0)},
+		{name: "log", args: []Value{NewInt64(2), NewInt64(8)}, expected: NewFloat64(3)},
+		{name: "log", args: []Value{NewInt64(1), NewInt64(8)}, expected: nil},
+		{name: "log2", args: []Value{NewInt64(8)}, expected: NewFloat64(3)},
+		{name: "log10", args: []Value{NewInt64(100)}, expected: NewFloat64(2)},
+		{name: "sign", args: []Value{NewFloat64(-0.5)}, expected: NewInt64(-1)},
+		{name: "sign", args: []Value{NewInt64(0)}, expected: NewInt64(0)},
+		{name: "pi", args: nil, expected: NewFloat64(math.Pi)},
+		{name: "greatest", args: []Value{NewInt64(1), NewInt64(3), NewInt64(2)}, expected: NewInt64(3)},
+		{name: "greatest", args: []Value{NewInt64(1), NewFloat64(2.5)}, expected: NewFloat64(2.5)},
+		{name: "greatest", args: []Value{NewVarChar("a"), NewVarChar("b")}, expected: NewVarChar("b")},
+		{name: "greatest", args: []Value{NewInt64(1), nil}, expected: nil},
+		{name: "least", args: []Value{NewInt64(1), NewInt64(3), NewInt64(-2)}, expected: NewInt64(-2)},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.name, i), func(t *testing.T) {
+			actual, err := d.Dispatch(tt.name, tt.args)
+			require.NoError(t, err)
+			if expected, ok := tt.expected.(*Float64); ok {
+				require.IsType(t, expected, actual)
+				require.InDelta(t, expected.Float(), actual.(*Float64).Float(), 1e-9)
+			} else {
+				require.Equal(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWithMath_OutOfRange(t *testing.T) {
+	d := NewDispatcher(WithMath())
+
+	tests := []struct {
+		name string
+		args []Value
+	}{
+		{name: "round", args: []Value{NewInt64(math.MaxInt64), NewInt64(-1)}},
+		{name: "round", args: []Value{NewInt64(math.MinInt64), NewInt64(-1)}},
+		{name: "round", args: []Value{NewUint64(math.MaxUint64), NewInt64(-1)}},
+		{name: "abs", args: []Value{NewInt64(math.MinInt64)}},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.name, i), func(t *testing.T) {
+			_, err := d.Dispatch(tt.name, tt.args)
+			require.ErrorContains(t, err, "out of range")
+		})
+	}
+}
+
+func TestWithMath_Rand(t *testing.T) {
+	d := NewDispatcher(WithMath())
+
+	v, err := d.Dispatch("rand", nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, v.(*Float64).Float(), 0.0)
+	require.Less(t, v.(*Float64).Float(), 1.0)
+
+	v1, err := d.Dispatch("rand", []Value{NewInt64(42)})
+	require.NoError(t, err)
+	v2, err := d.Dispatch("rand", []Value{NewInt64(42)})
+	require.NoError(t, err)
+	require.Equal(t, v1, v2)
+
+	v3, err := d.Dispatch("rand", []Value{NewInt64(7)})
+	require.NoError(t, err)
+	require.NotEqual(t, v1, v3)
+}
+
+func TestWithMath_RandSeed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	d := NewDispatcher(WithMath())
+	call := func() *CallExpr {
+		return &CallExpr{Dispatcher: d, Name: Rand, Input: &LiteralExpr{Value: sqltypes.NewInt64(3)}}
+	}
+
+	expr := call()
+	v1, err := expr.Eval(ctx, schema.Row{}, nil)
+	require.NoError(t, err)
+	v2, err := expr.Eval(ctx, schema.Row{}, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, v1, v2)
+
+	expr = call()
+	v3, err := expr.Eval(ctx, schema.Row{}, nil)
+	require.NoError(t, err)
+	v4, err := expr.Eval(ctx, schema.Row{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []Value{v1, v2}, []Value{v3, v4})
+}
@@ -16,6 +16,10 @@ func (p *NOPPlan) Run(_ context.Context, _ map[string]*querypb.BindVariable) (sc
 	return schema.NewInMemoryCursor(nil), nil
 }
 
+func (p *NOPPlan) Schema(_ context.Context) ([]schema.Column, error) {
+	return []schema.Column{}, nil
+}
+
 func (p *NOPPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	return f(p)
 }
@@ -63,6 +63,10 @@ func (p *OrderPlan) Run(ctx context.Context, binds map[string]*querypb.BindVaria
 	return schema.NewInMemoryCursor(rows), nil
 }
 
+func (p *OrderPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Input.Schema(ctx)
+}
+
 func (p *OrderPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	if cont, err := f(p); !cont || err != nil {
 		return cont, err
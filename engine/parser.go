@@ -0,0 +1,1031 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+type token struct {
+	typ int
+	val string
+	pos int
+}
+
+type scanner struct {
+	sql    string
+	tokens []token
+	offset int
+}
+
+// Parse parses sql into a statement, accepting the statements sqlparser drops or rejects.
+func Parse(sql string) (sqlparser.Statement, error) {
+	tokens := tokenize(sql)
+	for _, parse := range []func(*scanner) (sqlparser.Statement, bool, error){parseShow, parseCreateView, parseDropView, parseWith} {
+		if stmt, ok, err := parse(&scanner{sql: sql, tokens: tokens}); ok {
+			return stmt, err
+		}
+	}
+
+	sql = rewriteExtract(sql, tokens)
+	sql, tables, err := rewriteJSONTable(sql)
+	if err != nil {
+		return nil, err
+	}
+	sql, filters, err := rewriteFilter(sql)
+	if err != nil {
+		return nil, err
+	}
+	sql, windows, err := rewriteWindow(sql)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) > 0 {
+		if err := replaceJSONTable(stmt, tables); err != nil {
+			return nil, err
+		}
+	}
+	if len(windows) > 0 {
+		if err := replaceWindow(stmt, windows); err != nil {
+			return nil, err
+		}
+	}
+	if len(filters) > 0 {
+		if err := replaceFilter(stmt, filters); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+// rewriteExtract rewrites EXTRACT(unit FROM expr), which sqlparser rejects, into the call extract('unit', expr).
+func rewriteExtract(sql string, tokens []token) string {
+	for i := len(tokens) - 4; i >= 0; i-- {
+		if tokens[i].typ != sqlparser.ID || !strings.EqualFold(tokens[i].val, "extract") || tokens[i+1].typ != '(' || tokens[i+3].typ != sqlparser.FROM {
+			continue
+		}
+		unit := tokens[i+2]
+		if unit.val == "" || strings.ContainsFunc(unit.val, func(r rune) bool { return r != '_' && !unicode.IsLetter(r) }) {
+			continue
+		}
+		sql = sql[:unit.pos] + "'" + sql[unit.pos:unit.pos+len(unit.val)] + "'," + sql[tokens[i+3].pos+len("from"):]
+	}
+	return sql
+}
+
+// rewriteJSONTable replaces each JSON_TABLE(...), which sqlparser rejects, with a placeholder table name
+// and returns the parsed tables by placeholder.
+func rewriteJSONTable(sql string) (string, map[string]*JSONTable, error) {
+	tokens := tokenize(sql)
+	if !slices.ContainsFunc(tokens, func(tok token) bool { return tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "json_table") }) {
+		return sql, nil, nil
+	}
+
+	tables := make(map[string]*JSONTable)
+	for i := len(tokens) - 2; i >= 0; i-- {
+		if tokens[i].typ != sqlparser.ID || !strings.EqualFold(tokens[i].val, "json_table") || tokens[i+1].typ != '(' {
+			continue
+		}
+		s := &scanner{sql: sql, tokens: tokens, offset: i + 2}
+		table, err := parseJSONTable(s)
+		if err != nil {
+			return "", nil, err
+		}
+		name := fmt.Sprintf("__json_table_%d", len(tables))
+		tables[name] = table
+		sql = sql[:tokens[i].pos] + name + sql[s.tokens[s.offset-1].pos+1:]
+	}
+	return sql, tables, nil
+}
+
+// replaceJSONTable puts the tables back in place of their placeholders.
+func replaceJSONTable(stmt sqlparser.Statement, tables map[string]*JSONTable) error {
+	var err error
+	replace := func(expr sqlparser.TableExpr) sqlparser.TableExpr {
+		e, ok := expr.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return expr
+		}
+		name, ok := e.Expr.(sqlparser.TableName)
+		if !ok || !name.Qualifier.IsEmpty() {
+			return expr
+		}
+		table, ok := tables[name.Name.String()]
+		if !ok {
+			return expr
+		}
+		if e.As.IsEmpty() {
+			err = fmt.Errorf("every table function must have an alias")
+		}
+		table.AliasedTableExpr = e
+		return table
+	}
+
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.Select:
+			for i, expr := range n.From {
+				n.From[i] = replace(expr)
+			}
+		case *sqlparser.ParenTableExpr:
+			for i, expr := range n.Exprs {
+				n.Exprs[i] = replace(expr)
+			}
+		case *sqlparser.JoinTableExpr:
+			n.LeftExpr = replace(n.LeftExpr)
+			n.RightExpr = replace(n.RightExpr)
+		}
+		return true, nil
+	}, stmt)
+	return err
+}
+
+// rewriteWindow rewrites each fn(args) OVER (spec), which sqlparser rejects, into the call __window_N(args)
+// and returns the windows by placeholder.
+func rewriteWindow(sql string) (string, map[string]*WindowExpr, error) {
+	windows := make(map[string]*WindowExpr)
+	for {
+		tokens := tokenize(sql)
+		i := 1
+		for ; i < len(tokens)-1; i++ {
+			if tokens[i].typ == sqlparser.ID && strings.EqualFold(tokens[i].val, "over") && tokens[i-1].typ == ')' && tokens[i+1].typ == '(' {
+				break
+			}
+		}
+		if i >= len(tokens)-1 {
+			return sql, windows, nil
+		}
+
+		j, depth := i-1, 0
+		for ; j >= 0; j-- {
+			if tokens[j].typ == ')' {
+				depth++
+			} else if tokens[j].typ == '(' {
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if j < 1 || tokens[j-1].typ != sqlparser.ID {
+			return "", nil, errSyntax(sql, tokens[i].pos)
+		}
+		name := tokens[j-1]
+
+		s := &scanner{sql: sql, tokens: tokens, offset: i + 2}
+		spec, err := parseWindowSpec(s)
+		if err != nil {
+			return "", nil, err
+		}
+		if tok := s.next(); tok.typ != ')' {
+			return "", nil, errSyntax(sql, tok.pos)
+		}
+
+		placeholder := fmt.Sprintf("__window_%d", len(windows))
+		windows[placeholder] = &WindowExpr{FuncExpr: &sqlparser.FuncExpr{Name: sqlparser.NewColIdent(name.val)}, Over: spec}
+		sql = sql[:name.pos] + placeholder + sql[tokens[j].pos:tokens[i].pos] + sql[s.tokens[s.offset-1].pos+1:]
+	}
+}
+
+// replaceWindow puts the windows back in place of their placeholder calls, which may only appear
+// in the select list and ORDER BY.
+func replaceWindow(stmt sqlparser.Statement, windows map[string]*WindowExpr) error {
+	calls := make(map[*sqlparser.FuncExpr]*WindowExpr)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if n, ok := node.(*sqlparser.FuncExpr); ok {
+			if window, ok := windows[n.Name.String()]; ok {
+				n.Name = window.Name
+				window.FuncExpr = n
+				calls[n] = window
+			}
+		}
+		return true, nil
+	}, stmt)
+
+	replace := func(expr sqlparser.Expr) sqlparser.Expr {
+		for call, window := range calls {
+			expr = sqlparser.ReplaceExpr(expr, call, window)
+		}
+		return expr
+	}
+
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.AliasedExpr:
+			n.Expr = replace(n.Expr)
+		case *sqlparser.Order:
+			n.Expr = replace(n.Expr)
+		}
+		return true, nil
+	}, stmt)
+
+	// A window's own call is never visited, so any call left over was not replaced.
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if n, ok := node.(*sqlparser.FuncExpr); ok && calls[n] != nil {
+			return false, fmt.Errorf("window functions are only allowed in the select list and order by")
+		}
+		return true, nil
+	}, stmt)
+}
+
+// rewriteFilter rewrites each fn(args) FILTER (WHERE cond), which sqlparser rejects, into the call
+// __filter_N(args) and returns the filtered calls by placeholder.
+func rewriteFilter(sql string) (string, map[string]*FilterExpr, error) {
+	filters := make(map[string]*FilterExpr)
+	for {
+		tokens := tokenize(sql)
+		i := 1
+		for ; i < len(tokens)-2; i++ {
+			if tokens[i].typ == sqlparser.ID && strings.EqualFold(tokens[i].val, "filter") && tokens[i-1].typ == ')' && tokens[i+1].typ == '(' && tokens[i+2].typ == sqlparser.WHERE {
+				break
+			}
+		}
+		if i >= len(tokens)-2 {
+			return sql, filters, nil
+		}
+
+		j, depth := i-1, 0
+		for ; j >= 0; j-- {
+			if tokens[j].typ == ')' {
+				depth++
+			} else if tokens[j].typ == '(' {
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if j < 1 || tokens[j-1].typ != sqlparser.ID {
+			return "", nil, errSyntax(sql, tokens[i].pos)
+		}
+		name := tokens[j-1]
+
+		s := &scanner{sql: sql, tokens: tokens, offset: i + 3}
+		cond, err := parseExpr(s, func(token) bool { return false })
+		if err != nil {
+			return "", nil, err
+		}
+		if tok := s.next(); tok.typ != ')' {
+			return "", nil, errSyntax(sql, tok.pos)
+		}
+		if tok := s.peek(); tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "over") {
+			return "", nil, fmt.Errorf("FILTER is not supported in window functions")
+		}
+
+		placeholder := fmt.Sprintf("__filter_%d", len(filters))
+		filters[placeholder] = &FilterExpr{FuncExpr: &sqlparser.FuncExpr{Name: sqlparser.NewColIdent(name.val)}, Where: cond}
+		sql = sql[:name.pos] + placeholder + sql[tokens[j].pos:tokens[i].pos] + sql[s.tokens[s.offset-1].pos+1:]
+	}
+}
+
+// replaceFilter puts the filtered calls back in place of their placeholder calls, which may only appear
+// in the select list, HAVING, ORDER BY and windows.
+func replaceFilter(stmt sqlparser.Statement, filters map[string]*FilterExpr) error {
+	calls := make(map[*sqlparser.FuncExpr]*FilterExpr)
+	var find func(node sqlparser.SQLNode) (bool, error)
+	find = func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.FuncExpr:
+			if filter, ok := filters[n.Name.String()]; ok {
+				n.Name = filter.Name
+				filter.FuncExpr = n
+				calls[n] = filter
+			}
+		case *WindowExpr:
+			_ = sqlparser.Walk(find, n.Over.PartitionBy, n.Over.OrderBy)
+		}
+		return true, nil
+	}
+	_ = sqlparser.Walk(find, stmt)
+
+	replace := func(expr sqlparser.Expr) sqlparser.Expr {
+		for call, filter := range calls {
+			expr = sqlparser.ReplaceExpr(expr, call, filter)
+		}
+		return expr
+	}
+
+	var visit func(node sqlparser.SQLNode) (bool, error)
+	visit = func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.AliasedExpr:
+			n.Expr = replace(n.Expr)
+		case *sqlparser.Order:
+			n.Expr = replace(n.Expr)
+		case *sqlparser.Where:
+			if n != nil && n.Type == sqlparser.HavingStr {
+				n.Expr = replace(n.Expr)
+			}
+		case *WindowExpr:
+			for i, expr := range n.Over.PartitionBy {
+				n.Over.PartitionBy[i] = replace(expr)
+			}
+			_ = sqlparser.Walk(visit, n.Over.OrderBy)
+		}
+		return true, nil
+	}
+	_ = sqlparser.Walk(visit, stmt)
+
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if n, ok := node.(*sqlparser.FuncExpr); ok && calls[n] != nil {
+			return false, fmt.Errorf("FILTER is only allowed in the select list, having and order by")
+		}
+		return true, nil
+	}, stmt)
+}
+
+// parseJSONTable parses the arguments of JSON_TABLE(expr, path COLUMNS (column, ...)) up to the closing parenthesis.
+func parseJSONTable(s *scanner) (*JSONTable, error) {
+	expr, err := parseExpr(s, func(tok token) bool { return tok.typ == ',' })
+	if err != nil {
+		return nil, err
+	}
+	if tok := s.next(); tok.typ != ',' {
+		return nil, errSyntax(s.sql, tok.pos)
+	}
+
+	path := s.next()
+	if path.typ != sqlparser.STRING {
+		return nil, errSyntax(s.sql, path.pos)
+	}
+	columns, err := parseJSONTableColumns(s)
+	if err != nil {
+		return nil, err
+	}
+	if tok := s.next(); tok.typ != ')' {
+		return nil, errSyntax(s.sql, tok.pos)
+	}
+	return &JSONTable{Expr: expr, Path: path.val, Columns: columns}, nil
+}
+
+// parseJSONTableColumns parses COLUMNS (column, ...), where a column is one of
+// name FOR ORDINALITY, name type [EXISTS] PATH path [on_empty] [on_error], or NESTED [PATH] path COLUMNS (...).
+func parseJSONTableColumns(s *scanner) ([]*JSONTableColumn, error) {
+	if tok := s.next(); tok.typ != sqlparser.ID || !strings.EqualFold(tok.val, "columns") {
+		return nil, errSyntax(s.sql, tok.pos)
+	}
+	if tok := s.next(); tok.typ != '(' {
+		return nil, errSyntax(s.sql, tok.pos)
+	}
+
+	var columns []*JSONTableColumn
+	for {
+		name := s.next()
+		if name.typ != sqlparser.ID {
+			return nil, errSyntax(s.sql, name.pos)
+		}
+
+		col := &JSONTableColumn{Name: sqlparser.NewColIdent(name.val)}
+		switch tok := s.peek(); {
+		case strings.EqualFold(name.val, "nested") && (tok.typ == sqlparser.STRING || (tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "path"))):
+			if tok.typ == sqlparser.ID {
+				s.next()
+			}
+			path := s.next()
+			if path.typ != sqlparser.STRING {
+				return nil, errSyntax(s.sql, path.pos)
+			}
+			nested, err := parseJSONTableColumns(s)
+			if err != nil {
+				return nil, err
+			}
+			col = &JSONTableColumn{Path: path.val, Nested: nested}
+		case tok.typ == sqlparser.FOR:
+			s.next()
+			if tok := s.next(); tok.typ != sqlparser.ID || !strings.EqualFold(tok.val, "ordinality") {
+				return nil, errSyntax(s.sql, tok.pos)
+			}
+			col.Type = querypb.Type_UINT32
+			col.Ordinality = true
+		default:
+			typ, err := parseColumnType(s)
+			if err != nil {
+				return nil, err
+			}
+			col.Type = typ
+			if s.peek().typ == sqlparser.EXISTS {
+				s.next()
+				col.Exists = true
+			}
+			if tok := s.next(); tok.typ != sqlparser.ID || !strings.EqualFold(tok.val, "path") {
+				return nil, errSyntax(s.sql, tok.pos)
+			}
+			path := s.next()
+			if path.typ != sqlparser.STRING {
+				return nil, errSyntax(s.sql, path.pos)
+			}
+			col.Path = path.val
+			if err := parseJSONTableFallbacks(s, col); err != nil {
+				return nil, err
+			}
+		}
+		columns = append(columns, col)
+
+		if tok := s.next(); tok.typ == ')' {
+			return columns, nil
+		} else if tok.typ != ',' {
+			return nil, errSyntax(s.sql, tok.pos)
+		}
+	}
+}
+
+// parseJSONTableFallbacks parses {NULL | ERROR | DEFAULT value} ON {EMPTY | ERROR}, at most once each.
+func parseJSONTableFallbacks(s *scanner, col *JSONTableColumn) error {
+	for {
+		var fallback JSONTableFallback
+		switch tok := s.peek(); {
+		case tok.typ == sqlparser.NULL:
+		case tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "error"):
+			fallback.Error = true
+		case tok.typ == sqlparser.DEFAULT:
+			s.next()
+			val := s.peek()
+			if val.typ != sqlparser.STRING {
+				return errSyntax(s.sql, val.pos)
+			}
+			fallback.Default = sqlparser.NewStrVal([]byte(val.val))
+		default:
+			return nil
+		}
+		s.next()
+
+		if tok := s.next(); tok.typ != sqlparser.ON {
+			return errSyntax(s.sql, tok.pos)
+		}
+		switch tok := s.next(); {
+		case tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "empty"):
+			col.OnEmpty = fallback
+		case tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "error"):
+			col.OnError = fallback
+		default:
+			return errSyntax(s.sql, tok.pos)
+		}
+	}
+}
+
+// parseColumnType parses a type such as INT, INT UNSIGNED, VARCHAR(255) or DECIMAL(10, 2).
+func parseColumnType(s *scanner) (querypb.Type, error) {
+	tok := s.next()
+	name := tok.val
+	if tok.typ == sqlparser.UNSIGNED {
+		name = "unsigned " + s.next().val
+	}
+	if s.peek().typ == '(' {
+		for tok := s.next(); tok.typ != ')'; tok = s.next() {
+			if tok.typ == 0 {
+				return 0, errSyntax(s.sql, tok.pos)
+			}
+		}
+	}
+	if s.peek().typ == sqlparser.UNSIGNED {
+		s.next()
+		name = "unsigned " + name
+	}
+	typ, ok := schema.ParseTypeName(name)
+	if !ok {
+		return 0, errSyntax(s.sql, tok.pos)
+	}
+	return typ, nil
+}
+
+// parseWindowSpec parses [PARTITION BY expr, ...] [ORDER BY expr [ASC | DESC], ...] [{ROWS | RANGE} frame]
+// up to the closing parenthesis.
+func parseWindowSpec(s *scanner) (*WindowSpec, error) {
+	spec := &WindowSpec{}
+	if s.peek().typ == sqlparser.PARTITION {
+		s.next()
+		if tok := s.next(); tok.typ != sqlparser.BY {
+			return nil, errSyntax(s.sql, tok.pos)
+		}
+		stmt, err := parseSpan(s, "select 1 from dual group by %s", func(tok token) bool { return tok.typ == sqlparser.ORDER || isWindowFrameUnit(tok) })
+		if err != nil {
+			return nil, err
+		}
+		spec.PartitionBy = sqlparser.Exprs(stmt.GroupBy)
+	}
+	if s.peek().typ == sqlparser.ORDER {
+		s.next()
+		if tok := s.next(); tok.typ != sqlparser.BY {
+			return nil, errSyntax(s.sql, tok.pos)
+		}
+		stmt, err := parseSpan(s, "select 1 from dual order by %s", isWindowFrameUnit)
+		if err != nil {
+			return nil, err
+		}
+		spec.OrderBy = stmt.OrderBy
+	}
+	if isWindowFrameUnit(s.peek()) {
+		frame := &WindowFrame{Unit: strings.ToLower(s.next().val), End: WindowFrameBound{Type: CurrentRowStr}}
+		between := s.peek().typ == sqlparser.BETWEEN
+		if between {
+			s.next()
+		}
+		start, err := parseWindowFrameBound(s)
+		if err != nil {
+			return nil, err
+		}
+		frame.Start = start
+		if between {
+			if tok := s.next(); tok.typ != sqlparser.AND {
+				return nil, errSyntax(s.sql, tok.pos)
+			}
+			if frame.End, err = parseWindowFrameBound(s); err != nil {
+				return nil, err
+			}
+		}
+		spec.Frame = frame
+	}
+	return spec, nil
+}
+
+// parseWindowFrameBound parses UNBOUNDED {PRECEDING | FOLLOWING}, CURRENT ROW or expr {PRECEDING | FOLLOWING}.
+func parseWindowFrameBound(s *scanner) (WindowFrameBound, error) {
+	isDirection := func(tok token) bool {
+		return tok.typ == sqlparser.ID && (strings.EqualFold(tok.val, "preceding") || strings.EqualFold(tok.val, "following"))
+	}
+
+	switch tok := s.peek(); {
+	case tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "unbounded"):
+		s.next()
+		tok := s.next()
+		if !isDirection(tok) {
+			return WindowFrameBound{}, errSyntax(s.sql, tok.pos)
+		}
+		return WindowFrameBound{Type: "unbounded " + strings.ToLower(tok.val)}, nil
+	case tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "current"):
+		s.next()
+		if tok := s.next(); tok.typ != sqlparser.ID || !strings.EqualFold(tok.val, "row") {
+			return WindowFrameBound{}, errSyntax(s.sql, tok.pos)
+		}
+		return WindowFrameBound{Type: CurrentRowStr}, nil
+	}
+
+	offset, err := parseExpr(s, isDirection)
+	if err != nil {
+		return WindowFrameBound{}, err
+	}
+	tok := s.next()
+	if !isDirection(tok) {
+		return WindowFrameBound{}, errSyntax(s.sql, tok.pos)
+	}
+	return WindowFrameBound{Type: strings.ToLower(tok.val), Offset: offset}, nil
+}
+
+func isWindowFrameUnit(tok token) bool {
+	return (tok.typ == sqlparser.ID || tok.typ == sqlparser.UNUSED) && (strings.EqualFold(tok.val, RowsStr) || strings.EqualFold(tok.val, RangeStr))
+}
+
+// parseExpr parses the expression up to the first token at depth 0 that stop accepts.
+func parseExpr(s *scanner, stop func(token) bool) (sqlparser.Expr, error) {
+	start := s.peek().pos
+	stmt, err := parseSpan(s, "select %s from dual", stop)
+	if err != nil {
+		return nil, err
+	}
+	expr, ok := stmt.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok || len(stmt.SelectExprs) > 1 {
+		return nil, errSyntax(s.sql, start)
+	}
+	return expr.Expr, nil
+}
+
+// parseSpan parses the tokens up to the first one at depth 0 that stop accepts or that closes an enclosing
+// parenthesis, formatted into a select statement by format.
+func parseSpan(s *scanner, format string, stop func(token) bool) (*sqlparser.Select, error) {
+	start := s.peek().pos
+	depth := 0
+	for {
+		tok := s.peek()
+		if tok.typ == 0 {
+			return nil, errSyntax(s.sql, tok.pos)
+		}
+		if depth == 0 && (tok.typ == ')' || stop(tok)) {
+			break
+		}
+		switch tok.typ {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		s.next()
+	}
+	if start == s.peek().pos {
+		return nil, errSyntax(s.sql, start)
+	}
+
+	stmt, err := sqlparser.Parse(fmt.Sprintf(format, s.sql[start:s.peek().pos]))
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, errSyntax(s.sql, start)
+	}
+	return sel, nil
+}
+
+// parseCreateView parses CREATE [OR REPLACE] VIEW name [(column, ...)] AS select.
+func parseCreateView(s *scanner) (sqlparser.Statement, bool, error) {
+	if s.next().typ != sqlparser.CREATE {
+		return nil, false, nil
+	}
+	orReplace := false
+	if s.peek().typ == sqlparser.OR {
+		s.next()
+		if tok := s.next(); tok.typ != sqlparser.REPLACE {
+			return nil, false, nil
+		}
+		orReplace = true
+	}
+	if s.next().typ != sqlparser.VIEW {
+		return nil, false, nil
+	}
+
+	name, ok := parseTableName(s)
+	if !ok {
+		return nil, true, errSyntax(s.sql, s.peek().pos)
+	}
+
+	columns, err := parseColumns(s)
+	if err != nil {
+		return nil, true, err
+	}
+
+	if tok := s.next(); tok.typ != sqlparser.AS {
+		return nil, true, errSyntax(s.sql, tok.pos)
+	}
+
+	sel, err := parseSelectStatement(s, s.peek().pos, len(s.sql))
+	if err != nil {
+		return nil, true, err
+	}
+	if columns != nil && !aliasColumns(sel, columns) {
+		return nil, true, fmt.Errorf("view column list does not match the select list")
+	}
+
+	return &CreateView{
+		DDL:       &sqlparser.DDL{Action: sqlparser.CreateStr, NewName: name},
+		Select:    sel,
+		OrReplace: orReplace,
+	}, true, nil
+}
+
+// parseWith parses WITH [RECURSIVE] name [(column, ...)] AS (select) [, ...] select.
+func parseWith(s *scanner) (sqlparser.Statement, bool, error) {
+	if s.next().typ != sqlparser.WITH {
+		return nil, false, nil
+	}
+
+	with := &With{}
+	if tok := s.peek(); tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "recursive") {
+		s.next()
+		with.Recursive = true
+	}
+
+	for {
+		name := s.next()
+		if name.typ != sqlparser.ID {
+			return nil, true, errSyntax(s.sql, name.pos)
+		}
+		columns, err := parseColumns(s)
+		if err != nil {
+			return nil, true, err
+		}
+		if tok := s.next(); tok.typ != sqlparser.AS {
+			return nil, true, errSyntax(s.sql, tok.pos)
+		}
+		if tok := s.next(); tok.typ != '(' {
+			return nil, true, errSyntax(s.sql, tok.pos)
+		}
+
+		start := s.peek().pos
+		for depth := 0; depth > 0 || s.peek().typ != ')'; {
+			switch tok := s.next(); tok.typ {
+			case 0:
+				return nil, true, errSyntax(s.sql, tok.pos)
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+		}
+		sel, err := parseSelectStatement(s, start, s.next().pos)
+		if err != nil {
+			return nil, true, err
+		}
+		if columns != nil && !aliasColumns(sel, columns) {
+			return nil, true, fmt.Errorf("column list of %s does not match the select list", name.val)
+		}
+		with.CTEs = append(with.CTEs, &CommonTableExpr{Name: sqlparser.NewTableIdent(name.val), Select: sel})
+
+		if s.peek().typ != ',' {
+			break
+		}
+		s.next()
+	}
+
+	sel, err := parseSelectStatement(s, s.peek().pos, len(s.sql))
+	if err != nil {
+		return nil, true, err
+	}
+	if _, ok := sel.(*With); ok {
+		return nil, true, errSyntax(s.sql, s.peek().pos)
+	}
+	with.SelectStatement = sel
+	return with, true, nil
+}
+
+// parseSelectStatement parses the select statement between start and end.
+func parseSelectStatement(s *scanner, start, end int) (sqlparser.SelectStatement, error) {
+	stmt, err := Parse(s.sql[start:end])
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(sqlparser.SelectStatement)
+	if !ok {
+		return nil, errSyntax(s.sql, start)
+	}
+	return sel, nil
+}
+
+// parseColumns parses an optional (column, ...) list.
+func parseColumns(s *scanner) (sqlparser.Columns, error) {
+	if s.peek().typ != '(' {
+		return nil, nil
+	}
+	s.next()
+
+	var columns sqlparser.Columns
+	for {
+		tok := s.next()
+		if tok.typ != sqlparser.ID {
+			return nil, errSyntax(s.sql, tok.pos)
+		}
+		columns = append(columns, sqlparser.NewColIdent(tok.val))
+		if tok = s.next(); tok.typ == ')' {
+			return columns, nil
+		} else if tok.typ != ',' {
+			return nil, errSyntax(s.sql, tok.pos)
+		}
+	}
+}
+
+// aliasColumns names the select list of sel, or of the first select of a union, after columns.
+// It reports false if the select list has a * or a different length.
+func aliasColumns(sel sqlparser.SelectStatement, columns sqlparser.Columns) bool {
+	for {
+		switch n := sel.(type) {
+		case *sqlparser.Union:
+			sel = n.Left
+			continue
+		case *sqlparser.ParenSelect:
+			sel = n.Select
+			continue
+		case *sqlparser.Select:
+			if len(n.SelectExprs) != len(columns) {
+				return false
+			}
+			for i, expr := range n.SelectExprs {
+				e, ok := expr.(*sqlparser.AliasedExpr)
+				if !ok {
+					return false
+				}
+				e.As = columns[i]
+			}
+			return true
+		}
+		return false
+	}
+}
+
+// parseDropView parses DROP VIEW [IF EXISTS] name.
+func parseDropView(s *scanner) (sqlparser.Statement, bool, error) {
+	if s.next().typ != sqlparser.DROP || s.next().typ != sqlparser.VIEW {
+		return nil, false, nil
+	}
+
+	exists := false
+	if s.peek().typ == sqlparser.IF {
+		s.next()
+		if tok := s.next(); tok.typ != sqlparser.EXISTS {
+			return nil, true, errSyntax(s.sql, tok.pos)
+		}
+		exists = true
+	}
+
+	name, ok := parseTableName(s)
+	if !ok {
+		return nil, true, errSyntax(s.sql, s.peek().pos)
+	}
+	if tok := s.next(); tok.typ != 0 && tok.typ != ';' {
+		return nil, true, errSyntax(s.sql, tok.pos)
+	}
+
+	return &DropView{DDL: &sqlparser.DDL{Action: sqlparser.DropStr, Table: name, IfExists: exists}}, true, nil
+}
+
+// parseShow parses SHOW [FULL] {COLUMNS | FIELDS | INDEX | INDEXES | KEYS} {FROM | IN} tbl [{FROM | IN} db] [LIKE 'pattern' | WHERE expr]
+// and SHOW [GLOBAL | SESSION] VARIABLES [LIKE 'pattern' | WHERE expr].
+func parseShow(s *scanner) (sqlparser.Statement, bool, error) {
+	if s.next().typ != sqlparser.SHOW {
+		return nil, false, nil
+	}
+
+	scope := ""
+	if tok := s.peek(); tok.typ == sqlparser.GLOBAL || tok.typ == sqlparser.SESSION {
+		s.next()
+		scope = strings.ToLower(tok.val)
+	}
+	if s.peek().typ == sqlparser.VARIABLES {
+		s.next()
+		opt := &sqlparser.ShowTablesOpt{}
+		if err := parseShowFilter(s, opt); err != nil {
+			return nil, true, err
+		}
+		return &sqlparser.Show{Type: "variables", Scope: scope, ShowTablesOpt: opt}, true, nil
+	} else if scope != "" {
+		return nil, false, nil
+	}
+
+	full := ""
+	if s.peek().typ == sqlparser.FULL {
+		s.next()
+		full = "full "
+	}
+
+	var typ string
+	switch tok := s.next(); {
+	case tok.typ == sqlparser.ID && (strings.EqualFold(tok.val, "columns") || strings.EqualFold(tok.val, "fields")):
+		typ = "columns"
+	case tok.typ == sqlparser.INDEX || tok.typ == sqlparser.KEYS || (tok.typ == sqlparser.ID && strings.EqualFold(tok.val, "indexes")):
+		typ = "index"
+	default:
+		return nil, false, nil
+	}
+
+	if tok := s.next(); tok.typ != sqlparser.FROM && tok.typ != sqlparser.IN {
+		return nil, false, nil
+	}
+
+	table, ok := parseTableName(s)
+	if !ok {
+		return nil, true, errSyntax(s.sql, s.peek().pos)
+	}
+
+	if tok := s.peek(); tok.typ == sqlparser.FROM || tok.typ == sqlparser.IN {
+		s.next()
+		if tok = s.next(); tok.typ != sqlparser.ID {
+			return nil, true, errSyntax(s.sql, tok.pos)
+		}
+		table.Qualifier = sqlparser.NewTableIdent(tok.val)
+	}
+
+	opt := &sqlparser.ShowTablesOpt{Full: full, DbName: table.Qualifier.String()}
+	if err := parseShowFilter(s, opt); err != nil {
+		return nil, true, err
+	}
+	return &sqlparser.Show{Type: typ, OnTable: table, ShowTablesOpt: opt}, true, nil
+}
+
+// parseShowFilter parses the [LIKE 'pattern' | WHERE expr] that ends a SHOW statement into opt.
+func parseShowFilter(s *scanner, opt *sqlparser.ShowTablesOpt) error {
+	switch tok := s.next(); tok.typ {
+	case 0, ';':
+	case sqlparser.LIKE:
+		pattern := s.next()
+		if pattern.typ != sqlparser.STRING {
+			return errSyntax(s.sql, pattern.pos)
+		}
+		opt.Filter = &sqlparser.ShowFilter{Like: pattern.val}
+	case sqlparser.WHERE:
+		stmt, err := sqlparser.Parse("select 1 from dual " + s.sql[tok.pos:])
+		if err != nil {
+			return err
+		}
+		sel, ok := stmt.(*sqlparser.Select)
+		if !ok || sel.Where == nil || sel.GroupBy != nil || sel.Having != nil || sel.OrderBy != nil || sel.Limit != nil {
+			return errSyntax(s.sql, tok.pos)
+		}
+		opt.Filter = &sqlparser.ShowFilter{Filter: sel.Where.Expr}
+	default:
+		return errSyntax(s.sql, tok.pos)
+	}
+	return nil
+}
+
+func parseTableName(s *scanner) (sqlparser.TableName, bool) {
+	tok := s.next()
+	if tok.typ != sqlparser.ID {
+		return sqlparser.TableName{}, false
+	}
+	name := sqlparser.TableName{Name: sqlparser.NewTableIdent(tok.val)}
+	if s.peek().typ == '.' {
+		s.next()
+		if tok = s.next(); tok.typ != sqlparser.ID {
+			return sqlparser.TableName{}, false
+		}
+		name.Qualifier = name.Name
+		name.Name = sqlparser.NewTableIdent(tok.val)
+	}
+	return name, true
+}
+
+func (s *scanner) next() token {
+	tok := s.peek()
+	if s.offset < len(s.tokens) {
+		s.offset++
+	}
+	return tok
+}
+
+func (s *scanner) peek() token {
+	if s.offset < len(s.tokens) {
+		return s.tokens[s.offset]
+	}
+	return token{pos: len(s.sql)}
+}
+
+func tokenize(sql string) []token {
+	var tokens []token
+	tokenizer := sqlparser.NewStringTokenizer(sql)
+	for {
+		pos := max(tokenizer.Position-1, 0)
+		typ, val := tokenizer.Scan()
+		if typ == 0 || typ == sqlparser.LEX_ERROR {
+			return tokens
+		}
+		if typ == sqlparser.COMMENT {
+			continue
+		}
+		for pos < len(sql) && strings.ContainsRune(" \t\r\n", rune(sql[pos])) {
+			pos++
+		}
+		tokens = append(tokens, token{typ: typ, val: string(val), pos: pos})
+	}
+}
+
+func errSyntax(sql string, pos int) error {
+	if pos < len(sql) {
+		if fields := strings.Fields(sql[pos:]); len(fields) > 0 {
+			return fmt.Errorf("syntax error at position %d near '%s'", pos+1, fields[0])
+		}
+	}
+	return fmt.Errorf("syntax error at position %d", pos+1)
+}
+
+// BindQuestionMarks rewrites the ? placeholders of query outside of quotes and comments into :v1, :v2 and so on,
+// returning how many there were.
+func BindQuestionMarks(query string) (string, int) {
+	var n int
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		if j := SkipLiteral(query, i); j > i {
+			b.WriteString(query[i:j])
+			i = j - 1
+			continue
+		}
+		if query[i] == '?' {
+			n++
+			b.WriteString(fmt.Sprintf(":v%d", n))
+		} else {
+			b.WriteByte(query[i])
+		}
+	}
+	return b.String(), n
+}
+
+// SkipLiteral returns the end of the quoted text or comment starting at i in query, or i if none does.
+func SkipLiteral(query string, i int) int {
+	switch ch := query[i]; {
+	case ch == '\'' || ch == '"' || ch == '`':
+		for j := i + 1; j < len(query); j++ {
+			if query[j] == '\\' && ch != '`' {
+				j++
+			} else if query[j] == ch {
+				return j + 1
+			}
+		}
+		return len(query)
+	case ch == '#' || strings.HasPrefix(query[i:], "--"):
+		if j := strings.IndexByte(query[i:], '\n'); j >= 0 {
+			return i + j + 1
+		}
+		return len(query)
+	case strings.HasPrefix(query[i:], "/*"):
+		if j := strings.Index(query[i+2:], "*/"); j >= 0 {
+			return i + j + 4
+		}
+		return len(query)
+	}
+	return i
+}
@@ -0,0 +1,314 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		stmt  sqlparser.Statement
+		err   bool
+	}{
+		{
+			query: "SHOW COLUMNS FROM users",
+			stmt: &sqlparser.Show{
+				Type:          "columns",
+				OnTable:       sqlparser.TableName{Name: sqlparser.NewTableIdent("users")},
+				ShowTablesOpt: &sqlparser.ShowTablesOpt{},
+			},
+		},
+		{
+			query: "SHOW FULL FIELDS IN `users` FROM app LIKE 'id%'",
+			stmt: &sqlparser.Show{
+				Type:          "columns",
+				OnTable:       sqlparser.TableName{Name: sqlparser.NewTableIdent("users"), Qualifier: sqlparser.NewTableIdent("app")},
+				ShowTablesOpt: &sqlparser.ShowTablesOpt{Full: "full ", DbName: "app", Filter: &sqlparser.ShowFilter{Like: "id%"}},
+			},
+		},
+		{
+			query: "SHOW INDEX FROM app.users WHERE Key_name = 'id'",
+			stmt: &sqlparser.Show{
+				Type:    "index",
+				OnTable: sqlparser.TableName{Name: sqlparser.NewTableIdent("users"), Qualifier: sqlparser.NewTableIdent("app")},
+				ShowTablesOpt: &sqlparser.ShowTablesOpt{DbName: "app", Filter: &sqlparser.ShowFilter{Filter: &sqlparser.ComparisonExpr{
+					Operator: sqlparser.EqualStr,
+					Left:     &sqlparser.ColName{Name: sqlparser.NewColIdent("Key_name")},
+					Right:    sqlparser.NewStrVal([]byte("id")),
+				}}},
+			},
+		},
+		{
+			query: "SHOW KEYS IN users;",
+			stmt: &sqlparser.Show{
+				Type:          "index",
+				OnTable:       sqlparser.TableName{Name: sqlparser.NewTableIdent("users")},
+				ShowTablesOpt: &sqlparser.ShowTablesOpt{},
+			},
+		},
+		{
+			query: "SHOW TABLES",
+			stmt:  &sqlparser.Show{Type: "tables", ShowTablesOpt: &sqlparser.ShowTablesOpt{}},
+		},
+		{
+			query: "SHOW COLUMNS FROM users LIMIT 1",
+			err:   true,
+		},
+		{
+			query: "SHOW VARIABLES",
+			stmt:  &sqlparser.Show{Type: "variables", ShowTablesOpt: &sqlparser.ShowTablesOpt{}},
+		},
+		{
+			query: "SHOW SESSION VARIABLES LIKE 'version%'",
+			stmt:  &sqlparser.Show{Type: "variables", Scope: "session", ShowTablesOpt: &sqlparser.ShowTablesOpt{Filter: &sqlparser.ShowFilter{Like: "version%"}}},
+		},
+		{
+			query: "SHOW GLOBAL VARIABLES WHERE Variable_name = 'autocommit'",
+			stmt: &sqlparser.Show{Type: "variables", Scope: "global", ShowTablesOpt: &sqlparser.ShowTablesOpt{Filter: &sqlparser.ShowFilter{Filter: &sqlparser.ComparisonExpr{
+				Operator: sqlparser.EqualStr,
+				Left:     &sqlparser.ColName{Name: sqlparser.NewColIdent("Variable_name")},
+				Right:    sqlparser.NewStrVal([]byte("autocommit")),
+			}}}},
+		},
+		{
+			query: "CREATE VIEW app.active_users AS SELECT id FROM users WHERE active = 1",
+			stmt: &CreateView{
+				DDL: &sqlparser.DDL{Action: sqlparser.CreateStr, NewName: sqlparser.TableName{Name: sqlparser.NewTableIdent("active_users"), Qualifier: sqlparser.NewTableIdent("app")}},
+				Select: &sqlparser.Select{
+					SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}},
+					From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("users")}}},
+					Where: &sqlparser.Where{Type: sqlparser.WhereStr, Expr: &sqlparser.ComparisonExpr{
+						Operator: sqlparser.EqualStr,
+						Left:     &sqlparser.ColName{Name: sqlparser.NewColIdent("active")},
+						Right:    sqlparser.NewIntVal([]byte("1")),
+					}},
+				},
+			},
+		},
+		{
+			query: "CREATE OR REPLACE VIEW v (a) AS SELECT id FROM users",
+			stmt: &CreateView{
+				DDL: &sqlparser.DDL{Action: sqlparser.CreateStr, NewName: sqlparser.TableName{Name: sqlparser.NewTableIdent("v")}},
+				Select: &sqlparser.Select{
+					SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, As: sqlparser.NewColIdent("a")}},
+					From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("users")}}},
+				},
+				OrReplace: true,
+			},
+		},
+		{
+			query: "CREATE VIEW v (a, b) AS SELECT id FROM users",
+			err:   true,
+		},
+		{
+			query: "DROP VIEW IF EXISTS v",
+			stmt:  &DropView{DDL: &sqlparser.DDL{Action: sqlparser.DropStr, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("v")}, IfExists: true}},
+		},
+		{
+			query: "SELECT EXTRACT(YEAR FROM d) FROM t",
+			stmt: &sqlparser.Select{
+				SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.FuncExpr{
+					Name: sqlparser.NewColIdent("EXTRACT"),
+					Exprs: sqlparser.SelectExprs{
+						&sqlparser.AliasedExpr{Expr: sqlparser.NewStrVal([]byte("YEAR"))},
+						&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("d")}},
+					},
+				}}},
+				From: sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}}},
+			},
+		},
+		{
+			query: "SELECT jt.sku FROM orders AS o, JSON_TABLE(o.body, '$.items[*]' COLUMNS (n FOR ORDINALITY, sku VARCHAR(10) PATH '$.sku' DEFAULT '\"-\"' ON EMPTY, qty INT PATH '$.qty' ERROR ON ERROR)) AS jt",
+			stmt: &sqlparser.Select{
+				SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("sku"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("jt")}}}},
+				From: sqlparser.TableExprs{
+					&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("orders")}, As: sqlparser.NewTableIdent("o")},
+					&JSONTable{
+						AliasedTableExpr: &sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("__json_table_0")}, As: sqlparser.NewTableIdent("jt")},
+						Expr:             &sqlparser.ColName{Name: sqlparser.NewColIdent("body"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("o")}},
+						Path:             "$.items[*]",
+						Columns: []*JSONTableColumn{
+							{Name: sqlparser.NewColIdent("n"), Type: sqltypes.Uint32, Ordinality: true},
+							{Name: sqlparser.NewColIdent("sku"), Type: sqltypes.VarChar, Path: "$.sku", OnEmpty: JSONTableFallback{Default: sqlparser.NewStrVal([]byte(`"-"`))}},
+							{Name: sqlparser.NewColIdent("qty"), Type: sqltypes.Int32, Path: "$.qty", OnError: JSONTableFallback{Error: true}},
+						},
+					},
+				},
+			},
+		},
+		{
+			query: "SELECT * FROM JSON_TABLE('[1]', '$[*]' COLUMNS (v INT PATH '$'))",
+			err:   true,
+		},
+		{
+			query: "SELECT * FROM JSON_TABLE('[1]', '$[*]' COLUMNS (v INT PATH '$' ON EMPTY)) AS jt",
+			err:   true,
+		},
+		{
+			query: "SELECT ROW_NUMBER() OVER (PARTITION BY a ORDER BY b DESC ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) FROM t",
+			stmt: &sqlparser.Select{
+				SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &WindowExpr{
+					FuncExpr: &sqlparser.FuncExpr{Name: sqlparser.NewColIdent("ROW_NUMBER")},
+					Over: &WindowSpec{
+						PartitionBy: sqlparser.Exprs{&sqlparser.ColName{Name: sqlparser.NewColIdent("a")}},
+						OrderBy:     sqlparser.OrderBy{&sqlparser.Order{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("b")}, Direction: sqlparser.DescScr}},
+						Frame: &WindowFrame{
+							Unit:  RowsStr,
+							Start: WindowFrameBound{Type: PrecedingStr, Offset: sqlparser.NewIntVal([]byte("1"))},
+							End:   WindowFrameBound{Type: CurrentRowStr},
+						},
+					},
+				}}},
+				From: sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}}},
+			},
+		},
+		{
+			query: "SELECT id FROM t ORDER BY SUM(x) OVER (RANGE UNBOUNDED PRECEDING)",
+			stmt: &sqlparser.Select{
+				SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}},
+				From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}}},
+				OrderBy: sqlparser.OrderBy{&sqlparser.Order{
+					Expr: &WindowExpr{
+						FuncExpr: &sqlparser.FuncExpr{Name: sqlparser.NewColIdent("SUM"), Exprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("x")}}}},
+						Over:     &WindowSpec{Frame: &WindowFrame{Unit: RangeStr, Start: WindowFrameBound{Type: UnboundedPrecedingStr}, End: WindowFrameBound{Type: CurrentRowStr}}},
+					},
+					Direction: sqlparser.AscScr,
+				}},
+			},
+		},
+		{
+			query: "SELECT id FROM t WHERE ROW_NUMBER() OVER () = 1",
+			err:   true,
+		},
+		{
+			query: "SELECT SUM(x) OVER (ROWS BETWEEN 1 PRECEDING) FROM t",
+			err:   true,
+		},
+		{
+			query: "SELECT COUNT(*) FILTER (WHERE x > 1) FROM t",
+			stmt: &sqlparser.Select{
+				SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &FilterExpr{
+					FuncExpr: &sqlparser.FuncExpr{Name: sqlparser.NewColIdent("COUNT"), Exprs: sqlparser.SelectExprs{&sqlparser.StarExpr{}}},
+					Where: &sqlparser.ComparisonExpr{
+						Operator: sqlparser.GreaterThanStr,
+						Left:     &sqlparser.ColName{Name: sqlparser.NewColIdent("x")},
+						Right:    sqlparser.NewIntVal([]byte("1")),
+					},
+				}}},
+				From: sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}}},
+			},
+		},
+		{
+			query: "SELECT SUM(x) FILTER (WHERE x > 1) OVER () FROM t",
+			err:   true,
+		},
+		{
+			query: "SELECT x FROM t WHERE COUNT(*) FILTER (WHERE x > 1) > 0",
+			err:   true,
+		},
+		{
+			query: "WITH a (x) AS (SELECT id FROM t), b AS (SELECT * FROM a) SELECT x FROM b",
+			stmt: &With{
+				SelectStatement: &sqlparser.Select{
+					SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("x")}}},
+					From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("b")}}},
+				},
+				CTEs: []*CommonTableExpr{
+					{
+						Name: sqlparser.NewTableIdent("a"),
+						Select: &sqlparser.Select{
+							SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, As: sqlparser.NewColIdent("x")}},
+							From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}}},
+						},
+					},
+					{
+						Name: sqlparser.NewTableIdent("b"),
+						Select: &sqlparser.Select{
+							SelectExprs: sqlparser.SelectExprs{&sqlparser.StarExpr{}},
+							From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("a")}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			query: "WITH RECURSIVE r (n) AS (SELECT id FROM t UNION ALL SELECT n FROM r) SELECT n FROM r",
+			stmt: &With{
+				SelectStatement: &sqlparser.Select{
+					SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("n")}}},
+					From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("r")}}},
+				},
+				Recursive: true,
+				CTEs: []*CommonTableExpr{{
+					Name: sqlparser.NewTableIdent("r"),
+					Select: &sqlparser.Union{
+						Type: sqlparser.UnionAllStr,
+						Left: &sqlparser.Select{
+							SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, As: sqlparser.NewColIdent("n")}},
+							From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}}},
+						},
+						Right: &sqlparser.Select{
+							SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: sqlparser.NewColIdent("n")}}},
+							From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewTableIdent("r")}}},
+						},
+					},
+				}},
+			},
+		},
+		{
+			query: "WITH a (x, y) AS (SELECT id FROM t) SELECT * FROM a",
+			err:   true,
+		},
+		{
+			query: "WITH a AS (SELECT id FROM t SELECT * FROM a",
+			err:   true,
+		},
+		{
+			query: "WITH a AS (SELECT id FROM t) WITH b AS (SELECT 1) SELECT * FROM b",
+			err:   true,
+		},
+		{
+			query: "DROP TABLE t",
+			stmt:  &sqlparser.DDL{Action: sqlparser.DropStr, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			stmt, err := Parse(tt.query)
+			if tt.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.stmt, stmt)
+			}
+		})
+	}
+}
+
+func TestBindQuestionMarks(t *testing.T) {
+	tests := []struct {
+		query  string
+		bound  string
+		params int
+	}{
+		{query: "SELECT * FROM t WHERE a = ? AND b = ?", bound: "SELECT * FROM t WHERE a = :v1 AND b = :v2", params: 2},
+		{query: "SELECT 'a?b' AS s FROM t WHERE c = ?", bound: "SELECT 'a?b' AS s FROM t WHERE c = :v1", params: 1},
+		{query: `SELECT "it\"s?", ` + "`q?`" + ` FROM t`, bound: `SELECT "it\"s?", ` + "`q?`" + ` FROM t`},
+		{query: "SELECT 'don''t?' FROM t", bound: "SELECT 'don''t?' FROM t"},
+		{query: "SELECT a /* ? */ FROM t -- ?\nWHERE b = ? # ?", bound: "SELECT a /* ? */ FROM t -- ?\nWHERE b = :v1 # ?", params: 1},
+		{query: "SELECT 'open?", bound: "SELECT 'open?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			bound, params := BindQuestionMarks(tt.query)
+			require.Equal(t, tt.bound, bound)
+			require.Equal(t, tt.params, params)
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 type Plan interface {
 	Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error)
+	// Schema returns the output columns of the plan, or nil if they are only known at run time.
+	Schema(ctx context.Context) ([]schema.Column, error)
 	Walk(func(Plan) (bool, error)) (bool, error)
 	String() string
 }
@@ -1,11 +1,18 @@
 package engine
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
 	"math/big"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/siyul-park/sqlbridge/schema"
 	"github.com/xwb1989/sqlparser"
@@ -16,27 +23,125 @@ import (
 type Planner struct {
 	catalog    schema.Catalog
 	dispatcher *Dispatcher
+	registry   schema.Registry
+	database   string
+	location   *time.Location
+	views      []string
+	ctes       map[string]*commonTable
+	maxDepth   int
+	parallel   int
+	variables  map[string]sqltypes.Value
 }
 
-func NewPlanner(catalog schema.Catalog, dispatcher *Dispatcher) *Planner {
-	return &Planner{
+// commonTable is a CTE in scope; plan is nil while it is being planned.
+type commonTable struct {
+	plan Plan
+	work *RecursivePlan
+}
+
+type PlannerOption func(*Planner)
+
+var (
+	ErrViewNotSupported = errors.New("catalog does not support views")
+	ErrRecursiveView    = errors.New("view references itself")
+	ErrGroupFunction    = errors.New("invalid use of group function")
+	ErrJoinNotSupported = errors.New("join is not supported")
+)
+
+func WithRegistry(registry schema.Registry) PlannerOption {
+	return func(p *Planner) { p.registry = registry }
+}
+
+func WithDatabase(name string) PlannerOption {
+	return func(p *Planner) { p.database = name }
+}
+
+func WithLocation(loc *time.Location) PlannerOption {
+	return func(p *Planner) { p.location = loc }
+}
+
+// WithMaxRecursionDepth bounds the iterations of a recursive CTE.
+func WithMaxRecursionDepth(depth int) PlannerOption {
+	return func(p *Planner) { p.maxDepth = depth }
+}
+
+// WithParallelism sets the number of GROUP BY workers.
+func WithParallelism(workers int) PlannerOption {
+	return func(p *Planner) { p.parallel = workers }
+}
+
+// WithVariables overrides DefaultVariables.
+func WithVariables(vars map[string]sqltypes.Value) PlannerOption {
+	return func(p *Planner) {
+		for name, val := range vars {
+			p.variables[strings.ToLower(name)] = val
+		}
+	}
+}
+
+func NewPlanner(catalog schema.Catalog, dispatcher *Dispatcher, opts ...PlannerOption) *Planner {
+	p := &Planner{
 		catalog:    catalog,
 		dispatcher: dispatcher,
+		location:   time.UTC,
+		maxDepth:   DefaultMaxRecursionDepth,
+		variables:  DefaultVariables(),
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	if p.registry == nil {
+		p.registry = schema.NewInMemoryRegistry(map[string]schema.Catalog{p.database: catalog})
+	}
+	return p
+}
+
+func (p *Planner) Database() string {
+	return p.database
+}
+
+func (p *Planner) Location() *time.Location {
+	return p.location
+}
+
+func (p *Planner) SetLocation(loc *time.Location) {
+	p.location = loc
+}
+
+func (p *Planner) Use(name string) error {
+	catalog, err := p.registry.Catalog(name)
+	if err != nil {
+		return err
+	}
+	p.catalog = catalog
+	p.database = name
+	return nil
 }
 
 func (p *Planner) Plan(node sqlparser.Statement) (Plan, error) {
 	switch n := node.(type) {
 	case sqlparser.SelectStatement:
-		return p.planSelectStatement(n)
+		plan, err := p.planSelectStatement(n)
+		if err != nil {
+			return nil, err
+		}
+		p.prune(plan)
+		return plan, nil
 	case *sqlparser.Insert:
 	case *sqlparser.Update:
 	case *sqlparser.Delete:
 	case *sqlparser.Set:
+		return p.planSet(n)
 	case *sqlparser.DBDDL:
+	case *CreateView:
+		return p.planCreateView(n)
+	case *DropView:
+		return p.planDropView(n)
 	case *sqlparser.DDL:
 	case *sqlparser.Show:
+		return p.planShow(n)
 	case *sqlparser.Use:
+		return p.planUse(n)
 	case *sqlparser.Begin:
 	case *sqlparser.Commit:
 	case *sqlparser.Rollback:
@@ -47,30 +152,283 @@ func (p *Planner) Plan(node sqlparser.Statement) (Plan, error) {
 	return nil, driver.ErrSkip
 }
 
+func (p *Planner) planCreateView(node *CreateView) (Plan, error) {
+	catalog, database, err := p.resolve(node.NewName.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+	store, ok := catalog.(schema.ViewStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrViewNotSupported, database)
+	}
+
+	query := sqlparser.String(node.Select)
+	if _, err := p.with(catalog, database).planSelectStatement(node.Select); err != nil {
+		return nil, err
+	}
+
+	return &CreateViewPlan{
+		Catalog:   store,
+		Name:      node.NewName.Name.String(),
+		Query:     query,
+		OrReplace: node.OrReplace,
+	}, nil
+}
+
+func (p *Planner) planDropView(node *DropView) (Plan, error) {
+	catalog, database, err := p.resolve(node.Table.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+	store, ok := catalog.(schema.ViewStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrViewNotSupported, database)
+	}
+
+	return &DropViewPlan{
+		Catalog:  store,
+		Name:     node.Table.Name.String(),
+		IfExists: node.IfExists,
+	}, nil
+}
+
+func (p *Planner) planSet(node *sqlparser.Set) (Plan, error) {
+	if len(node.Exprs) != 1 || node.Scope == sqlparser.GlobalStr {
+		return nil, driver.ErrSkip
+	}
+
+	name := strings.ToLower(node.Exprs[0].Name.String())
+	for _, prefix := range []string{"@@session.", "@@local.", "@@"} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if name != "time_zone" {
+		return nil, driver.ErrSkip
+	}
+
+	value, err := p.planExpr(node.Exprs[0].Expr)
+	if err != nil {
+		return nil, err
+	}
+	return &SetPlan{Planner: p, Name: name, Value: value}, nil
+}
+
+func (p *Planner) planUse(node *sqlparser.Use) (Plan, error) {
+	if _, err := p.registry.Catalog(node.DBName.String()); err != nil {
+		return nil, err
+	}
+	return &UsePlan{Planner: p, Database: node.DBName}, nil
+}
+
+func (p *Planner) planShow(node *sqlparser.Show) (Plan, error) {
+	database := p.database
+	if node.ShowTablesOpt != nil && node.ShowTablesOpt.DbName != "" {
+		database = node.ShowTablesOpt.DbName
+	}
+
+	catalog, name := schema.Catalog(nil), schema.InformationSchemaName
+	var query, field string
+	switch strings.ToLower(node.Type) {
+	case "variables":
+		catalog, name = p.variablesCatalog(), ""
+		field = "Variable_name"
+		query = "select Variable_name, Value from variables"
+	case "databases", "schemas":
+		field = "Database"
+		query = "select SCHEMA_NAME as `Database` from `SCHEMATA`"
+	case "tables":
+		field = "Tables_in_" + database
+		query = fmt.Sprintf("select TABLE_NAME as %s", sqlparser.String(sqlparser.NewColIdent(field)))
+		if node.ShowTablesOpt != nil && node.ShowTablesOpt.Full != "" {
+			query += ", TABLE_TYPE as Table_type"
+		}
+		query += fmt.Sprintf(" from `TABLES` where TABLE_SCHEMA = %s", sqlparser.String(sqlparser.NewStrVal([]byte(database))))
+	case "columns", "index":
+		catalog, err := p.registry.Catalog(database)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := catalog.Table(node.OnTable.Name.String()); err != nil {
+			return nil, err
+		}
+
+		if node.Type == "columns" {
+			field = "Field"
+			query = "select COLUMN_NAME as Field, COLUMN_TYPE as Type, IS_NULLABLE as `Null`, '' as `Key`, null as `Default`, '' as Extra from `COLUMNS`"
+		} else {
+			field = "Key_name"
+			query = "select TABLE_NAME as `Table`, NON_UNIQUE as Non_unique, INDEX_NAME as Key_name, SEQ_IN_INDEX as Seq_in_index, COLUMN_NAME as Column_name from `STATISTICS`"
+		}
+		query += fmt.Sprintf(" where TABLE_SCHEMA = %s and TABLE_NAME = %s", sqlparser.String(sqlparser.NewStrVal([]byte(database))), sqlparser.String(sqlparser.NewStrVal([]byte(node.OnTable.Name.String()))))
+	default:
+		return nil, driver.ErrSkip
+	}
+
+	if node.ShowTablesOpt != nil && node.ShowTablesOpt.Filter != nil {
+		filter := node.ShowTablesOpt.Filter.Filter
+		if filter == nil {
+			filter = &sqlparser.ComparisonExpr{
+				Operator: sqlparser.LikeStr,
+				Left:     &sqlparser.ColName{Name: sqlparser.NewColIdent(field)},
+				Right:    sqlparser.NewStrVal([]byte(node.ShowTablesOpt.Filter.Like)),
+			}
+		}
+		query = fmt.Sprintf("select * from (%s) as t where %s", query, sqlparser.String(filter))
+	}
+
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if catalog == nil {
+		catalog = schema.NewInformationSchema(p.registry)
+	}
+	return p.with(catalog, name).Plan(stmt)
+}
+
+func (p *Planner) variablesCatalog() schema.Catalog {
+	vars := maps.Clone(p.variables)
+	vars["time_zone"] = sqltypes.NewVarChar(p.location.String())
+
+	columns := []*sqlparser.ColName{
+		{Name: sqlparser.NewColIdent("Variable_name")},
+		{Name: sqlparser.NewColIdent("Value")},
+	}
+	rows := make([]schema.Row, 0, len(vars))
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		rows = append(rows, schema.Row{Columns: columns, Values: []sqltypes.Value{sqltypes.NewVarChar(name), sqltypes.NewVarChar(vars[name].ToString())}})
+	}
+
+	table := schema.NewInMemoryTable(rows)
+	_ = table.SetColumns(context.Background(), []schema.Column{
+		{Name: columns[0], Type: sqltypes.VarChar},
+		{Name: columns[1], Type: sqltypes.VarChar, Nullable: true},
+	})
+	return schema.NewInMemoryCatalog(map[string]schema.Table{"variables": table})
+}
+
 func (p *Planner) planSelectStatement(node sqlparser.SelectStatement) (Plan, error) {
 	switch n := node.(type) {
 	case *sqlparser.Union:
+		return p.planUnion(n)
 	case *sqlparser.Select:
 		return p.planSelect(n)
 	case *sqlparser.ParenSelect:
+		return p.planSelectStatement(n.Select)
+	case *With:
+		return p.planWith(n)
 	}
 	return nil, driver.ErrSkip
 }
 
+func (p *Planner) planUnion(node *sqlparser.Union) (Plan, error) {
+	left, err := p.planSelectStatement(node.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := p.planSelectStatement(node.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	input := Plan(&UnionPlan{Left: left, Right: right})
+	if node.Type != sqlparser.UnionAllStr {
+		input = &DistinctPlan{Input: input}
+	}
+	if input, err = p.planOrderBy(input, node.OrderBy, nil); err != nil {
+		return nil, err
+	}
+	return p.planLimit(input, node.Limit)
+}
+
+// planWith materializes a CTE referred to more than once so that it runs only once.
+func (p *Planner) planWith(node *With) (Plan, error) {
+	planner := p.with(p.catalog, p.database)
+	planner.ctes = maps.Clone(p.ctes)
+	if planner.ctes == nil {
+		planner.ctes = make(map[string]*commonTable)
+	}
+
+	for i, cte := range node.CTEs {
+		name := cte.Name.String()
+		if slices.ContainsFunc(node.CTEs[:i], func(c *CommonTableExpr) bool { return c.Name.String() == name }) {
+			return nil, fmt.Errorf("duplicate common table expression name: %s", name)
+		}
+
+		table := &commonTable{}
+		var plan Plan
+		var err error
+		if node.Recursive && references(name, cte.Select) > 0 {
+			planner.ctes[name] = table
+			plan, err = planner.planRecursive(table, cte)
+		} else {
+			plan, err = planner.planSelectStatement(cte.Select)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		others := []sqlparser.SelectStatement{node.SelectStatement}
+		for _, c := range node.CTEs[i+1:] {
+			others = append(others, c.Select)
+		}
+		if references(name, others...) > 1 {
+			plan = &MaterializePlan{Input: plan}
+		}
+		table.plan = plan
+		planner.ctes[name] = table
+	}
+
+	input, err := planner.planSelectStatement(node.SelectStatement)
+	if err != nil {
+		return nil, err
+	}
+	return &WithPlan{Input: input}, nil
+}
+
+func (p *Planner) planRecursive(table *commonTable, node *CommonTableExpr) (Plan, error) {
+	name := node.Name.String()
+	union, ok := node.Select.(*sqlparser.Union)
+	if !ok || union.OrderBy != nil || union.Limit != nil || references(name, union.Left) > 0 {
+		return nil, fmt.Errorf("recursive common table expression %s must be a union of a non-recursive and a recursive select", name)
+	}
+	if references(name, union.Right) > 1 {
+		return nil, fmt.Errorf("recursive common table expression %s can be referred to only once in its recursive select", name)
+	}
+
+	plan := &RecursivePlan{Name: node.Name, Distinct: union.Type != sqlparser.UnionAllStr, MaxDepth: p.maxDepth}
+	anchor, err := p.planSelectStatement(union.Left)
+	if err != nil {
+		return nil, err
+	}
+	plan.Anchor = anchor
+
+	table.work = plan
+	recursive, err := p.planSelectStatement(union.Right)
+	table.work = nil
+	if err != nil {
+		return nil, err
+	}
+	plan.Recursive = recursive
+	return plan, nil
+}
+
 func (p *Planner) planSelect(node *sqlparser.Select) (Plan, error) {
 	if input, err := p.planTableExprs(node.From); err != nil {
 		return nil, err
 	} else if input, err = p.planWhere(input, node.Where); err != nil {
 		return nil, err
-	} else if input, err = p.planGroupBy(input, node.GroupBy); err != nil {
+	} else if input, err = p.planGroupBy(input, node); err != nil {
+		return nil, err
+	} else if input, err = p.planHaving(input, node.Having, node.SelectExprs); err != nil {
 		return nil, err
-	} else if input, err = p.planHaving(input, node.Having); err != nil {
+	} else if input, err = p.planWindows(input, node); err != nil {
 		return nil, err
 	} else if input, err = p.planSelectExprs(input, node.SelectExprs); err != nil {
 		return nil, err
 	} else if input, err = p.planDistinct(input, node.Distinct); err != nil {
 		return nil, err
-	} else if input, err = p.planOrderBy(input, node.OrderBy); err != nil {
+	} else if input, err = p.planOrderBy(input, node.OrderBy, node.SelectExprs); err != nil {
 		return nil, err
 	} else if input, err = p.planLimit(input, node.Limit); err != nil {
 		return nil, err
@@ -90,6 +448,13 @@ func (p *Planner) planTableExprs(node sqlparser.TableExprs) (Plan, error) {
 	}
 
 	for _, expr := range node[1:] {
+		if jt, ok := expr.(*JSONTable); ok {
+			if left, err = p.planJSONTable(left, jt); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		right, err := p.planTableExpr(expr)
 		if err != nil {
 			return nil, err
@@ -110,6 +475,8 @@ func (p *Planner) planTableExpr(node sqlparser.TableExpr) (Plan, error) {
 		return p.planParenTableExpr(n)
 	case *sqlparser.JoinTableExpr:
 		return p.planJoinTableExpr(n)
+	case *JSONTable:
+		return p.planJSONTable(nil, n)
 	}
 	return nil, driver.ErrSkip
 }
@@ -130,17 +497,22 @@ func (p *Planner) planAliasedTableExpr(node *sqlparser.AliasedTableExpr) (Plan,
 		return nil, err
 	}
 
+	var qualifier sqlparser.TableIdent
 	as := node.As
 	if as.IsEmpty() {
 		switch expr := node.Expr.(type) {
 		case sqlparser.TableName:
 			as = expr.Name
+			qualifier = expr.Qualifier
+			if _, ok := p.cte(expr); qualifier.IsEmpty() && !ok {
+				qualifier = sqlparser.NewTableIdent(p.database)
+			}
 		default:
 			as = sqlparser.NewTableIdent(sqlparser.String(expr))
 		}
 	}
 
-	return &AliasPlan{Input: plan, As: as}, nil
+	return &AliasPlan{Input: plan, As: as, Qualifier: qualifier}, nil
 }
 
 func (p *Planner) planParenTableExpr(node *sqlparser.ParenTableExpr) (Plan, error) {
@@ -152,19 +524,25 @@ func (p *Planner) planJoinTableExpr(node *sqlparser.JoinTableExpr) (Plan, error)
 	if err != nil {
 		return nil, err
 	}
+
+	if jt, ok := node.RightExpr.(*JSONTable); ok {
+		return p.planJSONTableJoin(left, jt, node)
+	}
+
 	right, err := p.planTableExpr(node.RightExpr)
 	if err != nil {
 		return nil, err
 	}
-
-	var plan Plan = &JoinPlan{
+	plan := Plan(&JoinPlan{
 		Left:  left,
 		Right: right,
-	}
+	})
 
 	if node.Condition.On != nil {
 		if expr, err := p.planExpr(node.Condition.On); err != nil {
 			return nil, err
+		} else if err := p.bind(plan, expr); err != nil {
+			return nil, err
 		} else {
 			plan = &FilterPlan{
 				Input: plan,
@@ -183,8 +561,108 @@ func (p *Planner) planJoinTableExpr(node *sqlparser.JoinTableExpr) (Plan, error)
 	return plan, nil
 }
 
+// planJSONTableJoin checks ON while expanding, so a LEFT JOIN keeps unmatched rows of left.
+func (p *Planner) planJSONTableJoin(left Plan, jt *JSONTable, node *sqlparser.JoinTableExpr) (Plan, error) {
+	switch node.Join {
+	case sqlparser.JoinStr, sqlparser.StraightJoinStr, sqlparser.LeftJoinStr:
+	default:
+		return nil, fmt.Errorf("%w: %s JSON_TABLE", ErrJoinNotSupported, strings.ToUpper(node.Join))
+	}
+	if len(node.Condition.Using) > 0 {
+		return nil, fmt.Errorf("%w: JSON_TABLE with USING", ErrJoinNotSupported)
+	}
+
+	plan, err := p.planJSONTable(left, jt)
+	if err != nil {
+		return nil, err
+	}
+	plan.Left = node.Join == sqlparser.LeftJoinStr
+	if node.Condition.On != nil {
+		if plan.On, err = p.planExpr(node.Condition.On); err != nil {
+			return nil, err
+		}
+		if err := p.bind(plan, plan.On); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+func (p *Planner) planJSONTable(input Plan, node *JSONTable) (*JSONTablePlan, error) {
+	expr, err := p.planExpr(node.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var outer Plan = &NOPPlan{}
+	if input != nil {
+		outer = input
+	}
+	if err := p.bind(outer, expr); err != nil {
+		return nil, err
+	}
+	if _, err := newJSONTableScanner(node.Path, node.Columns); err != nil {
+		return nil, err
+	}
+
+	return &JSONTablePlan{
+		Input:   input,
+		Expr:    expr,
+		Path:    node.Path,
+		Columns: node.Columns,
+		As:      node.As,
+	}, nil
+}
+
 func (p *Planner) planTableName(node sqlparser.TableName) (Plan, error) {
-	return &ScanPlan{Catalog: p.catalog, Table: node}, nil
+	if table, ok := p.cte(node); ok {
+		switch {
+		case table.work != nil:
+			return &WorkTablePlan{Table: table.work}, nil
+		case table.plan == nil:
+			return nil, fmt.Errorf("recursive common table expression %s must be a union of a non-recursive and a recursive select", node.Name.String())
+		}
+		return table.plan, nil
+	}
+	if node.Qualifier.IsEmpty() && strings.EqualFold(node.Name.String(), "dual") {
+		return &DualPlan{}, nil
+	}
+
+	catalog, database, err := p.resolve(node.Qualifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewer, ok := catalog.(schema.Viewer); ok {
+		query, err := viewer.View(node.Name.String())
+		if err == nil {
+			return p.planView(catalog, database, node.Name.String(), query)
+		}
+		if !errors.Is(err, schema.ErrViewNotFound) {
+			return nil, err
+		}
+	}
+	return &ScanPlan{Catalog: catalog, Table: node}, nil
+}
+
+func (p *Planner) planView(catalog schema.Catalog, database, name, query string) (Plan, error) {
+	key := database + "." + name
+	if slices.Contains(p.views, key) {
+		return nil, fmt.Errorf("%w: %s", ErrRecursiveView, key)
+	}
+
+	stmt, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(sqlparser.SelectStatement)
+	if !ok {
+		return nil, fmt.Errorf("view is not a select statement: %s", key)
+	}
+
+	planner := p.with(catalog, database)
+	planner.views = append(slices.Clone(p.views), key)
+	return planner.planSelectStatement(sel)
 }
 
 func (p *Planner) planSubquery(node *sqlparser.Subquery) (Plan, error) {
@@ -197,112 +675,507 @@ func (p *Planner) planWhere(input Plan, node *sqlparser.Where) (Plan, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := p.bind(input, expr); err != nil {
+			return nil, err
+		}
+
+		p.pushdown(input, expr)
+
+		return &FilterPlan{
+			Input: input,
+			Expr:  expr,
+		}, nil
+	}
+	return input, nil
+}
+
+func (p *Planner) planGroupBy(input Plan, node *sqlparser.Select) (Plan, error) {
+	var calls []sqlparser.Expr
+	var visit func(node sqlparser.SQLNode) (bool, error)
+	visit = func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.FuncExpr, *FilterExpr, *sqlparser.GroupConcatExpr:
+			call := n.(sqlparser.Expr)
+			if !p.isAggregate(call) {
+				if _, ok := n.(*FilterExpr); ok {
+					return false, fmt.Errorf("FILTER is only allowed for aggregate functions")
+				}
+				return true, nil
+			}
+			if err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+				if e, ok := node.(sqlparser.Expr); ok && e != call && p.isAggregate(e) {
+					return false, fmt.Errorf("%w: aggregate function calls cannot be nested", ErrGroupFunction)
+				}
+				_, ok := node.(*sqlparser.Subquery)
+				return !ok, nil
+			}, n); err != nil {
+				return false, err
+			}
+			calls = append(calls, call)
+			return false, nil
+		case *WindowExpr:
+			// A window's arguments and OVER clause may still use aggregates.
+			return false, sqlparser.Walk(visit, n.Exprs, n.Over.PartitionBy, n.Over.OrderBy)
+		case *sqlparser.Subquery:
+			return false, nil
+		}
+		return true, nil
+	}
+	if err := sqlparser.Walk(visit, node.SelectExprs, node.Having, node.OrderBy); err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 && len(node.GroupBy) == 0 {
+		return input, nil
+	}
+
+	exprs := make([]Expr, 0, len(node.GroupBy))
+	for _, expr := range node.GroupBy {
+		e, err := p.planExpr(unalias(expr, node.SelectExprs))
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+	if err := p.bind(input, exprs...); err != nil {
+		return nil, err
+	}
+
+	var aggs []*AggregateFunc
+	columns := make(map[sqlparser.Expr]*sqlparser.ColName, len(calls))
+	for _, call := range calls {
+		as := &sqlparser.ColName{Name: sqlparser.NewColIdent(sqlparser.String(call))}
+		columns[call] = as
+		if slices.ContainsFunc(aggs, func(fn *AggregateFunc) bool { return fn.As.Name.Equal(as.Name) }) {
+			continue
+		}
+
+		fn, err := p.planAggregate(call)
+		if err != nil {
+			return nil, err
+		}
+		fn.As = as
+
+		binds := slices.Clone(fn.Args)
+		for _, order := range fn.Order {
+			binds = append(binds, order.Expr)
+		}
+		if fn.Filter != nil {
+			binds = append(binds, fn.Filter)
+		}
+		if err := p.bind(input, binds...); err != nil {
+			return nil, err
+		}
+		aggs = append(aggs, fn)
+	}
+
+	replace := func(expr sqlparser.Expr) sqlparser.Expr {
+		for call, col := range columns {
+			expr = sqlparser.ReplaceExpr(expr, call, col)
+		}
+		return expr
+	}
+	var rewrite func(node sqlparser.SQLNode) (bool, error)
+	rewrite = func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.AliasedExpr:
+			n.Expr = replace(n.Expr)
+		case *sqlparser.Order:
+			n.Expr = replace(n.Expr)
+		case *WindowExpr:
+			for i, expr := range n.Over.PartitionBy {
+				n.Over.PartitionBy[i] = replace(expr)
+			}
+			_ = sqlparser.Walk(rewrite, n.Over.OrderBy)
+		case *sqlparser.Subquery:
+			return false, nil
+		}
+		return true, nil
+	}
+	_ = sqlparser.Walk(rewrite, node.SelectExprs, node.OrderBy)
+	if node.Having != nil {
+		node.Having.Expr = replace(node.Having.Expr)
+	}
+	if len(aggs) > 0 {
+		p.expandStar(node)
+	}
+
+	return &GroupPlan{
+		Input:       input,
+		Exprs:       exprs,
+		Aggregates:  aggs,
+		Parallelism: p.parallel,
+	}, nil
+}
+
+func (p *Planner) planAggregate(call sqlparser.Expr) (*AggregateFunc, error) {
+	fn := &AggregateFunc{Dispatcher: p.dispatcher}
+
+	var args sqlparser.SelectExprs
+	switch call := call.(type) {
+	case *sqlparser.FuncExpr:
+		fn.Name, fn.Distinct, args = call.Name, call.Distinct, call.Exprs
+	case *FilterExpr:
+		fn.Name, fn.Distinct, args = call.Name, call.Distinct, call.Exprs
+		filter, err := p.planExpr(call.Where)
+		if err != nil {
+			return nil, err
+		}
+		fn.Filter = filter
+	case *sqlparser.GroupConcatExpr:
+		fn.Name, fn.Distinct, args = GroupConcat, call.Distinct == sqlparser.DistinctStr, call.Exprs
+		// sqlparser keeps the SEPARATOR clause as it formats it.
+		sep := ","
+		if call.Separator != "" {
+			sep = strings.TrimSuffix(strings.TrimPrefix(call.Separator, " separator '"), "'")
+		}
+		fn.Args = append(fn.Args, &LiteralExpr{Value: sqltypes.NewVarChar(sep)})
+		for _, order := range call.OrderBy {
+			expr, err := p.planExpr(order.Expr)
+			if err != nil {
+				return nil, err
+			}
+			fn.Order = append(fn.Order, WindowOrder{Expr: expr, Direction: order.Direction})
+		}
+	}
+
+	for _, arg := range args {
+		switch e := arg.(type) {
+		case *sqlparser.StarExpr:
+			// COUNT(*) counts every row, so * adds no arguments.
+		case *sqlparser.AliasedExpr:
+			expr, err := p.planExpr(e.Expr)
+			if err != nil {
+				return nil, err
+			}
+			fn.Args = append(fn.Args, expr)
+		default:
+			return nil, driver.ErrSkip
+		}
+	}
+	return fn, nil
+}
+
+func (p *Planner) planHaving(input Plan, node *sqlparser.Where, selectExprs sqlparser.SelectExprs) (Plan, error) {
+	if node != nil {
+		expr, err := p.planExpr(unalias(node.Expr, selectExprs))
+		if err != nil {
+			return nil, err
+		}
+		if err := p.bind(input, expr); err != nil {
+			return nil, err
+		}
+
+		return &FilterPlan{
+			Input: input,
+			Expr:  expr,
+		}, nil
+	}
+	return input, nil
+}
+
+// unalias returns a copy of expr with select aliases replaced by their expressions.
+func unalias(expr sqlparser.Expr, selectExprs sqlparser.SelectExprs) sqlparser.Expr {
+	expr = cloneNode(expr)
+
+	var cols []*sqlparser.ColName
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.ColName:
+			if n.Qualifier.IsEmpty() {
+				cols = append(cols, n)
+			}
+		case *sqlparser.Subquery:
+			return false, nil
+		}
+		return true, nil
+	}, expr)
+
+	for _, col := range cols {
+		for _, e := range selectExprs {
+			if e, ok := e.(*sqlparser.AliasedExpr); ok && e.As.Equal(col.Name) {
+				if c, ok := e.Expr.(*sqlparser.ColName); !ok || !c.Name.Equal(col.Name) {
+					expr = sqlparser.ReplaceExpr(expr, col, cloneNode(e.Expr))
+				}
+				break
+			}
+		}
+	}
+	return expr
+}
 
-		exprs := p.splitByTables(expr)
+func cloneNode[T sqlparser.SQLNode](node T) T {
+	src := reflect.ValueOf(&node).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	cloneValue(dst, src)
+	return dst.Interface().(T)
+}
+
+func cloneValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if !src.IsNil() {
+			ptr := reflect.New(src.Type().Elem())
+			cloneValue(ptr.Elem(), src.Elem())
+			dst.Set(ptr)
+		}
+	case reflect.Interface:
+		if !src.IsNil() {
+			v := reflect.New(src.Elem().Type()).Elem()
+			cloneValue(v, src.Elem())
+			dst.Set(v)
+		}
+	case reflect.Slice:
+		if !src.IsNil() {
+			s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+			for i := range src.Len() {
+				cloneValue(s.Index(i), src.Index(i))
+			}
+			dst.Set(s)
+		}
+	case reflect.Struct:
+		dst.Set(src)
+		for i := range src.NumField() {
+			if dst.Field(i).CanSet() {
+				cloneValue(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+func (p *Planner) planSelectExprs(input Plan, node sqlparser.SelectExprs) (Plan, error) {
+	if len(node) > 0 {
+		var keys, windows []Expr
+		grouped := false
 		_, _ = input.Walk(func(plan Plan) (bool, error) {
 			switch p := plan.(type) {
-			case *AliasPlan:
-				if i, ok := p.Input.(*ScanPlan); ok {
-					for table, expr := range exprs {
-						if (table.Name.IsEmpty() || table.Name == p.As) && (table.Qualifier.IsEmpty() || table.Qualifier == i.Table.Qualifier) {
-							expr = expr.Copy()
-							_, _ = expr.Walk(func(expr Expr) (bool, error) {
-								if e, ok := expr.(*ColumnExpr); ok {
-									e.Value.Qualifier = sqlparser.TableName{}
-								}
-								return true, nil
-							})
-							if i.Expr == nil {
-								i.Expr = expr
-							} else {
-								i.Expr = &AndExpr{Left: i.Expr, Right: expr}
-							}
-						}
-					}
+			case *FilterPlan:
+				return true, nil
+			case *WindowPlan:
+				for _, fn := range p.Funcs {
+					windows = append(windows, &ColumnExpr{Value: fn.As})
 				}
-			case *JoinPlan:
 				return true, nil
+			case *GroupPlan:
+				keys = slices.Concat(p.Exprs, windows)
+				for _, fn := range p.Aggregates {
+					keys = append(keys, &ColumnExpr{Value: fn.As})
+				}
+				grouped = true
 			}
 			return false, nil
 		})
 
-		return &FilterPlan{
+		columns, err := p.schema(input)
+		if err != nil {
+			return nil, err
+		}
+		binder := NewBinder(columns)
+
+		items := make([]ProjectionItem, 0, len(node))
+		for _, expr := range node {
+			switch e := expr.(type) {
+			case *sqlparser.StarExpr:
+				if grouped {
+					for _, col := range columns {
+						if !schema.MatchTable(col.Name.Qualifier, e.TableName) {
+							continue
+						}
+						if err := binder.Grouped(&ColumnExpr{Value: col.Name}, keys); err != nil {
+							return nil, err
+						}
+					}
+				}
+				items = append(items, &StartItem{Table: e.TableName})
+			case *sqlparser.AliasedExpr:
+				expr, err := p.planExpr(e.Expr)
+				if err != nil {
+					return nil, err
+				}
+				if err := binder.Bind(expr); err != nil {
+					return nil, err
+				}
+				if grouped {
+					if err := binder.Grouped(expr, keys); err != nil {
+						return nil, err
+					}
+				}
+				as := e.As
+				if as.IsEmpty() {
+					as = sqlparser.NewColIdent(sqlparser.String(e.Expr))
+					switch expr := expr.(type) {
+					case *IndexExpr:
+						if left, ok := expr.Left.(*ColumnExpr); ok {
+							as = left.Value.Name
+						}
+					case *ColumnExpr:
+						as = expr.Value.Name
+					default:
+					}
+				}
+				items = append(items, &AliasItem{Expr: expr, As: as})
+			default:
+				return nil, driver.ErrSkip
+			}
+		}
+		return &ProjectionPlan{
 			Input: input,
-			Expr:  expr,
+			Items: items,
 		}, nil
 	}
-	return input, nil
-}
+	return input, nil
+}
+
+func (p *Planner) planWindows(input Plan, node *sqlparser.Select) (Plan, error) {
+	var exprs []*WindowExpr
+	err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *WindowExpr:
+			if err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+				if _, ok := node.(*WindowExpr); ok {
+					return false, fmt.Errorf("window function calls cannot be nested")
+				}
+				return true, nil
+			}, n.Exprs, n.Over.PartitionBy, n.Over.OrderBy); err != nil {
+				return false, err
+			}
+			if !slices.ContainsFunc(exprs, func(e *WindowExpr) bool { return sqlparser.String(e) == sqlparser.String(n) }) {
+				exprs = append(exprs, n)
+			}
+			return false, nil
+		case *sqlparser.Subquery:
+			return false, nil
+		}
+		return true, nil
+	}, node.SelectExprs, node.OrderBy)
+	if err != nil || len(exprs) == 0 {
+		return input, err
+	}
+
+	funcs := make([]*WindowFunc, 0, len(exprs))
+	for _, expr := range exprs {
+		fn, err := p.planWindowExpr(input, expr)
+		if err != nil {
+			return nil, err
+		}
+		funcs = append(funcs, fn)
+	}
+
+	p.expandStar(node)
+
+	return &WindowPlan{Input: input, Funcs: funcs}, nil
+}
+
+// expandStar qualifies a bare * so as not to select computed columns.
+func (p *Planner) expandStar(node *sqlparser.Select) {
+	var selectExprs sqlparser.SelectExprs
+	for _, expr := range node.SelectExprs {
+		if star, ok := expr.(*sqlparser.StarExpr); ok && star.TableName.IsEmpty() {
+			for _, table := range p.tables(node.From) {
+				selectExprs = append(selectExprs, &sqlparser.StarExpr{TableName: table})
+			}
+		} else {
+			selectExprs = append(selectExprs, expr)
+		}
+	}
+	node.SelectExprs = selectExprs
+}
+
+func (p *Planner) planWindowExpr(input Plan, node *WindowExpr) (*WindowFunc, error) {
+	if !IsWindowFunction(node.Name) && !p.isAggregate(node.FuncExpr) {
+		return nil, fmt.Errorf("%s is not a window function", node.Name.String())
+	}
+	if node.Distinct {
+		return nil, fmt.Errorf("DISTINCT is not supported in window functions")
+	}
+
+	funcExpr := node.FuncExpr
+	if p.isAggregate(funcExpr) {
+		f := *funcExpr
+		f.Exprs = slices.DeleteFunc(slices.Clone(f.Exprs), func(expr sqlparser.SelectExpr) bool {
+			_, ok := expr.(*sqlparser.StarExpr)
+			return ok
+		})
+		funcExpr = &f
+	}
+
+	call, err := p.planFuncExpr(funcExpr)
+	if err != nil {
+		return nil, err
+	}
+	fn := &WindowFunc{
+		Call: call.(*CallExpr),
+		As:   windowColumn(node),
+	}
+	exprs := []Expr{fn.Call}
+
+	for _, expr := range node.Over.PartitionBy {
+		e, err := p.planExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		fn.Partition = append(fn.Partition, e)
+		exprs = append(exprs, e)
+	}
+	for _, order := range node.Over.OrderBy {
+		e, err := p.planExpr(order.Expr)
+		if err != nil {
+			return nil, err
+		}
+		fn.Order = append(fn.Order, WindowOrder{Expr: e, Direction: order.Direction})
+		exprs = append(exprs, e)
+	}
 
-func (p *Planner) planGroupBy(input Plan, node sqlparser.GroupBy) (Plan, error) {
-	if len(node) > 0 {
-		exprs := make([]Expr, 0, len(node))
-		for _, expr := range node {
-			e, err := p.planExpr(expr)
+	if frame := node.Over.Frame; frame != nil {
+		fn.Unit = frame.Unit
+		for _, bound := range []struct {
+			from WindowFrameBound
+			to   *WindowBound
+		}{{frame.Start, &fn.Start}, {frame.End, &fn.End}} {
+			offset, err := p.planExpr(bound.from.Offset)
 			if err != nil {
 				return nil, err
 			}
-			exprs = append(exprs, e)
-		}
-		input = &GroupPlan{
-			Input: input,
-			Exprs: exprs,
+			if offset != nil {
+				exprs = append(exprs, offset)
+				if fn.Unit == RangeStr && len(fn.Order) != 1 {
+					return nil, fmt.Errorf("RANGE with an offset requires exactly one ORDER BY expression")
+				}
+			}
+			*bound.to = WindowBound{Type: bound.from.Type, Offset: offset}
 		}
 	}
-	return input, nil
-}
-
-func (p *Planner) planHaving(input Plan, node *sqlparser.Where) (Plan, error) {
-	if node != nil {
-		expr, err := p.planExpr(node.Expr)
-		if err != nil {
-			return nil, err
-		}
 
-		return &FilterPlan{
-			Input: input,
-			Expr:  expr,
-		}, nil
+	if err := p.bind(input, exprs...); err != nil {
+		return nil, err
 	}
-	return input, nil
+	return fn, nil
 }
 
-func (p *Planner) planSelectExprs(input Plan, node sqlparser.SelectExprs) (Plan, error) {
-	if len(node) > 0 {
-		items := make([]ProjectionItem, 0, len(node))
-		for _, expr := range node {
-			switch e := expr.(type) {
-			case *sqlparser.StarExpr:
-				items = append(items, &StartItem{Table: e.TableName})
-			case *sqlparser.AliasedExpr:
-				expr, err := p.planExpr(e.Expr)
-				if err != nil {
-					return nil, err
-				}
-				as := e.As
-				if as.IsEmpty() {
-					as = sqlparser.NewColIdent(sqlparser.String(e.Expr))
-					switch expr := expr.(type) {
-					case *IndexExpr:
-						if left, ok := expr.Left.(*ColumnExpr); ok {
-							as = left.Value.Name
-						}
-					case *ColumnExpr:
-						as = expr.Value.Name
-					default:
-					}
+func (p *Planner) tables(node sqlparser.TableExprs) []sqlparser.TableName {
+	var tables []sqlparser.TableName
+	for _, expr := range node {
+		switch n := expr.(type) {
+		case *sqlparser.AliasedTableExpr:
+			as := n.As
+			if as.IsEmpty() {
+				if name, ok := n.Expr.(sqlparser.TableName); ok {
+					as = name.Name
+				} else {
+					as = sqlparser.NewTableIdent(sqlparser.String(n.Expr))
 				}
-				items = append(items, &AliasItem{Expr: expr, As: as})
-			default:
-				return nil, driver.ErrSkip
 			}
+			tables = append(tables, sqlparser.TableName{Name: as})
+		case *JSONTable:
+			tables = append(tables, sqlparser.TableName{Name: n.As})
+		case *sqlparser.ParenTableExpr:
+			tables = append(tables, p.tables(n.Exprs)...)
+		case *sqlparser.JoinTableExpr:
+			tables = append(tables, p.tables(sqlparser.TableExprs{n.LeftExpr, n.RightExpr})...)
 		}
-		return &ProjectionPlan{
-			Input: input,
-			Items: items,
-		}, nil
 	}
-	return input, nil
+	return tables
 }
 
 func (p *Planner) planDistinct(input Plan, distinct string) (Plan, error) {
@@ -312,19 +1185,49 @@ func (p *Planner) planDistinct(input Plan, distinct string) (Plan, error) {
 	return input, nil
 }
 
-func (p *Planner) planOrderBy(input Plan, node sqlparser.OrderBy) (Plan, error) {
-	left := input
-	for i := len(node) - 1; i >= 0; i-- {
-		expr, err := p.planExpr(node[i].Expr)
+func (p *Planner) planOrderBy(input Plan, node sqlparser.OrderBy, selectExprs sqlparser.SelectExprs) (Plan, error) {
+	exprs := make([]Expr, 0, len(node))
+	for _, order := range node {
+		expr, err := p.planExpr(order.Expr)
 		if err != nil {
 			return nil, err
 		}
+		exprs = append(exprs, expr)
+	}
+
+	var projection *ProjectionPlan
+	if err := p.bind(input, exprs...); err != nil {
+		var ok bool
+		if projection, ok = input.(*ProjectionPlan); !ok || !errors.Is(err, ErrUnknownColumn) {
+			return nil, err
+		}
+		// Select aliases are not columns yet below the projection.
+		for i, order := range node {
+			if exprs[i], err = p.planExpr(unalias(order.Expr, selectExprs)); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.bind(projection.Input, exprs...); err != nil {
+			return nil, err
+		}
+	}
+
+	left := input
+	if projection != nil {
+		left = projection.Input
+	}
+	for i := len(node) - 1; i >= 0; i-- {
 		left = &OrderPlan{
 			Input:     left,
-			Expr:      expr,
+			Expr:      exprs[i],
 			Direction: node[i].Direction,
 		}
 	}
+
+	if projection != nil {
+		projection.Input = left
+		return projection, nil
+	}
 	return left, nil
 }
 
@@ -338,6 +1241,7 @@ func (p *Planner) planLimit(input Plan, node *sqlparser.Limit) (Plan, error) {
 		if err != nil {
 			return nil, err
 		}
+		p.pushLimit(input, offset, count)
 		input = &LimitPlan{
 			Input:  input,
 			Offset: offset,
@@ -347,6 +1251,36 @@ func (p *Planner) planLimit(input Plan, node *sqlparser.Limit) (Plan, error) {
 	return input, nil
 }
 
+// pushLimit caps the rows a single-table scan reads when only projections and a pushed-down filter lie between.
+func (p *Planner) pushLimit(input Plan, offset, count Expr) {
+	var filter *FilterPlan
+	for {
+		switch plan := input.(type) {
+		case *ProjectionPlan:
+			input = plan.Input
+			continue
+		case *AliasPlan:
+			input = plan.Input
+			continue
+		case *FilterPlan:
+			if filter != nil {
+				return
+			}
+			filter, input = plan, plan.Input
+			continue
+		case *ScanPlan:
+			if filter != nil && (plan.Expr == nil || len(conjuncts(filter.Expr)) != len(conjuncts(plan.Expr))) {
+				return
+			}
+			if offset != nil {
+				count = &AddExpr{Left: offset, Right: count}
+			}
+			plan.Limit = count
+		}
+		return
+	}
+}
+
 func (p *Planner) planExpr(expr sqlparser.Expr) (Expr, error) {
 	if expr == nil {
 		return nil, nil
@@ -370,6 +1304,8 @@ func (p *Planner) planExpr(expr sqlparser.Expr) (Expr, error) {
 		return p.planExistsExpr(expr)
 	case *sqlparser.SQLVal:
 		return p.planSQLVal(expr)
+	case *WindowExpr:
+		return p.planColName(windowColumn(expr))
 	case *sqlparser.NullVal:
 		return p.planNullValue(expr)
 	case sqlparser.BoolVal:
@@ -390,7 +1326,12 @@ func (p *Planner) planExpr(expr sqlparser.Expr) (Expr, error) {
 		return p.planIntervalExpr(expr)
 	case *sqlparser.CollateExpr:
 	case *sqlparser.FuncExpr:
+		if p.isAggregate(expr) {
+			return nil, ErrGroupFunction
+		}
 		return p.planFuncExpr(expr)
+	case *FilterExpr:
+		return nil, ErrGroupFunction
 	case *sqlparser.CaseExpr:
 		return p.planCaseExpr(expr)
 	case *sqlparser.ValuesFuncExpr:
@@ -403,7 +1344,7 @@ func (p *Planner) planExpr(expr sqlparser.Expr) (Expr, error) {
 	case *sqlparser.MatchExpr:
 		return p.planMatchExpr(expr)
 	case *sqlparser.GroupConcatExpr:
-		return p.planGroupConcatExpr(expr)
+		return nil, ErrGroupFunction
 	case *sqlparser.Default:
 		return p.planDefault(expr)
 	}
@@ -457,8 +1398,10 @@ func (p *Planner) planComparisonExpr(expr *sqlparser.ComparisonExpr) (Expr, erro
 	}
 
 	switch expr.Operator {
-	case sqlparser.EqualStr, sqlparser.NullSafeEqualStr:
+	case sqlparser.EqualStr:
 		return &EqualExpr{Left: left, Right: right}, nil
+	case sqlparser.NullSafeEqualStr:
+		return &NullSafeEqualExpr{Left: left, Right: right}, nil
 	case sqlparser.NotEqualStr:
 		return &NotExpr{Input: &EqualExpr{Left: left, Right: right}}, nil
 	case sqlparser.LessThanStr:
@@ -532,13 +1475,13 @@ func (p *Planner) planIsExpr(expr *sqlparser.IsExpr) (Expr, error) {
 		return &CallExpr{
 			Dispatcher: p.dispatcher,
 			Name:       NVL2,
-			Input:      &TupleExpr{Exprs: []Expr{input, &LiteralExpr{Value: sqltypes.NewInt64(1)}, &LiteralExpr{Value: sqltypes.NewInt64(0)}}},
+			Input:      &TupleExpr{Exprs: []Expr{input, &LiteralExpr{Value: sqltypes.NewInt64(0)}, &LiteralExpr{Value: sqltypes.NewInt64(1)}}},
 		}, nil
 	case sqlparser.IsNotNullStr:
 		return &CallExpr{
 			Dispatcher: p.dispatcher,
 			Name:       NVL2,
-			Input:      &TupleExpr{Exprs: []Expr{input, &LiteralExpr{Value: sqltypes.NewInt64(0)}, &LiteralExpr{Value: sqltypes.NewInt64(1)}}},
+			Input:      &TupleExpr{Exprs: []Expr{input, &LiteralExpr{Value: sqltypes.NewInt64(1)}, &LiteralExpr{Value: sqltypes.NewInt64(0)}}},
 		}, nil
 	case sqlparser.IsTrueStr:
 		return &IfExpr{
@@ -546,12 +1489,24 @@ func (p *Planner) planIsExpr(expr *sqlparser.IsExpr) (Expr, error) {
 			Then: &LiteralExpr{Value: sqltypes.NewInt64(1)},
 			Else: &LiteralExpr{Value: sqltypes.NewInt64(0)},
 		}, nil
-	case sqlparser.IsFalseStr:
+	case sqlparser.IsNotTrueStr:
 		return &IfExpr{
 			When: input,
 			Then: &LiteralExpr{Value: sqltypes.NewInt64(0)},
 			Else: &LiteralExpr{Value: sqltypes.NewInt64(1)},
 		}, nil
+	case sqlparser.IsFalseStr:
+		return &IfExpr{
+			When: &NotExpr{Input: input},
+			Then: &LiteralExpr{Value: sqltypes.NewInt64(1)},
+			Else: &LiteralExpr{Value: sqltypes.NewInt64(0)},
+		}, nil
+	case sqlparser.IsNotFalseStr:
+		return &IfExpr{
+			When: &NotExpr{Input: input},
+			Then: &LiteralExpr{Value: sqltypes.NewInt64(0)},
+			Else: &LiteralExpr{Value: sqltypes.NewInt64(1)},
+		}, nil
 	default:
 		return nil, driver.ErrSkip
 	}
@@ -604,7 +1559,7 @@ func (p *Planner) planSQLVal(expr *sqlparser.SQLVal) (Expr, error) {
 			return &LiteralExpr{Value: val}, nil
 		}
 	case sqlparser.ValArg:
-		return &ValArgExpr{Value: string(expr.Val)}, nil
+		return &ValArgExpr{Value: strings.TrimPrefix(string(expr.Val), ":")}, nil
 	case sqlparser.BitVal:
 		if data, ok := new(big.Int).SetString(string(expr.Val), 2); !ok {
 			return nil, fmt.Errorf("invalid bit string '%s'", expr.Val)
@@ -622,17 +1577,31 @@ func (p *Planner) planNullValue(_ *sqlparser.NullVal) (Expr, error) {
 }
 
 func (p *Planner) planBoolVal(expr sqlparser.BoolVal) (Expr, error) {
-	val := sqltypes.NewInt64(1)
+	val := sqltypes.NewInt64(0)
 	if expr {
-		val = sqltypes.NewInt64(0)
+		val = sqltypes.NewInt64(1)
 	}
 	return &LiteralExpr{Value: val}, nil
 }
 
 func (p *Planner) planColName(expr *sqlparser.ColName) (Expr, error) {
+	if name, ok := variableName(expr.Name.String()); ok && expr.Qualifier.IsEmpty() {
+		return p.planVariable(name)
+	}
 	return &IndexExpr{Left: &ColumnExpr{Value: expr}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}, nil
 }
 
+func (p *Planner) planVariable(name string) (Expr, error) {
+	if name == "time_zone" {
+		return &LiteralExpr{Value: sqltypes.NewVarChar(p.location.String())}, nil
+	}
+	val, ok := p.variables[name]
+	if !ok {
+		return nil, errUnknownVariable(name)
+	}
+	return &LiteralExpr{Value: val}, nil
+}
+
 func (p *Planner) planValTuple(expr sqlparser.ValTuple) (Expr, error) {
 	var exprs []Expr
 	for _, val := range expr {
@@ -654,7 +1623,7 @@ func (p *Planner) planSubqueryExpr(expr *sqlparser.Subquery) (Expr, error) {
 }
 
 func (p *Planner) planListArg(expr sqlparser.ListArg) (Expr, error) {
-	return &ValArgExpr{Value: string(expr)}, nil
+	return &ValArgExpr{Value: strings.TrimPrefix(string(expr), "::")}, nil
 }
 
 func (p *Planner) planBinaryExpr(expr *sqlparser.BinaryExpr) (Expr, error) {
@@ -702,6 +1671,10 @@ func (p *Planner) planBinaryExpr(expr *sqlparser.BinaryExpr) (Expr, error) {
 			Name:       BitXor,
 			Input:      &TupleExpr{Exprs: []Expr{left, right}},
 		}, nil
+	case sqlparser.JSONExtractOp:
+		return &JSONExtractExpr{Left: left, Right: right}, nil
+	case sqlparser.JSONUnquoteExtractOp:
+		return &ConvertExpr{Input: &JSONExtractExpr{Left: left, Right: right}, Type: &sqlparser.ConvertType{Type: querypb.Type_name[int32(querypb.Type_VARCHAR)]}}, nil
 	default:
 		return nil, driver.ErrSkip
 	}
@@ -738,12 +1711,30 @@ func (p *Planner) planIntervalExpr(expr *sqlparser.IntervalExpr) (Expr, error) {
 }
 
 func (p *Planner) planFuncExpr(expr *sqlparser.FuncExpr) (Expr, error) {
+	// DATABASE() and VERSION() read the session, which functions do not see.
+	if expr.Qualifier.IsEmpty() && len(expr.Exprs) == 0 {
+		switch expr.Name.Lowered() {
+		case "database", "schema":
+			if p.database == "" {
+				return &LiteralExpr{Value: sqltypes.NULL}, nil
+			}
+			return &LiteralExpr{Value: sqltypes.NewVarChar(p.database)}, nil
+		case "version":
+			return p.planVariable("version")
+		}
+	}
+
 	exprs := make([]Expr, 0, len(expr.Exprs))
-	for _, expr := range expr.Exprs {
-		switch e := expr.(type) {
+	for i, arg := range expr.Exprs {
+		switch e := arg.(type) {
 		case *sqlparser.StarExpr:
 			exprs = append(exprs, &TableExpr{Value: e.TableName})
 		case *sqlparser.AliasedExpr:
+			// TIMESTAMPDIFF and TIMESTAMPADD take a bare unit keyword as their first argument.
+			if col, ok := e.Expr.(*sqlparser.ColName); ok && i == 0 && col.Qualifier.IsEmpty() && (expr.Name.Equal(TimestampDiff) || expr.Name.Equal(TimestampAdd)) {
+				exprs = append(exprs, &LiteralExpr{Value: sqltypes.NewVarChar(col.Name.String())})
+				continue
+			}
 			expr, err := p.planExpr(e.Expr)
 			if err != nil {
 				return nil, err
@@ -759,13 +1750,57 @@ func (p *Planner) planFuncExpr(expr *sqlparser.FuncExpr) (Expr, error) {
 		input = &DistinctExpr{Input: input}
 	}
 
-	return &CallExpr{
+	call := &CallExpr{
 		Dispatcher: p.dispatcher,
 		Qualifier:  expr.Qualifier,
 		Name:       expr.Name,
-		Aggregate:  expr.IsAggregate(),
+		Aggregate:  p.isAggregate(expr),
 		Input:      input,
-	}, nil
+	}
+	if call.Aggregate {
+		return call, nil
+	}
+	spec, ok := p.dispatcher.Function(call.FuncName())
+	if !ok {
+		return call, nil
+	}
+
+	// Column types are checked once the call is bound.
+	if err := call.check(nil); err != nil {
+		return nil, err
+	}
+
+	if !spec.Deterministic {
+		return call, nil
+	}
+	for _, arg := range exprs {
+		if _, ok := arg.(*LiteralExpr); !ok {
+			return call, nil
+		}
+	}
+	val, err := call.Eval(p.context(), schema.Row{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if lit, ok := literal(val); ok {
+		return lit, nil
+	}
+	return call, nil
+}
+
+// literal reports false if val does not round-trip as a literal.
+func literal(val Value) (*LiteralExpr, bool) {
+	switch val.(type) {
+	case nil:
+		return &LiteralExpr{Value: sqltypes.NULL}, true
+	case *Int64, *Uint64, *Float64, *VarChar, *VarBinary, *JSON:
+		v, err := ToSQL(val, val.Type())
+		if err != nil {
+			return nil, false
+		}
+		return &LiteralExpr{Value: v}, true
+	}
+	return nil, false
 }
 
 func (p *Planner) planCaseExpr(expr *sqlparser.CaseExpr) (Expr, error) {
@@ -809,7 +1844,11 @@ func (p *Planner) planConvertExpr(expr *sqlparser.ConvertExpr) (Expr, error) {
 }
 
 func (p *Planner) planSubstrExpr(expr *sqlparser.SubstrExpr) (Expr, error) {
-	exprs := []Expr{&ColumnExpr{Value: expr.Name}}
+	name, err := p.planColName(expr.Name)
+	if err != nil {
+		return nil, err
+	}
+	exprs := []Expr{name}
 	if expr.From != nil {
 		from, err := p.planExpr(expr.From)
 		if err != nil {
@@ -859,48 +1898,275 @@ func (p *Planner) planMatchExpr(expr *sqlparser.MatchExpr) (Expr, error) {
 	}, nil
 }
 
-func (p *Planner) planGroupConcatExpr(expr *sqlparser.GroupConcatExpr) (Expr, error) {
-	exprs := make([]Expr, 0, len(expr.Exprs))
-	for _, expr := range expr.Exprs {
-		switch e := expr.(type) {
-		case *sqlparser.StarExpr:
-			exprs = append(exprs, &TableExpr{Value: e.TableName})
-		case *sqlparser.AliasedExpr:
-			expr, err := p.planExpr(e.Expr)
-			if err != nil {
-				return nil, err
+func (p *Planner) planDefault(_ *sqlparser.Default) (Expr, error) {
+	return &LiteralExpr{Value: sqltypes.NULL}, nil
+}
+
+func (p *Planner) isAggregate(expr sqlparser.Expr) bool {
+	switch e := expr.(type) {
+	case *sqlparser.FuncExpr:
+		_, ok := p.dispatcher.Aggregate(e.Name.String())
+		return ok || e.IsAggregate()
+	case *FilterExpr:
+		return p.isAggregate(e.FuncExpr)
+	case *sqlparser.GroupConcatExpr:
+		return true
+	}
+	return false
+}
+
+func (p *Planner) resolve(qualifier sqlparser.TableIdent) (schema.Catalog, string, error) {
+	if qualifier.IsEmpty() || qualifier.String() == p.database {
+		return p.catalog, p.database, nil
+	}
+	catalog, err := p.registry.Catalog(qualifier.String())
+	if err != nil {
+		return nil, "", err
+	}
+	return catalog, qualifier.String(), nil
+}
+
+func (p *Planner) with(catalog schema.Catalog, database string) *Planner {
+	return &Planner{
+		catalog:    catalog,
+		dispatcher: p.dispatcher,
+		registry:   p.registry,
+		database:   database,
+		location:   p.location,
+		views:      p.views,
+		maxDepth:   p.maxDepth,
+		parallel:   p.parallel,
+		variables:  p.variables,
+	}
+}
+
+// cte shadows any table of the same name.
+func (p *Planner) cte(node sqlparser.TableName) (*commonTable, bool) {
+	if !node.Qualifier.IsEmpty() {
+		return nil, false
+	}
+	table, ok := p.ctes[node.Name.String()]
+	return table, ok
+}
+
+func (p *Planner) bind(input Plan, exprs ...Expr) error {
+	columns, err := p.schema(input)
+	if err != nil {
+		return err
+	}
+	binder := NewBinder(columns)
+	for _, expr := range exprs {
+		if err := binder.Bind(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Planner) context() context.Context {
+	return ContextWithLocation(context.Background(), p.location)
+}
+
+func (p *Planner) schema(input Plan) ([]schema.Column, error) {
+	columns, err := input.Schema(context.Background())
+	if errors.Is(err, schema.ErrTableNotFound) {
+		return nil, nil
+	}
+	return columns, err
+}
+
+func (p *Planner) pushdown(input Plan, expr Expr) {
+	exprs := p.splitByTables(expr)
+	_, _ = input.Walk(func(plan Plan) (bool, error) {
+		switch plan := plan.(type) {
+		case *AliasPlan:
+			for table, expr := range exprs {
+				if (!table.Name.IsEmpty() && table.Name != plan.As) || (!table.Qualifier.IsEmpty() && table.Qualifier != plan.Qualifier) {
+					continue
+				}
+
+				expr = expr.Copy()
+				_, _ = expr.Walk(func(expr Expr) (bool, error) {
+					if e, ok := expr.(*ColumnExpr); ok {
+						e.Value.Qualifier = sqlparser.TableName{}
+					}
+					return true, nil
+				})
+
+				switch input := plan.Input.(type) {
+				case *ScanPlan:
+					if input.Expr == nil {
+						input.Expr = expr
+					} else {
+						input.Expr = &AndExpr{Left: input.Expr, Right: expr}
+					}
+				case *ProjectionPlan:
+					if expr, ok := p.unproject(input, expr); ok {
+						p.pushdown(input.Input, expr)
+					}
+				}
 			}
-			exprs = append(exprs, expr)
-		default:
-			return nil, driver.ErrSkip
+		case *JoinPlan, *FilterPlan, *OrderPlan, *JSONTablePlan:
+			return true, nil
 		}
+		return false, nil
+	})
+}
+
+// prune tells each scan which columns the query uses. It gives up on SELECT * and on queries using no column.
+func (p *Planner) prune(plan Plan) {
+	var scans []*ScanPlan
+	var computed []string
+	names := make(map[string]sqlparser.ColIdent)
+	whole := false
+
+	var visit func(plan Plan)
+	visit = func(plan Plan) {
+		_, _ = plan.Walk(func(plan Plan) (bool, error) {
+			switch plan := plan.(type) {
+			case *ScanPlan:
+				scans = append(scans, plan)
+			case *GroupPlan:
+				for _, fn := range plan.Aggregates {
+					computed = append(computed, fn.As.Name.Lowered())
+				}
+			case *WindowPlan:
+				for _, fn := range plan.Funcs {
+					computed = append(computed, fn.As.Name.Lowered())
+				}
+			}
+			exprs, ok := p.exprsOf(plan)
+			if !ok {
+				whole = true
+			}
+			for _, expr := range exprs {
+				_, _ = expr.Walk(func(expr Expr) (bool, error) {
+					switch e := expr.(type) {
+					case *ColumnExpr:
+						names[e.Value.Name.Lowered()] = e.Value.Name
+					case *SubqueryExpr:
+						visit(e.Input)
+					case *TableExpr, *InlineExpr:
+						whole = true
+					}
+					return true, nil
+				})
+			}
+			return !whole, nil
+		})
+	}
+	visit(plan)
+	if whole {
+		return
+	}
+	for _, name := range computed {
+		delete(names, name)
+	}
+	if len(names) == 0 {
+		return
 	}
 
-	input := Expr(&SpreadExpr{Exprs: exprs})
-	for _, order := range expr.OrderBy {
-		right, err := p.planExpr(order.Expr)
-		if err != nil {
-			return nil, err
+	columns := make([]*sqlparser.ColName, 0, len(names))
+	for _, key := range slices.Sorted(maps.Keys(names)) {
+		columns = append(columns, &sqlparser.ColName{Name: names[key]})
+	}
+	for _, scan := range scans {
+		scan.Columns = columns
+	}
+}
+
+func (p *Planner) exprsOf(plan Plan) ([]Expr, bool) {
+	var exprs []Expr
+	switch plan := plan.(type) {
+	case *ScanPlan:
+		exprs = append(exprs, plan.Expr)
+	case *FilterPlan:
+		exprs = append(exprs, plan.Expr)
+	case *OrderPlan:
+		exprs = append(exprs, plan.Expr)
+	case *LimitPlan:
+		exprs = append(exprs, plan.Offset, plan.Count)
+	case *JSONTablePlan:
+		exprs = append(exprs, plan.Expr)
+		if plan.On != nil {
+			exprs = append(exprs, plan.On)
+		}
+	case *ProjectionPlan:
+		for _, item := range plan.Items {
+			alias, ok := item.(*AliasItem)
+			if !ok {
+				return nil, false
+			}
+			exprs = append(exprs, alias.Expr)
+		}
+	case *GroupPlan:
+		exprs = append(exprs, plan.Exprs...)
+		for _, fn := range plan.Aggregates {
+			exprs = append(exprs, fn.Args...)
+			exprs = append(exprs, fn.Filter)
+			for _, order := range fn.Order {
+				exprs = append(exprs, order.Expr)
+			}
 		}
-		input = &OrderExpr{
-			Left:      input,
-			Right:     right,
-			Direction: order.Direction,
+	case *WindowPlan:
+		for _, fn := range plan.Funcs {
+			exprs = append(exprs, fn.Call, fn.Start.Offset, fn.End.Offset)
+			exprs = append(exprs, fn.Partition...)
+			for _, order := range fn.Order {
+				exprs = append(exprs, order.Expr)
+			}
 		}
+	case *JoinPlan, *UnionPlan, *AliasPlan, *DistinctPlan, *WithPlan, *MaterializePlan, *RecursivePlan, *WorkTablePlan, *NOPPlan, *DualPlan:
+	default:
+		return nil, false
 	}
-	if expr.Distinct == sqlparser.DistinctStr {
-		input = &DistinctExpr{Input: input}
+	return slices.DeleteFunc(exprs, func(expr Expr) bool { return expr == nil }), true
+}
+
+// unproject rewrites expr over a projection's output into one over its input.
+func (p *Planner) unproject(projection *ProjectionPlan, expr Expr) (Expr, bool) {
+	ok := true
+	_, _ = projection.Input.Walk(func(plan Plan) (bool, error) {
+		switch plan.(type) {
+		case *FilterPlan, *OrderPlan:
+			return true, nil
+		case *GroupPlan, *LimitPlan, *DistinctPlan, *WindowPlan:
+			ok = false
+		}
+		return false, nil
+	})
+	if !ok {
+		return nil, false
 	}
 
-	return &CallExpr{
-		Dispatcher: p.dispatcher,
-		Name:       ConcatWs,
-		Input:      &SpreadExpr{Exprs: []Expr{&LiteralExpr{Value: sqltypes.NewVarChar(expr.Separator)}, input}},
-	}, nil
-}
+	_, _ = expr.Walk(func(expr Expr) (bool, error) {
+		e, isColumn := expr.(*ColumnExpr)
+		if !isColumn {
+			return true, nil
+		}
+		for _, item := range projection.Items {
+			alias, isAlias := item.(*AliasItem)
+			if !isAlias || !alias.As.Equal(e.Value.Name) {
+				continue
+			}
 
-func (p *Planner) planDefault(_ *sqlparser.Default) (Expr, error) {
-	return &LiteralExpr{Value: sqltypes.NULL}, nil
+			var col *ColumnExpr
+			switch a := alias.Expr.(type) {
+			case *ColumnExpr:
+				col = a
+			case *IndexExpr:
+				col, _ = a.Left.(*ColumnExpr)
+			}
+			if col == nil {
+				ok = false
+				return false, nil
+			}
+			e.Value = &sqlparser.ColName{Metadata: col.Value.Metadata, Name: col.Value.Name, Qualifier: col.Value.Qualifier}
+			return false, nil
+		}
+		return false, nil
+	})
+	return expr, ok
 }
 
 func (p *Planner) splitByTables(expr Expr) map[sqlparser.TableName]Expr {
@@ -934,3 +2200,35 @@ func (p *Planner) splitByTables(expr Expr) map[sqlparser.TableName]Expr {
 	}
 	return exprs
 }
+
+func conjuncts(expr Expr) []Expr {
+	if e, ok := expr.(*AndExpr); ok {
+		return append(conjuncts(e.Left), conjuncts(e.Right)...)
+	}
+	return []Expr{expr}
+}
+
+func windowColumn(expr *WindowExpr) *sqlparser.ColName {
+	return &sqlparser.ColName{Name: sqlparser.NewColIdent(sqlparser.String(expr))}
+}
+
+// references counts unqualified references to name, nested CTEs included.
+func references(name string, stmts ...sqlparser.SelectStatement) int {
+	count := 0
+	for _, stmt := range stmts {
+		if with, ok := stmt.(*With); ok {
+			for _, cte := range with.CTEs {
+				count += references(name, cte.Select)
+			}
+		}
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if n, ok := node.(*sqlparser.AliasedTableExpr); ok {
+				if table, ok := n.Expr.(sqlparser.TableName); ok && table.Qualifier.IsEmpty() && table.Name.String() == name {
+					count++
+				}
+			}
+			return true, nil
+		}, stmt)
+	}
+	return count
+}
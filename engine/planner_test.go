@@ -1,7 +1,9 @@
 package engine
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/siyul-park/sqlbridge/schema"
 	"github.com/stretchr/testify/require"
@@ -53,7 +55,7 @@ func TestPlanner_Plan(t *testing.T) {
 			},
 			plan: &ProjectionPlan{
 				Input: &AliasPlan{
-					Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+					Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}, Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}},
 					As:    sqlparser.NewTableIdent("t1"),
 				},
 				Items: []ProjectionItem{&AliasItem{Expr: &IndexExpr{Left: &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}, As: sqlparser.NewColIdent("id")}},
@@ -212,8 +214,12 @@ func TestPlanner_Plan(t *testing.T) {
 			plan: &LimitPlan{
 				Input: &ProjectionPlan{
 					Input: &AliasPlan{
-						Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
-						As:    sqlparser.NewTableIdent("t1"),
+						Input: &ScanPlan{
+							Catalog: catalog,
+							Table:   sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")},
+							Limit:   &AddExpr{Left: &LiteralExpr{Value: sqltypes.NewInt64(1)}, Right: &LiteralExpr{Value: sqltypes.NewInt64(1)}},
+						},
+						As: sqlparser.NewTableIdent("t1"),
 					},
 					Items: []ProjectionItem{&StartItem{}},
 				},
@@ -231,3 +237,668 @@ func TestPlanner_Plan(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanner_Bind(t *testing.T) {
+	columns := func(table string) []schema.Column {
+		return []schema.Column{
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+			{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent(table + "_name")}, Type: querypb.Type_VARCHAR},
+		}
+	}
+
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(context.TODO(), columns("t1"))
+	t2 := schema.NewInMemoryTable(nil)
+	_ = t2.SetColumns(context.TODO(), columns("t2"))
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		"t1": t1,
+		"t2": t2,
+	})
+	dispatcher := NewDispatcher()
+	planner := NewPlanner(catalog, dispatcher)
+
+	tests := []struct {
+		query string
+		err   error
+	}{
+		{query: "SELECT id, t1_name FROM t1 WHERE id = 1"},
+		{query: "SELECT t1.id FROM t1 JOIN t2 ON t1.id = t2.id"},
+		{query: "SELECT id, COUNT(*) AS c FROM t1 GROUP BY id HAVING c > 1"},
+		{query: "SELECT id FROM t1 ORDER BY t1_name"},
+		{query: "SELECT age FROM t1", err: ErrUnknownColumn},
+		{query: "SELECT * FROM t1 WHERE age = 1", err: ErrUnknownColumn},
+		{query: "SELECT id FROM t1 JOIN t2 ON t1.id = t2.id", err: ErrAmbiguousColumn},
+		{query: "SELECT * FROM t1 JOIN t2 ON id = id", err: ErrAmbiguousColumn},
+		{query: "SELECT id, t1_name FROM t1 GROUP BY id", err: ErrNotGrouped},
+		{query: "SELECT * FROM t1 GROUP BY id", err: ErrNotGrouped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := sqlparser.Parse(tt.query)
+			require.NoError(t, err)
+
+			_, err = planner.Plan(node)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPlanner_PlanOrderByHiddenColumn(t *testing.T) {
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(context.TODO(), []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+	planner := NewPlanner(catalog, NewDispatcher())
+
+	node, err := sqlparser.Parse("SELECT id FROM t1 ORDER BY name")
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(node)
+	require.NoError(t, err)
+
+	projection, ok := plan.(*ProjectionPlan)
+	require.True(t, ok)
+	_, ok = projection.Input.(*OrderPlan)
+	require.True(t, ok)
+}
+
+func TestPlanner_PlanOrderByAlias(t *testing.T) {
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+	t1 := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("a")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("a")}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewVarChar("b")}},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+	planner := NewPlanner(catalog, NewDispatcher(WithBuiltIn()))
+
+	tests := []struct {
+		query  string
+		values []string
+	}{
+		{query: "SELECT name AS n FROM t1 ORDER BY n DESC, id", values: []string{"b", "a", "a"}},
+		{query: "SELECT id * 10 AS n FROM t1 ORDER BY name, n", values: []string{"10", "20", "30"}},
+		{query: "SELECT name AS n, COUNT(*) AS c FROM t1 GROUP BY name HAVING n = 'a' AND c > 1", values: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+			query := sqlparser.String(node)
+
+			plan, err := planner.Plan(node)
+			require.NoError(t, err)
+
+			cursor, err := plan.Run(context.TODO(), nil)
+			require.NoError(t, err)
+
+			rows, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+
+			var values []string
+			for _, row := range rows {
+				values = append(values, row.Values[0].ToString())
+			}
+			require.Equal(t, tt.values, values)
+
+			if sel := node.(*sqlparser.Select); sel.Having != nil {
+				require.Contains(t, sqlparser.String(sel.Having), "n = 'a'")
+			} else {
+				require.Equal(t, query, sqlparser.String(node))
+			}
+		})
+	}
+}
+
+func TestPlanner_PlanProjection(t *testing.T) {
+	t1 := schema.NewInMemoryTable(nil)
+	t2 := schema.NewInMemoryTable(nil)
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1, "t2": t2})
+	planner := NewPlanner(catalog, NewDispatcher(WithBuiltIn()))
+
+	tests := []struct {
+		query   string
+		columns []string
+	}{
+		{query: "SELECT name FROM t1 WHERE id > 1", columns: []string{"id", "name"}},
+		{query: "SELECT t1.name, COUNT(*) FROM t1 JOIN t2 USING (id) GROUP BY t1.name", columns: []string{"id", "name"}},
+		{query: "SELECT id FROM t1 WHERE EXISTS (SELECT 1 FROM t2 WHERE t2.parent_id = t1.id)", columns: []string{"id", "parent_id"}},
+		{query: "SELECT * FROM t1"},
+		{query: "SELECT id FROM (SELECT * FROM t1) AS t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			plan, err := planner.Plan(node)
+			require.NoError(t, err)
+
+			var columns []string
+			_, _ = plan.Walk(func(plan Plan) (bool, error) {
+				if scan, ok := plan.(*ScanPlan); ok && columns == nil {
+					for _, col := range scan.Columns {
+						columns = append(columns, col.Name.String())
+					}
+				}
+				return true, nil
+			})
+			require.Equal(t, tt.columns, columns)
+		})
+	}
+}
+
+func TestPlanner_PlanShow(t *testing.T) {
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		"t1": schema.NewInMemoryTable(nil),
+		"t2": schema.NewInMemoryTable(nil),
+	})
+	planner := NewPlanner(catalog, NewDispatcher(), WithDatabase("app"))
+
+	node, err := Parse("SHOW TABLES LIKE '%2'")
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(node)
+	require.NoError(t, err)
+
+	cursor, err := plan.Run(context.TODO(), nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "Tables_in_app", rows[0].Columns[0].Name.String())
+	require.Equal(t, "t2", rows[0].Values[0].ToString())
+
+	node, err = Parse("SHOW COLUMNS FROM t3")
+	require.NoError(t, err)
+
+	_, err = planner.Plan(node)
+	require.ErrorIs(t, err, schema.ErrTableNotFound)
+}
+
+func TestPlanner_PlanVariables(t *testing.T) {
+	planner := NewPlanner(schema.NewInMemoryCatalog(nil), NewDispatcher(), WithDatabase("app"), WithVariables(map[string]sqltypes.Value{"Version": sqltypes.NewVarChar("8.0.36")}))
+
+	tests := []struct {
+		query  string
+		values [][]string
+		err    error
+	}{
+		{query: "SELECT 1", values: [][]string{{"1"}}},
+		{query: "SELECT 1 + 1 FROM DUAL", values: [][]string{{"2"}}},
+		{query: "select @@version_comment limit 1", values: [][]string{{"sqlbridge"}}},
+		{query: "SELECT @@session.transaction_isolation, @@GLOBAL.autocommit", values: [][]string{{"REPEATABLE-READ", "1"}}},
+		{query: "SELECT DATABASE(), VERSION(), @@time_zone", values: [][]string{{"app", "8.0.36", "UTC"}}},
+		{query: "SHOW VARIABLES LIKE 'version%'", values: [][]string{{"version", "8.0.36"}, {"version_comment", "sqlbridge"}}},
+		{query: "SHOW SESSION VARIABLES WHERE Variable_name = 'max_allowed_packet'", values: [][]string{{"max_allowed_packet", "67108864"}}},
+		{query: "SELECT @@missing", err: ErrUnknownVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			plan, err := planner.Plan(node)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			cursor, err := plan.Run(context.TODO(), nil)
+			require.NoError(t, err)
+
+			rows, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+
+			values := make([][]string, 0, len(rows))
+			for _, row := range rows {
+				vals := make([]string, 0, len(row.Values))
+				for _, val := range row.Values {
+					vals = append(vals, val.ToString())
+				}
+				values = append(values, vals)
+			}
+			require.Equal(t, tt.values, values)
+		})
+	}
+}
+
+func TestPlanner_PlanView(t *testing.T) {
+	users := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("active")}}, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(1)}},
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("active")}}, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewInt64(0)}},
+		{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("active")}}, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewInt64(1)}},
+	})
+
+	catalog := schema.NewViewCatalog(schema.NewInMemoryCatalog(map[string]schema.Table{"users": users}))
+	require.NoError(t, catalog.SetView("active_users", "SELECT id AS user_id FROM users WHERE active = 1"))
+	require.NoError(t, catalog.SetView("loop", "SELECT * FROM loop"))
+
+	planner := NewPlanner(catalog, NewDispatcher())
+
+	node, err := Parse("SELECT user_id FROM active_users WHERE user_id = 3")
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(node)
+	require.NoError(t, err)
+
+	var scan *ScanPlan
+	_, _ = plan.Walk(func(plan Plan) (bool, error) {
+		if p, ok := plan.(*ScanPlan); ok {
+			scan = p
+		}
+		return true, nil
+	})
+	require.NotNil(t, scan)
+	require.Equal(t, "And(Equal(Indexes(Column(active), INT64(0)), INT64(1)), Equal(Indexes(Column(id), INT64(0)), INT64(3)))", scan.Expr.String())
+
+	cursor, err := plan.Run(context.TODO(), nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "3", rows[0].Values[0].ToString())
+
+	node, err = Parse("SELECT * FROM loop")
+	require.NoError(t, err)
+
+	_, err = planner.Plan(node)
+	require.ErrorIs(t, err, ErrRecursiveView)
+}
+
+func TestPlanner_PlanCreateView(t *testing.T) {
+	catalog := schema.NewViewCatalog(schema.NewInMemoryCatalog(map[string]schema.Table{"users": schema.NewInMemoryTable(nil)}))
+	planner := NewPlanner(catalog, NewDispatcher())
+
+	node, err := Parse("CREATE VIEW v AS SELECT * FROM users")
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(node)
+	require.NoError(t, err)
+	require.Equal(t, &CreateViewPlan{Catalog: catalog, Name: "v", Query: "select * from users"}, plan)
+
+	node, err = Parse("DROP VIEW IF EXISTS v")
+	require.NoError(t, err)
+
+	plan, err = planner.Plan(node)
+	require.NoError(t, err)
+	require.Equal(t, &DropViewPlan{Catalog: catalog, Name: "v", IfExists: true}, plan)
+
+	planner = NewPlanner(schema.NewInMemoryCatalog(nil), NewDispatcher())
+
+	node, err = Parse("CREATE VIEW v AS SELECT 1")
+	require.NoError(t, err)
+
+	_, err = planner.Plan(node)
+	require.ErrorIs(t, err, ErrViewNotSupported)
+}
+
+func TestPlanner_PlanWindow(t *testing.T) {
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(context.TODO(), []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+	planner := NewPlanner(catalog, NewDispatcher(WithBuiltIn()))
+
+	tests := []struct {
+		query string
+		err   error
+		msg   string
+	}{
+		{query: "SELECT id, ROW_NUMBER() OVER (PARTITION BY name ORDER BY id) FROM t1"},
+		{query: "SELECT id, RANK() OVER (ORDER BY id) FROM t1 GROUP BY id"},
+		{query: "SELECT id FROM t1 ORDER BY SUM(id) OVER (ORDER BY id ROWS 1 PRECEDING)"},
+		{query: "SELECT id, ROW_NUMBER() OVER (ORDER BY age) FROM t1", err: ErrUnknownColumn},
+		{query: "SELECT name, ROW_NUMBER() OVER (ORDER BY id) FROM t1 GROUP BY id", err: ErrNotGrouped},
+		{query: "SELECT UPPER(name) OVER () FROM t1", msg: "UPPER is not a window function"},
+		{query: "SELECT SUM(ROW_NUMBER() OVER ()) OVER () FROM t1", msg: "window function calls cannot be nested"},
+		{query: "SELECT SUM(id) OVER (ORDER BY id, name RANGE 1 PRECEDING) FROM t1", msg: "RANGE with an offset requires exactly one ORDER BY expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			_, err = planner.Plan(node)
+			switch {
+			case tt.err != nil:
+				require.ErrorIs(t, err, tt.err)
+			case tt.msg != "":
+				require.EqualError(t, err, tt.msg)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("star", func(t *testing.T) {
+		node, err := Parse("SELECT *, ROW_NUMBER() OVER () FROM t1")
+		require.NoError(t, err)
+
+		plan, err := planner.Plan(node)
+		require.NoError(t, err)
+
+		projection, ok := plan.(*ProjectionPlan)
+		require.True(t, ok)
+		require.Equal(t, &StartItem{Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}}, projection.Items[0])
+
+		cols, err := plan.Schema(context.TODO())
+		require.NoError(t, err)
+		require.Len(t, cols, 3)
+	})
+}
+
+func TestPlanner_PlanAggregate(t *testing.T) {
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(context.TODO(), []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+	dispatcher := NewDispatcher(WithBuiltIn(), WithAggregate("any_value", NewMax()))
+	planner := NewPlanner(catalog, dispatcher, WithParallelism(2))
+
+	tests := []struct {
+		query string
+		err   error
+		msg   string
+	}{
+		{query: "SELECT name, COUNT(*) FROM t1 GROUP BY name"},
+		{query: "SELECT COUNT(*), SUM(id) FILTER (WHERE id > 1) FROM t1"},
+		{query: "SELECT name, ANY_VALUE(id) FROM t1 GROUP BY name HAVING ANY_VALUE(id) > 1"},
+		{query: "SELECT name FROM t1 GROUP BY name ORDER BY COUNT(DISTINCT id)"},
+		{query: "SELECT name AS n, COUNT(*) FROM t1 GROUP BY n"},
+		{query: "SELECT id % 2 AS parity, COUNT(*) FROM t1 GROUP BY parity"},
+		{query: "SELECT name, COUNT(*) FROM t1", err: ErrNotGrouped},
+		{query: "SELECT SUM(COUNT(*)) FROM t1", err: ErrGroupFunction},
+		{query: "SELECT id FROM t1 WHERE SUM(id) > 1", err: ErrGroupFunction},
+		{query: "SELECT COUNT(age) FROM t1", err: ErrUnknownColumn},
+		{query: "SELECT UPPER(name) FILTER (WHERE id > 1) FROM t1", msg: "FILTER is only allowed for aggregate functions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			_, err = planner.Plan(node)
+			switch {
+			case tt.err != nil:
+				require.ErrorIs(t, err, tt.err)
+			case tt.msg != "":
+				require.EqualError(t, err, tt.msg)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("star", func(t *testing.T) {
+		node, err := Parse("SELECT *, COUNT(*) FROM t1 GROUP BY id, name")
+		require.NoError(t, err)
+
+		plan, err := planner.Plan(node)
+		require.NoError(t, err)
+
+		var group *GroupPlan
+		_, _ = plan.Walk(func(plan Plan) (bool, error) {
+			group, _ = plan.(*GroupPlan)
+			return group == nil, nil
+		})
+		require.NotNil(t, group)
+		require.Equal(t, 2, group.Parallelism)
+		require.Len(t, group.Aggregates, 1)
+
+		cols, err := plan.Schema(context.TODO())
+		require.NoError(t, err)
+		require.Len(t, cols, 3)
+	})
+
+	t.Run("alias", func(t *testing.T) {
+		columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+		t2 := schema.NewInMemoryTable([]schema.Row{
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("foo")}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewVarChar("bar")}},
+		})
+		planner := NewPlanner(schema.NewInMemoryCatalog(map[string]schema.Table{"t2": t2}), dispatcher)
+
+		node, err := Parse("SELECT name AS n, COUNT(*) FROM t2 GROUP BY n ORDER BY n")
+		require.NoError(t, err)
+
+		plan, err := planner.Plan(node)
+		require.NoError(t, err)
+
+		cursor, err := plan.Run(context.TODO(), nil)
+		require.NoError(t, err)
+
+		rows, err := schema.ReadAll(cursor)
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		require.Equal(t, []sqltypes.Value{sqltypes.NewVarChar("bar"), sqltypes.NewInt64(1)}, rows[0].Values)
+		require.Equal(t, []sqltypes.Value{sqltypes.NewVarChar("foo"), sqltypes.NewInt64(2)}, rows[1].Values)
+	})
+}
+
+func TestPlanner_PlanFunction(t *testing.T) {
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(context.TODO(), []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("created")}, Type: querypb.Type_DATETIME},
+	})
+
+	calls := 0
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+	dispatcher := NewDispatcher(
+		WithBuiltIn(),
+		WithTime(),
+		WithFunctionSpec("twice", FunctionSpec{
+			Call: func(_ context.Context, args []Value) (Value, error) {
+				calls++
+				return NewInt64(args[0].(*Int64).Int() * 2), nil
+			},
+			Params:        []querypb.Type{querypb.Type_INT64},
+			Return:        querypb.Type_INT64,
+			Deterministic: true,
+		}),
+		WithFunctionSpec("pick", FunctionSpec{
+			Call: func(_ context.Context, args []Value) (Value, error) {
+				return args[len(args)-1], nil
+			},
+			Params:   []querypb.Type{querypb.Type_NULL_TYPE, querypb.Type_NULL_TYPE},
+			Variadic: true,
+			Infer: func(args []querypb.Type) querypb.Type {
+				return args[len(args)-1]
+			},
+		}),
+	)
+	planner := NewPlanner(catalog, dispatcher)
+
+	tests := []struct {
+		query string
+		err   error
+	}{
+		{query: "SELECT twice(id) FROM t1"},
+		{query: "SELECT pick(id, name) FROM t1"},
+		{query: "SELECT twice(id, 1) FROM t1", err: ErrArgumentCount},
+		{query: "SELECT pick() FROM t1", err: ErrArgumentCount},
+		{query: "SELECT twice(now()) FROM t1"},
+		{query: "SELECT twice(CAST(name AS DATETIME)) FROM t1", err: ErrArgumentType},
+		{query: "SELECT twice(created) FROM t1", err: ErrArgumentType},
+		{query: "SELECT id FROM t1 WHERE twice(created) > 1", err: ErrArgumentType},
+		{query: "SELECT nvl(id) FROM t1", err: ErrArgumentCount},
+		{query: "SELECT concat_ws(name) FROM t1", err: ErrArgumentCount},
+		{query: "SELECT week(created, 1) FROM t1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			_, err = planner.Plan(node)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("schema", func(t *testing.T) {
+		node, err := Parse("SELECT twice(id), pick(id, name) FROM t1")
+		require.NoError(t, err)
+
+		plan, err := planner.Plan(node)
+		require.NoError(t, err)
+
+		cols, err := plan.Schema(context.TODO())
+		require.NoError(t, err)
+		require.Len(t, cols, 2)
+		require.Equal(t, querypb.Type_INT64, cols[0].Type)
+		require.Equal(t, querypb.Type_VARCHAR, cols[1].Type)
+	})
+
+	t.Run("fold", func(t *testing.T) {
+		node, err := Parse("SELECT id FROM t1 WHERE id = twice(twice(1))")
+		require.NoError(t, err)
+
+		calls = 0
+		plan, err := planner.Plan(node)
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+		require.Contains(t, plan.String(), "4")
+		require.NotContains(t, plan.String(), "twice")
+	})
+
+	t.Run("location", func(t *testing.T) {
+		planner := NewPlanner(catalog, dispatcher, WithLocation(time.FixedZone("+09:00", 9*60*60)))
+
+		node, err := Parse("SELECT from_unixtime(0, '%H') FROM t1")
+		require.NoError(t, err)
+
+		plan, err := planner.Plan(node)
+		require.NoError(t, err)
+		require.Contains(t, plan.String(), "Alias(VARCHAR(\"09\")")
+	})
+}
+
+func TestPlanner_PlanDivisionByZero(t *testing.T) {
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}
+	t1 := schema.NewInMemoryTable([]schema.Row{{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(0)}}})
+
+	planner := NewPlanner(schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1}), NewDispatcher())
+
+	for _, query := range []string{"SELECT 10 / 0 FROM t1", "SELECT 10 DIV 0 FROM t1", "SELECT 10 / id FROM t1", "SELECT 10 DIV id FROM t1"} {
+		t.Run(query, func(t *testing.T) {
+			node, err := Parse(query)
+			require.NoError(t, err)
+
+			plan, err := planner.Plan(node)
+			require.NoError(t, err)
+
+			cursor, err := plan.Run(context.TODO(), nil)
+			require.NoError(t, err)
+
+			rows, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+			require.Len(t, rows, 1)
+			require.Equal(t, []sqltypes.Value{sqltypes.NULL}, rows[0].Values)
+		})
+	}
+}
+
+func TestPlanner_PlanWith(t *testing.T) {
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(context.TODO(), []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("parent_id")}, Type: querypb.Type_INT64},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+	planner := NewPlanner(catalog, NewDispatcher(WithBuiltIn()))
+
+	tests := []struct {
+		query       string
+		materialize int
+		err         error
+		msg         string
+	}{
+		{query: "WITH a AS (SELECT id FROM t1) SELECT id FROM a"},
+		{query: "WITH a AS (SELECT id FROM t1) SELECT x.id FROM a AS x JOIN a AS y ON x.id = y.id", materialize: 1},
+		{query: "WITH a AS (SELECT id FROM t1), b AS (SELECT id FROM a) SELECT id FROM a UNION SELECT id FROM b", materialize: 1},
+		{query: "WITH t1 AS (SELECT id AS n FROM t1) SELECT n FROM t1"},
+		{query: "WITH RECURSIVE r AS (SELECT id FROM t1 WHERE parent_id IS NULL UNION ALL SELECT t1.id FROM t1 JOIN r ON t1.parent_id = r.id) SELECT id FROM r"},
+		{query: "WITH a AS (SELECT id FROM t1) SELECT age FROM a", err: ErrUnknownColumn},
+		{query: "WITH a AS (SELECT id FROM t1), a AS (SELECT id FROM t1) SELECT id FROM a", msg: "duplicate common table expression name: a"},
+		{query: "WITH RECURSIVE r AS (SELECT id FROM r UNION ALL SELECT id FROM t1) SELECT id FROM r", msg: "recursive common table expression r must be a union of a non-recursive and a recursive select"},
+		{query: "WITH RECURSIVE r AS (SELECT id FROM t1 UNION ALL SELECT x.id FROM r AS x JOIN r AS y ON x.id = y.id) SELECT id FROM r", msg: "recursive common table expression r can be referred to only once in its recursive select"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			plan, err := planner.Plan(node)
+			switch {
+			case tt.err != nil:
+				require.ErrorIs(t, err, tt.err)
+			case tt.msg != "":
+				require.EqualError(t, err, tt.msg)
+			default:
+				require.NoError(t, err)
+
+				materialized := map[*MaterializePlan]struct{}{}
+				_, _ = plan.Walk(func(plan Plan) (bool, error) {
+					if p, ok := plan.(*MaterializePlan); ok {
+						materialized[p] = struct{}{}
+					}
+					return true, nil
+				})
+				require.Len(t, materialized, tt.materialize)
+			}
+		})
+	}
+
+	t.Run("pushdown", func(t *testing.T) {
+		node, err := Parse("WITH a AS (SELECT id FROM t1) SELECT id FROM a WHERE id = 1")
+		require.NoError(t, err)
+
+		plan, err := planner.Plan(node)
+		require.NoError(t, err)
+
+		var scan *ScanPlan
+		_, _ = plan.Walk(func(plan Plan) (bool, error) {
+			if p, ok := plan.(*ScanPlan); ok {
+				scan = p
+			}
+			return true, nil
+		})
+		require.NotNil(t, scan)
+		require.NotNil(t, scan.Expr)
+	})
+}
@@ -48,7 +48,7 @@ func (p *ProjectionPlan) Run(ctx context.Context, binds map[string]*querypb.Bind
 				for i := 0; i < len(row.Columns); i++ {
 					col := &sqlparser.ColName{Name: row.Columns[i].Name}
 					val := row.Values[i]
-					if !term.Table.IsEmpty() && row.Columns[i].Qualifier != term.Table {
+					if !schema.MatchTable(row.Columns[i].Qualifier, term.Table) {
 						continue
 					}
 					columns = append(columns, col)
@@ -59,9 +59,11 @@ func (p *ProjectionPlan) Run(ctx context.Context, binds map[string]*querypb.Bind
 				if err != nil {
 					return schema.Row{}, err
 				}
-				v, err := ToSQL(val, val.Type())
-				if err != nil {
-					return schema.Row{}, err
+				v := sqltypes.NULL
+				if val != nil {
+					if v, err = ToSQL(val, val.Type()); err != nil {
+						return schema.Row{}, err
+					}
 				}
 				columns = append(columns, &sqlparser.ColName{Name: term.As})
 				values = append(values, v)
@@ -73,6 +75,35 @@ func (p *ProjectionPlan) Run(ctx context.Context, binds map[string]*querypb.Bind
 	}), nil
 }
 
+func (p *ProjectionPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	input, err := p.Input.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []schema.Column{}
+	for _, term := range p.Items {
+		switch term := term.(type) {
+		case *StartItem:
+			if input == nil {
+				return nil, nil
+			}
+			for _, col := range input {
+				if !schema.MatchTable(col.Name.Qualifier, term.Table) {
+					continue
+				}
+				col.Name = &sqlparser.ColName{Name: col.Name.Name}
+				columns = append(columns, col)
+			}
+		case *AliasItem:
+			col := TypeOf(term.Expr, input)
+			col.Name = &sqlparser.ColName{Name: term.As}
+			columns = append(columns, col)
+		}
+	}
+	return columns, nil
+}
+
 func (p *ProjectionPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	if cont, err := f(p); !cont || err != nil {
 		return cont, err
@@ -101,3 +101,60 @@ func TestProjectionPlan_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectionPlan_Schema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(ctx, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true, Length: 255},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		"t1": t1,
+	})
+
+	input := &AliasPlan{
+		Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+		As:    sqlparser.NewTableIdent("t1"),
+	}
+
+	tests := []struct {
+		plan    Plan
+		columns []schema.Column
+	}{
+		{
+			plan: &ProjectionPlan{
+				Input: input,
+				Items: []ProjectionItem{&StartItem{}},
+			},
+			columns: []schema.Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true, Length: 255},
+			},
+		},
+		{
+			plan: &ProjectionPlan{
+				Input: input,
+				Items: []ProjectionItem{
+					&AliasItem{Expr: &IndexExpr{Left: &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}, As: sqlparser.NewColIdent("n")},
+					&AliasItem{Expr: &AddExpr{Left: &IndexExpr{Left: &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}, Right: &LiteralExpr{Value: sqltypes.NewFloat64(1)}}, As: sqlparser.NewColIdent("x")},
+				},
+			},
+			columns: []schema.Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("n")}, Type: sqltypes.VarChar, Nullable: true, Length: 255},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("x")}, Type: sqltypes.Float64},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plan.String(), func(t *testing.T) {
+			cols, err := tt.plan.Schema(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.columns, cols)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+// RecursivePlan evaluates a recursive common table expression to a fixpoint. It starts from the rows of Anchor
+// and runs Recursive over the rows of the previous iteration, read through a WorkTablePlan, until an iteration
+// adds no rows. Unless Distinct is false, rows seen before are dropped.
+type RecursivePlan struct {
+	Name      sqlparser.TableIdent
+	Anchor    Plan
+	Recursive Plan
+	Distinct  bool
+	MaxDepth  int
+}
+
+// WorkTablePlan returns the rows of the previous iteration of Table.
+type WorkTablePlan struct {
+	Table *RecursivePlan
+}
+
+type workTableKey struct {
+	table *RecursivePlan
+}
+
+const DefaultMaxRecursionDepth = 1000
+
+var ErrRecursionDepth = errors.New("recursive query aborted after exceeding the maximum recursion depth")
+
+var (
+	_ Plan = (*RecursivePlan)(nil)
+	_ Plan = (*WorkTablePlan)(nil)
+)
+
+func (p *RecursivePlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	anchor, err := p.Anchor.Run(ctx, binds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := schema.ReadAll(anchor)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err = p.distinct(nil, rows); err != nil {
+		return nil, err
+	}
+	columns := columnNames(ctx, p.Anchor, rows)
+
+	for depth, work := 1, rows; len(work) > 0; depth++ {
+		cursor, err := p.Recursive.Run(context.WithValue(ctx, workTableKey{table: p}, work), binds)
+		if err != nil {
+			return nil, err
+		}
+		next, err := schema.ReadAll(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if err := rename(next, columns); err != nil {
+			return nil, err
+		}
+		if next, err = p.distinct(rows, next); err != nil {
+			return nil, err
+		}
+		if len(next) > 0 && depth > p.MaxDepth {
+			return nil, fmt.Errorf("%w: %d", ErrRecursionDepth, p.MaxDepth)
+		}
+		rows = append(rows, next...)
+		work = next
+	}
+	return schema.NewInMemoryCursor(rows), nil
+}
+
+func (p *RecursivePlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Anchor.Schema(ctx)
+}
+
+func (p *RecursivePlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	if cont, err := f(p); !cont || err != nil {
+		return cont, err
+	}
+	if cont, err := p.Anchor.Walk(f); !cont || err != nil {
+		return cont, err
+	}
+	return p.Recursive.Walk(f)
+}
+
+func (p *RecursivePlan) String() string {
+	return fmt.Sprintf("RecursivePlan(%s, %s, %s)", sqlparser.String(p.Name), p.Anchor.String(), p.Recursive.String())
+}
+
+// distinct returns the rows of next that are neither in seen nor earlier in next, or next itself unless Distinct.
+func (p *RecursivePlan) distinct(seen, next []schema.Row) ([]schema.Row, error) {
+	if !p.Distinct {
+		return next, nil
+	}
+	contains := func(rows []schema.Row, row schema.Row) (bool, error) {
+		for _, r := range rows {
+			if ok, err := equalRows(r, row); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+
+	rows := make([]schema.Row, 0, len(next))
+	for _, row := range next {
+		if ok, err := contains(seen, row); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+		if ok, err := contains(rows, row); err != nil {
+			return nil, err
+		} else if !ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (p *WorkTablePlan) Run(ctx context.Context, _ map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	rows, _ := ctx.Value(workTableKey{table: p.Table}).([]schema.Row)
+	return schema.NewInMemoryCursor(rows), nil
+}
+
+func (p *WorkTablePlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Table.Anchor.Schema(ctx)
+}
+
+func (p *WorkTablePlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	return f(p)
+}
+
+func (p *WorkTablePlan) String() string {
+	return fmt.Sprintf("WorkTablePlan(%s)", sqlparser.String(p.Table.Name))
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestRecursivePlan_Run(t *testing.T) {
+	n := &sqlparser.ColName{Name: sqlparser.NewColIdent("n")}
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		"t1": schema.NewInMemoryTable([]schema.Row{
+			{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}, Values: []sqltypes.Value{sqltypes.NewInt64(1)}},
+		}),
+	})
+
+	// SELECT id AS n FROM t1 UNION [ALL] SELECT <next> AS n FROM r WHERE n < 3
+	recursive := func(distinct bool, next func(Expr) Expr) *RecursivePlan {
+		plan := &RecursivePlan{
+			Name: sqlparser.NewTableIdent("r"),
+			Anchor: &ProjectionPlan{
+				Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+				Items: []ProjectionItem{&AliasItem{Expr: &IndexExpr{Left: &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}, As: n.Name}},
+			},
+			Distinct: distinct,
+			MaxDepth: 10,
+		}
+		col := &IndexExpr{Left: &ColumnExpr{Value: n}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}
+		plan.Recursive = &ProjectionPlan{
+			Input: &FilterPlan{
+				Input: &WorkTablePlan{Table: plan},
+				Expr:  &LessThanExpr{Left: col, Right: &LiteralExpr{Value: sqltypes.NewInt64(3)}},
+			},
+			Items: []ProjectionItem{&AliasItem{Expr: next(col), As: n.Name}},
+		}
+		return plan
+	}
+	row := func(v int64) schema.Row {
+		return schema.Row{Columns: []*sqlparser.ColName{n}, Values: []sqltypes.Value{sqltypes.NewInt64(v)}}
+	}
+
+	tests := []struct {
+		plan Plan
+		rows []schema.Row
+		err  error
+	}{
+		{
+			plan: recursive(false, func(col Expr) Expr { return &AddExpr{Left: col, Right: &LiteralExpr{Value: sqltypes.NewInt64(1)}} }),
+			rows: []schema.Row{row(1), row(2), row(3)},
+		},
+		{
+			plan: recursive(true, func(col Expr) Expr { return col }),
+			rows: []schema.Row{row(1)},
+		},
+		{
+			plan: recursive(false, func(col Expr) Expr { return col }),
+			err:  ErrRecursionDepth,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plan.String(), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+			defer cancel()
+
+			cursor, err := tt.plan.Run(ctx, nil)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			rows, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+			require.Equal(t, tt.rows, rows)
+		})
+	}
+}
@@ -61,7 +61,7 @@ var _ Expr = (*ColumnExpr)(nil)
 func (e *ColumnExpr) Eval(_ context.Context, row schema.Row, _ map[string]*querypb.BindVariable) (Value, error) {
 	var vals []Value
 	for i, col := range row.Columns {
-		if (e.Value.Qualifier.IsEmpty() && col.Name.Equal(e.Value.Name)) || (!e.Value.Qualifier.IsEmpty() && col.Equal(e.Value)) {
+		if schema.Match(col, e.Value) {
 			val, err := FromSQL(row.Values[i])
 			if err != nil {
 				return nil, err
@@ -99,7 +99,7 @@ var _ Expr = (*TableExpr)(nil)
 func (e *TableExpr) Eval(_ context.Context, row schema.Row, _ map[string]*querypb.BindVariable) (Value, error) {
 	var vals []Value
 	for i, col := range row.Columns {
-		if !e.Value.IsEmpty() && col.Qualifier != e.Value {
+		if !schema.MatchTable(col.Qualifier, e.Value) {
 			continue
 		}
 		val, err := FromSQL(row.Values[i])
@@ -15,6 +15,8 @@ type ScanPlan struct {
 	Catalog schema.Catalog
 	Table   sqlparser.TableName
 	Expr    Expr
+	Columns []*sqlparser.ColName
+	Limit   Expr
 }
 
 var _ Plan = (*ScanPlan)(nil)
@@ -57,9 +59,41 @@ func (p *ScanPlan) Run(ctx context.Context, bindVars map[string]*querypb.BindVar
 		hints = append(hints, hint)
 	}
 
+	filters, exact, err := p.buildFilters(ctx, p.Expr, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	pushdown := schema.ScanHint{Columns: p.Columns, Filters: filters}
+	if p.Limit != nil && exact {
+		val, err := p.Limit.Eval(ctx, schema.Row{}, bindVars)
+		if err != nil {
+			return nil, err
+		}
+		limit, err := ToInt(val)
+		if err != nil {
+			return nil, err
+		}
+		pushdown.Limit = int(limit)
+	}
+	if pushdown.Columns != nil || pushdown.Filters != nil || pushdown.Limit > 0 {
+		hints = append(hints, pushdown)
+	}
+
 	return table.Scan(ctx, hints...)
 }
 
+func (p *ScanPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	table, err := p.Catalog.Table(p.Table.Name.CompliantName())
+	if err != nil {
+		return nil, err
+	}
+	describer, ok := table.(schema.Describer)
+	if !ok {
+		return nil, nil
+	}
+	return describer.Columns(ctx)
+}
+
 func (p *ScanPlan) String() string {
 	var b strings.Builder
 	b.WriteString("ScanPlan(")
@@ -149,24 +183,26 @@ func (p *ScanPlan) buildScanHint(ctx context.Context, index schema.Index, expr E
 	case *EqualExpr, *GreaterThanExpr, *GreaterThanOrEqualExpr, *LessThanExpr, *LessThanOrEqualExpr:
 		var colExpr *ColumnExpr
 		var valExpr Expr
+		var flipped bool
 		switch e := e.(type) {
 		case *EqualExpr:
-			colExpr, valExpr = p.operands(e.Left, e.Right, index)
+			colExpr, valExpr, flipped = p.operands(e.Left, e.Right, index)
 		case *GreaterThanExpr:
-			colExpr, valExpr = p.operands(e.Left, e.Right, index)
+			colExpr, valExpr, flipped = p.operands(e.Left, e.Right, index)
 		case *GreaterThanOrEqualExpr:
-			colExpr, valExpr = p.operands(e.Left, e.Right, index)
+			colExpr, valExpr, flipped = p.operands(e.Left, e.Right, index)
 		case *LessThanExpr:
-			colExpr, valExpr = p.operands(e.Left, e.Right, index)
+			colExpr, valExpr, flipped = p.operands(e.Left, e.Right, index)
 		case *LessThanOrEqualExpr:
-			colExpr, valExpr = p.operands(e.Left, e.Right, index)
+			colExpr, valExpr, flipped = p.operands(e.Left, e.Right, index)
 		}
 		if colExpr == nil || valExpr == nil {
 			return schema.ScanHint{}, nil
 		}
 
 		val, err := valExpr.Eval(ctx, schema.Row{}, bindVars)
-		if err != nil {
+		if err != nil || val == nil {
+			// A comparison with NULL holds for no row, which the filter finds out on its own.
 			return schema.ScanHint{}, err
 		}
 		sqlVal, err := ToSQL(val, val.Type())
@@ -191,14 +227,18 @@ func (p *ScanPlan) buildScanHint(ctx context.Context, index schema.Index, expr E
 		case *EqualExpr:
 			rng.Min = &sqlVal
 			rng.Max = &sqlVal
-		case *GreaterThanExpr:
-			rng.Min = &sqlVal
-		case *GreaterThanOrEqualExpr:
-			rng.Min = &sqlVal
-		case *LessThanExpr:
-			rng.Max = &sqlVal
-		case *LessThanOrEqualExpr:
-			rng.Max = &sqlVal
+		case *GreaterThanExpr, *GreaterThanOrEqualExpr:
+			if flipped {
+				rng.Max = &sqlVal
+			} else {
+				rng.Min = &sqlVal
+			}
+		case *LessThanExpr, *LessThanOrEqualExpr:
+			if flipped {
+				rng.Min = &sqlVal
+			} else {
+				rng.Max = &sqlVal
+			}
 		}
 
 		hint.Ranges[offset] = rng
@@ -234,6 +274,9 @@ func (p *ScanPlan) buildScanHint(ctx context.Context, index schema.Index, expr E
 		var minVal, maxVal *sqltypes.Value
 
 		for _, val := range tuple.Values() {
+			if val == nil {
+				continue
+			}
 			sqlVal, err := ToSQL(val, val.Type())
 			if err != nil {
 				return schema.ScanHint{}, err
@@ -269,6 +312,9 @@ func (p *ScanPlan) buildScanHint(ctx context.Context, index schema.Index, expr E
 				maxVal = &sqlVal
 			}
 		}
+		if minVal == nil {
+			return schema.ScanHint{}, nil
+		}
 
 		rng := schema.Range{
 			Min: minVal,
@@ -287,14 +333,16 @@ func (p *ScanPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
 	return f(p)
 }
 
-func (p *ScanPlan) operands(left, right Expr, index schema.Index) (*ColumnExpr, Expr) {
+// operands splits a comparison into the column of index it compares and the value it compares it to, reporting
+// whether the column is on the right.
+func (p *ScanPlan) operands(left, right Expr, index schema.Index) (*ColumnExpr, Expr, bool) {
 	if l, ok := p.colName(left); ok && p.isIndexable(index, l) && p.isFoldable(right) {
-		return l, right
+		return l, right, false
 	}
 	if r, ok := p.colName(right); ok && p.isIndexable(index, r) && p.isFoldable(left) {
-		return r, left
+		return r, left, true
 	}
-	return nil, nil
+	return nil, nil, false
 }
 
 func (p *ScanPlan) isIndexable(index schema.Index, expr *ColumnExpr) bool {
@@ -329,3 +377,134 @@ func (p *ScanPlan) colName(expr Expr) (*ColumnExpr, bool) {
 	}
 	return nil, false
 }
+
+// buildFilters translates the conjuncts of expr comparing a column with a value into filters, reporting whether all
+// of them were.
+func (p *ScanPlan) buildFilters(ctx context.Context, expr Expr, bindVars map[string]*querypb.BindVariable) ([]schema.Filter, bool, error) {
+	if expr == nil {
+		return nil, true, nil
+	}
+	if e, ok := expr.(*AndExpr); ok {
+		left, lexact, err := p.buildFilters(ctx, e.Left, bindVars)
+		if err != nil {
+			return nil, false, err
+		}
+		right, rexact, err := p.buildFilters(ctx, e.Right, bindVars)
+		if err != nil {
+			return nil, false, err
+		}
+		return append(left, right...), lexact && rexact, nil
+	}
+
+	negate := false
+	if e, ok := expr.(*NotExpr); ok {
+		expr, negate = e.Input, true
+	}
+
+	var op string
+	var left, right Expr
+	switch e := expr.(type) {
+	case *EqualExpr:
+		op, left, right = sqlparser.EqualStr, e.Left, e.Right
+		if negate {
+			op = sqlparser.NotEqualStr
+		}
+	case *InExpr:
+		op, left, right = sqlparser.InStr, e.Left, e.Right
+		if negate {
+			op = sqlparser.NotInStr
+		}
+	case *LikeExpr:
+		op, left, right = sqlparser.LikeStr, e.Left, e.Right
+		if negate {
+			op = sqlparser.NotLikeStr
+		}
+	case *GreaterThanExpr:
+		op, left, right = sqlparser.GreaterThanStr, e.Left, e.Right
+	case *GreaterThanOrEqualExpr:
+		op, left, right = sqlparser.GreaterEqualStr, e.Left, e.Right
+	case *LessThanExpr:
+		op, left, right = sqlparser.LessThanStr, e.Left, e.Right
+	case *LessThanOrEqualExpr:
+		op, left, right = sqlparser.LessEqualStr, e.Left, e.Right
+	case *CallExpr:
+		if col, op, ok := p.nullTest(e); ok && !negate {
+			return []schema.Filter{{Column: col.Value, Operator: op}}, true, nil
+		}
+	}
+	if op == "" || (negate && op != sqlparser.NotEqualStr && op != sqlparser.NotInStr && op != sqlparser.NotLikeStr) {
+		return nil, false, nil
+	}
+
+	col, ok := p.colName(left)
+	if !ok || !p.isFoldable(right) {
+		if col, ok = p.colName(right); !ok || !p.isFoldable(left) || op == sqlparser.InStr || op == sqlparser.NotInStr || op == sqlparser.LikeStr || op == sqlparser.NotLikeStr {
+			return nil, false, nil
+		}
+		left, right = right, left
+		switch op {
+		case sqlparser.GreaterThanStr:
+			op = sqlparser.LessThanStr
+		case sqlparser.GreaterEqualStr:
+			op = sqlparser.LessEqualStr
+		case sqlparser.LessThanStr:
+			op = sqlparser.GreaterThanStr
+		case sqlparser.LessEqualStr:
+			op = sqlparser.GreaterEqualStr
+		}
+	}
+
+	val, err := right.Eval(ctx, schema.Row{}, bindVars)
+	if err != nil {
+		return nil, false, err
+	}
+	vals := []Value{val}
+	if tuple, ok := val.(*Tuple); ok {
+		vals = tuple.Values()
+	}
+	if len(vals) == 0 || ((op != sqlparser.InStr && op != sqlparser.NotInStr) && len(vals) != 1) {
+		return nil, false, nil
+	}
+
+	filter := schema.Filter{Column: col.Value, Operator: op}
+	for _, val := range vals {
+		if val == nil {
+			filter.Values = append(filter.Values, sqltypes.NULL)
+			continue
+		}
+		if _, ok := val.(*Tuple); ok {
+			return nil, false, nil
+		}
+		v, err := ToSQL(val, val.Type())
+		if err != nil {
+			return nil, false, nil
+		}
+		filter.Values = append(filter.Values, v)
+	}
+	return []schema.Filter{filter}, true, nil
+}
+
+// nullTest returns the column expr tests for IS NULL or IS NOT NULL, as planned into NVL2(column, 0, 1) and
+// NVL2(column, 1, 0).
+func (p *ScanPlan) nullTest(expr *CallExpr) (*ColumnExpr, string, bool) {
+	tuple, ok := expr.Input.(*TupleExpr)
+	if !expr.Name.Equal(NVL2) || !ok || len(tuple.Exprs) != 3 {
+		return nil, "", false
+	}
+	col, ok := p.colName(tuple.Exprs[0])
+	if !ok {
+		return nil, "", false
+	}
+	then, ok1 := tuple.Exprs[1].(*LiteralExpr)
+	otherwise, ok2 := tuple.Exprs[2].(*LiteralExpr)
+	if !ok1 || !ok2 {
+		return nil, "", false
+	}
+	switch then.Value.ToString() + otherwise.Value.ToString() {
+	case "01":
+		return col, sqlparser.IsNullStr, true
+	case "10":
+		return col, sqlparser.IsNotNullStr, true
+	}
+	return nil, "", false
+}
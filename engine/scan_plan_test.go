@@ -108,3 +108,155 @@ func TestScanPlan_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestScanPlan_RunNull(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}
+	t1 := schema.NewInMemoryTable([]schema.Row{
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(0)}},
+		{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1)}},
+	})
+	_ = t1.SetIndex(ctx, schema.Index{Name: "id", Columns: columns})
+
+	planner := NewPlanner(schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1}), NewDispatcher())
+
+	tests := []struct {
+		query  string
+		binds  map[string]*querypb.BindVariable
+		values []sqltypes.Value
+	}{
+		{query: "SELECT id FROM t1 WHERE id = NULL"},
+		{query: "SELECT id FROM t1 WHERE NULL < id"},
+		{query: "SELECT id FROM t1 WHERE id IN (NULL)"},
+		{query: "SELECT id FROM t1 WHERE id IN (1, NULL)", values: []sqltypes.Value{sqltypes.NewInt64(1)}},
+		{query: "SELECT id FROM t1 WHERE id = :v1", binds: map[string]*querypb.BindVariable{"v1": sqltypes.NullBindVariable}},
+		{query: "SELECT id FROM t1 WHERE id IN (:v1, :v2)", binds: map[string]*querypb.BindVariable{"v1": sqltypes.NullBindVariable, "v2": sqltypes.Int64BindVariable(0)}, values: []sqltypes.Value{sqltypes.NewInt64(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			plan, err := planner.Plan(node)
+			require.NoError(t, err)
+
+			cursor, err := plan.Run(ctx, tt.binds)
+			require.NoError(t, err)
+
+			rows, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+
+			var values []sqltypes.Value
+			for _, row := range rows {
+				values = append(values, row.Values...)
+			}
+			require.Equal(t, tt.values, values)
+		})
+	}
+}
+
+func TestScanPlan_Schema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true},
+	}
+
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(ctx, columns)
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{
+		"t1": t1,
+	})
+
+	plan := &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}}
+
+	cols, err := plan.Schema(ctx)
+	require.NoError(t, err)
+	require.Equal(t, columns, cols)
+}
+
+type hintTable struct {
+	schema.Table
+	hints []schema.ScanHint
+}
+
+func (t *hintTable) Scan(ctx context.Context, hints ...schema.ScanHint) (schema.Cursor, error) {
+	t.hints = hints
+	return t.Table.Scan(ctx, hints...)
+}
+
+func TestScanPlan_Pushdown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	column := func(name string) Expr {
+		return &IndexExpr{Left: &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent(name)}}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}
+	}
+
+	tests := []struct {
+		name  string
+		expr  Expr
+		limit Expr
+		hints []schema.ScanHint
+	}{
+		{
+			name: "exact",
+			expr: &AndExpr{
+				Left: &AndExpr{
+					Left:  &LessThanExpr{Left: &LiteralExpr{Value: sqltypes.NewInt64(1)}, Right: column("id")},
+					Right: &NotExpr{Input: &EqualExpr{Left: column("name"), Right: &LiteralExpr{Value: sqltypes.NewVarChar("foo")}}},
+				},
+				Right: &CallExpr{
+					Dispatcher: NewDispatcher(),
+					Name:       NVL2,
+					Input:      &TupleExpr{Exprs: []Expr{column("name"), &LiteralExpr{Value: sqltypes.NewInt64(1)}, &LiteralExpr{Value: sqltypes.NewInt64(0)}}},
+				},
+			},
+			limit: &LiteralExpr{Value: sqltypes.NewInt64(2)},
+			hints: []schema.ScanHint{{
+				Filters: []schema.Filter{
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Operator: sqlparser.GreaterThanStr, Values: []sqltypes.Value{sqltypes.NewInt64(1)}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Operator: sqlparser.NotEqualStr, Values: []sqltypes.Value{sqltypes.NewVarChar("foo")}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Operator: sqlparser.IsNotNullStr},
+				},
+				Limit: 2,
+			}},
+		},
+		{
+			name: "inexact",
+			expr: &AndExpr{
+				Left: &InExpr{Left: column("id"), Right: &TupleExpr{Exprs: []Expr{&LiteralExpr{Value: sqltypes.NewInt64(1)}, &LiteralExpr{Value: sqltypes.NewInt64(2)}}}},
+				Right: &OrExpr{
+					Left:  &EqualExpr{Left: column("id"), Right: &LiteralExpr{Value: sqltypes.NewInt64(1)}},
+					Right: &EqualExpr{Left: column("name"), Right: &LiteralExpr{Value: sqltypes.NewVarChar("foo")}},
+				},
+			},
+			limit: &LiteralExpr{Value: sqltypes.NewInt64(2)},
+			hints: []schema.ScanHint{{
+				Filters: []schema.Filter{
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Operator: sqlparser.InStr, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(2)}},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &hintTable{Table: schema.NewInMemoryTable(nil)}
+			catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": table})
+
+			plan := &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}, Expr: tt.expr, Limit: tt.limit}
+
+			cursor, err := plan.Run(ctx, nil)
+			require.NoError(t, err)
+			require.NoError(t, cursor.Close())
+			require.Equal(t, tt.hints, table.hints)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+type SetPlan struct {
+	Planner *Planner
+	Name    string
+	Value   Expr
+}
+
+var _ Plan = (*SetPlan)(nil)
+
+func (p *SetPlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	val, err := p.Value.Eval(ctx, schema.Row{}, binds)
+	if err != nil {
+		return nil, err
+	}
+	name, err := ToString(val)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := ParseLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	p.Planner.SetLocation(loc)
+	return schema.NewInMemoryCursor(nil), nil
+}
+
+func (p *SetPlan) Schema(_ context.Context) ([]schema.Column, error) {
+	return []schema.Column{}, nil
+}
+
+func (p *SetPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	return f(p)
+}
+
+func (p *SetPlan) String() string {
+	return fmt.Sprintf("SetPlan(%s, %s)", p.Name, p.Value.String())
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestSetPlan_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	planner := NewPlanner(schema.NewInMemoryCatalog(nil), NewDispatcher())
+	require.Equal(t, time.UTC, planner.Location())
+
+	plan := &SetPlan{Planner: planner, Name: "time_zone", Value: &LiteralExpr{Value: sqltypes.NewVarChar("+09:00")}}
+
+	cursor, err := plan.Run(ctx, nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, planner.Location()).Zone()
+	require.Equal(t, 9*60*60, offset)
+
+	plan = &SetPlan{Planner: planner, Name: "time_zone", Value: &LiteralExpr{Value: sqltypes.NewVarChar("Nowhere/Invalid")}}
+
+	_, err = plan.Run(ctx, nil)
+	require.Error(t, err)
+
+	_, offset = time.Date(2024, 1, 1, 0, 0, 0, 0, planner.Location()).Zone()
+	require.Equal(t, 9*60*60, offset)
+}
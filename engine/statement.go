@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+// CreateView is a CREATE VIEW statement with the query sqlparser discards.
+type CreateView struct {
+	*sqlparser.DDL
+	Select    sqlparser.SelectStatement
+	OrReplace bool
+}
+
+// DropView is a DROP VIEW statement, which sqlparser does not tell apart from DROP TABLE.
+type DropView struct {
+	*sqlparser.DDL
+}
+
+// With is a select statement with common table expressions, which sqlparser does not support.
+type With struct {
+	sqlparser.SelectStatement
+	Recursive bool
+	CTEs      []*CommonTableExpr
+}
+
+// CommonTableExpr is a named query of a WITH clause.
+type CommonTableExpr struct {
+	Name   sqlparser.TableIdent
+	Select sqlparser.SelectStatement
+}
+
+// JSONTable is a JSON_TABLE(expr, path COLUMNS (...)) table function, which sqlparser does not support.
+// The embedded table expression carries its alias.
+type JSONTable struct {
+	*sqlparser.AliasedTableExpr
+	Expr    sqlparser.Expr
+	Path    string
+	Columns []*JSONTableColumn
+}
+
+// JSONTableColumn is a column of JSON_TABLE, or a NESTED PATH of further columns when Nested is set.
+type JSONTableColumn struct {
+	Name       sqlparser.ColIdent
+	Type       querypb.Type
+	Path       string
+	Ordinality bool
+	Exists     bool
+	OnEmpty    JSONTableFallback
+	OnError    JSONTableFallback
+	Nested     []*JSONTableColumn
+}
+
+// JSONTableFallback is the ON EMPTY or ON ERROR clause of a JSON_TABLE column. The zero value is NULL.
+type JSONTableFallback struct {
+	Error   bool
+	Default *sqlparser.SQLVal
+}
+
+// WindowExpr is a function call with an OVER clause, which sqlparser does not support.
+type WindowExpr struct {
+	*sqlparser.FuncExpr
+	Over *WindowSpec
+}
+
+// FilterExpr is an aggregate call with a FILTER (WHERE cond) clause, which sqlparser does not support.
+type FilterExpr struct {
+	*sqlparser.FuncExpr
+	Where sqlparser.Expr
+}
+
+// WindowSpec is the PARTITION BY, ORDER BY and frame of an OVER clause.
+type WindowSpec struct {
+	PartitionBy sqlparser.Exprs
+	OrderBy     sqlparser.OrderBy
+	Frame       *WindowFrame
+}
+
+// WindowFrame is the ROWS or RANGE frame of a window. Start and End are CURRENT ROW when omitted.
+type WindowFrame struct {
+	Unit  string
+	Start WindowFrameBound
+	End   WindowFrameBound
+}
+
+// WindowFrameBound is a frame bound; Offset is set for PRECEDING and FOLLOWING.
+type WindowFrameBound struct {
+	Type   string
+	Offset sqlparser.Expr
+}
+
+const (
+	RowsStr               = "rows"
+	RangeStr              = "range"
+	UnboundedPrecedingStr = "unbounded preceding"
+	PrecedingStr          = "preceding"
+	CurrentRowStr         = "current row"
+	FollowingStr          = "following"
+	UnboundedFollowingStr = "unbounded following"
+)
+
+var (
+	_ sqlparser.Statement = (*CreateView)(nil)
+	_ sqlparser.Statement = (*DropView)(nil)
+	_ sqlparser.Statement = (*With)(nil)
+	_ sqlparser.TableExpr = (*JSONTable)(nil)
+	_ sqlparser.Expr      = (*WindowExpr)(nil)
+	_ sqlparser.Expr      = (*FilterExpr)(nil)
+)
+
+func (node *CreateView) Format(buf *sqlparser.TrackedBuffer) {
+	replace := ""
+	if node.OrReplace {
+		replace = " or replace"
+	}
+	buf.Myprintf("create%s view %v as %v", replace, node.NewName, node.Select)
+}
+
+func (node *DropView) Format(buf *sqlparser.TrackedBuffer) {
+	exists := ""
+	if node.IfExists {
+		exists = " if exists"
+	}
+	buf.Myprintf("drop view%s %v", exists, node.Table)
+}
+
+func (node *With) Format(buf *sqlparser.TrackedBuffer) {
+	buf.Myprintf("with ")
+	if node.Recursive {
+		buf.Myprintf("recursive ")
+	}
+	for i, cte := range node.CTEs {
+		if i > 0 {
+			buf.Myprintf(", ")
+		}
+		buf.Myprintf("%v as (%v)", cte.Name, cte.Select)
+	}
+	buf.Myprintf(" %v", node.SelectStatement)
+}
+
+func (node *JSONTable) Format(buf *sqlparser.TrackedBuffer) {
+	buf.Myprintf("json_table(%v, %v columns (", node.Expr, sqlparser.NewStrVal([]byte(node.Path)))
+	formatJSONTableColumns(buf, node.Columns)
+	buf.Myprintf(")) as %v", node.As)
+}
+
+func formatJSONTableColumns(buf *sqlparser.TrackedBuffer, columns []*JSONTableColumn) {
+	for i, col := range columns {
+		if i > 0 {
+			buf.Myprintf(", ")
+		}
+		switch {
+		case col.Nested != nil:
+			buf.Myprintf("nested path %v columns (", sqlparser.NewStrVal([]byte(col.Path)))
+			formatJSONTableColumns(buf, col.Nested)
+			buf.Myprintf(")")
+		case col.Ordinality:
+			buf.Myprintf("%v for ordinality", col.Name)
+		default:
+			exists := ""
+			if col.Exists {
+				exists = " exists"
+			}
+			buf.Myprintf("%v %s%s path %v", col.Name, strings.ToLower(schema.TypeName(col.Type)), exists, sqlparser.NewStrVal([]byte(col.Path)))
+			col.OnEmpty.format(buf, "empty")
+			col.OnError.format(buf, "error")
+		}
+	}
+}
+
+func (f JSONTableFallback) format(buf *sqlparser.TrackedBuffer, on string) {
+	switch {
+	case f.Error:
+		buf.Myprintf(" error on %s", on)
+	case f.Default != nil:
+		buf.Myprintf(" default %v on %s", f.Default, on)
+	}
+}
+
+func (node *WindowExpr) Format(buf *sqlparser.TrackedBuffer) {
+	buf.Myprintf("%v over (", node.FuncExpr)
+	node.Over.format(buf)
+	buf.Myprintf(")")
+}
+
+func (node *FilterExpr) Format(buf *sqlparser.TrackedBuffer) {
+	buf.Myprintf("%v filter (where %v)", node.FuncExpr, node.Where)
+}
+
+func (node *WindowSpec) format(buf *sqlparser.TrackedBuffer) {
+	sep := ""
+	if len(node.PartitionBy) > 0 {
+		buf.Myprintf("partition by %v", node.PartitionBy)
+		sep = " "
+	}
+	for i, order := range node.OrderBy {
+		if i == 0 {
+			buf.Myprintf("%sorder by %v", sep, order)
+		} else {
+			buf.Myprintf(", %v", order)
+		}
+		sep = " "
+	}
+	if node.Frame != nil {
+		buf.Myprintf("%s%s between ", sep, node.Frame.Unit)
+		node.Frame.Start.format(buf)
+		buf.Myprintf(" and ")
+		node.Frame.End.format(buf)
+	}
+}
+
+func (node WindowFrameBound) format(buf *sqlparser.TrackedBuffer) {
+	if node.Offset != nil {
+		buf.Myprintf("%v ", node.Offset)
+	}
+	buf.Myprintf("%s", node.Type)
+}
+
+// Bindvars returns the bind variables of stmt, including those of the common table expressions and FILTER clauses
+// sqlparser does not walk.
+func Bindvars(stmt sqlparser.Statement) map[string]struct{} {
+	binds := sqlparser.GetBindvars(stmt)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if n, ok := node.(*FilterExpr); ok {
+			maps.Copy(binds, Bindvars(&sqlparser.Select{Where: sqlparser.NewWhere(sqlparser.WhereStr, n.Where)}))
+		}
+		return true, nil
+	}, stmt)
+	if with, ok := stmt.(*With); ok {
+		for _, cte := range with.CTEs {
+			maps.Copy(binds, Bindvars(cte.Select))
+		}
+	}
+	return binds
+}
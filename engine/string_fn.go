@@ -0,0 +1,377 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+var (
+	Concat          = sqlparser.NewColIdent("concat")
+	Lower           = sqlparser.NewColIdent("lower")
+	Lcase           = sqlparser.NewColIdent("lcase")
+	Upper           = sqlparser.NewColIdent("upper")
+	Ucase           = sqlparser.NewColIdent("ucase")
+	Trim            = sqlparser.NewColIdent("trim")
+	Ltrim           = sqlparser.NewColIdent("ltrim")
+	Rtrim           = sqlparser.NewColIdent("rtrim")
+	Length          = sqlparser.NewColIdent("length")
+	OctetLength     = sqlparser.NewColIdent("octet_length")
+	CharLength      = sqlparser.NewColIdent("char_length")
+	CharacterLength = sqlparser.NewColIdent("character_length")
+	Replace         = sqlparser.NewColIdent("replace")
+	Locate          = sqlparser.NewColIdent("locate")
+	Instr           = sqlparser.NewColIdent("instr")
+	Lpad            = sqlparser.NewColIdent("lpad")
+	Rpad            = sqlparser.NewColIdent("rpad")
+	Reverse         = sqlparser.NewColIdent("reverse")
+	Left            = sqlparser.NewColIdent("left")
+	Right           = sqlparser.NewColIdent("right")
+	Repeat          = sqlparser.NewColIdent("repeat")
+	Space           = sqlparser.NewColIdent("space")
+	Ascii           = sqlparser.NewColIdent("ascii")
+	Strcmp          = sqlparser.NewColIdent("strcmp")
+	Mid             = sqlparser.NewColIdent("mid")
+	SubstringIndex  = sqlparser.NewColIdent("substring_index")
+	SplitPart       = sqlparser.NewColIdent("split_part")
+)
+
+// maxStringLength is the length in bytes above which functions building strings return NULL instead, as MySQL does
+// above max_allowed_packet.
+const maxStringLength = 64 << 20
+
+// WithString registers the MySQL string functions. Positions and lengths count characters, not bytes.
+func WithString() DispatchOption {
+	return func(d *Dispatcher) {
+		d.specs[Concat.String()] = builtin(1, -1, withoutContext(NewConcat()))
+		d.specs[Lower.String()] = builtin(1, 1, withoutContext(NewLower()))
+		d.specs[Lcase.String()] = builtin(1, 1, withoutContext(NewLower()))
+		d.specs[Upper.String()] = builtin(1, 1, withoutContext(NewUpper()))
+		d.specs[Ucase.String()] = builtin(1, 1, withoutContext(NewUpper()))
+		d.specs[Trim.String()] = builtin(1, 2, withoutContext(NewTrim()))
+		d.specs[Ltrim.String()] = builtin(1, 1, withoutContext(NewLtrim()))
+		d.specs[Rtrim.String()] = builtin(1, 1, withoutContext(NewRtrim()))
+		d.specs[Length.String()] = builtin(1, 1, withoutContext(NewLength()))
+		d.specs[OctetLength.String()] = builtin(1, 1, withoutContext(NewLength()))
+		d.specs[CharLength.String()] = builtin(1, 1, withoutContext(NewCharLength()))
+		d.specs[CharacterLength.String()] = builtin(1, 1, withoutContext(NewCharLength()))
+		d.specs[Replace.String()] = builtin(3, 3, withoutContext(NewReplace()))
+		d.specs[Locate.String()] = builtin(2, 3, withoutContext(NewLocate()))
+		d.specs[Instr.String()] = builtin(2, 2, withoutContext(NewInstr()))
+		d.specs[Lpad.String()] = builtin(3, 3, withoutContext(NewLpad()))
+		d.specs[Rpad.String()] = builtin(3, 3, withoutContext(NewRpad()))
+		d.specs[Reverse.String()] = builtin(1, 1, withoutContext(NewReverse()))
+		d.specs[Left.String()] = builtin(2, 2, withoutContext(NewLeft()))
+		d.specs[Right.String()] = builtin(2, 2, withoutContext(NewRight()))
+		d.specs[Repeat.String()] = builtin(2, 2, withoutContext(NewRepeat()))
+		d.specs[Space.String()] = builtin(1, 1, withoutContext(NewSpace()))
+		d.specs[Ascii.String()] = builtin(1, 1, withoutContext(NewAscii()))
+		d.specs[Strcmp.String()] = builtin(2, 2, withoutContext(NewStrcmp()))
+		d.specs[Substr.String()] = builtin(2, 3, withoutContext(NewSubstr()))
+		d.specs[Mid.String()] = builtin(2, 3, withoutContext(NewSubstr()))
+		d.specs[SubstringIndex.String()] = builtin(3, 3, withoutContext(NewSubstringIndex()))
+		d.specs[SplitPart.String()] = builtin(3, 3, withoutContext(NewSplitPart()))
+	}
+}
+
+func NewConcat() Function {
+	return NewStringFunction(1, -1, func(args []string) (Value, error) {
+		return NewVarChar(strings.Join(args, "")), nil
+	})
+}
+
+func NewLower() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		return NewVarChar(strings.ToLower(args[0])), nil
+	})
+}
+
+func NewUpper() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		return NewVarChar(strings.ToUpper(args[0])), nil
+	})
+}
+
+func NewTrim() Function {
+	return NewStringFunction(1, 2, func(args []string) (Value, error) {
+		if len(args) == 1 {
+			return NewVarChar(strings.Trim(args[0], " ")), nil
+		}
+		str, cut := args[0], args[1]
+		if cut == "" {
+			return NewVarChar(str), nil
+		}
+		for strings.HasPrefix(str, cut) {
+			str = str[len(cut):]
+		}
+		for strings.HasSuffix(str, cut) {
+			str = str[:len(str)-len(cut)]
+		}
+		return NewVarChar(str), nil
+	})
+}
+
+func NewLtrim() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		return NewVarChar(strings.TrimLeft(args[0], " ")), nil
+	})
+}
+
+func NewRtrim() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		return NewVarChar(strings.TrimRight(args[0], " ")), nil
+	})
+}
+
+func NewLength() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		return NewInt64(int64(len(args[0]))), nil
+	})
+}
+
+func NewCharLength() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		return NewInt64(int64(utf8.RuneCountInString(args[0]))), nil
+	})
+}
+
+func NewReplace() Function {
+	return NewStringFunction(3, 3, func(args []string) (Value, error) {
+		if args[1] == "" {
+			return NewVarChar(args[0]), nil
+		}
+		return NewVarChar(strings.ReplaceAll(args[0], args[1], args[2])), nil
+	})
+}
+
+func NewLocate() Function {
+	return NewStringFunction(2, 3, func(args []string) (Value, error) {
+		pos := int64(1)
+		if len(args) > 2 {
+			var err error
+			if pos, err = ToInt(NewVarChar(args[2])); err != nil {
+				return nil, err
+			}
+		}
+		return NewInt64(locate(args[0], args[1], pos)), nil
+	})
+}
+
+func NewInstr() Function {
+	return NewStringFunction(2, 2, func(args []string) (Value, error) {
+		return NewInt64(locate(args[1], args[0], 1)), nil
+	})
+}
+
+func NewLpad() Function {
+	return NewStringFunction(3, 3, func(args []string) (Value, error) {
+		return pad(args[0], args[1], args[2], true)
+	})
+}
+
+func NewRpad() Function {
+	return NewStringFunction(3, 3, func(args []string) (Value, error) {
+		return pad(args[0], args[1], args[2], false)
+	})
+}
+
+func NewReverse() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		runes := []rune(args[0])
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return NewVarChar(string(runes)), nil
+	})
+}
+
+func NewLeft() Function {
+	return NewStringFunction(2, 2, func(args []string) (Value, error) {
+		n, err := ToInt(NewVarChar(args[1]))
+		if err != nil {
+			return nil, err
+		}
+		runes := []rune(args[0])
+		n = min(max(n, 0), int64(len(runes)))
+		return NewVarChar(string(runes[:n])), nil
+	})
+}
+
+func NewRight() Function {
+	return NewStringFunction(2, 2, func(args []string) (Value, error) {
+		n, err := ToInt(NewVarChar(args[1]))
+		if err != nil {
+			return nil, err
+		}
+		runes := []rune(args[0])
+		n = min(max(n, 0), int64(len(runes)))
+		return NewVarChar(string(runes[int64(len(runes))-n:])), nil
+	})
+}
+
+func NewRepeat() Function {
+	return NewStringFunction(2, 2, func(args []string) (Value, error) {
+		n, err := ToInt(NewVarChar(args[1]))
+		if err != nil {
+			return nil, err
+		}
+		if n <= 0 || args[0] == "" {
+			return NewVarChar(""), nil
+		}
+		if n > maxStringLength/int64(len(args[0])) {
+			return nil, nil
+		}
+		return NewVarChar(strings.Repeat(args[0], int(n))), nil
+	})
+}
+
+func NewSpace() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		n, err := ToInt(NewVarChar(args[0]))
+		if err != nil {
+			return nil, err
+		}
+		if n > maxStringLength {
+			return nil, nil
+		}
+		return NewVarChar(strings.Repeat(" ", int(max(n, 0)))), nil
+	})
+}
+
+func NewAscii() Function {
+	return NewStringFunction(1, 1, func(args []string) (Value, error) {
+		if args[0] == "" {
+			return NewInt64(0), nil
+		}
+		return NewInt64(int64(args[0][0])), nil
+	})
+}
+
+func NewStrcmp() Function {
+	return NewStringFunction(2, 2, func(args []string) (Value, error) {
+		return NewInt64(int64(strings.Compare(args[0], args[1]))), nil
+	})
+}
+
+func NewSubstringIndex() Function {
+	return NewStringFunction(3, 3, func(args []string) (Value, error) {
+		str, delim := args[0], args[1]
+		count, err := ToInt(NewVarChar(args[2]))
+		if err != nil {
+			return nil, err
+		}
+		if delim == "" || count == 0 {
+			return NewVarChar(""), nil
+		}
+
+		parts := strings.Split(str, delim)
+		if count > 0 {
+			if count >= int64(len(parts)) {
+				return NewVarChar(str), nil
+			}
+			return NewVarChar(strings.Join(parts[:count], delim)), nil
+		}
+		if -count >= int64(len(parts)) {
+			return NewVarChar(str), nil
+		}
+		return NewVarChar(strings.Join(parts[int64(len(parts))+count:], delim)), nil
+	})
+}
+
+func NewSplitPart() Function {
+	return NewStringFunction(3, 3, func(args []string) (Value, error) {
+		str, delim := args[0], args[1]
+		n, err := ToInt(NewVarChar(args[2]))
+		if err != nil {
+			return nil, err
+		}
+
+		parts := []string{str}
+		if delim != "" {
+			parts = strings.Split(str, delim)
+		}
+		if n < 0 {
+			n += int64(len(parts)) + 1
+		}
+		if n < 1 || n > int64(len(parts)) {
+			return NewVarChar(""), nil
+		}
+		return NewVarChar(parts[n-1]), nil
+	})
+}
+
+// NewStringFunction returns a function taking between minArgs and maxArgs string arguments, or any number
+// above minArgs when maxArgs is negative. A NULL argument yields NULL.
+func NewStringFunction(minArgs, maxArgs int, fn func(args []string) (Value, error)) Function {
+	return func(args []Value) (Value, error) {
+		if len(args) < minArgs || (maxArgs >= 0 && len(args) > maxArgs) {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		strs := make([]string, 0, len(args))
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+			str, err := ToString(arg)
+			if err != nil {
+				return nil, err
+			}
+			strs = append(strs, str)
+		}
+		return fn(strs)
+	}
+}
+
+func locate(substr, str string, pos int64) int64 {
+	runes := []rune(str)
+	if pos < 1 || pos > int64(len(runes))+1 {
+		return 0
+	}
+	i := strings.Index(string(runes[pos-1:]), substr)
+	if i < 0 {
+		return 0
+	}
+	return pos + int64(utf8.RuneCountInString(string(runes[pos-1:])[:i]))
+}
+
+func pad(str, length, padding string, left bool) (Value, error) {
+	n, err := ToInt(NewVarChar(length))
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, nil
+	}
+
+	runes := []rune(str)
+	if int64(len(runes)) >= n {
+		return NewVarChar(string(runes[:n])), nil
+	}
+	fill := []rune(padding)
+	if len(fill) == 0 {
+		return NewVarChar(""), nil
+	}
+	if n > maxStringLength {
+		return nil, nil
+	}
+	need := int(n) - len(runes)
+	size := len(str) + need/len(fill)*len(padding) + len(string(fill[:need%len(fill)]))
+	if size > maxStringLength {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	if !left {
+		b.WriteString(str)
+	}
+	for i := 0; i < need; i++ {
+		b.WriteRune(fill[i%len(fill)])
+	}
+	if left {
+		b.WriteString(str)
+	}
+	return NewVarChar(b.String()), nil
+}
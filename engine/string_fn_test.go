@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithString(t *testing.T) {
+	d := NewDispatcher(WithBuiltIn(), WithString())
+
+	tests := []struct {
+		name     string
+		args     []Value
+		expected Value
+	}{
+		{name: "concat", args: []Value{NewVarChar("foo"), NewInt64(1), NewVarChar("bar")}, expected: NewVarChar("foo1bar")},
+		{name: "concat", args: []Value{NewVarChar("foo"), nil}, expected: nil},
+		{name: "concat_ws", args: []Value{NewVarChar(","), NewVarChar("a"), nil, NewVarChar("b")}, expected: NewVarChar("a,b")},
+		{name: "lower", args: []Value{NewVarChar("ÀBC")}, expected: NewVarChar("àbc")},
+		{name: "upper", args: []Value{NewVarChar("àbc")}, expected: NewVarChar("ÀBC")},
+		{name: "trim", args: []Value{NewVarChar("  foo  ")}, expected: NewVarChar("foo")},
+		{name: "trim", args: []Value{NewVarChar("xxfooxx"), NewVarChar("x")}, expected: NewVarChar("foo")},
+		{name: "ltrim", args: []Value{NewVarChar("  foo  ")}, expected: NewVarChar("foo  ")},
+		{name: "rtrim", args: []Value{NewVarChar("  foo  ")}, expected: NewVarChar("  foo")},
+		{name: "length", args: []Value{NewVarChar("héllo")}, expected: NewInt64(6)},
+		{name: "char_length", args: []Value{NewVarChar("héllo")}, expected: NewInt64(5)},
+		{name: "replace", args: []Value{NewVarChar("foo bar foo"), NewVarChar("foo"), NewVarChar("baz")}, expected: NewVarChar("baz bar baz")},
+		{name: "locate", args: []Value{NewVarChar("l"), NewVarChar("héllo")}, expected: NewInt64(3)},
+		{name: "locate", args: []Value{NewVarChar("l"), NewVarChar("héllo"), NewInt64(4)}, expected: NewInt64(4)},
+		{name: "locate", args: []Value{NewVarChar("z"), NewVarChar("héllo")}, expected: NewInt64(0)},
+		{name: "instr", args: []Value{NewVarChar("héllo"), NewVarChar("llo")}, expected: NewInt64(3)},
+		{name: "lpad", args: []Value{NewVarChar("hé"), NewInt64(5), NewVarChar("ab")}, expected: NewVarChar("abahé")},
+		{name: "lpad", args: []Value{NewVarChar("héllo"), NewInt64(2), NewVarChar("x")}, expected: NewVarChar("hé")},
+		{name: "rpad", args: []Value{NewVarChar("hé"), NewInt64(4), NewVarChar("x")}, expected: NewVarChar("héxx")},
+		{name: "lpad", args: []Value{NewVarChar("a"), NewInt64(3000000000), NewVarChar("x")}, expected: nil},
+		{name: "rpad", args: []Value{NewVarChar("a"), NewInt64(maxStringLength), NewVarChar("é")}, expected: nil},
+		{name: "reverse", args: []Value{NewVarChar("héllo")}, expected: NewVarChar("olléh")},
+		{name: "left", args: []Value{NewVarChar("héllo"), NewInt64(2)}, expected: NewVarChar("hé")},
+		{name: "right", args: []Value{NewVarChar("héllo"), NewInt64(3)}, expected: NewVarChar("llo")},
+		{name: "repeat", args: []Value{NewVarChar("ab"), NewInt64(3)}, expected: NewVarChar("ababab")},
+		{name: "repeat", args: []Value{NewVarChar("a"), NewInt64(9223372036854775807)}, expected: nil},
+		{name: "repeat", args: []Value{NewVarChar(""), NewInt64(9223372036854775807)}, expected: NewVarChar("")},
+		{name: "space", args: []Value{NewInt64(2)}, expected: NewVarChar("  ")},
+		{name: "space", args: []Value{NewInt64(9223372036854775807)}, expected: nil},
+		{name: "ascii", args: []Value{NewVarChar("A")}, expected: NewInt64(65)},
+		{name: "strcmp", args: []Value{NewVarChar("a"), NewVarChar("b")}, expected: NewInt64(-1)},
+		{name: "substr", args: []Value{NewVarChar("héllo"), NewInt64(2)}, expected: NewVarChar("éllo")},
+		{name: "substr", args: []Value{NewVarChar("héllo"), NewInt64(2), NewInt64(3)}, expected: NewVarChar("éll")},
+		{name: "substr", args: []Value{NewVarChar("héllo"), NewInt64(-3), NewInt64(2)}, expected: NewVarChar("ll")},
+		{name: "substr", args: []Value{NewVarChar("héllo"), NewInt64(0)}, expected: NewVarChar("")},
+		{name: "substr", args: []Value{NewVarChar("héllo"), NewInt64(2), NewInt64(9223372036854775807)}, expected: NewVarChar("éllo")},
+		{name: "substr", args: []Value{nil, NewInt64(1)}, expected: nil},
+		{name: "substring_index", args: []Value{NewVarChar("www.example.com"), NewVarChar("."), NewInt64(2)}, expected: NewVarChar("www.example")},
+		{name: "substring_index", args: []Value{NewVarChar("www.example.com"), NewVarChar("."), NewInt64(-2)}, expected: NewVarChar("example.com")},
+		{name: "split_part", args: []Value{NewVarChar("a,b,c"), NewVarChar(","), NewInt64(2)}, expected: NewVarChar("b")},
+		{name: "split_part", args: []Value{NewVarChar("a,b,c"), NewVarChar(","), NewInt64(-1)}, expected: NewVarChar("c")},
+		{name: "split_part", args: []Value{NewVarChar("a,b,c"), NewVarChar(","), NewInt64(4)}, expected: NewVarChar("")},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.name, i), func(t *testing.T) {
+			actual, err := d.Dispatch(tt.name, tt.args)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,822 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+var (
+	Now              = sqlparser.NewColIdent("now")
+	CurrentTimestamp = sqlparser.NewColIdent("current_timestamp")
+	LocalTime        = sqlparser.NewColIdent("localtime")
+	LocalTimestamp   = sqlparser.NewColIdent("localtimestamp")
+	SysDate          = sqlparser.NewColIdent("sysdate")
+	UTCTimestamp     = sqlparser.NewColIdent("utc_timestamp")
+	CurDate          = sqlparser.NewColIdent("curdate")
+	CurrentDate      = sqlparser.NewColIdent("current_date")
+	UTCDate          = sqlparser.NewColIdent("utc_date")
+	Date             = sqlparser.NewColIdent("date")
+	DateAdd          = sqlparser.NewColIdent("date_add")
+	AddDate          = sqlparser.NewColIdent("adddate")
+	DateSub          = sqlparser.NewColIdent("date_sub")
+	SubDate          = sqlparser.NewColIdent("subdate")
+	DateDiff         = sqlparser.NewColIdent("datediff")
+	TimestampDiff    = sqlparser.NewColIdent("timestampdiff")
+	TimestampAdd     = sqlparser.NewColIdent("timestampadd")
+	DateFormat       = sqlparser.NewColIdent("date_format")
+	StrToDate        = sqlparser.NewColIdent("str_to_date")
+	Extract          = sqlparser.NewColIdent("extract")
+	Year             = sqlparser.NewColIdent("year")
+	Quarter          = sqlparser.NewColIdent("quarter")
+	Month            = sqlparser.NewColIdent("month")
+	Week             = sqlparser.NewColIdent("week")
+	Day              = sqlparser.NewColIdent("day")
+	DayOfMonth       = sqlparser.NewColIdent("dayofmonth")
+	DayOfWeek        = sqlparser.NewColIdent("dayofweek")
+	DayOfYear        = sqlparser.NewColIdent("dayofyear")
+	Weekday          = sqlparser.NewColIdent("weekday")
+	Hour             = sqlparser.NewColIdent("hour")
+	Minute           = sqlparser.NewColIdent("minute")
+	Second           = sqlparser.NewColIdent("second")
+	Microsecond      = sqlparser.NewColIdent("microsecond")
+	DayName          = sqlparser.NewColIdent("dayname")
+	MonthName        = sqlparser.NewColIdent("monthname")
+	LastDay          = sqlparser.NewColIdent("last_day")
+	UnixTimestamp    = sqlparser.NewColIdent("unix_timestamp")
+	FromUnixtime     = sqlparser.NewColIdent("from_unixtime")
+	ConvertTz        = sqlparser.NewColIdent("convert_tz")
+)
+
+type locationKey struct{}
+
+// WithTime registers the MySQL date and time functions. DateTime values are instants; their calendar fields are
+// read in the session time zone of the query context.
+func WithTime() DispatchOption {
+	return func(d *Dispatcher) {
+		d.specs[Now.String()] = volatile(builtin(0, 1, NewNow()))
+		d.specs[CurrentTimestamp.String()] = volatile(builtin(0, 1, NewNow()))
+		d.specs[LocalTime.String()] = volatile(builtin(0, 1, NewNow()))
+		d.specs[LocalTimestamp.String()] = volatile(builtin(0, 1, NewNow()))
+		d.specs[SysDate.String()] = volatile(builtin(0, 1, NewNow()))
+		d.specs[UTCTimestamp.String()] = volatile(builtin(0, 1, NewUTCTimestamp()))
+		d.specs[CurDate.String()] = volatile(builtin(0, 0, NewCurDate()))
+		d.specs[CurrentDate.String()] = volatile(builtin(0, 0, NewCurDate()))
+		d.specs[UTCDate.String()] = volatile(builtin(0, 0, NewUTCDate()))
+		d.specs[Date.String()] = builtin(1, 1, NewDate())
+		d.specs[DateAdd.String()] = builtin(2, 2, NewDateAdd())
+		d.specs[AddDate.String()] = builtin(2, 2, NewDateAdd())
+		d.specs[DateSub.String()] = builtin(2, 2, NewDateSub())
+		d.specs[SubDate.String()] = builtin(2, 2, NewDateSub())
+		d.specs[DateDiff.String()] = builtin(2, 2, NewDateDiff())
+		d.specs[TimestampDiff.String()] = builtin(3, 3, NewTimestampDiff())
+		d.specs[TimestampAdd.String()] = builtin(3, 3, NewTimestampAdd())
+		d.specs[DateFormat.String()] = builtin(2, 2, NewDateFormat())
+		d.specs[StrToDate.String()] = builtin(2, 2, NewStrToDate())
+		d.specs[Extract.String()] = builtin(2, 2, NewExtract())
+		d.specs[Year.String()] = builtin(1, 1, NewDatePart("year"))
+		d.specs[Quarter.String()] = builtin(1, 1, NewDatePart("quarter"))
+		d.specs[Month.String()] = builtin(1, 1, NewDatePart("month"))
+		d.specs[Week.String()] = builtin(1, 2, NewWeek())
+		d.specs[Day.String()] = builtin(1, 1, NewDatePart("day"))
+		d.specs[DayOfMonth.String()] = builtin(1, 1, NewDatePart("day"))
+		d.specs[DayOfWeek.String()] = builtin(1, 1, NewDatePart("dayofweek"))
+		d.specs[DayOfYear.String()] = builtin(1, 1, NewDatePart("dayofyear"))
+		d.specs[Weekday.String()] = builtin(1, 1, NewDatePart("weekday"))
+		d.specs[Hour.String()] = builtin(1, 1, NewDatePart("hour"))
+		d.specs[Minute.String()] = builtin(1, 1, NewDatePart("minute"))
+		d.specs[Second.String()] = builtin(1, 1, NewDatePart("second"))
+		d.specs[Microsecond.String()] = builtin(1, 1, NewDatePart("microsecond"))
+		d.specs[DayName.String()] = builtin(1, 1, NewDayName())
+		d.specs[MonthName.String()] = builtin(1, 1, NewMonthName())
+		d.specs[LastDay.String()] = builtin(1, 1, NewLastDay())
+		d.specs[UnixTimestamp.String()] = volatile(builtin(0, 1, NewUnixTimestamp()))
+		d.specs[FromUnixtime.String()] = builtin(1, 2, NewFromUnixtime())
+		d.specs[ConvertTz.String()] = builtin(3, 3, NewConvertTz())
+	}
+}
+
+// ContextWithLocation returns a copy of ctx carrying the session time zone.
+func ContextWithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// LocationFromContext returns the session time zone of ctx, or UTC if none is set.
+func LocationFromContext(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// ParseLocation parses a MySQL time zone value: SYSTEM, an offset such as +09:00, or a named zone.
+func ParseLocation(name string) (*time.Location, error) {
+	switch {
+	case strings.EqualFold(name, "SYSTEM"):
+		return time.Local, nil
+	case strings.EqualFold(name, "UTC"):
+		return time.UTC, nil
+	case strings.HasPrefix(name, "+") || strings.HasPrefix(name, "-"):
+		hours, minutes, ok := strings.Cut(name[1:], ":")
+		h, err1 := strconv.Atoi(hours)
+		m, err2 := strconv.Atoi(minutes)
+		if !ok || err1 != nil || err2 != nil || h > 14 || m > 59 {
+			return nil, fmt.Errorf("unknown or incorrect time zone: '%s'", name)
+		}
+		offset := h*60*60 + m*60
+		if name[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(name, offset), nil
+	default:
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown or incorrect time zone: '%s'", name)
+		}
+		return loc, nil
+	}
+}
+
+func NewNow() ContextFunction {
+	return NewTimeFunction(0, 1, func(ctx context.Context, _ []Value) (Value, error) {
+		return NewDateTime(time.Now().In(LocationFromContext(ctx)).Truncate(time.Second)), nil
+	})
+}
+
+func NewUTCTimestamp() ContextFunction {
+	return NewTimeFunction(0, 1, func(_ context.Context, _ []Value) (Value, error) {
+		return NewDateTime(time.Now().UTC().Truncate(time.Second)), nil
+	})
+}
+
+func NewCurDate() ContextFunction {
+	return NewTimeFunction(0, 0, func(ctx context.Context, _ []Value) (Value, error) {
+		return NewDateTime(truncateDay(time.Now().In(LocationFromContext(ctx)))), nil
+	})
+}
+
+func NewUTCDate() ContextFunction {
+	return NewTimeFunction(0, 0, func(_ context.Context, _ []Value) (Value, error) {
+		return NewDateTime(truncateDay(time.Now().UTC())), nil
+	})
+}
+
+func NewDate() ContextFunction {
+	return NewTimeFunction(1, 1, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewDateTime(truncateDay(t)), nil
+	})
+}
+
+func NewDateAdd() ContextFunction {
+	return NewTimeFunction(2, 2, func(ctx context.Context, args []Value) (Value, error) {
+		interval, err := toInterval(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return addInterval(ctx, args[0], interval)
+	})
+}
+
+func NewDateSub() ContextFunction {
+	return NewTimeFunction(2, 2, func(ctx context.Context, args []Value) (Value, error) {
+		interval, err := toInterval(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return addInterval(ctx, args[0], interval.Scale(-1))
+	})
+}
+
+func NewDateDiff() ContextFunction {
+	return NewTimeFunction(2, 2, func(ctx context.Context, args []Value) (Value, error) {
+		lhs, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		rhs, err := toDateTime(ctx, args[1])
+		if err != nil {
+			return nil, err
+		}
+		return NewInt64(days(lhs) - days(rhs)), nil
+	})
+}
+
+func NewTimestampDiff() ContextFunction {
+	return NewTimeFunction(3, 3, func(ctx context.Context, args []Value) (Value, error) {
+		unit, err := ToString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		from, err := toDateTime(ctx, args[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := toDateTime(ctx, args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		switch unit = NewInterval(0, unit).Unit(); unit {
+		case "year", "quarter", "month":
+			months := int64(to.Year()-from.Year())*12 + int64(to.Month()-from.Month())
+			if rest := remainder(to).Sub(remainder(from)); months > 0 && rest < 0 {
+				months--
+			} else if months < 0 && rest > 0 {
+				months++
+			}
+			switch unit {
+			case "year":
+				return NewInt64(months / 12), nil
+			case "quarter":
+				return NewInt64(months / 3), nil
+			default:
+				return NewInt64(months), nil
+			}
+		case "week":
+			return NewInt64(int64(to.Sub(from) / (7 * 24 * time.Hour))), nil
+		case "day":
+			return NewInt64(int64(to.Sub(from) / (24 * time.Hour))), nil
+		case "hour":
+			return NewInt64(int64(to.Sub(from) / time.Hour)), nil
+		case "minute":
+			return NewInt64(int64(to.Sub(from) / time.Minute)), nil
+		case "second":
+			return NewInt64(int64(to.Sub(from) / time.Second)), nil
+		case "microsecond":
+			return NewInt64(int64(to.Sub(from) / time.Microsecond)), nil
+		default:
+			return nil, fmt.Errorf("unsupported interval unit: %s", unit)
+		}
+	})
+}
+
+func NewTimestampAdd() ContextFunction {
+	return NewTimeFunction(3, 3, func(ctx context.Context, args []Value) (Value, error) {
+		unit, err := ToString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		amount, err := ToInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return addInterval(ctx, args[2], NewInterval(amount, unit))
+	})
+}
+
+func NewDateFormat() ContextFunction {
+	return NewTimeFunction(2, 2, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		layout, err := ToString(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return NewVarChar(formatDateTime(t, layout)), nil
+	})
+}
+
+func NewStrToDate() ContextFunction {
+	return NewTimeFunction(2, 2, func(ctx context.Context, args []Value) (Value, error) {
+		str, err := ToString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		layout, err := ToString(args[1])
+		if err != nil {
+			return nil, err
+		}
+		t, ok := parseDateTimeFormat(str, layout, LocationFromContext(ctx))
+		if !ok {
+			return nil, nil
+		}
+		return NewDateTime(t), nil
+	})
+}
+
+func NewExtract() ContextFunction {
+	return NewTimeFunction(2, 2, func(ctx context.Context, args []Value) (Value, error) {
+		unit, err := ToString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		t, err := toDateTime(ctx, args[1])
+		if err != nil {
+			return nil, err
+		}
+		return datePart(t, strings.ToLower(unit))
+	})
+}
+
+func NewDatePart(unit string) ContextFunction {
+	return NewTimeFunction(1, 1, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return datePart(t, unit)
+	})
+}
+
+func NewWeek() ContextFunction {
+	return NewTimeFunction(1, 2, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		mode := int64(0)
+		if len(args) > 1 {
+			if mode, err = ToInt(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		w, _ := week(t, int(mode))
+		return NewInt64(int64(w)), nil
+	})
+}
+
+func NewDayName() ContextFunction {
+	return NewTimeFunction(1, 1, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewVarChar(t.Weekday().String()), nil
+	})
+}
+
+func NewMonthName() ContextFunction {
+	return NewTimeFunction(1, 1, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewVarChar(t.Month().String()), nil
+	})
+}
+
+func NewLastDay() ContextFunction {
+	return NewTimeFunction(1, 1, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return NewDateTime(first.AddDate(0, 1, -1)), nil
+	})
+}
+
+func NewUnixTimestamp() ContextFunction {
+	return NewTimeFunction(0, 1, func(ctx context.Context, args []Value) (Value, error) {
+		if len(args) == 0 {
+			return NewInt64(time.Now().Unix()), nil
+		}
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewInt64(t.Unix()), nil
+	})
+}
+
+func NewFromUnixtime() ContextFunction {
+	return NewTimeFunction(1, 2, func(ctx context.Context, args []Value) (Value, error) {
+		sec, err := ToFloat(args[0])
+		if err != nil {
+			return nil, err
+		}
+		t := time.UnixMicro(int64(sec * 1e6)).In(LocationFromContext(ctx))
+		if len(args) > 1 {
+			layout, err := ToString(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return NewVarChar(formatDateTime(t, layout)), nil
+		}
+		return NewDateTime(t), nil
+	})
+}
+
+func NewConvertTz() ContextFunction {
+	return NewTimeFunction(3, 3, func(ctx context.Context, args []Value) (Value, error) {
+		t, err := toDateTime(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 2)
+		for i, arg := range args[1:] {
+			if names[i], err = ToString(arg); err != nil {
+				return nil, err
+			}
+		}
+		from, err := ParseLocation(names[0])
+		if err != nil {
+			return nil, nil
+		}
+		to, err := ParseLocation(names[1])
+		if err != nil {
+			return nil, nil
+		}
+		wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), from)
+		return NewDateTime(wall.In(to)), nil
+	})
+}
+
+// NewTimeFunction returns a context function taking between minArgs and maxArgs arguments. A NULL argument yields NULL.
+func NewTimeFunction(minArgs, maxArgs int, fn ContextFunction) ContextFunction {
+	return func(ctx context.Context, args []Value) (Value, error) {
+		if len(args) < minArgs || len(args) > maxArgs {
+			return nil, fmt.Errorf("incorrect number of arguments: %d", len(args))
+		}
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+		}
+		return fn(ctx, args)
+	}
+}
+
+func toDateTime(ctx context.Context, val Value) (time.Time, error) {
+	loc := LocationFromContext(ctx)
+	switch v := val.(type) {
+	case *DateTime:
+		if v.date {
+			// A DATE is a day of the calendar rather than an instant.
+			year, month, day := v.Time().Date()
+			return time.Date(year, month, day, 0, 0, 0, 0, loc), nil
+		}
+		return v.Time().In(loc), nil
+	case *VarChar:
+		return parseDateTime(v.String(), loc)
+	case *VarBinary:
+		return parseDateTime(string(v.Bytes()), loc)
+	default:
+		t, err := ToDateTime(val)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.In(loc), nil
+	}
+}
+
+func toInterval(val Value) (*Interval, error) {
+	if v, ok := val.(*Interval); ok {
+		return v, nil
+	}
+	amount, err := ToInt(val)
+	if err != nil {
+		return nil, err
+	}
+	return NewInterval(amount, "day"), nil
+}
+
+// addInterval adds interval to val, which stays a DATE if it is one, or text of one, and interval has no time part.
+func addInterval(ctx context.Context, val Value, interval *Interval) (Value, error) {
+	t, err := toDateTime(ctx, val)
+	if err != nil {
+		return nil, err
+	}
+	return (&DateTime{data: t, date: isDate(val)}).Add(interval)
+}
+
+// isDate reports whether val is a DATE or text of the form of one.
+func isDate(val Value) bool {
+	var s string
+	switch v := val.(type) {
+	case *DateTime:
+		return v.date
+	case *VarChar:
+		s = v.String()
+	case *VarBinary:
+		s = string(v.Bytes())
+	default:
+		return false
+	}
+	_, err := time.Parse(time.DateOnly, s)
+	return err == nil
+}
+
+func datePart(t time.Time, unit string) (Value, error) {
+	switch unit {
+	case "year":
+		return NewInt64(int64(t.Year())), nil
+	case "quarter":
+		return NewInt64(int64(t.Month()+2) / 3), nil
+	case "month":
+		return NewInt64(int64(t.Month())), nil
+	case "week":
+		w, _ := week(t, 0)
+		return NewInt64(int64(w)), nil
+	case "day":
+		return NewInt64(int64(t.Day())), nil
+	case "dayofweek":
+		return NewInt64(int64(t.Weekday()) + 1), nil
+	case "weekday":
+		return NewInt64((int64(t.Weekday()) + 6) % 7), nil
+	case "dayofyear":
+		return NewInt64(int64(t.YearDay())), nil
+	case "hour":
+		return NewInt64(int64(t.Hour())), nil
+	case "minute":
+		return NewInt64(int64(t.Minute())), nil
+	case "second":
+		return NewInt64(int64(t.Second())), nil
+	case "microsecond":
+		return NewInt64(int64(t.Nanosecond() / 1000)), nil
+	case "year_month":
+		return NewInt64(int64(t.Year())*100 + int64(t.Month())), nil
+	case "day_hour":
+		return NewInt64(int64(t.Day())*100 + int64(t.Hour())), nil
+	case "hour_minute":
+		return NewInt64(int64(t.Hour())*100 + int64(t.Minute())), nil
+	case "minute_second":
+		return NewInt64(int64(t.Minute())*100 + int64(t.Second())), nil
+	default:
+		return nil, fmt.Errorf("unsupported date part: %s", unit)
+	}
+}
+
+// week follows MySQL's WEEK() modes. It returns the week number and the year the week belongs to.
+func week(t time.Time, mode int) (int, int) {
+	mode &= 7
+	if mode&1 == 0 {
+		mode ^= 4
+	}
+	mondayFirst := mode&1 != 0
+	weekYear := mode&2 != 0
+	firstWeekday := mode&4 != 0
+
+	year := t.Year()
+	daynr := days(t)
+	first := days(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC))
+	weekday := weekdayOf(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), mondayFirst)
+
+	if t.Month() == 1 && t.Day() <= 7-weekday {
+		if !weekYear && ((firstWeekday && weekday != 0) || (!firstWeekday && weekday >= 4)) {
+			return 0, year
+		}
+		weekYear = true
+		year--
+		n := daysInYear(year)
+		first -= int64(n)
+		weekday = (weekday + 53*7 - n) % 7
+	}
+
+	var d int64
+	if (firstWeekday && weekday != 0) || (!firstWeekday && weekday >= 4) {
+		d = daynr - (first + int64(7-weekday))
+	} else {
+		d = daynr - (first - int64(weekday))
+	}
+
+	if weekYear && d >= 52*7 {
+		weekday = (weekday + daysInYear(year)) % 7
+		if (!firstWeekday && weekday < 4) || (firstWeekday && weekday == 0) {
+			return 1, year + 1
+		}
+	}
+	return int(d/7) + 1, year
+}
+
+func weekdayOf(t time.Time, mondayFirst bool) int {
+	if mondayFirst {
+		return (int(t.Weekday()) + 6) % 7
+	}
+	return int(t.Weekday())
+}
+
+func daysInYear(year int) int {
+	if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+		return 366
+	}
+	return 365
+}
+
+func days(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+}
+
+// remainder returns the day of month and time of day of t, on a common month.
+func remainder(t time.Time) time.Time {
+	return time.Date(2000, 1, t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func formatDateTime(t time.Time, layout string) string {
+	var b strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' || i+1 == len(layout) {
+			b.WriteByte(layout[i])
+			continue
+		}
+		i++
+		switch layout[i] {
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'c':
+			fmt.Fprintf(&b, "%d", int(t.Month()))
+		case 'M':
+			b.WriteString(t.Month().String())
+		case 'b':
+			b.WriteString(t.Month().String()[:3])
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'e':
+			fmt.Fprintf(&b, "%d", t.Day())
+		case 'D':
+			b.WriteString(ordinal(t.Day()))
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'k':
+			fmt.Fprintf(&b, "%d", t.Hour())
+		case 'h', 'I':
+			fmt.Fprintf(&b, "%02d", (t.Hour()+11)%12+1)
+		case 'l':
+			fmt.Fprintf(&b, "%d", (t.Hour()+11)%12+1)
+		case 'i':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 's', 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 'f':
+			fmt.Fprintf(&b, "%06d", t.Nanosecond()/1000)
+		case 'p':
+			if t.Hour() < 12 {
+				b.WriteString("AM")
+			} else {
+				b.WriteString("PM")
+			}
+		case 'r':
+			b.WriteString(formatDateTime(t, "%h:%i:%s %p"))
+		case 'T':
+			b.WriteString(formatDateTime(t, "%H:%i:%s"))
+		case 'W':
+			b.WriteString(t.Weekday().String())
+		case 'a':
+			b.WriteString(t.Weekday().String()[:3])
+		case 'w':
+			fmt.Fprintf(&b, "%d", int(t.Weekday()))
+		case 'U':
+			w, _ := week(t, 0)
+			fmt.Fprintf(&b, "%02d", w)
+		case 'u':
+			w, _ := week(t, 1)
+			fmt.Fprintf(&b, "%02d", w)
+		case 'V':
+			w, _ := week(t, 2)
+			fmt.Fprintf(&b, "%02d", w)
+		case 'v':
+			w, _ := week(t, 3)
+			fmt.Fprintf(&b, "%02d", w)
+		case 'X':
+			_, y := week(t, 2)
+			fmt.Fprintf(&b, "%04d", y)
+		case 'x':
+			_, y := week(t, 3)
+			fmt.Fprintf(&b, "%04d", y)
+		default:
+			b.WriteByte(layout[i])
+		}
+	}
+	return b.String()
+}
+
+func parseDateTimeFormat(str, layout string, loc *time.Location) (time.Time, bool) {
+	year, month, day, hour, minute, second, micro := 0, 1, 1, 0, 0, 0, 0
+	pm := -1
+
+	number := func(max int) (int, bool) {
+		n := 0
+		i := 0
+		for ; i < len(str) && i < max && str[i] >= '0' && str[i] <= '9'; i++ {
+			n = n*10 + int(str[i]-'0')
+		}
+		str = str[i:]
+		return n, i > 0
+	}
+	name := func(names func(int) string, count int) (int, bool) {
+		for i := 0; i < count; i++ {
+			full := names(i)
+			for _, candidate := range []string{full, full[:3]} {
+				if len(str) >= len(candidate) && strings.EqualFold(str[:len(candidate)], candidate) {
+					str = str[len(candidate):]
+					return i, true
+				}
+			}
+		}
+		return 0, false
+	}
+
+	ok := true
+	for i := 0; i < len(layout) && ok; i++ {
+		if layout[i] != '%' || i+1 == len(layout) {
+			if len(str) == 0 || str[0] != layout[i] {
+				return time.Time{}, false
+			}
+			str = str[1:]
+			continue
+		}
+		i++
+		switch layout[i] {
+		case 'Y':
+			year, ok = number(4)
+		case 'y':
+			if year, ok = number(2); year < 70 {
+				year += 2000
+			} else {
+				year += 1900
+			}
+		case 'm', 'c':
+			month, ok = number(2)
+		case 'M', 'b':
+			var m int
+			m, ok = name(func(i int) string { return time.Month(i + 1).String() }, 12)
+			month = m + 1
+		case 'd', 'e':
+			day, ok = number(2)
+		case 'H', 'k', 'h', 'I', 'l':
+			hour, ok = number(2)
+		case 'i':
+			minute, ok = number(2)
+		case 's', 'S':
+			second, ok = number(2)
+		case 'f':
+			digits := len(str)
+			micro, ok = number(6)
+			for digits -= len(str); digits < 6; digits++ {
+				micro *= 10
+			}
+		case 'p':
+			switch {
+			case len(str) >= 2 && strings.EqualFold(str[:2], "AM"):
+				pm = 0
+			case len(str) >= 2 && strings.EqualFold(str[:2], "PM"):
+				pm = 1
+			default:
+				ok = false
+			}
+			str = str[min(2, len(str)):]
+		case 'W', 'a':
+			_, ok = name(func(i int) string { return time.Weekday(i).String() }, 7)
+		case 'T':
+			var t time.Time
+			if t, ok = parseDateTimeFormat(str[:min(8, len(str))], "%H:%i:%s", loc); ok {
+				hour, minute, second = t.Hour(), t.Minute(), t.Second()
+				str = str[min(8, len(str)):]
+			}
+		case '%':
+			if ok = len(str) > 0 && str[0] == '%'; ok {
+				str = str[1:]
+			}
+		default:
+			ok = false
+		}
+	}
+	if !ok || str != "" || month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+	if pm == 1 && hour < 12 {
+		hour += 12
+	} else if pm == 0 && hour == 12 {
+		hour = 0
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, second, micro*1000, loc)
+	if t.Day() != day {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
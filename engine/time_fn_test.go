@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTime(t *testing.T) {
+	seoul := time.FixedZone("+09:00", 9*60*60)
+	ctx := ContextWithLocation(context.TODO(), seoul)
+
+	d := NewDispatcher(WithTime())
+
+	ts := NewDateTime(time.Date(2024, 1, 31, 20, 30, 15, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		args     []Value
+		expected Value
+	}{
+		{name: "date", args: []Value{ts}, expected: NewDateTime(time.Date(2024, 2, 1, 0, 0, 0, 0, seoul))},
+		{name: "date", args: []Value{nil}, expected: nil},
+		{name: "date_add", args: []Value{NewVarChar("2024-01-31"), NewInterval(1, "month")}, expected: &DateTime{data: time.Date(2024, 2, 29, 0, 0, 0, 0, seoul), date: true}},
+		{name: "date_add", args: []Value{NewVarChar("2024-01-31"), NewInterval(1, "hour")}, expected: NewDateTime(time.Date(2024, 1, 31, 1, 0, 0, 0, seoul))},
+		{name: "date_add", args: []Value{&DateTime{data: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), date: true}, NewInterval(1, "day")}, expected: &DateTime{data: time.Date(2024, 2, 1, 0, 0, 0, 0, seoul), date: true}},
+		{name: "date_add", args: []Value{&DateTime{data: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), date: true}, NewInterval(90, "minute")}, expected: NewDateTime(time.Date(2024, 1, 31, 1, 30, 0, 0, seoul))},
+		{name: "date_add", args: []Value{NewVarChar("2024-01-31 10:00:00"), NewInt64(2)}, expected: NewDateTime(time.Date(2024, 2, 2, 10, 0, 0, 0, seoul))},
+		{name: "date_sub", args: []Value{NewVarChar("2024-03-01"), NewInterval(1, "day")}, expected: &DateTime{data: time.Date(2024, 2, 29, 0, 0, 0, 0, seoul), date: true}},
+		{name: "date_sub", args: []Value{&DateTime{data: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), date: true}, NewInterval(1, "month")}, expected: &DateTime{data: time.Date(2024, 2, 1, 0, 0, 0, 0, seoul), date: true}},
+		{name: "datediff", args: []Value{NewVarChar("2024-03-01"), NewVarChar("2024-02-01 23:59:59")}, expected: NewInt64(29)},
+		{name: "timestampdiff", args: []Value{NewVarChar("month"), NewVarChar("2024-01-31"), NewVarChar("2024-02-29")}, expected: NewInt64(0)},
+		{name: "timestampdiff", args: []Value{NewVarChar("MONTH"), NewVarChar("2024-01-31"), NewVarChar("2024-03-31")}, expected: NewInt64(2)},
+		{name: "timestampdiff", args: []Value{NewVarChar("hour"), NewVarChar("2024-01-01"), NewVarChar("2024-01-02 12:00:00")}, expected: NewInt64(36)},
+		{name: "timestampadd", args: []Value{NewVarChar("week"), NewInt64(1), NewVarChar("2024-01-01")}, expected: &DateTime{data: time.Date(2024, 1, 8, 0, 0, 0, 0, seoul), date: true}},
+		{name: "date_format", args: []Value{ts, NewVarChar("%Y-%m-%d %H:%i:%s %W %M %D %j %p")}, expected: NewVarChar("2024-02-01 05:30:15 Thursday February 1st 032 AM")},
+		{name: "str_to_date", args: []Value{NewVarChar("01/02/2024 3:04 PM"), NewVarChar("%m/%d/%Y %h:%i %p")}, expected: NewDateTime(time.Date(2024, 1, 2, 15, 4, 0, 0, seoul))},
+		{name: "str_to_date", args: []Value{NewVarChar("02/30/2024"), NewVarChar("%m/%d/%Y")}, expected: nil},
+		{name: "extract", args: []Value{NewVarChar("YEAR_MONTH"), ts}, expected: NewInt64(202402)},
+		{name: "year", args: []Value{ts}, expected: NewInt64(2024)},
+		{name: "quarter", args: []Value{ts}, expected: NewInt64(1)},
+		{name: "month", args: []Value{ts}, expected: NewInt64(2)},
+		{name: "day", args: []Value{ts}, expected: NewInt64(1)},
+		{name: "hour", args: []Value{ts}, expected: NewInt64(5)},
+		{name: "dayofweek", args: []Value{ts}, expected: NewInt64(5)},
+		{name: "weekday", args: []Value{ts}, expected: NewInt64(3)},
+		{name: "week", args: []Value{NewVarChar("2024-01-01")}, expected: NewInt64(0)},
+		{name: "week", args: []Value{NewVarChar("2024-01-07")}, expected: NewInt64(1)},
+		{name: "week", args: []Value{NewVarChar("2024-12-30"), NewInt64(3)}, expected: NewInt64(1)},
+		{name: "week", args: []Value{NewVarChar("2021-01-03"), NewInt64(3)}, expected: NewInt64(53)},
+		{name: "dayname", args: []Value{ts}, expected: NewVarChar("Thursday")},
+		{name: "monthname", args: []Value{ts}, expected: NewVarChar("February")},
+		{name: "last_day", args: []Value{NewVarChar("2024-02-10")}, expected: NewDateTime(time.Date(2024, 2, 29, 0, 0, 0, 0, seoul))},
+		{name: "unix_timestamp", args: []Value{NewVarChar("1970-01-01 09:00:00")}, expected: NewInt64(0)},
+		{name: "unix_timestamp", args: []Value{ts}, expected: NewInt64(1706733015)},
+		{name: "from_unixtime", args: []Value{NewInt64(0)}, expected: NewDateTime(time.Date(1970, 1, 1, 9, 0, 0, 0, seoul))},
+		{name: "from_unixtime", args: []Value{NewInt64(0), NewVarChar("%Y-%m-%d %T")}, expected: NewVarChar("1970-01-01 09:00:00")},
+		{name: "convert_tz", args: []Value{NewVarChar("2024-01-01 12:00:00"), NewVarChar("+00:00"), NewVarChar("-05:00")}, expected: NewDateTime(time.Date(2024, 1, 1, 7, 0, 0, 0, time.FixedZone("-05:00", -5*60*60)))},
+		{name: "convert_tz", args: []Value{NewVarChar("2024-01-01 12:00:00"), NewVarChar("+00:00"), NewVarChar("Nowhere")}, expected: nil},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.name, i), func(t *testing.T) {
+			actual, err := d.DispatchContext(ctx, tt.name, tt.args)
+			require.NoError(t, err)
+			if expected, ok := tt.expected.(*DateTime); ok {
+				require.IsType(t, expected, actual)
+				require.Equal(t, expected.Type(), actual.Type())
+				require.True(t, expected.Time().Equal(actual.(*DateTime).Time()), "expected %v, actual %v", expected.Time(), actual.(*DateTime).Time())
+			} else {
+				require.Equal(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWithTime_Now(t *testing.T) {
+	seoul := time.FixedZone("+09:00", 9*60*60)
+	ctx := ContextWithLocation(context.TODO(), seoul)
+
+	d := NewDispatcher(WithTime())
+
+	now, err := d.DispatchContext(ctx, "now", nil)
+	require.NoError(t, err)
+	require.Equal(t, seoul, now.(*DateTime).Time().Location())
+	require.WithinDuration(t, time.Now(), now.(*DateTime).Time(), 2*time.Second)
+
+	today, err := d.DispatchContext(ctx, "curdate", nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, today.(*DateTime).Time().Hour())
+}
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		err    bool
+	}{
+		{name: "UTC", offset: 0},
+		{name: "+09:00", offset: 9 * 60 * 60},
+		{name: "-05:30", offset: -(5*60 + 30) * 60},
+		{name: "Asia/Seoul", offset: 9 * 60 * 60},
+		{name: "+9", err: true},
+		{name: "Nowhere/Invalid", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := ParseLocation(tt.name)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Zone()
+			require.Equal(t, tt.offset, offset)
+		})
+	}
+}
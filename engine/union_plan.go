@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+// UnionPlan returns the rows of Left followed by the rows of Right, which take the column names of Left.
+type UnionPlan struct {
+	Left  Plan
+	Right Plan
+}
+
+var ErrColumnCount = errors.New("the used select statements have a different number of columns")
+
+var _ Plan = (*UnionPlan)(nil)
+
+func (p *UnionPlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	left, err := p.Left.Run(ctx, binds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := schema.ReadAll(left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := p.Right.Run(ctx, binds)
+	if err != nil {
+		return nil, err
+	}
+	others, err := schema.ReadAll(right)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rename(others, columnNames(ctx, p.Left, rows)); err != nil {
+		return nil, err
+	}
+	return schema.NewInMemoryCursor(append(rows, others...)), nil
+}
+
+func (p *UnionPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Left.Schema(ctx)
+}
+
+func (p *UnionPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	if cont, err := f(p); !cont || err != nil {
+		return cont, err
+	}
+	if cont, err := p.Left.Walk(f); !cont || err != nil {
+		return cont, err
+	}
+	return p.Right.Walk(f)
+}
+
+func (p *UnionPlan) String() string {
+	return fmt.Sprintf("UnionPlan(%s, %s)", p.Left.String(), p.Right.String())
+}
+
+// columnNames returns the names of the columns of plan, from its schema or else from the first of its rows.
+func columnNames(ctx context.Context, plan Plan, rows []schema.Row) []*sqlparser.ColName {
+	if columns, err := plan.Schema(ctx); err == nil && columns != nil {
+		names := make([]*sqlparser.ColName, 0, len(columns))
+		for _, col := range columns {
+			names = append(names, &sqlparser.ColName{Name: col.Name.Name})
+		}
+		return names
+	}
+	if len(rows) > 0 {
+		return rows[0].Columns
+	}
+	return nil
+}
+
+// rename gives the columns of rows the names of columns by position. Nothing is renamed when columns is nil.
+func rename(rows []schema.Row, columns []*sqlparser.ColName) error {
+	if columns == nil {
+		return nil
+	}
+	for i, row := range rows {
+		if len(row.Values) != len(columns) {
+			return ErrColumnCount
+		}
+		rows[i].Columns = columns
+	}
+	return nil
+}
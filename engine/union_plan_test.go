@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestUnionPlan_Run(t *testing.T) {
+	id := &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}
+	name := &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}
+	t1 := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{id}, Values: []sqltypes.Value{sqltypes.NewInt64(1)}},
+	})
+	t2 := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{name}, Values: []sqltypes.Value{sqltypes.NewVarChar("foo")}},
+	})
+	t3 := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{id, name}, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("bar")}},
+	})
+
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1, "t2": t2, "t3": t3})
+	scan := func(table string) Plan {
+		return &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent(table)}}
+	}
+
+	tests := []struct {
+		plan   Plan
+		cursor schema.Cursor
+		err    error
+	}{
+		{
+			plan: &UnionPlan{Left: scan("t1"), Right: scan("t2")},
+			cursor: schema.NewInMemoryCursor([]schema.Row{
+				{Columns: []*sqlparser.ColName{id}, Values: []sqltypes.Value{sqltypes.NewInt64(1)}},
+				{Columns: []*sqlparser.ColName{id}, Values: []sqltypes.Value{sqltypes.NewVarChar("foo")}},
+			}),
+		},
+		{
+			plan: &UnionPlan{Left: scan("t1"), Right: scan("t3")},
+			err:  ErrColumnCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plan.String(), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+			defer cancel()
+
+			cursor, err := tt.plan.Run(ctx, nil)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			expected, err := schema.ReadAll(tt.cursor)
+			require.NoError(t, err)
+
+			actual, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+type UsePlan struct {
+	Planner  *Planner
+	Database sqlparser.TableIdent
+}
+
+var _ Plan = (*UsePlan)(nil)
+
+func (p *UsePlan) Run(_ context.Context, _ map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	if err := p.Planner.Use(p.Database.String()); err != nil {
+		return nil, err
+	}
+	return schema.NewInMemoryCursor(nil), nil
+}
+
+func (p *UsePlan) Schema(_ context.Context) ([]schema.Column, error) {
+	return []schema.Column{}, nil
+}
+
+func (p *UsePlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	return f(p)
+}
+
+func (p *UsePlan) String() string {
+	return fmt.Sprintf("UsePlan(%s)", sqlparser.String(p.Database))
+}
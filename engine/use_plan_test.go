@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+)
+
+func TestUsePlan_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	c1 := schema.NewInMemoryCatalog(nil)
+	c2 := schema.NewInMemoryCatalog(nil)
+
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"c1": c1,
+		"c2": c2,
+	})
+	planner := NewPlanner(c1, NewDispatcher(), WithRegistry(registry), WithDatabase("c1"))
+
+	plan := &UsePlan{Planner: planner, Database: sqlparser.NewTableIdent("c2")}
+
+	cursor, err := plan.Run(ctx, nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	require.Equal(t, "c2", planner.Database())
+
+	plan = &UsePlan{Planner: planner, Database: sqlparser.NewTableIdent("c3")}
+
+	_, err = plan.Run(ctx, nil)
+	require.ErrorIs(t, err, schema.ErrCatalogNotFound)
+	require.Equal(t, "c2", planner.Database())
+}
@@ -23,7 +23,10 @@ type Uint64 struct{ data uint64 }
 type Float64 struct{ data float64 }
 type VarChar struct{ data string }
 type VarBinary struct{ data []byte }
-type DateTime struct{ data time.Time }
+type DateTime struct {
+	data time.Time
+	date bool
+}
 type Interval struct {
 	amount int64
 	unit   string
@@ -51,6 +54,10 @@ var (
 func Compare(lhs, rhs Value) (int, error) {
 	if lhs == nil && rhs == nil {
 		return 0, nil
+	} else if lhs == nil {
+		return -1, nil
+	} else if rhs == nil {
+		return 1, nil
 	}
 
 	lhs, rhs, err := Promote(lhs, rhs)
@@ -356,7 +363,7 @@ func FromSQL(val sqltypes.Value) (Value, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &DateTime{data: t}, nil
+		return &DateTime{data: t, date: true}, nil
 
 	case sqltypes.Timestamp, sqltypes.Datetime:
 		layouts := []string{
@@ -563,9 +570,9 @@ func ToDateTime(val Value) (time.Time, error) {
 	case *Float64:
 		return time.UnixMilli(int64(v.Float() * 1000)), nil
 	case *VarChar:
-		return time.Parse(time.RFC3339, v.String())
+		return parseDateTime(v.String(), time.UTC)
 	case *VarBinary:
-		return time.Parse(time.RFC3339, string(v.Bytes()))
+		return parseDateTime(string(v.Bytes()), time.UTC)
 	case *DateTime:
 		return v.Time(), nil
 	default:
@@ -670,7 +677,7 @@ func NewVarChar(s string) *VarChar      { return &VarChar{data: s} }
 func NewVarBinary(b []byte) *VarBinary  { return &VarBinary{data: b} }
 func NewDateTime(t time.Time) *DateTime { return &DateTime{data: t} }
 func NewInterval(amount int64, unit string) *Interval {
-	return &Interval{amount: amount, unit: strings.ToLower(unit)}
+	return &Interval{amount: amount, unit: strings.TrimSuffix(strings.ToLower(unit), "s")}
 }
 func NewJSON(j any) *JSON          { return &JSON{data: j} }
 func NewTuple(vals []Value) *Tuple { return &Tuple{data: vals} }
@@ -695,26 +702,41 @@ func (v *VarBinary) Type() querypb.Type { return querypb.Type_VARBINARY }
 func (v *VarBinary) Interface() any     { return v.data }
 func (v *VarBinary) Bytes() []byte      { return v.data }
 
-func (v *DateTime) Type() querypb.Type { return querypb.Type_DATETIME }
-func (v *DateTime) Interface() any     { return v.data }
-func (v *DateTime) Time() time.Time    { return v.data }
+func (v *DateTime) Type() querypb.Type {
+	if v.date {
+		return querypb.Type_DATE
+	}
+	return querypb.Type_DATETIME
+}
+func (v *DateTime) Interface() any  { return v.data }
+func (v *DateTime) Time() time.Time { return v.data }
+
+// Add returns the time d after v, which stays a DATE if v is one and d has no time part, as in MySQL.
 func (v *DateTime) Add(d *Interval) (*DateTime, error) {
+	var t time.Time
 	switch d.Unit() {
-	case "years":
-		return &DateTime{data: v.data.AddDate(int(d.Amount()), 0, 0)}, nil
-	case "months":
-		return &DateTime{data: v.data.AddDate(0, int(d.Amount()), 0)}, nil
-	case "days":
-		return &DateTime{data: v.data.AddDate(0, 0, int(d.Amount()))}, nil
-	case "hours":
+	case "year":
+		t = addMonths(v.data, 12*d.Amount())
+	case "quarter":
+		t = addMonths(v.data, 3*d.Amount())
+	case "month":
+		t = addMonths(v.data, d.Amount())
+	case "week":
+		t = v.data.AddDate(0, 0, 7*int(d.Amount()))
+	case "day":
+		t = v.data.AddDate(0, 0, int(d.Amount()))
+	case "hour":
 		return &DateTime{data: v.data.Add(time.Duration(d.Amount()) * time.Hour)}, nil
-	case "minutes":
+	case "minute":
 		return &DateTime{data: v.data.Add(time.Duration(d.Amount()) * time.Minute)}, nil
-	case "seconds":
+	case "second":
 		return &DateTime{data: v.data.Add(time.Duration(d.Amount()) * time.Second)}, nil
+	case "microsecond":
+		return &DateTime{data: v.data.Add(time.Duration(d.Amount()) * time.Microsecond)}, nil
 	default:
-		return nil, fmt.Errorf("unsupported duration unit: %s", d.Unit())
+		return nil, fmt.Errorf("unsupported interval unit: %s", d.Unit())
 	}
+	return &DateTime{data: t, date: v.date}, nil
 }
 
 func (v *Interval) Type() querypb.Type { return querypb.Type_VARCHAR }
@@ -727,18 +749,24 @@ func (v *Interval) Scale(factor float64) *Interval {
 }
 func (v *Interval) Second() int64 {
 	switch v.unit {
-	case "years":
+	case "year":
 		return v.amount * 365 * 24 * 60 * 60
-	case "months":
+	case "quarter":
+		return v.amount * 3 * 30 * 24 * 60 * 60
+	case "month":
 		return v.amount * 30 * 24 * 60 * 60
-	case "days":
+	case "week":
+		return v.amount * 7 * 24 * 60 * 60
+	case "day":
 		return v.amount * 24 * 60 * 60
-	case "hours":
+	case "hour":
 		return v.amount * 60 * 60
-	case "minutes":
+	case "minute":
 		return v.amount * 60
-	case "seconds":
+	case "second":
 		return v.amount
+	case "microsecond":
+		return v.amount / 1_000_000
 	default:
 		return 0
 	}
@@ -763,3 +791,27 @@ func (v *Tuple) Interface() any {
 func (v *Tuple) Values() []Value {
 	return v.data
 }
+
+func parseDateTime(s string, loc *time.Location) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05.999999999",
+		time.DateOnly,
+	}
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func addMonths(t time.Time, months int64) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
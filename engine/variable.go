@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+var ErrUnknownVariable = errors.New("unknown system variable")
+
+// DefaultVariables returns the system variables a planner has unless given others, with the values clients such as
+// the mysql command-line client and Connector/J read when they connect.
+func DefaultVariables() map[string]sqltypes.Value {
+	text := sqltypes.NewVarChar
+	return map[string]sqltypes.Value{
+		"auto_increment_increment": sqltypes.NewInt64(1),
+		"autocommit":               sqltypes.NewInt64(1),
+		"character_set_client":     text("utf8mb4"),
+		"character_set_connection": text("utf8mb4"),
+		"character_set_database":   text("utf8mb4"),
+		"character_set_results":    text("utf8mb4"),
+		"character_set_server":     text("utf8mb4"),
+		"collation_connection":     text("utf8mb4_general_ci"),
+		"collation_database":       text("utf8mb4_general_ci"),
+		"collation_server":         text("utf8mb4_general_ci"),
+		"init_connect":             text(""),
+		"interactive_timeout":      sqltypes.NewInt64(28800),
+		"license":                  text(""),
+		"lower_case_table_names":   sqltypes.NewInt64(0),
+		"max_allowed_packet":       sqltypes.NewInt64(64 << 20),
+		"net_buffer_length":        sqltypes.NewInt64(16384),
+		"net_write_timeout":        sqltypes.NewInt64(60),
+		"performance_schema":       sqltypes.NewInt64(0),
+		"query_cache_size":         sqltypes.NewInt64(0),
+		"query_cache_type":         text("OFF"),
+		"sql_mode":                 text(""),
+		"system_time_zone":         text("UTC"),
+		"transaction_isolation":    text("REPEATABLE-READ"),
+		"transaction_read_only":    sqltypes.NewInt64(0),
+		"tx_isolation":             text("REPEATABLE-READ"),
+		"tx_read_only":             sqltypes.NewInt64(0),
+		"version":                  text("8.0.0"),
+		"version_comment":          text("sqlbridge"),
+		"wait_timeout":             sqltypes.NewInt64(28800),
+	}
+}
+
+// variableName returns the name of the system variable @@[scope.]name refers to, and whether it is one.
+func variableName(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if !strings.HasPrefix(name, "@@") {
+		return "", false
+	}
+	name = strings.TrimPrefix(name, "@@")
+	for _, scope := range []string{"session.", "local.", "global."} {
+		name = strings.TrimPrefix(name, scope)
+	}
+	return name, true
+}
+
+func errUnknownVariable(name string) error {
+	return fmt.Errorf("%w '%s'", ErrUnknownVariable, name)
+}
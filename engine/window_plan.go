@@ -0,0 +1,587 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// WindowPlan appends the result of each of Funcs to every row of Input.
+type WindowPlan struct {
+	Input Plan
+	Funcs []*WindowFunc
+}
+
+// WindowFunc is a function evaluated over the partition of each row. Aggregates and value functions see the
+// rows of the frame, which without a Unit is the whole partition, or the rows up to the last peer of the
+// current one when there is an Order.
+type WindowFunc struct {
+	Call      *CallExpr
+	Partition []Expr
+	Order     []WindowOrder
+	Unit      string
+	Start     WindowBound
+	End       WindowBound
+	As        *sqlparser.ColName
+}
+
+type WindowOrder struct {
+	Expr      Expr
+	Direction string
+}
+
+type WindowBound struct {
+	Type   string
+	Offset Expr
+}
+
+type windowPartition struct {
+	rows    []schema.Row
+	keys    [][]Value
+	peers   []int
+	first   []int
+	last    []int
+	running *windowRunning
+}
+
+// windowRunning is an aggregate over the frames of a partition that all start at its first row, which only grow
+// from one row to the next, so that each row is accumulated once.
+type windowRunning struct {
+	fn    AggregateFunction
+	state AggregateState
+	end   int
+}
+
+var (
+	RowNumber   = sqlparser.NewColIdent("row_number")
+	Rank        = sqlparser.NewColIdent("rank")
+	DenseRank   = sqlparser.NewColIdent("dense_rank")
+	PercentRank = sqlparser.NewColIdent("percent_rank")
+	CumeDist    = sqlparser.NewColIdent("cume_dist")
+	Ntile       = sqlparser.NewColIdent("ntile")
+	Lag         = sqlparser.NewColIdent("lag")
+	Lead        = sqlparser.NewColIdent("lead")
+	FirstValue  = sqlparser.NewColIdent("first_value")
+	LastValue   = sqlparser.NewColIdent("last_value")
+	NthValue    = sqlparser.NewColIdent("nth_value")
+)
+
+var windowFunctions = []sqlparser.ColIdent{RowNumber, Rank, DenseRank, PercentRank, CumeDist, Ntile, Lag, Lead, FirstValue, LastValue, NthValue}
+
+var _ Plan = (*WindowPlan)(nil)
+
+// IsWindowFunction reports whether name is a function that can only be called with an OVER clause.
+func IsWindowFunction(name sqlparser.ColIdent) bool {
+	return slices.ContainsFunc(windowFunctions, name.Equal)
+}
+
+func (p *WindowPlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	input, err := p.Input.Run(ctx, binds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := schema.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]sqltypes.Value, len(rows))
+	for _, fn := range p.Funcs {
+		vals, err := fn.eval(ctx, rows, binds)
+		if err != nil {
+			return nil, err
+		}
+		for i, val := range vals {
+			values[i] = append(values[i], val)
+		}
+	}
+
+	columns := make([]*sqlparser.ColName, 0, len(p.Funcs))
+	for _, fn := range p.Funcs {
+		columns = append(columns, fn.As)
+	}
+	for i, row := range rows {
+		rows[i] = schema.Row{
+			Columns:  slices.Concat(row.Columns, columns),
+			Values:   slices.Concat(row.Values, values[i]),
+			Children: row.Children,
+		}
+	}
+	return schema.NewInMemoryCursor(rows), nil
+}
+
+func (p *WindowPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	input, err := p.Input.Schema(ctx)
+	if err != nil || input == nil {
+		return nil, err
+	}
+
+	columns := slices.Clone(input)
+	for _, fn := range p.Funcs {
+		var col schema.Column
+		switch name := fn.Call.Name; {
+		case name.Equal(RowNumber), name.Equal(Rank), name.Equal(DenseRank):
+			col = schema.Column{Type: querypb.Type_INT64}
+		case name.Equal(Ntile):
+			col = schema.Column{Type: querypb.Type_INT64, Nullable: true}
+		case name.Equal(PercentRank), name.Equal(CumeDist):
+			col = schema.Column{Type: querypb.Type_FLOAT64}
+		case name.Equal(Lag), name.Equal(Lead), name.Equal(FirstValue), name.Equal(LastValue), name.Equal(NthValue):
+			col = schema.Column{Type: querypb.Type_NULL_TYPE}
+			if spread, ok := fn.Call.Input.(*SpreadExpr); ok && len(spread.Exprs) > 0 {
+				col = TypeOf(spread.Exprs[0], input)
+			}
+			col.Nullable = true
+		default:
+			col = TypeOf(fn.Call, input)
+		}
+		col.Name = fn.As
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (p *WindowPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	if cont, err := f(p); !cont || err != nil {
+		return cont, err
+	}
+	return p.Input.Walk(f)
+}
+
+func (p *WindowPlan) String() string {
+	var funcs []string
+	for _, fn := range p.Funcs {
+		funcs = append(funcs, fn.String())
+	}
+	return fmt.Sprintf("WindowPlan(%s, %s)", p.Input.String(), strings.Join(funcs, ", "))
+}
+
+func (f *WindowFunc) String() string {
+	var partition []string
+	for _, expr := range f.Partition {
+		partition = append(partition, expr.String())
+	}
+	var order []string
+	for _, o := range f.Order {
+		order = append(order, o.Expr.String()+" "+o.Direction)
+	}
+	return fmt.Sprintf("Window(%s, [%s], [%s], %s)", f.Call.String(), strings.Join(partition, ", "), strings.Join(order, ", "), f.Unit)
+}
+
+func (f *WindowFunc) eval(ctx context.Context, rows []schema.Row, binds map[string]*querypb.BindVariable) ([]sqltypes.Value, error) {
+	// Partitions are looked up by a hash of their key, whose collisions are told apart by comparing the keys.
+	buckets := map[string][]int{}
+	var keys []*Tuple
+	var indexes [][]int
+	for i, row := range rows {
+		var vals []Value
+		for _, expr := range f.Partition {
+			val, err := expr.Eval(ctx, row, binds)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		key := NewTuple(vals)
+		hash := hashKey(vals)
+
+		j := slices.IndexFunc(buckets[hash], func(j int) bool {
+			cmp, err := Compare(keys[j], key)
+			return cmp == 0 && err == nil
+		})
+		if j < 0 {
+			keys = append(keys, key)
+			indexes = append(indexes, nil)
+			j = len(keys) - 1
+			buckets[hash] = append(buckets[hash], j)
+		} else {
+			j = buckets[hash][j]
+		}
+		indexes[j] = append(indexes[j], i)
+	}
+
+	values := make([]sqltypes.Value, len(rows))
+	for _, index := range indexes {
+		part, err := f.partition(ctx, rows, index, binds)
+		if err != nil {
+			return nil, err
+		}
+		if f.Call.Aggregate && (f.Unit == "" || f.Start.Type == UnboundedPrecedingStr) {
+			fn, ok := f.Call.Dispatcher.Aggregate(f.Call.FuncName())
+			if !ok {
+				return nil, fmt.Errorf("aggregate function not found: %s", f.Call.FuncName())
+			}
+			part.running = &windowRunning{fn: fn, state: fn()}
+		}
+		for i := range index {
+			val, err := f.evalAt(ctx, part, i, binds)
+			if err != nil {
+				return nil, err
+			}
+			v := sqltypes.NULL
+			if val != nil {
+				if v, err = ToSQL(val, val.Type()); err != nil {
+					return nil, err
+				}
+			}
+			values[index[i]] = v
+		}
+	}
+	return values, nil
+}
+
+// partition sorts the rows at index by Order, in place, and finds the peers of each row.
+func (f *WindowFunc) partition(ctx context.Context, rows []schema.Row, index []int, binds map[string]*querypb.BindVariable) (*windowPartition, error) {
+	keys := make([][]Value, len(index))
+	for i, j := range index {
+		for _, order := range f.Order {
+			val, err := order.Expr.Eval(ctx, rows[j], binds)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = append(keys[i], val)
+		}
+	}
+
+	perm := make([]int, len(index))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(a, b int) bool {
+		return f.compare(keys[perm[a]], keys[perm[b]]) < 0
+	})
+
+	part := &windowPartition{}
+	sorted := make([]int, len(index))
+	for i, j := range perm {
+		sorted[i] = index[j]
+		part.rows = append(part.rows, rows[index[j]])
+		part.keys = append(part.keys, keys[j])
+	}
+	copy(index, sorted)
+
+	for i := range part.rows {
+		if i > 0 && f.compare(part.keys[i-1], part.keys[i]) == 0 {
+			part.peers = append(part.peers, part.peers[i-1])
+			part.first = append(part.first, part.first[i-1])
+		} else {
+			part.peers = append(part.peers, len(part.last))
+			part.first = append(part.first, i)
+			part.last = append(part.last, i)
+		}
+		part.last[part.peers[i]] = i
+	}
+	return part, nil
+}
+
+func (f *WindowFunc) compare(lhs, rhs []Value) int {
+	for i, order := range f.Order {
+		cmp, err := Compare(lhs[i], rhs[i])
+		if err != nil {
+			return 0
+		}
+		if order.Direction == sqlparser.DescScr {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+func (f *WindowFunc) evalAt(ctx context.Context, part *windowPartition, i int, binds map[string]*querypb.BindVariable) (Value, error) {
+	n := len(part.rows)
+	row := part.rows[i]
+
+	switch name := f.Call.Name; {
+	case name.Equal(RowNumber):
+		return NewInt64(int64(i + 1)), nil
+	case name.Equal(Rank):
+		return NewInt64(int64(part.first[i] + 1)), nil
+	case name.Equal(DenseRank):
+		return NewInt64(int64(part.peers[i] + 1)), nil
+	case name.Equal(PercentRank):
+		if n == 1 {
+			return NewFloat64(0), nil
+		}
+		return NewFloat64(float64(part.first[i]) / float64(n-1)), nil
+	case name.Equal(CumeDist):
+		return NewFloat64(float64(part.last[part.peers[i]]+1) / float64(n)), nil
+	case name.Equal(Ntile):
+		args, err := f.args(ctx, row, 1, 1, binds)
+		if err != nil || args[0] == nil {
+			return nil, err
+		}
+		buckets, err := ToInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if buckets <= 0 {
+			return nil, fmt.Errorf("invalid argument for NTILE: %d", buckets)
+		}
+		// The first n % buckets buckets hold one more row than the others.
+		size, rest, pos := int64(n)/buckets, int64(n)%buckets, int64(i)
+		if pos < rest*(size+1) {
+			return NewInt64(pos/(size+1) + 1), nil
+		}
+		return NewInt64(rest + (pos-rest*(size+1))/size + 1), nil
+	case name.Equal(Lag), name.Equal(Lead):
+		args, err := f.args(ctx, row, 1, 3, binds)
+		if err != nil {
+			return nil, err
+		}
+		offset := int64(1)
+		if len(args) > 1 {
+			if offset, err = ToInt(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		if offset < 0 {
+			return nil, fmt.Errorf("invalid argument for %s: %d", strings.ToUpper(name.String()), offset)
+		}
+		if offset < int64(n) {
+			j := int64(i) + offset
+			if name.Equal(Lag) {
+				j = int64(i) - offset
+			}
+			if j >= 0 && j < int64(n) {
+				return f.arg(ctx, part.rows[j], binds)
+			}
+		}
+		if len(args) > 2 {
+			return args[2], nil
+		}
+		return nil, nil
+	}
+
+	lo, hi, err := f.frame(ctx, part, i, binds)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name := f.Call.Name; {
+	case name.Equal(FirstValue):
+		if lo < hi {
+			return f.arg(ctx, part.rows[lo], binds)
+		}
+		return nil, nil
+	case name.Equal(LastValue):
+		if lo < hi {
+			return f.arg(ctx, part.rows[hi-1], binds)
+		}
+		return nil, nil
+	case name.Equal(NthValue):
+		args, err := f.args(ctx, row, 2, 2, binds)
+		if err != nil {
+			return nil, err
+		}
+		nth, err := ToInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if nth <= 0 {
+			return nil, fmt.Errorf("invalid argument for NTH_VALUE: %d", nth)
+		}
+		if nth <= int64(hi-lo) {
+			return f.arg(ctx, part.rows[lo+int(nth)-1], binds)
+		}
+		return nil, nil
+	}
+
+	if lo == hi {
+		return f.Call.Dispatcher.DispatchContext(ctx, f.Call.Name.String(), nil)
+	}
+	if part.running != nil {
+		return part.running.eval(ctx, f.Call, part.rows[:hi], binds)
+	}
+	return f.Call.Eval(ctx, schema.Row{Columns: row.Columns, Values: row.Values, Children: part.rows[lo:hi]}, binds)
+}
+
+// frame returns the bounds of the frame of the i-th row of part, as a half-open range.
+func (f *WindowFunc) frame(ctx context.Context, part *windowPartition, i int, binds map[string]*querypb.BindVariable) (int, int, error) {
+	n := len(part.rows)
+	if f.Unit == "" {
+		if len(f.Order) == 0 {
+			return 0, n, nil
+		}
+		return 0, part.last[part.peers[i]] + 1, nil
+	}
+
+	lo, err := f.bound(ctx, part, i, f.Start, true, binds)
+	if err != nil {
+		return 0, 0, err
+	}
+	hi, err := f.bound(ctx, part, i, f.End, false, binds)
+	if err != nil {
+		return 0, 0, err
+	}
+	lo = min(max(lo, 0), n)
+	hi = min(max(hi, lo), n)
+	return lo, hi, nil
+}
+
+func (f *WindowFunc) bound(ctx context.Context, part *windowPartition, i int, bound WindowBound, start bool, binds map[string]*querypb.BindVariable) (int, error) {
+	n := len(part.rows)
+	switch bound.Type {
+	case UnboundedPrecedingStr:
+		return 0, nil
+	case UnboundedFollowingStr:
+		return n, nil
+	case CurrentRowStr:
+		if f.Unit == RangeStr {
+			if start {
+				return part.first[i], nil
+			}
+			return part.last[part.peers[i]] + 1, nil
+		}
+		if start {
+			return i, nil
+		}
+		return i + 1, nil
+	}
+
+	val, err := bound.Offset.Eval(ctx, part.rows[i], binds)
+	if err != nil {
+		return 0, err
+	}
+	if val == nil {
+		return 0, fmt.Errorf("frame offset must be a non-negative number")
+	}
+	offset, err := ToFloat(val)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("frame offset must be a non-negative number")
+	}
+	if bound.Type == PrecedingStr {
+		offset = -offset
+	}
+
+	if f.Unit == RowsStr {
+		// Offsets beyond the partition are clamped so that they fit in an int.
+		j := i + int(min(max(offset, float64(-n)), float64(n)))
+		if !start {
+			j++
+		}
+		return j, nil
+	}
+
+	// RANGE offsets compare the single order key, negated when descending so that the partition is ascending.
+	key, err := f.rangeKey(part.keys[i][0])
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(key, 0) {
+		if start {
+			return part.first[i], nil
+		}
+		return part.last[part.peers[i]] + 1, nil
+	}
+	// The keys ascend, so the bound is the first row past the target.
+	target := key + offset
+	j := sort.Search(n, func(j int) bool {
+		k, e := f.rangeKey(part.keys[j][0])
+		if e != nil {
+			err = e
+			return true
+		}
+		return (start && k >= target) || (!start && k > target)
+	})
+	return j, err
+}
+
+func (r *windowRunning) eval(ctx context.Context, call *CallExpr, rows []schema.Row, binds map[string]*querypb.BindVariable) (Value, error) {
+	for ; r.end < len(rows); r.end++ {
+		args, err := call.args(ctx, rows[r.end], binds)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.state.Accumulate(args); err != nil {
+			return nil, err
+		}
+	}
+	// A state is finalized once, so the running one is merged into a fresh one to be finalized instead.
+	state := r.fn()
+	if err := state.Merge(r.state); err != nil {
+		return nil, err
+	}
+	return state.Finalize()
+}
+
+func (f *WindowFunc) rangeKey(val Value) (float64, error) {
+	desc := f.Order[0].Direction == sqlparser.DescScr
+	if val == nil {
+		if desc {
+			return math.Inf(1), nil
+		}
+		return math.Inf(-1), nil
+	}
+	key, err := ToFloat(val)
+	if err != nil {
+		return 0, err
+	}
+	if desc {
+		key = -key
+	}
+	return key, nil
+}
+
+func (f *WindowFunc) arg(ctx context.Context, row schema.Row, binds map[string]*querypb.BindVariable) (Value, error) {
+	args, err := f.args(ctx, row, 1, -1, binds)
+	if err != nil {
+		return nil, err
+	}
+	return args[0], nil
+}
+
+func (f *WindowFunc) args(ctx context.Context, row schema.Row, least, most int, binds map[string]*querypb.BindVariable) ([]Value, error) {
+	val, err := f.Call.Input.Eval(ctx, row, binds)
+	if err != nil {
+		return nil, err
+	}
+	args := []Value{val}
+	if t, ok := val.(*Tuple); ok {
+		args = t.Values()
+	}
+	if len(args) < least || (most >= 0 && len(args) > most) {
+		return nil, fmt.Errorf("incorrect number of arguments for %s: %d", strings.ToUpper(f.Call.Name.String()), len(args))
+	}
+	return args, nil
+}
+
+// hashKey returns a hash of vals under which values that compare equal, such as numbers of different types, agree.
+func hashKey(vals []Value) string {
+	var b strings.Builder
+	for _, val := range vals {
+		switch v := val.(type) {
+		case nil:
+			b.WriteString("n")
+		case *Int64, *Uint64, *Float64:
+			f, _ := ToFloat(v)
+			b.WriteString("f" + strconv.FormatFloat(f, 'g', -1, 64))
+		case *VarChar:
+			if f, err := strconv.ParseFloat(v.String(), 64); err == nil {
+				b.WriteString("f" + strconv.FormatFloat(f, 'g', -1, 64))
+			} else {
+				b.WriteString("s" + v.String())
+			}
+		case *Tuple:
+			b.WriteString("t(" + hashKey(v.Values()) + ")")
+		default:
+			b.WriteString("v" + fmt.Sprint(v.Interface()))
+		}
+		b.WriteByte(0)
+	}
+	return b.String()
+}
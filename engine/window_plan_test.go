@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestWindowPlan_Run(t *testing.T) {
+	id := &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}
+	grp := &sqlparser.ColName{Name: sqlparser.NewColIdent("grp")}
+	t1 := schema.NewInMemoryTable([]schema.Row{
+		{Columns: []*sqlparser.ColName{id, grp}, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("a")}},
+		{Columns: []*sqlparser.ColName{id, grp}, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("b")}},
+		{Columns: []*sqlparser.ColName{id, grp}, Values: []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewVarChar("a")}},
+		{Columns: []*sqlparser.ColName{id, grp}, Values: []sqltypes.Value{sqltypes.NewInt64(4), sqltypes.NewVarChar("a")}},
+	})
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+	scan := &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}}
+
+	dispatcher := NewDispatcher(WithBuiltIn())
+	column := func(col *sqlparser.ColName) Expr {
+		return &IndexExpr{Left: &ColumnExpr{Value: col}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}
+	}
+	call := func(name sqlparser.ColIdent, aggregate bool, args ...Expr) *CallExpr {
+		return &CallExpr{Dispatcher: dispatcher, Name: name, Input: &SpreadExpr{Exprs: args}, Aggregate: aggregate}
+	}
+	as := &sqlparser.ColName{Name: sqlparser.NewColIdent("w")}
+	row := func(i int64, g string, w sqltypes.Value) schema.Row {
+		return schema.Row{Columns: []*sqlparser.ColName{id, grp, as}, Values: []sqltypes.Value{sqltypes.NewInt64(i), sqltypes.NewVarChar(g), w}}
+	}
+
+	tests := []struct {
+		fn   *WindowFunc
+		rows []schema.Row
+	}{
+		{
+			fn: &WindowFunc{
+				Call:      call(RowNumber, false),
+				Partition: []Expr{column(grp)},
+				Order:     []WindowOrder{{Expr: column(id), Direction: sqlparser.DescScr}},
+				As:        as,
+			},
+			rows: []schema.Row{row(1, "a", sqltypes.NewInt64(3)), row(2, "b", sqltypes.NewInt64(1)), row(3, "a", sqltypes.NewInt64(2)), row(4, "a", sqltypes.NewInt64(1))},
+		},
+		{
+			fn: &WindowFunc{
+				Call:  call(Rank, false),
+				Order: []WindowOrder{{Expr: column(grp), Direction: sqlparser.AscScr}},
+				As:    as,
+			},
+			rows: []schema.Row{row(1, "a", sqltypes.NewInt64(1)), row(2, "b", sqltypes.NewInt64(4)), row(3, "a", sqltypes.NewInt64(1)), row(4, "a", sqltypes.NewInt64(1))},
+		},
+		{
+			fn: &WindowFunc{
+				Call:  call(Ntile, false, &LiteralExpr{Value: sqltypes.NewInt64(3)}),
+				Order: []WindowOrder{{Expr: column(id), Direction: sqlparser.AscScr}},
+				As:    as,
+			},
+			rows: []schema.Row{row(1, "a", sqltypes.NewInt64(1)), row(2, "b", sqltypes.NewInt64(1)), row(3, "a", sqltypes.NewInt64(2)), row(4, "a", sqltypes.NewInt64(3))},
+		},
+		{
+			fn: &WindowFunc{
+				Call:  call(Lead, false, column(id), &LiteralExpr{Value: sqltypes.NewInt64(2)}),
+				Order: []WindowOrder{{Expr: column(id), Direction: sqlparser.AscScr}},
+				As:    as,
+			},
+			rows: []schema.Row{row(1, "a", sqltypes.NewInt64(3)), row(2, "b", sqltypes.NewInt64(4)), row(3, "a", sqltypes.NULL), row(4, "a", sqltypes.NULL)},
+		},
+		{
+			fn: &WindowFunc{
+				Call:  call(Sum, true, column(id)),
+				Order: []WindowOrder{{Expr: column(id), Direction: sqlparser.AscScr}},
+				Unit:  RowsStr,
+				Start: WindowBound{Type: CurrentRowStr},
+				End:   WindowBound{Type: FollowingStr, Offset: &LiteralExpr{Value: sqltypes.NewInt64(1)}},
+				As:    as,
+			},
+			rows: []schema.Row{row(1, "a", sqltypes.NewInt64(3)), row(2, "b", sqltypes.NewInt64(5)), row(3, "a", sqltypes.NewInt64(7)), row(4, "a", sqltypes.NewInt64(4))},
+		},
+		{
+			fn: &WindowFunc{
+				Call:  call(Count, true, column(id)),
+				Order: []WindowOrder{{Expr: column(id), Direction: sqlparser.AscScr}},
+				Unit:  RowsStr,
+				Start: WindowBound{Type: FollowingStr, Offset: &LiteralExpr{Value: sqltypes.NewInt64(2)}},
+				End:   WindowBound{Type: UnboundedFollowingStr},
+				As:    as,
+			},
+			rows: []schema.Row{row(1, "a", sqltypes.NewInt64(2)), row(2, "b", sqltypes.NewInt64(1)), row(3, "a", sqltypes.NewInt64(0)), row(4, "a", sqltypes.NewInt64(0))},
+		},
+		{
+			fn: &WindowFunc{
+				Call:  call(LastValue, false, column(id)),
+				Order: []WindowOrder{{Expr: column(id), Direction: sqlparser.DescScr}},
+				Unit:  RangeStr,
+				Start: WindowBound{Type: PrecedingStr, Offset: &LiteralExpr{Value: sqltypes.NewInt64(1)}},
+				End:   WindowBound{Type: FollowingStr, Offset: &LiteralExpr{Value: sqltypes.NewInt64(1)}},
+				As:    as,
+			},
+			rows: []schema.Row{row(1, "a", sqltypes.NewInt64(1)), row(2, "b", sqltypes.NewInt64(1)), row(3, "a", sqltypes.NewInt64(2)), row(4, "a", sqltypes.NewInt64(3))},
+		},
+	}
+
+	for _, tt := range tests {
+		plan := &WindowPlan{Input: scan, Funcs: []*WindowFunc{tt.fn}}
+		t.Run(plan.String(), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+			defer cancel()
+
+			cursor, err := plan.Run(ctx, map[string]*querypb.BindVariable{})
+			require.NoError(t, err)
+
+			actual, err := schema.ReadAll(cursor)
+			require.NoError(t, err)
+			require.Equal(t, tt.rows, actual)
+		})
+	}
+}
+
+func TestWindowPlan_Schema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	t1 := schema.NewInMemoryTable(nil)
+	_ = t1.SetColumns(ctx, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+	})
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": t1})
+
+	id := &IndexExpr{Left: &ColumnExpr{Value: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}}, Right: &LiteralExpr{Value: sqltypes.NewInt64(0)}}
+	plan := &WindowPlan{
+		Input: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}},
+		Funcs: []*WindowFunc{
+			{Call: &CallExpr{Name: RowNumber, Input: &SpreadExpr{}}, As: &sqlparser.ColName{Name: sqlparser.NewColIdent("rn")}},
+			{Call: &CallExpr{Name: Lag, Input: &SpreadExpr{Exprs: []Expr{id}}}, As: &sqlparser.ColName{Name: sqlparser.NewColIdent("prev")}},
+		},
+	}
+
+	cols, err := plan.Schema(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("rn")}, Type: sqltypes.Int64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("prev")}, Type: sqltypes.Int64, Nullable: true},
+	}, cols)
+}
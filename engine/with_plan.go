@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+// WithPlan runs Input with a fresh store for the common table expressions it materializes, unless it is
+// nested in a run that already has one.
+type WithPlan struct {
+	Input Plan
+}
+
+// MaterializePlan runs Input at most once per run of the enclosing WithPlan and replays its rows to every reader.
+type MaterializePlan struct {
+	Input Plan
+}
+
+type materialized struct {
+	rows map[*MaterializePlan][]schema.Row
+	mu   sync.Mutex
+}
+
+type materializedKey struct{}
+
+var (
+	_ Plan = (*WithPlan)(nil)
+	_ Plan = (*MaterializePlan)(nil)
+)
+
+func (p *WithPlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	if _, ok := ctx.Value(materializedKey{}).(*materialized); !ok {
+		ctx = context.WithValue(ctx, materializedKey{}, &materialized{rows: make(map[*MaterializePlan][]schema.Row)})
+	}
+	return p.Input.Run(ctx, binds)
+}
+
+func (p *WithPlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Input.Schema(ctx)
+}
+
+func (p *WithPlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	if cont, err := f(p); !cont || err != nil {
+		return cont, err
+	}
+	return p.Input.Walk(f)
+}
+
+func (p *WithPlan) String() string {
+	return fmt.Sprintf("WithPlan(%s)", p.Input.String())
+}
+
+func (p *MaterializePlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	store, ok := ctx.Value(materializedKey{}).(*materialized)
+	if !ok {
+		return p.Input.Run(ctx, binds)
+	}
+
+	store.mu.Lock()
+	rows, ok := store.rows[p]
+	store.mu.Unlock()
+	if !ok {
+		cursor, err := p.Input.Run(ctx, binds)
+		if err != nil {
+			return nil, err
+		}
+		if rows, err = schema.ReadAll(cursor); err != nil {
+			return nil, err
+		}
+
+		store.mu.Lock()
+		store.rows[p] = rows
+		store.mu.Unlock()
+	}
+	return schema.NewInMemoryCursor(rows), nil
+}
+
+func (p *MaterializePlan) Schema(ctx context.Context) ([]schema.Column, error) {
+	return p.Input.Schema(ctx)
+}
+
+func (p *MaterializePlan) Walk(f func(Plan) (bool, error)) (bool, error) {
+	if cont, err := f(p); !cont || err != nil {
+		return cont, err
+	}
+	return p.Input.Walk(f)
+}
+
+func (p *MaterializePlan) String() string {
+	return fmt.Sprintf("MaterializePlan(%s)", p.Input.String())
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+type countPlan struct {
+	Plan
+	runs int
+}
+
+func (p *countPlan) Run(ctx context.Context, binds map[string]*querypb.BindVariable) (schema.Cursor, error) {
+	p.runs++
+	return p.Plan.Run(ctx, binds)
+}
+
+func TestWithPlan_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	row := schema.Row{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}, Values: []sqltypes.Value{sqltypes.NewInt64(1)}}
+	catalog := schema.NewInMemoryCatalog(map[string]schema.Table{"t1": schema.NewInMemoryTable([]schema.Row{row})})
+
+	input := &countPlan{Plan: &ScanPlan{Catalog: catalog, Table: sqlparser.TableName{Name: sqlparser.NewTableIdent("t1")}}}
+	materialize := &MaterializePlan{Input: input}
+	plan := &WithPlan{Input: &UnionPlan{Left: materialize, Right: materialize}}
+
+	for i := 1; i <= 2; i++ {
+		cursor, err := plan.Run(ctx, nil)
+		require.NoError(t, err)
+
+		rows, err := schema.ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []schema.Row{row, row}, rows)
+		require.Equal(t, i, input.runs)
+	}
+
+	cursor, err := materialize.Run(ctx, nil)
+	require.NoError(t, err)
+
+	rows, err := schema.ReadAll(cursor)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Row{row}, rows)
+	require.Equal(t, 3, input.runs)
+}
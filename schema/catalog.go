@@ -1,11 +1,20 @@
 package schema
 
-import "github.com/pkg/errors"
+import (
+	"slices"
+
+	"github.com/pkg/errors"
+)
 
 type Catalog interface {
 	Table(name string) (Table, error)
 }
 
+// TableLister is implemented by catalogs that can enumerate their tables.
+type TableLister interface {
+	Tables() ([]string, error)
+}
+
 type CompositeCatalog struct {
 	catalogs []Catalog
 }
@@ -17,8 +26,10 @@ type InMemoryCatalog struct {
 var ErrTableNotFound = errors.New("table not found")
 
 var (
-	_ Catalog = (*CompositeCatalog)(nil)
-	_ Catalog = (*InMemoryCatalog)(nil)
+	_ Catalog     = (*CompositeCatalog)(nil)
+	_ Catalog     = (*InMemoryCatalog)(nil)
+	_ TableLister = (*CompositeCatalog)(nil)
+	_ TableLister = (*InMemoryCatalog)(nil)
 )
 
 func NewCompositeCatalog(catalogs ...Catalog) *CompositeCatalog {
@@ -45,6 +56,27 @@ func (c *CompositeCatalog) Table(name string) (Table, error) {
 	return nil, errors.WithStack(ErrTableNotFound)
 }
 
+func (c *CompositeCatalog) Tables() ([]string, error) {
+	var names []string
+	for _, catalog := range c.catalogs {
+		lister, ok := catalog.(TableLister)
+		if !ok {
+			continue
+		}
+		tables, err := lister.Tables()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range tables {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
 func (c *InMemoryCatalog) Table(name string) (Table, error) {
 	table, ok := c.tables[name]
 	if !ok {
@@ -52,3 +84,12 @@ func (c *InMemoryCatalog) Table(name string) (Table, error) {
 	}
 	return table, nil
 }
+
+func (c *InMemoryCatalog) Tables() ([]string, error) {
+	names := make([]string, 0, len(c.tables))
+	for name := range c.tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -35,3 +35,30 @@ func TestInMemoryCatalog_Table(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, table, tbl)
 }
+
+func TestCompositeCatalog_Tables(t *testing.T) {
+	upper := NewInMemoryCatalog(map[string]Table{
+		"b": NewInMemoryTable(nil),
+	})
+	lower := NewInMemoryCatalog(map[string]Table{
+		"a": NewInMemoryTable(nil),
+		"b": NewInMemoryTable(nil),
+	})
+
+	catalog := NewCompositeCatalog(upper, lower)
+
+	names, err := catalog.Tables()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestInMemoryCatalog_Tables(t *testing.T) {
+	catalog := NewInMemoryCatalog(map[string]Table{
+		"b": NewInMemoryTable(nil),
+		"a": NewInMemoryTable(nil),
+	})
+
+	names, err := catalog.Tables()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, names)
+}
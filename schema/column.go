@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"context"
+	"strings"
+
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+// Describer is implemented by tables that declare their columns up front.
+type Describer interface {
+	Columns(ctx context.Context) ([]Column, error)
+}
+
+type Column struct {
+	Name      *sqlparser.ColName
+	Type      querypb.Type
+	Nullable  bool
+	Length    int64
+	Precision int64
+	Scale     int64
+}
+
+var typeNames = map[querypb.Type]string{
+	querypb.Type_INT8:      "TINYINT",
+	querypb.Type_UINT8:     "UNSIGNED TINYINT",
+	querypb.Type_INT16:     "SMALLINT",
+	querypb.Type_UINT16:    "UNSIGNED SMALLINT",
+	querypb.Type_INT24:     "MEDIUMINT",
+	querypb.Type_UINT24:    "UNSIGNED MEDIUMINT",
+	querypb.Type_INT32:     "INT",
+	querypb.Type_UINT32:    "UNSIGNED INT",
+	querypb.Type_INT64:     "BIGINT",
+	querypb.Type_UINT64:    "UNSIGNED BIGINT",
+	querypb.Type_FLOAT32:   "FLOAT",
+	querypb.Type_FLOAT64:   "DOUBLE",
+	querypb.Type_TIMESTAMP: "TIMESTAMP",
+	querypb.Type_DATE:      "DATE",
+	querypb.Type_TIME:      "TIME",
+	querypb.Type_DATETIME:  "DATETIME",
+	querypb.Type_YEAR:      "YEAR",
+	querypb.Type_DECIMAL:   "DECIMAL",
+	querypb.Type_TEXT:      "TEXT",
+	querypb.Type_BLOB:      "BLOB",
+	querypb.Type_VARCHAR:   "VARCHAR",
+	querypb.Type_VARBINARY: "VARBINARY",
+	querypb.Type_CHAR:      "CHAR",
+	querypb.Type_BINARY:    "BINARY",
+	querypb.Type_BIT:       "BIT",
+	querypb.Type_ENUM:      "ENUM",
+	querypb.Type_SET:       "SET",
+	querypb.Type_GEOMETRY:  "GEOMETRY",
+	querypb.Type_JSON:      "JSON",
+}
+
+var typeAliases = map[string]querypb.Type{
+	"INTEGER":          querypb.Type_INT32,
+	"UNSIGNED INTEGER": querypb.Type_UINT32,
+	"BOOL":             querypb.Type_INT8,
+	"BOOLEAN":          querypb.Type_INT8,
+	"REAL":             querypb.Type_FLOAT64,
+	"NUMERIC":          querypb.Type_DECIMAL,
+	"DEC":              querypb.Type_DECIMAL,
+	"TINYTEXT":         querypb.Type_TEXT,
+	"MEDIUMTEXT":       querypb.Type_TEXT,
+	"LONGTEXT":         querypb.Type_TEXT,
+	"TINYBLOB":         querypb.Type_BLOB,
+	"MEDIUMBLOB":       querypb.Type_BLOB,
+	"LONGBLOB":         querypb.Type_BLOB,
+}
+
+// TypeName returns the MySQL name of typ, or an empty string if it has none.
+func TypeName(typ querypb.Type) string {
+	return typeNames[typ]
+}
+
+// ParseTypeName returns the type with the given MySQL name, such as "VARCHAR" or "UNSIGNED INT".
+func ParseTypeName(name string) (querypb.Type, bool) {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	if typ, ok := typeAliases[name]; ok {
+		return typ, true
+	}
+	for typ, n := range typeNames {
+		if n == name {
+			return typ, true
+		}
+	}
+	return 0, false
+}
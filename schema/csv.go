@@ -0,0 +1,502 @@
+package schema
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// CSVCatalog maps CSV and TSV files to tables named after the files without their extension.
+type CSVCatalog struct {
+	tables map[string]*CSVTable
+}
+
+// CSVTable reads the records of a delimited text file. Its columns are named by the header, and typed by the values
+// of a sample of records as integers, floats, datetimes or strings. The file is read anew on every scan, which fails
+// on a field past the sample that does not read as the type of its column.
+type CSVTable struct {
+	path    string
+	options csvOptions
+	columns []Column
+	once    sync.Once
+	err     error
+}
+
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	delimiter rune
+	quote     rune
+	null      *string
+	header    *bool
+	sample    int
+}
+
+type csvReader struct {
+	reader    *bufio.Reader
+	delimiter rune
+	quote     rune
+	line      int
+}
+
+type csvCursor struct {
+	file    *os.File
+	reader  *csvReader
+	table   *CSVTable
+	indexes []int
+	names   []*sqlparser.ColName
+	header  bool
+	err     error
+	close   sync.Once
+}
+
+var ErrCSVRecord = errors.New("malformed csv record")
+
+var (
+	_ Catalog     = (*CSVCatalog)(nil)
+	_ TableLister = (*CSVCatalog)(nil)
+	_ Table       = (*CSVTable)(nil)
+	_ Describer   = (*CSVTable)(nil)
+	_ Cursor      = (*csvCursor)(nil)
+)
+
+var csvLayouts = []string{
+	time.DateOnly,
+	time.DateTime,
+	"2006-01-02T15:04:05",
+	time.RFC3339Nano,
+}
+
+// WithDelimiter sets the rune separating fields, which defaults to a comma, or to a tab for .tsv files.
+func WithDelimiter(delimiter rune) CSVOption {
+	return func(o *csvOptions) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithQuote sets the rune quoting fields, which defaults to a double quote. A quote is escaped within a quoted
+// field by doubling it.
+func WithQuote(quote rune) CSVOption {
+	return func(o *csvOptions) {
+		o.quote = quote
+	}
+}
+
+// WithNullToken reads fields equal to token as NULL. By default, only empty fields are NULL.
+func WithNullToken(token string) CSVOption {
+	return func(o *csvOptions) {
+		o.null = &token
+	}
+}
+
+// WithHeader sets whether the first record is a header. By default, it is detected from the sample.
+func WithHeader(header bool) CSVOption {
+	return func(o *csvOptions) {
+		o.header = &header
+	}
+}
+
+// WithSampleSize sets how many records are read to infer the types of the columns, 100 by default. A size of
+// zero or less reads the whole file.
+func WithSampleSize(size int) CSVOption {
+	return func(o *csvOptions) {
+		o.sample = size
+	}
+}
+
+// NewCSVCatalog returns a catalog with a table for each .csv and .tsv file in dir.
+func NewCSVCatalog(dir string, opts ...CSVOption) (*CSVCatalog, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".csv", ".tsv":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return NewCSVFileCatalog(paths, opts...)
+}
+
+// NewCSVFileCatalog returns a catalog with a table for each of paths.
+func NewCSVFileCatalog(paths []string, opts ...CSVOption) (*CSVCatalog, error) {
+	c := &CSVCatalog{tables: make(map[string]*CSVTable)}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if _, ok := c.tables[name]; ok {
+			return nil, errors.Wrap(ErrTableExists, name)
+		}
+		c.tables[name] = NewCSVTable(path, opts...)
+	}
+	return c, nil
+}
+
+func (c *CSVCatalog) Table(name string) (Table, error) {
+	table, ok := c.tables[name]
+	if !ok {
+		return nil, errors.WithStack(ErrTableNotFound)
+	}
+	return table, nil
+}
+
+func (c *CSVCatalog) Tables() ([]string, error) {
+	names := make([]string, 0, len(c.tables))
+	for name := range c.tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func NewCSVTable(path string, opts ...CSVOption) *CSVTable {
+	options := csvOptions{delimiter: ',', quote: '"', sample: 100}
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		options.delimiter = '\t'
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &CSVTable{path: path, options: options}
+}
+
+func (t *CSVTable) Columns(_ context.Context) ([]Column, error) {
+	t.once.Do(func() { t.columns, t.err = t.infer() })
+	return append([]Column(nil), t.columns...), t.err
+}
+
+func (t *CSVTable) Indexes(_ context.Context) ([]Index, error) {
+	return nil, nil
+}
+
+// Scan streams the records of the file, reading only the columns listed by the hints if any.
+func (t *CSVTable) Scan(ctx context.Context, hints ...ScanHint) (Cursor, error) {
+	columns, err := t.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &csvCursor{
+		file:   file,
+		reader: t.reader(file),
+		table:  t,
+		header: t.header(),
+	}
+
+	projection := Projection(hints...)
+	for i, col := range columns {
+		if projection != nil && !slices.ContainsFunc(projection, func(name *sqlparser.ColName) bool {
+			return col.Name.Name.Equal(name.Name)
+		}) {
+			continue
+		}
+		cursor.indexes = append(cursor.indexes, i)
+		cursor.names = append(cursor.names, col.Name)
+	}
+	return cursor, nil
+}
+
+func (t *CSVTable) infer() ([]Column, error) {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := t.reader(file)
+
+	var records [][]string
+	for t.options.sample <= 0 || len(records) <= t.options.sample {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	width := 0
+	for _, record := range records {
+		width = max(width, len(record))
+	}
+
+	types := make([]querypb.Type, width)
+	nullable := make([]bool, width)
+	for i := range types {
+		types[i] = querypb.Type_NULL_TYPE
+	}
+	sample := records[1:]
+	if t.options.header != nil && !*t.options.header {
+		sample = records
+	}
+	for _, record := range sample {
+		for i := range width {
+			if i >= len(record) || t.isNull(record[i]) {
+				nullable[i] = true
+				continue
+			}
+			types[i] = widen(types[i], csvType(record[i]))
+		}
+	}
+
+	header := t.options.header != nil && *t.options.header
+	if t.options.header == nil {
+		header = t.detect(records[0], types, width)
+		if !header {
+			for i := range width {
+				if i >= len(records[0]) || t.isNull(records[0][i]) {
+					nullable[i] = true
+				} else {
+					types[i] = widen(types[i], csvType(records[0][i]))
+				}
+			}
+		}
+	}
+	t.options.header = &header
+
+	columns := make([]Column, width)
+	for i := range columns {
+		name := fmt.Sprintf("c%d", i+1)
+		if header && i < len(records[0]) && records[0][i] != "" {
+			name = records[0][i]
+		}
+		typ := types[i]
+		if typ == querypb.Type_NULL_TYPE {
+			typ = querypb.Type_VARCHAR
+		}
+		columns[i] = Column{
+			Name:     &sqlparser.ColName{Name: sqlparser.NewColIdent(name)},
+			Type:     typ,
+			Nullable: nullable[i],
+		}
+	}
+	return columns, nil
+}
+
+// detect reports whether the first record is a header: its fields are distinct names, and none of them reads as
+// the type the other records have in its column.
+func (t *CSVTable) detect(record []string, types []querypb.Type, width int) bool {
+	if len(record) != width {
+		return false
+	}
+	for i, field := range record {
+		if field == "" || t.isNull(field) || slices.Contains(record[:i], field) {
+			return false
+		}
+		if types[i] != querypb.Type_NULL_TYPE && types[i] != querypb.Type_VARCHAR && widen(types[i], csvType(field)) == types[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *CSVTable) header() bool {
+	return t.options.header != nil && *t.options.header
+}
+
+func (t *CSVTable) reader(r io.Reader) *csvReader {
+	return &csvReader{reader: bufio.NewReader(r), delimiter: t.options.delimiter, quote: t.options.quote}
+}
+
+func (t *CSVTable) isNull(field string) bool {
+	if t.options.null != nil {
+		return field == *t.options.null
+	}
+	return field == ""
+}
+
+func (t *CSVTable) value(field string, col Column) (sqltypes.Value, error) {
+	if t.isNull(field) {
+		return sqltypes.NULL, nil
+	}
+
+	var value any
+	var err error
+	switch col.Type {
+	case querypb.Type_INT64:
+		value, err = strconv.ParseInt(field, 10, 64)
+	case querypb.Type_FLOAT64:
+		value, err = strconv.ParseFloat(field, 64)
+	case querypb.Type_DATETIME:
+		value, err = parseCSVTime(field)
+	default:
+		value = field
+	}
+	if err != nil {
+		return sqltypes.NULL, errors.Wrapf(ErrCSVRecord, "column %s: %v", col.Name.Name.String(), err)
+	}
+	return Marshal(value)
+}
+
+func (c *csvCursor) Next() (Row, error) {
+	if c.err != nil {
+		return Row{}, c.err
+	}
+	row, err := c.next()
+	if err != nil {
+		c.err = err
+		_ = c.Close()
+	}
+	return row, err
+}
+
+func (c *csvCursor) Close() error {
+	var err error
+	c.close.Do(func() {
+		if c.err == nil {
+			c.err = io.EOF
+		}
+		err = c.file.Close()
+	})
+	return err
+}
+
+func (c *csvCursor) next() (Row, error) {
+	if c.header {
+		c.header = false
+		if _, err := c.reader.Read(); err != nil {
+			return Row{}, err
+		}
+	}
+
+	record, err := c.reader.Read()
+	if err != nil {
+		return Row{}, err
+	}
+
+	values := make([]sqltypes.Value, len(c.indexes))
+	for i, index := range c.indexes {
+		if index >= len(record) {
+			values[i] = sqltypes.NULL
+			continue
+		}
+		values[i], err = c.table.value(record[index], c.table.columns[index])
+		if err != nil {
+			return Row{}, errors.Wrapf(err, "line %d", c.reader.line)
+		}
+	}
+	return Row{Columns: c.names, Values: values}, nil
+}
+
+// Read returns the fields of the next record, or io.EOF after the last one. Blank lines are skipped.
+func (r *csvReader) Read() ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	started := false
+
+	for {
+		ch, _, err := r.reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			if quoted {
+				return nil, errors.Wrapf(ErrCSVRecord, "line %d: unterminated quote", r.line+1)
+			}
+			if !started {
+				return nil, io.EOF
+			}
+			r.line++
+			return append(fields, field.String()), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case quoted && ch == r.quote:
+			next, _, err := r.reader.ReadRune()
+			if err == nil && next == r.quote {
+				field.WriteRune(r.quote)
+				continue
+			}
+			if err == nil {
+				_ = r.reader.UnreadRune()
+			}
+			quoted = false
+		case quoted:
+			field.WriteRune(ch)
+		case ch == r.quote && field.Len() == 0:
+			quoted = true
+			started = true
+		case ch == r.delimiter:
+			fields = append(fields, field.String())
+			field.Reset()
+			started = true
+		case ch == '\r':
+		case ch == '\n':
+			if !started && field.Len() == 0 {
+				r.line++
+				continue
+			}
+			r.line++
+			return append(fields, field.String()), nil
+		default:
+			field.WriteRune(ch)
+			started = true
+		}
+	}
+}
+
+func csvType(field string) querypb.Type {
+	if _, err := strconv.ParseInt(field, 10, 64); err == nil {
+		return querypb.Type_INT64
+	}
+	if _, err := strconv.ParseFloat(field, 64); err == nil {
+		return querypb.Type_FLOAT64
+	}
+	if _, err := parseCSVTime(field); err == nil {
+		return querypb.Type_DATETIME
+	}
+	return querypb.Type_VARCHAR
+}
+
+// widen returns the narrowest type holding values of both types.
+func widen(lhs, rhs querypb.Type) querypb.Type {
+	switch {
+	case lhs == querypb.Type_NULL_TYPE, lhs == rhs:
+		return rhs
+	case rhs == querypb.Type_NULL_TYPE:
+		return lhs
+	case (lhs == querypb.Type_INT64 && rhs == querypb.Type_FLOAT64) || (lhs == querypb.Type_FLOAT64 && rhs == querypb.Type_INT64):
+		return querypb.Type_FLOAT64
+	}
+	return querypb.Type_VARCHAR
+}
+
+func parseCSVTime(field string) (time.Time, error) {
+	var err error
+	for _, layout := range csvLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, field); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
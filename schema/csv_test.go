@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestNewCSVCatalog(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name\n1,foo\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.TSV"), []byte("id\tamount\n1\t2.5\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("foo"), 0o644))
+
+	catalog, err := NewCSVCatalog(dir)
+	require.NoError(t, err)
+
+	names, err := catalog.Tables()
+	require.NoError(t, err)
+	require.Equal(t, []string{"orders", "users"}, names)
+
+	table, err := catalog.Table("orders")
+	require.NoError(t, err)
+
+	columns, err := table.(Describer).Columns(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("amount")}, Type: querypb.Type_FLOAT64},
+	}, columns)
+
+	_, err = catalog.Table("notes")
+	require.ErrorIs(t, err, ErrTableNotFound)
+}
+
+func TestNewCSVFileCatalog(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewCSVFileCatalog([]string{filepath.Join(dir, "a", "users.csv"), filepath.Join(dir, "b", "users.csv")})
+	require.ErrorIs(t, err, ErrTableExists)
+}
+
+func TestCSVTable_Columns(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		opts    []CSVOption
+		columns []Column
+	}{
+		{
+			name: "header",
+			data: "id,score,created_at,name\n1,1.5,2024-01-02,foo\n2,2,2024-01-02 03:04:05,\n",
+			columns: []Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("score")}, Type: querypb.Type_FLOAT64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("created_at")}, Type: querypb.Type_DATETIME},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR, Nullable: true},
+			},
+		},
+		{
+			name: "headless",
+			data: "1,foo\n2,bar\n",
+			columns: []Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("c1")}, Type: querypb.Type_INT64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("c2")}, Type: querypb.Type_VARCHAR},
+			},
+		},
+		{
+			name: "forced header",
+			data: "1,2\n3,4\n",
+			opts: []CSVOption{WithHeader(true)},
+			columns: []Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("1")}, Type: querypb.Type_INT64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("2")}, Type: querypb.Type_INT64},
+			},
+		},
+		{
+			name: "null token",
+			data: "id;name\n1;\\N\n2;\n",
+			opts: []CSVOption{WithDelimiter(';'), WithNullToken("\\N")},
+			columns: []Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR, Nullable: true},
+			},
+		},
+		{
+			name: "sample",
+			data: "id\n1\n2\nfoo\n",
+			opts: []CSVOption{WithSampleSize(2)},
+			columns: []Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "t.csv")
+			require.NoError(t, os.WriteFile(path, []byte(tt.data), 0o644))
+
+			columns, err := NewCSVTable(path, tt.opts...).Columns(context.TODO())
+			require.NoError(t, err)
+			require.Equal(t, tt.columns, columns)
+		})
+	}
+}
+
+func TestCSVTable_Scan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "t.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,name,note\r\n1,'foo, bar',x\r\n\r\n2,'it''s\nok',\r\n"), 0o644))
+
+	table := NewCSVTable(path, WithQuote('\''))
+
+	t.Run("all", func(t *testing.T) {
+		cursor, err := table.Scan(ctx)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+
+		columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}, {Name: sqlparser.NewColIdent("note")}}
+		require.Equal(t, []Row{
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo, bar"), sqltypes.NewVarChar("x")}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("it's\nok"), sqltypes.NULL}},
+		}, rows)
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		cursor, err := table.Scan(ctx, ScanHint{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("NAME")}}})
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+
+		columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("name")}}
+		require.Equal(t, []Row{
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewVarChar("foo, bar")}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewVarChar("it's\nok")}},
+		}, rows)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "t.csv")
+		require.NoError(t, os.WriteFile(path, []byte("id\n1\nfoo\n"), 0o644))
+
+		cursor, err := NewCSVTable(path, WithSampleSize(1)).Scan(ctx)
+		require.NoError(t, err)
+
+		_, err = ReadAll(cursor)
+		require.ErrorIs(t, err, ErrCSVRecord)
+	})
+}
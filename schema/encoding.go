@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
 // Marshal safely converts a JSON-encoded interface into a sqltypes.Value.
 func Marshal(value any) (sqltypes.Value, error) {
+	if t, ok := value.(time.Time); ok {
+		return sqltypes.MakeTrusted(sqltypes.Datetime, []byte(t.Format(time.RFC3339Nano))), nil
+	}
 	switch v := reflect.ValueOf(value); v.Kind() {
 	case reflect.Ptr:
 		if v.IsNil() {
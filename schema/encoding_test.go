@@ -3,6 +3,7 @@ package schema
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/xwb1989/sqlparser/dependency/sqltypes"
@@ -41,6 +42,10 @@ func TestMarshal(t *testing.T) {
 			value:    map[string]interface{}{"key": "value"},
 			expected: sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"key":"value"}`)),
 		},
+		{
+			value:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			expected: sqltypes.MakeTrusted(sqltypes.Datetime, []byte("2024-01-02T03:04:05Z")),
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,683 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xwb1989/sqlparser"
+)
+
+// HTTPTable reads the records of a JSON API, following its pages. Its columns are inferred from the records of the
+// first page as JSONTable infers them, and equality conditions on the columns mapped to query parameters are sent
+// along so that the API may filter the records itself.
+type HTTPTable struct {
+	url     string
+	options httpOptions
+	limiter *httpLimiter
+	columns []Column
+	first   *httpPage
+	mu      sync.Mutex
+}
+
+type HTTPOption func(*httpOptions)
+
+// Pagination decides how an HTTPTable walks through the pages of an API.
+type Pagination interface {
+	// First returns the URL of the first page, given the URL of the request.
+	First(u *url.URL) *url.URL
+	// Next returns the URL of the page after the one at u, given its response header and body and the number of
+	// records it held, or nil if it is the last.
+	Next(u *url.URL, header http.Header, body []byte, count int) (*url.URL, error)
+}
+
+// PagePagination numbers pages from Start in the query parameter Param, asking for Size records per page in
+// SizeParam if set. A page with no records, or with fewer than Size, is the last, as is one repeating the page
+// before it, which an API ignoring Param returns.
+type PagePagination struct {
+	Param     string
+	SizeParam string
+	Size      int
+	Start     int
+}
+
+// OffsetPagination skips the records read so far with the query parameter OffsetParam, asking for Limit records
+// per page in LimitParam. A page with no records, or with fewer than Limit, is the last.
+type OffsetPagination struct {
+	OffsetParam string
+	LimitParam  string
+	Limit       int
+}
+
+// CursorPagination passes the token found at Path in the body of a page as the query parameter Param of the next.
+// A page without a token is the last.
+type CursorPagination struct {
+	Param string
+	Path  string
+}
+
+// LinkPagination follows the URL of the "next" relation of the Link header. A page without one is the last.
+type LinkPagination struct{}
+
+type httpOptions struct {
+	client     *http.Client
+	method     string
+	header     http.Header
+	path       string
+	pagination Pagination
+	params     map[string]string
+	retries    int
+	backoff    time.Duration
+	interval   time.Duration
+}
+
+// httpPage is a page read to infer the columns, kept for the scan that follows.
+type httpPage struct {
+	url    string
+	header http.Header
+	body   []byte
+}
+
+type httpLimiter struct {
+	interval time.Duration
+	next     time.Time
+	mu       sync.Mutex
+}
+
+type httpCursor struct {
+	ctx     context.Context
+	table   *HTTPTable
+	url     *url.URL
+	columns []Column
+	decoder *jsonDecoder
+	header  http.Header
+	body    []byte
+	count   int
+	read    int
+	limit   int
+	indexes []int
+	names   []*sqlparser.ColName
+	err     error
+	close   sync.Once
+}
+
+var ErrHTTPStatus = errors.New("unexpected http status")
+
+var (
+	_ Table      = (*HTTPTable)(nil)
+	_ Describer  = (*HTTPTable)(nil)
+	_ Cursor     = (*httpCursor)(nil)
+	_ Pagination = PagePagination{}
+	_ Pagination = OffsetPagination{}
+	_ Pagination = CursorPagination{}
+	_ Pagination = LinkPagination{}
+)
+
+// WithHTTPClient sets the client sending requests, http.DefaultClient by default.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(o *httpOptions) {
+		o.client = client
+	}
+}
+
+// WithMethod sets the method of requests, GET by default.
+func WithMethod(method string) HTTPOption {
+	return func(o *httpOptions) {
+		o.method = method
+	}
+}
+
+// WithRequestHeader adds a header to every request.
+func WithRequestHeader(key, value string) HTTPOption {
+	return func(o *httpOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithRecordsPath sets the path to the array of records in the body of a page, such as "$.data.items". By default,
+// the body is the array itself.
+func WithRecordsPath(path string) HTTPOption {
+	return func(o *httpOptions) {
+		o.path = path
+	}
+}
+
+// WithPagination sets how pages are walked through. By default, only one page is read.
+func WithPagination(pagination Pagination) HTTPOption {
+	return func(o *httpOptions) {
+		o.pagination = pagination
+	}
+}
+
+// WithQueryParam sends an equality condition on column as the query parameter param.
+func WithQueryParam(column, param string) HTTPOption {
+	return func(o *httpOptions) {
+		o.params[strings.ToLower(column)] = param
+	}
+}
+
+// WithRetry retries a request failing with a 429 status up to retries times, waiting as long as its Retry-After
+// header asks, or else backoff before the first retry and twice as long before each next one. Requests of an
+// idempotent method, unlike POST, are also retried after a network error or a 5xx status.
+func WithRetry(retries int, backoff time.Duration) HTTPOption {
+	return func(o *httpOptions) {
+		o.retries = retries
+		o.backoff = backoff
+	}
+}
+
+// WithRateLimit waits at least interval between the starts of two requests of the table.
+func WithRateLimit(interval time.Duration) HTTPOption {
+	return func(o *httpOptions) {
+		o.interval = interval
+	}
+}
+
+// NewHTTPTable returns a table reading the records of the API at rawURL.
+func NewHTTPTable(rawURL string, opts ...HTTPOption) *HTTPTable {
+	options := httpOptions{
+		client: http.DefaultClient,
+		method: http.MethodGet,
+		header: make(http.Header),
+		params: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &HTTPTable{
+		url:     rawURL,
+		options: options,
+		limiter: &httpLimiter{interval: options.interval},
+	}
+}
+
+// Columns reads the first page to collect the keys of its records, until a read succeeds.
+func (t *HTTPTable) Columns(ctx context.Context) ([]Column, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.columns == nil {
+		columns, err := t.infer(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t.columns = columns
+	}
+	return slices.Clone(t.columns), nil
+}
+
+// Indexes returns an index on each column mapped to a query parameter, through which equality conditions on it
+// reach the scan.
+func (t *HTTPTable) Indexes(ctx context.Context) ([]Index, error) {
+	columns, err := t.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []Index
+	for _, col := range columns {
+		if _, ok := t.options.params[col.Name.Name.Lowered()]; ok {
+			indexes = append(indexes, Index{Name: col.Name.Name.String(), Columns: []*sqlparser.ColName{col.Name}})
+		}
+	}
+	return indexes, nil
+}
+
+// Scan walks through the pages of the API, reading only the columns listed by the hints if any. It stops once it
+// has read as many records as the limit of the hints if they carry no filters.
+func (t *HTTPTable) Scan(ctx context.Context, hints ...ScanHint) (Cursor, error) {
+	columns, err := t.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := t.request(hints)
+	if err != nil {
+		return nil, err
+	}
+	if t.options.pagination != nil {
+		u = t.options.pagination.First(u)
+	}
+
+	cursor := &httpCursor{ctx: ctx, table: t, url: u, columns: columns}
+	if len(Filters(hints...)) == 0 {
+		cursor.limit = Limit(hints...)
+	}
+
+	projection := Projection(hints...)
+	for i, col := range columns {
+		if projection != nil && !slices.ContainsFunc(projection, func(name *sqlparser.ColName) bool {
+			return col.Name.Name.Equal(name.Name)
+		}) {
+			continue
+		}
+		cursor.indexes = append(cursor.indexes, i)
+		cursor.names = append(cursor.names, col.Name)
+	}
+
+	if page := t.page(u); page != nil {
+		cursor.header, cursor.body, cursor.decoder = page.header, page.body, t.records(page.body)
+	} else if err := cursor.fetch(); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func (t *HTTPTable) infer(ctx context.Context) ([]Column, error) {
+	u, err := t.request(nil)
+	if err != nil {
+		return nil, err
+	}
+	if t.options.pagination != nil {
+		u = t.options.pagination.First(u)
+	}
+
+	header, body, err := t.fetch(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	t.first = &httpPage{url: u.String(), header: header, body: body}
+	return inferJSON(t.records(body))
+}
+
+// page returns the page read to infer the columns if it is the one at u. The page is handed out once, to the scan
+// following the inference, so that later scans see the records as they are then.
+func (t *HTTPTable) page(u *url.URL) *httpPage {
+	t.mu.Lock()
+	page := t.first
+	t.first = nil
+	t.mu.Unlock()
+
+	if page == nil || page.url != u.String() {
+		return nil
+	}
+	return page
+}
+
+// request returns the URL of the table with the equality conditions of hints on mapped columns as query parameters.
+func (t *HTTPTable) request(hints []ScanHint) (*url.URL, error) {
+	u, err := url.Parse(t.url)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	for _, hint := range hints {
+		if param, ok := t.options.params[strings.ToLower(hint.Index)]; ok && len(hint.Ranges) == 1 {
+			rng := hint.Ranges[0]
+			if rng.Min != nil && rng.Max != nil && rng.Min.ToString() == rng.Max.ToString() {
+				query.Set(param, rng.Min.ToString())
+			}
+		}
+		for _, filter := range hint.Filters {
+			if param, ok := t.options.params[filter.Column.Name.Lowered()]; ok && filter.Operator == sqlparser.EqualStr && len(filter.Values) == 1 && !filter.Values[0].IsNull() {
+				query.Set(param, filter.Values[0].ToString())
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// fetch sends a request for u, retrying it as the options allow, and returns the header and body of its response.
+func (t *HTTPTable) fetch(ctx context.Context, u *url.URL) (http.Header, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.wait(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		header, body, retry, err := t.send(ctx, u)
+		if err == nil {
+			return header, body, nil
+		}
+		if !retry || attempt >= t.options.retries || ctx.Err() != nil {
+			return nil, nil, err
+		}
+
+		delay := t.options.backoff << attempt
+		if d, ok := retryAfter(header); ok {
+			delay = d
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *HTTPTable) send(ctx context.Context, u *url.URL) (http.Header, []byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, t.options.method, u.String(), nil)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	for key, values := range t.options.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	// A request the server may have acted on is sent again only if doing so has the same effect.
+	idempotent := isIdempotent(t.options.method)
+
+	resp, err := t.options.client.Do(req)
+	if err != nil {
+		return nil, nil, idempotent, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, idempotent, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= 500 && idempotent)
+		return resp.Header, nil, retry, errors.Wrapf(ErrHTTPStatus, "%s %s: %s", t.options.method, u.Redacted(), resp.Status)
+	}
+	return resp.Header, body, false, nil
+}
+
+// isIdempotent reports whether sending a request of method twice has the effect of sending it once.
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns how long the Retry-After header of a response asks to wait, given in seconds or as a date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// records returns a decoder of the records in body.
+func (t *HTTPTable) records(body []byte) *jsonDecoder {
+	data, ok := jsonLookup(body, t.options.path)
+	if !ok || string(bytes.TrimSpace(data)) == "null" {
+		data = []byte("[]")
+	}
+	return newJSONDecoder(bytes.NewReader(data))
+}
+
+func (c *httpCursor) Next() (Row, error) {
+	if c.err != nil {
+		return Row{}, c.err
+	}
+	row, err := c.next()
+	if err != nil {
+		c.err = err
+		_ = c.Close()
+	}
+	return row, err
+}
+
+func (c *httpCursor) Close() error {
+	c.close.Do(func() {
+		if c.err == nil {
+			c.err = io.EOF
+		}
+		c.decoder = nil
+	})
+	return nil
+}
+
+func (c *httpCursor) next() (Row, error) {
+	for {
+		if c.limit > 0 && c.read >= c.limit {
+			return Row{}, io.EOF
+		}
+
+		fields, err := c.decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			if err := c.advance(); err != nil {
+				return Row{}, err
+			}
+			continue
+		}
+		if err != nil {
+			return Row{}, err
+		}
+		c.count++
+		c.read++
+
+		values, err := jsonRecord(fields, c.columns, c.indexes)
+		if err != nil {
+			return Row{}, err
+		}
+		return Row{Columns: c.names, Values: values}, nil
+	}
+}
+
+// advance moves on to the next page, or returns io.EOF after the last one.
+func (c *httpCursor) advance() error {
+	if c.table.options.pagination == nil {
+		return io.EOF
+	}
+	u, err := c.table.options.pagination.Next(c.url, c.header, c.body, c.count)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.String() == c.url.String() {
+		return io.EOF
+	}
+	c.url = u
+	return c.fetch()
+}
+
+func (c *httpCursor) fetch() error {
+	header, body, err := c.table.fetch(c.ctx, c.url)
+	if err != nil {
+		return err
+	}
+	if c.body != nil && bytes.Equal(body, c.body) {
+		return io.EOF
+	}
+	c.header, c.body, c.decoder, c.count = header, body, c.table.records(body), 0
+	return nil
+}
+
+func (l *httpLimiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (p PagePagination) First(u *url.URL) *url.URL {
+	return withQuery(u, func(query url.Values) {
+		query.Set(p.param(), strconv.Itoa(p.Start))
+		if p.SizeParam != "" && p.Size > 0 {
+			query.Set(p.SizeParam, strconv.Itoa(p.Size))
+		}
+	})
+}
+
+func (p PagePagination) Next(u *url.URL, _ http.Header, _ []byte, count int) (*url.URL, error) {
+	if count == 0 || (p.Size > 0 && count < p.Size) {
+		return nil, nil
+	}
+	page, err := strconv.Atoi(u.Query().Get(p.param()))
+	if err != nil {
+		return nil, err
+	}
+	return withQuery(u, func(query url.Values) {
+		query.Set(p.param(), strconv.Itoa(page+1))
+	}), nil
+}
+
+func (p PagePagination) param() string {
+	if p.Param == "" {
+		return "page"
+	}
+	return p.Param
+}
+
+func (p OffsetPagination) First(u *url.URL) *url.URL {
+	return withQuery(u, func(query url.Values) {
+		query.Set(p.offsetParam(), "0")
+		if p.Limit > 0 {
+			query.Set(p.limitParam(), strconv.Itoa(p.Limit))
+		}
+	})
+}
+
+func (p OffsetPagination) Next(u *url.URL, _ http.Header, _ []byte, count int) (*url.URL, error) {
+	if count == 0 || (p.Limit > 0 && count < p.Limit) {
+		return nil, nil
+	}
+	offset, err := strconv.Atoi(u.Query().Get(p.offsetParam()))
+	if err != nil {
+		return nil, err
+	}
+	return withQuery(u, func(query url.Values) {
+		query.Set(p.offsetParam(), strconv.Itoa(offset+count))
+	}), nil
+}
+
+func (p OffsetPagination) offsetParam() string {
+	if p.OffsetParam == "" {
+		return "offset"
+	}
+	return p.OffsetParam
+}
+
+func (p OffsetPagination) limitParam() string {
+	if p.LimitParam == "" {
+		return "limit"
+	}
+	return p.LimitParam
+}
+
+func (p CursorPagination) First(u *url.URL) *url.URL {
+	return u
+}
+
+func (p CursorPagination) Next(u *url.URL, _ http.Header, body []byte, _ int) (*url.URL, error) {
+	data, ok := jsonLookup(body, p.Path)
+	if !ok {
+		return nil, nil
+	}
+
+	var token any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&token); err != nil {
+		return nil, err
+	}
+
+	var value string
+	switch v := token.(type) {
+	case string:
+		value = v
+	case json.Number:
+		value = v.String()
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	param := p.Param
+	if param == "" {
+		param = "cursor"
+	}
+	return withQuery(u, func(query url.Values) {
+		query.Set(param, value)
+	}), nil
+}
+
+func (LinkPagination) First(u *url.URL) *url.URL {
+	return u
+}
+
+func (LinkPagination) Next(u *url.URL, header http.Header, _ []byte, _ int) (*url.URL, error) {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(key, "rel") || !slices.Contains(strings.Fields(strings.Trim(val, `"`)), "next") {
+					continue
+				}
+				next, err := url.Parse(target[1 : len(target)-1])
+				if err != nil {
+					return nil, err
+				}
+				return u.ResolveReference(next), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// withQuery returns a copy of u with its query changed by f.
+func withQuery(u *url.URL, f func(url.Values)) *url.URL {
+	clone := *u
+	query := clone.Query()
+	f(query)
+	clone.RawQuery = query.Encode()
+	return &clone
+}
+
+// jsonLookup returns the value at a path of member names, such as "$.data.items", within a JSON document.
+func jsonLookup(data []byte, path string) ([]byte, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return data, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, false
+		}
+		value, ok := object[key]
+		if !ok {
+			return nil, false
+		}
+		data = value
+	}
+	return data, true
+}
@@ -0,0 +1,304 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func newHTTPServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPTable_Columns(t *testing.T) {
+	server := newHTTPServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"items": [{"id": 1, "name": "foo"}, {"id": 2, "name": null, "tags": ["a"]}]}}`))
+	})
+
+	table := NewHTTPTable(server.URL, WithRecordsPath("$.data.items"))
+
+	columns, err := table.Columns(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR, Nullable: true},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("tags")}, Type: querypb.Type_JSON, Nullable: true},
+	}, columns)
+}
+
+func TestHTTPTable_ColumnsError(t *testing.T) {
+	var requests atomic.Int32
+	server := newHTTPServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1}]`))
+	})
+
+	table := NewHTTPTable(server.URL)
+
+	_, err := table.Columns(context.TODO())
+	require.ErrorIs(t, err, ErrHTTPStatus)
+
+	columns, err := table.Columns(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, columns, 1)
+}
+
+func TestHTTPTable_Scan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	records := func(from, to int) []map[string]any {
+		var items []map[string]any
+		for i := from; i < to && i < 5; i++ {
+			items = append(items, map[string]any{"id": i, "name": fmt.Sprintf("user%d", i)})
+		}
+		return items
+	}
+	ids := func(rows []Row) []int64 {
+		var ids []int64
+		for _, row := range rows {
+			id, _ := strconv.ParseInt(row.Values[0].ToString(), 10, 64)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		pagination Pagination
+		path       string
+	}{
+		{
+			name: "page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+				_ = json.NewEncoder(w).Encode(records((page-1)*2, page*2))
+			},
+			pagination: PagePagination{Param: "p", SizeParam: "size", Size: 2, Start: 1},
+		},
+		{
+			name: "offset",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				_ = json.NewEncoder(w).Encode(records(offset, offset+limit))
+			},
+			pagination: OffsetPagination{Limit: 2},
+		},
+		{
+			name: "cursor",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				from, _ := strconv.Atoi(r.URL.Query().Get("after"))
+				body := map[string]any{"items": records(from, from+2)}
+				if from+2 < 5 {
+					body["next"] = strconv.Itoa(from + 2)
+				}
+				_ = json.NewEncoder(w).Encode(body)
+			},
+			pagination: CursorPagination{Param: "after", Path: "next"},
+			path:       "items",
+		},
+		{
+			name: "link",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+				if from+2 < 5 {
+					w.Header().Set("Link", fmt.Sprintf(`</users?from=%d>; rel="next", </users?from=0>; rel="first"`, from+2))
+				}
+				_ = json.NewEncoder(w).Encode(records(from, from+2))
+			},
+			pagination: LinkPagination{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newHTTPServer(t, tt.handler)
+			table := NewHTTPTable(server.URL+"/users", WithPagination(tt.pagination), WithRecordsPath(tt.path))
+
+			cursor, err := table.Scan(ctx)
+			require.NoError(t, err)
+
+			rows, err := ReadAll(cursor)
+			require.NoError(t, err)
+			require.Equal(t, []int64{0, 1, 2, 3, 4}, ids(rows))
+		})
+	}
+
+	t.Run("hints", func(t *testing.T) {
+		var requests atomic.Int32
+		server := newHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if name := r.URL.Query().Get("username"); name != "" {
+				_ = json.NewEncoder(w).Encode([]map[string]any{{"id": 3, "name": name}})
+				return
+			}
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			_ = json.NewEncoder(w).Encode(records(page*2, page*2+2))
+		})
+
+		table := NewHTTPTable(server.URL,
+			WithRequestHeader("Authorization", "Bearer token"),
+			WithPagination(PagePagination{Size: 2}),
+			WithQueryParam("name", "username"),
+		)
+
+		indexes, err := table.Indexes(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []Index{{Name: "name", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("name")}}}}, indexes)
+
+		foo := sqltypes.NewVarChar("foo")
+		cursor, err := table.Scan(ctx, ScanHint{Index: "name", Ranges: []Range{{Min: &foo, Max: &foo}}})
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+
+		columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+		require.Equal(t, []Row{{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(3), foo}}}, rows)
+
+		requests.Store(0)
+		cursor, err = table.Scan(ctx, ScanHint{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}, Limit: 3})
+		require.NoError(t, err)
+
+		rows, err = ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []int64{0, 1, 2}, ids(rows))
+		require.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		var requests atomic.Int32
+		server := newHTTPServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			if requests.Add(1)%3 != 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(records(0, 1))
+		})
+
+		table := NewHTTPTable(server.URL, WithRetry(2, time.Millisecond))
+
+		cursor, err := table.Scan(ctx)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []int64{0}, ids(rows))
+	})
+
+	t.Run("status", func(t *testing.T) {
+		server := newHTTPServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		_, err := NewHTTPTable(server.URL, WithRetry(2, time.Millisecond)).Scan(ctx)
+		require.ErrorIs(t, err, ErrHTTPStatus)
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		server := newHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			_ = json.NewEncoder(w).Encode(records(page*2, page*2+2))
+		})
+
+		table := NewHTTPTable(server.URL, WithPagination(PagePagination{Size: 2}), WithRateLimit(20*time.Millisecond))
+
+		start := time.Now()
+		cursor, err := table.Scan(ctx)
+		require.NoError(t, err)
+
+		_, err = ReadAll(cursor)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("first page", func(t *testing.T) {
+		var requests atomic.Int32
+		server := newHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			_ = json.NewEncoder(w).Encode(records(page*2, page*2+2))
+		})
+
+		table := NewHTTPTable(server.URL, WithPagination(PagePagination{Size: 2}))
+
+		cursor, err := table.Scan(ctx)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []int64{0, 1, 2, 3, 4}, ids(rows))
+		require.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("repeated page", func(t *testing.T) {
+		var requests atomic.Int32
+		server := newHTTPServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			_ = json.NewEncoder(w).Encode(records(0, 2))
+		})
+
+		cursor, err := NewHTTPTable(server.URL, WithPagination(PagePagination{})).Scan(ctx)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []int64{0, 1}, ids(rows))
+		require.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		var requests atomic.Int32
+		server := newHTTPServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			if requests.Add(1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(records(0, 1))
+		})
+
+		start := time.Now()
+		cursor, err := NewHTTPTable(server.URL, WithMethod(http.MethodPost), WithRetry(1, time.Millisecond)).Scan(ctx)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(start), time.Second)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []int64{0}, ids(rows))
+	})
+
+	t.Run("not idempotent", func(t *testing.T) {
+		var requests atomic.Int32
+		server := newHTTPServer(t, func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		_, err := NewHTTPTable(server.URL, WithMethod(http.MethodPost), WithRetry(2, time.Millisecond)).Scan(ctx)
+		require.ErrorIs(t, err, ErrHTTPStatus)
+		require.Equal(t, int32(1), requests.Load())
+	})
+}
@@ -5,4 +5,6 @@ import "github.com/xwb1989/sqlparser"
 type Index struct {
 	Name    string
 	Columns []*sqlparser.ColName
+	// Unique indexes, such as a primary key, hold each key at most once.
+	Unique bool
 }
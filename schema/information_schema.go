@@ -0,0 +1,306 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// InformationSchema is a virtual catalog describing the catalogs of a registry.
+// Views are listed without their columns or indexes.
+type InformationSchema struct {
+	registry Registry
+}
+
+type informationTable struct {
+	columns []Column
+	rows    func(ctx context.Context) ([][]sqltypes.Value, error)
+}
+
+const InformationSchemaName = "information_schema"
+
+var (
+	_ Catalog     = (*InformationSchema)(nil)
+	_ TableLister = (*InformationSchema)(nil)
+	_ Table       = (*informationTable)(nil)
+	_ Describer   = (*informationTable)(nil)
+)
+
+var (
+	schemataColumns = []Column{
+		informationColumn("CATALOG_NAME", querypb.Type_VARCHAR, false),
+		informationColumn("SCHEMA_NAME", querypb.Type_VARCHAR, false),
+	}
+	tablesColumns = []Column{
+		informationColumn("TABLE_CATALOG", querypb.Type_VARCHAR, false),
+		informationColumn("TABLE_SCHEMA", querypb.Type_VARCHAR, false),
+		informationColumn("TABLE_NAME", querypb.Type_VARCHAR, false),
+		informationColumn("TABLE_TYPE", querypb.Type_VARCHAR, false),
+	}
+	columnsColumns = []Column{
+		informationColumn("TABLE_CATALOG", querypb.Type_VARCHAR, false),
+		informationColumn("TABLE_SCHEMA", querypb.Type_VARCHAR, false),
+		informationColumn("TABLE_NAME", querypb.Type_VARCHAR, false),
+		informationColumn("COLUMN_NAME", querypb.Type_VARCHAR, false),
+		informationColumn("ORDINAL_POSITION", querypb.Type_UINT64, false),
+		informationColumn("IS_NULLABLE", querypb.Type_VARCHAR, false),
+		informationColumn("DATA_TYPE", querypb.Type_VARCHAR, false),
+		informationColumn("CHARACTER_MAXIMUM_LENGTH", querypb.Type_INT64, true),
+		informationColumn("NUMERIC_PRECISION", querypb.Type_UINT64, true),
+		informationColumn("NUMERIC_SCALE", querypb.Type_UINT64, true),
+		informationColumn("COLUMN_TYPE", querypb.Type_VARCHAR, false),
+	}
+	statisticsColumns = []Column{
+		informationColumn("TABLE_CATALOG", querypb.Type_VARCHAR, false),
+		informationColumn("TABLE_SCHEMA", querypb.Type_VARCHAR, false),
+		informationColumn("TABLE_NAME", querypb.Type_VARCHAR, false),
+		informationColumn("NON_UNIQUE", querypb.Type_INT64, false),
+		informationColumn("INDEX_NAME", querypb.Type_VARCHAR, false),
+		informationColumn("SEQ_IN_INDEX", querypb.Type_UINT64, false),
+		informationColumn("COLUMN_NAME", querypb.Type_VARCHAR, false),
+	}
+)
+
+func NewInformationSchema(registry Registry) *InformationSchema {
+	return &InformationSchema{registry: registry}
+}
+
+func (c *InformationSchema) Table(name string) (Table, error) {
+	switch strings.ToUpper(name) {
+	case "SCHEMATA":
+		return &informationTable{columns: schemataColumns, rows: c.schemata}, nil
+	case "TABLES":
+		return &informationTable{columns: tablesColumns, rows: c.tables}, nil
+	case "COLUMNS":
+		return &informationTable{columns: columnsColumns, rows: c.columns}, nil
+	case "STATISTICS":
+		return &informationTable{columns: statisticsColumns, rows: c.statistics}, nil
+	}
+	return nil, errors.WithStack(ErrTableNotFound)
+}
+
+func (c *InformationSchema) Tables() ([]string, error) {
+	return []string{"COLUMNS", "SCHEMATA", "STATISTICS", "TABLES"}, nil
+}
+
+func (c *InformationSchema) schemata(_ context.Context) ([][]sqltypes.Value, error) {
+	names, err := c.catalogs()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]sqltypes.Value
+	for _, name := range names {
+		rows = append(rows, []sqltypes.Value{varchar("def"), varchar(name)})
+	}
+	return rows, nil
+}
+
+func (c *InformationSchema) tables(_ context.Context) ([][]sqltypes.Value, error) {
+	var rows [][]sqltypes.Value
+	err := c.each(func(catalog, name string, table Table) error {
+		typ := "BASE TABLE"
+		if catalog == InformationSchemaName {
+			typ = "SYSTEM VIEW"
+		} else if table == nil {
+			typ = "VIEW"
+		}
+		rows = append(rows, []sqltypes.Value{varchar("def"), varchar(catalog), varchar(name), varchar(typ)})
+		return nil
+	})
+	return rows, err
+}
+
+func (c *InformationSchema) columns(ctx context.Context) ([][]sqltypes.Value, error) {
+	var rows [][]sqltypes.Value
+	err := c.each(func(catalog, name string, table Table) error {
+		describer, ok := table.(Describer)
+		if !ok {
+			return nil
+		}
+		columns, err := describer.Columns(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i, col := range columns {
+			nullable := "NO"
+			if col.Nullable {
+				nullable = "YES"
+			}
+
+			length := sqltypes.NULL
+			if col.Length > 0 && (sqltypes.IsText(col.Type) || sqltypes.IsBinary(col.Type)) {
+				length = sqltypes.NewInt64(col.Length)
+			}
+			precision, scale := sqltypes.NULL, sqltypes.NULL
+			if col.Precision > 0 && (sqltypes.IsFloat(col.Type) || col.Type == querypb.Type_DECIMAL) {
+				precision = sqltypes.NewUint64(uint64(col.Precision))
+				scale = sqltypes.NewUint64(uint64(col.Scale))
+			}
+
+			dataType := strings.ToLower(strings.TrimPrefix(TypeName(col.Type), "UNSIGNED "))
+			columnType := dataType
+			if !length.IsNull() {
+				columnType = fmt.Sprintf("%s(%d)", dataType, col.Length)
+			} else if !precision.IsNull() {
+				columnType = fmt.Sprintf("%s(%d,%d)", dataType, col.Precision, col.Scale)
+			}
+			if sqltypes.IsUnsigned(col.Type) {
+				columnType += " unsigned"
+			}
+
+			rows = append(rows, []sqltypes.Value{
+				varchar("def"),
+				varchar(catalog),
+				varchar(name),
+				varchar(col.Name.Name.String()),
+				sqltypes.NewUint64(uint64(i + 1)),
+				varchar(nullable),
+				varchar(dataType),
+				length,
+				precision,
+				scale,
+				varchar(columnType),
+			})
+		}
+		return nil
+	})
+	return rows, err
+}
+
+func (c *InformationSchema) statistics(ctx context.Context) ([][]sqltypes.Value, error) {
+	var rows [][]sqltypes.Value
+	err := c.each(func(catalog, name string, table Table) error {
+		if table == nil {
+			return nil
+		}
+		indexes, err := table.Indexes(ctx)
+		if err != nil {
+			return err
+		}
+		for _, idx := range indexes {
+			for i, col := range idx.Columns {
+				rows = append(rows, []sqltypes.Value{
+					varchar("def"),
+					varchar(catalog),
+					varchar(name),
+					sqltypes.NewInt64(nonUnique(idx)),
+					varchar(idx.Name),
+					sqltypes.NewUint64(uint64(i + 1)),
+					varchar(col.Name.String()),
+				})
+			}
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// nonUnique is the NON_UNIQUE of the rows of idx: 0 for a primary key or unique index, and 1 otherwise.
+func nonUnique(idx Index) int64 {
+	if idx.Unique || strings.EqualFold(idx.Name, "PRIMARY") {
+		return 0
+	}
+	return 1
+}
+
+func (c *InformationSchema) catalogs() ([]string, error) {
+	var names []string
+	if lister, ok := c.registry.(CatalogLister); ok {
+		var err error
+		if names, err = lister.Catalogs(); err != nil {
+			return nil, err
+		}
+	}
+	if !slices.Contains(names, InformationSchemaName) {
+		names = append(names, InformationSchemaName)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (c *InformationSchema) catalog(name string) (Catalog, error) {
+	if name == InformationSchemaName {
+		return c, nil
+	}
+	return c.registry.Catalog(name)
+}
+
+func (c *InformationSchema) each(fn func(catalog, name string, table Table) error) error {
+	catalogs, err := c.catalogs()
+	if err != nil {
+		return err
+	}
+	for _, catalogName := range catalogs {
+		catalog, err := c.catalog(catalogName)
+		if err != nil {
+			return err
+		}
+		lister, ok := catalog.(TableLister)
+		if !ok {
+			continue
+		}
+		tables, err := lister.Tables()
+		if err != nil {
+			return err
+		}
+		for _, tableName := range tables {
+			table, err := catalog.Table(tableName)
+			if errors.Is(err, ErrTableNotFound) {
+				if viewer, ok := catalog.(Viewer); ok {
+					if _, e := viewer.View(tableName); e == nil {
+						table, err = nil, nil
+					}
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if err := fn(catalogName, tableName, table); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *informationTable) Columns(_ context.Context) ([]Column, error) {
+	return slices.Clone(t.columns), nil
+}
+
+func (t *informationTable) Indexes(_ context.Context) ([]Index, error) {
+	return nil, nil
+}
+
+func (t *informationTable) Scan(ctx context.Context, _ ...ScanHint) (Cursor, error) {
+	values, err := t.rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*sqlparser.ColName, 0, len(t.columns))
+	for _, col := range t.columns {
+		columns = append(columns, col.Name)
+	}
+
+	rows := make([]Row, 0, len(values))
+	for _, vals := range values {
+		rows = append(rows, Row{Columns: columns, Values: vals})
+	}
+	return NewInMemoryCursor(rows), nil
+}
+
+func informationColumn(name string, typ querypb.Type, nullable bool) Column {
+	return Column{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent(name)}, Type: typ, Nullable: nullable}
+}
+
+func varchar(val string) sqltypes.Value {
+	return sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte(val))
+}
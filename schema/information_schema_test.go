@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestInformationSchema_Table(t *testing.T) {
+	ctx := context.TODO()
+
+	users := NewInMemoryTable(nil)
+	_ = users.SetColumns(ctx, []Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_UINT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR, Nullable: true, Length: 255},
+	})
+	_ = users.SetIndex(ctx, Index{Name: "id", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}})
+	_ = users.SetIndex(ctx, Index{Name: "PRIMARY", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}})
+	_ = users.SetIndex(ctx, Index{Name: "name", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("name")}}, Unique: true})
+
+	registry := NewInMemoryRegistry(map[string]Catalog{
+		"app": NewInMemoryCatalog(map[string]Table{"users": users}),
+	})
+	catalog := NewInformationSchema(registry)
+
+	tests := []struct {
+		table  string
+		filter func(Row) bool
+		values [][]sqltypes.Value
+	}{
+		{
+			table: "SCHEMATA",
+			values: [][]sqltypes.Value{
+				{varchar("def"), varchar("app")},
+				{varchar("def"), varchar(InformationSchemaName)},
+			},
+		},
+		{
+			table: "tables",
+			filter: func(row Row) bool {
+				return row.Values[1].ToString() == "app"
+			},
+			values: [][]sqltypes.Value{
+				{varchar("def"), varchar("app"), varchar("users"), varchar("BASE TABLE")},
+			},
+		},
+		{
+			table: "COLUMNS",
+			filter: func(row Row) bool {
+				return row.Values[1].ToString() == "app"
+			},
+			values: [][]sqltypes.Value{
+				{varchar("def"), varchar("app"), varchar("users"), varchar("id"), sqltypes.NewUint64(1), varchar("NO"), varchar("bigint"), sqltypes.NULL, sqltypes.NULL, sqltypes.NULL, varchar("bigint unsigned")},
+				{varchar("def"), varchar("app"), varchar("users"), varchar("name"), sqltypes.NewUint64(2), varchar("YES"), varchar("varchar"), sqltypes.NewInt64(255), sqltypes.NULL, sqltypes.NULL, varchar("varchar(255)")},
+			},
+		},
+		{
+			table: "STATISTICS",
+			values: [][]sqltypes.Value{
+				{varchar("def"), varchar("app"), varchar("users"), sqltypes.NewInt64(1), varchar("id"), sqltypes.NewUint64(1), varchar("id")},
+				{varchar("def"), varchar("app"), varchar("users"), sqltypes.NewInt64(0), varchar("PRIMARY"), sqltypes.NewUint64(1), varchar("id")},
+				{varchar("def"), varchar("app"), varchar("users"), sqltypes.NewInt64(0), varchar("name"), sqltypes.NewUint64(1), varchar("name")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			table, err := catalog.Table(tt.table)
+			require.NoError(t, err)
+
+			cursor, err := table.Scan(ctx)
+			require.NoError(t, err)
+
+			rows, err := ReadAll(cursor)
+			require.NoError(t, err)
+
+			var values [][]sqltypes.Value
+			for _, row := range rows {
+				if tt.filter == nil || tt.filter(row) {
+					values = append(values, row.Values)
+				}
+			}
+			require.Equal(t, tt.values, values)
+		})
+	}
+}
+
+func TestInformationSchema_Tables(t *testing.T) {
+	catalog := NewInformationSchema(NewInMemoryRegistry(nil))
+
+	names, err := catalog.Tables()
+	require.NoError(t, err)
+	require.Equal(t, []string{"COLUMNS", "SCHEMATA", "STATISTICS", "TABLES"}, names)
+
+	for _, name := range names {
+		_, err := catalog.Table(name)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,359 @@
+package schema
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// JSONTable reads objects from newline-delimited JSON or from a JSON array. Its columns are the top-level keys of
+// all objects in the order they first appear, typed by their values: integers, floats and booleans as numbers,
+// strings as strings, and nested objects, arrays or values of mixed types as JSON. A key an object lacks is NULL.
+type JSONTable struct {
+	open    func() (io.ReadCloser, error)
+	columns []Column
+	once    sync.Once
+	err     error
+}
+
+type jsonDecoder struct {
+	decoder *json.Decoder
+	array   bool
+	started bool
+}
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+type jsonCursor struct {
+	reader  io.ReadCloser
+	decoder *jsonDecoder
+	table   *JSONTable
+	indexes []int
+	names   []*sqlparser.ColName
+	err     error
+	close   sync.Once
+}
+
+var ErrJSONRecord = errors.New("malformed json record")
+
+var (
+	_ Table     = (*JSONTable)(nil)
+	_ Describer = (*JSONTable)(nil)
+	_ Cursor    = (*jsonCursor)(nil)
+)
+
+// NewJSONTable returns a table reading the objects of the stream open returns, which is opened anew on every scan.
+func NewJSONTable(open func() (io.ReadCloser, error)) *JSONTable {
+	return &JSONTable{open: open}
+}
+
+// NewJSONFileTable returns a table reading the objects of the file at path.
+func NewJSONFileTable(path string) *JSONTable {
+	return NewJSONTable(func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+}
+
+// Columns reads the whole stream once to collect the keys of all of its objects.
+func (t *JSONTable) Columns(_ context.Context) ([]Column, error) {
+	t.once.Do(func() { t.columns, t.err = t.infer() })
+	return append([]Column(nil), t.columns...), t.err
+}
+
+func (t *JSONTable) Indexes(_ context.Context) ([]Index, error) {
+	return nil, nil
+}
+
+// Scan streams the objects of the stream, reading only the columns listed by the hints if any.
+func (t *JSONTable) Scan(ctx context.Context, hints ...ScanHint) (Cursor, error) {
+	columns, err := t.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &jsonCursor{
+		reader:  reader,
+		decoder: newJSONDecoder(reader),
+		table:   t,
+	}
+
+	projection := Projection(hints...)
+	for i, col := range columns {
+		if projection != nil && !slices.ContainsFunc(projection, func(name *sqlparser.ColName) bool {
+			return col.Name.Name.Equal(name.Name)
+		}) {
+			continue
+		}
+		cursor.indexes = append(cursor.indexes, i)
+		cursor.names = append(cursor.names, col.Name)
+	}
+	return cursor, nil
+}
+
+func (t *JSONTable) infer() ([]Column, error) {
+	reader, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return inferJSON(newJSONDecoder(reader))
+}
+
+func (c *jsonCursor) Next() (Row, error) {
+	if c.err != nil {
+		return Row{}, c.err
+	}
+	row, err := c.next()
+	if err != nil {
+		c.err = err
+		_ = c.Close()
+	}
+	return row, err
+}
+
+func (c *jsonCursor) Close() error {
+	var err error
+	c.close.Do(func() {
+		if c.err == nil {
+			c.err = io.EOF
+		}
+		err = c.reader.Close()
+	})
+	return err
+}
+
+func (c *jsonCursor) next() (Row, error) {
+	fields, err := c.decoder.Decode()
+	if err != nil {
+		return Row{}, err
+	}
+	values, err := jsonRecord(fields, c.table.columns, c.indexes)
+	if err != nil {
+		return Row{}, err
+	}
+	return Row{Columns: c.names, Values: values}, nil
+}
+
+func newJSONDecoder(r io.Reader) *jsonDecoder {
+	reader := bufio.NewReader(r)
+	d := &jsonDecoder{}
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			break
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = reader.ReadByte()
+			continue
+		case '[':
+			d.array = true
+		}
+		break
+	}
+	d.decoder = json.NewDecoder(reader)
+	d.decoder.UseNumber()
+	return d
+}
+
+// Decode returns the fields of the next object in the order they appear, or io.EOF after the last one.
+func (d *jsonDecoder) Decode() ([]jsonField, error) {
+	if d.array && !d.started {
+		d.started = true
+		if _, err := d.decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	if d.array && !d.decoder.More() {
+		if _, err := d.decoder.Token(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.Wrapf(ErrJSONRecord, "expected an object, got %v", token)
+	}
+
+	var fields []jsonField
+	for d.decoder.More() {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		var value any
+		if err := d.decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		if i := slices.IndexFunc(fields, func(f jsonField) bool { return f.key == key }); i >= 0 {
+			fields[i].value = value
+		} else {
+			fields = append(fields, jsonField{key: key, value: value})
+		}
+	}
+	if _, err := d.decoder.Token(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// inferJSON reads all objects of decoder to collect their keys as columns.
+func inferJSON(decoder *jsonDecoder) ([]Column, error) {
+	var columns []Column
+	var count int
+	seen := make(map[string]int)
+	for {
+		fields, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range columns {
+			if !slices.ContainsFunc(fields, func(f jsonField) bool { return f.key == columns[i].Name.Name.String() }) {
+				columns[i].Nullable = true
+			}
+		}
+		for _, field := range fields {
+			i, ok := seen[field.key]
+			if !ok {
+				i = len(columns)
+				seen[field.key] = i
+				columns = append(columns, Column{
+					Name:     &sqlparser.ColName{Name: sqlparser.NewColIdent(field.key)},
+					Type:     querypb.Type_NULL_TYPE,
+					Nullable: count > 0,
+				})
+			}
+			if field.value == nil {
+				columns[i].Nullable = true
+				continue
+			}
+			columns[i].Type = widenJSON(columns[i].Type, jsonType(field.value))
+		}
+		count++
+	}
+
+	for i := range columns {
+		if columns[i].Type == querypb.Type_NULL_TYPE {
+			columns[i].Type = querypb.Type_JSON
+		}
+	}
+	return columns, nil
+}
+
+// jsonRecord returns the values of the columns at indexes within the fields of an object.
+func jsonRecord(fields []jsonField, columns []Column, indexes []int) ([]sqltypes.Value, error) {
+	values := make([]sqltypes.Value, len(indexes))
+	for i, index := range indexes {
+		col := columns[index]
+		values[i] = sqltypes.NULL
+		for _, field := range fields {
+			if field.key != col.Name.Name.String() {
+				continue
+			}
+			var err error
+			if values[i], err = jsonValue(field.value, col.Type); err != nil {
+				return nil, errors.Wrapf(ErrJSONRecord, "column %s: %v", field.key, err)
+			}
+			break
+		}
+	}
+	return values, nil
+}
+
+func jsonType(value any) querypb.Type {
+	switch v := value.(type) {
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return querypb.Type_INT64
+		}
+		return querypb.Type_FLOAT64
+	case bool:
+		return querypb.Type_INT64
+	case string:
+		return querypb.Type_VARCHAR
+	}
+	return querypb.Type_JSON
+}
+
+// widenJSON returns the type holding values of both types, JSON for any but numbers of both kinds.
+func widenJSON(lhs, rhs querypb.Type) querypb.Type {
+	switch {
+	case lhs == querypb.Type_NULL_TYPE, lhs == rhs:
+		return rhs
+	case (lhs == querypb.Type_INT64 && rhs == querypb.Type_FLOAT64) || (lhs == querypb.Type_FLOAT64 && rhs == querypb.Type_INT64):
+		return querypb.Type_FLOAT64
+	}
+	return querypb.Type_JSON
+}
+
+func jsonValue(value any, typ querypb.Type) (sqltypes.Value, error) {
+	if value == nil {
+		return sqltypes.NULL, nil
+	}
+	if b, ok := value.(bool); ok && typ != querypb.Type_JSON {
+		if b {
+			return sqltypes.NewInt64(1), nil
+		}
+		return sqltypes.NewInt64(0), nil
+	}
+
+	switch typ {
+	case querypb.Type_INT64:
+		if n, ok := value.(json.Number); ok {
+			i, err := n.Int64()
+			if err != nil {
+				return sqltypes.NULL, err
+			}
+			return Marshal(i)
+		}
+	case querypb.Type_FLOAT64:
+		if n, ok := value.(json.Number); ok {
+			f, err := strconv.ParseFloat(n.String(), 64)
+			if err != nil {
+				return sqltypes.NULL, err
+			}
+			return Marshal(f)
+		}
+	case querypb.Type_VARCHAR:
+		if s, ok := value.(string); ok {
+			return Marshal(s)
+		}
+	case querypb.Type_JSON:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return sqltypes.NULL, err
+		}
+		return sqltypes.MakeTrusted(sqltypes.TypeJSON, data), nil
+	}
+	return sqltypes.NULL, errors.Errorf("%v is not %v", value, TypeName(typ))
+}
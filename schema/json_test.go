@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+func TestJSONTable_Columns(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		columns []Column
+	}{
+		{
+			name: "ndjson",
+			data: `{"id": 1, "name": "foo", "tags": ["a"]}` + "\n" + `{"id": 2, "score": 1.5, "name": null, "tags": {"b": true}}` + "\n",
+			columns: []Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR, Nullable: true},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("tags")}, Type: querypb.Type_JSON},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("score")}, Type: querypb.Type_FLOAT64, Nullable: true},
+			},
+		},
+		{
+			name: "array",
+			data: ` [{"id": 1, "value": 1}, {"id": 2.5, "value": "x"}, {"id": 3, "active": true}]`,
+			columns: []Column{
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_FLOAT64},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("value")}, Type: querypb.Type_JSON, Nullable: true},
+				{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("active")}, Type: querypb.Type_INT64, Nullable: true},
+			},
+		},
+		{
+			name: "empty",
+			data: "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewJSONTable(func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(tt.data)), nil
+			})
+
+			columns, err := table.Columns(context.TODO())
+			require.NoError(t, err)
+			require.Equal(t, tt.columns, columns)
+		})
+	}
+}
+
+func TestJSONTable_Scan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id": 1, "payload": {"a": 1}}`+"\n\n"+`{"id": 2, "user": "foo", "ok": false}`+"\n"), 0o644))
+
+	table := NewJSONFileTable(path)
+
+	t.Run("all", func(t *testing.T) {
+		cursor, err := table.Scan(ctx)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+
+		columns := []*sqlparser.ColName{
+			{Name: sqlparser.NewColIdent("id")},
+			{Name: sqlparser.NewColIdent("payload")},
+			{Name: sqlparser.NewColIdent("user")},
+			{Name: sqlparser.NewColIdent("ok")},
+		}
+		require.Equal(t, []Row{
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a":1}`)), sqltypes.NULL, sqltypes.NULL}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NULL, sqltypes.NewVarChar("foo"), sqltypes.NewInt64(0)}},
+		}, rows)
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		cursor, err := table.Scan(ctx, ScanHint{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("user")}}})
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+
+		columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("user")}}
+		require.Equal(t, []Row{
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NULL}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewVarChar("foo")}},
+		}, rows)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		table := NewJSONTable(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(`[{"id": 1}, 2]`)), nil
+		})
+
+		_, err := table.Scan(ctx)
+		require.ErrorIs(t, err, ErrJSONRecord)
+	})
+}
@@ -0,0 +1,697 @@
+package schema
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+	"github.com/pkg/errors"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// ParquetTable reads the rows of a Parquet file. Each top-level field of the file is a column, typed by its logical
+// type: timestamps as datetimes, decimals as decimals, strings as strings, and lists, maps and groups as JSON. A scan
+// decodes only the columns listed by the hints, and skips the row groups whose min/max statistics rule out their
+// ranges and filters.
+type ParquetTable struct {
+	path    string
+	columns []Column
+	fields  []parquetField
+	once    sync.Once
+	err     error
+}
+
+type parquetField struct {
+	name   string
+	leaves []int
+	node   parquet.Node
+	leaf   parquet.Type
+}
+
+type parquetCursor struct {
+	file    *os.File
+	pf      *parquet.File
+	groups  []int
+	fields  []parquetField
+	types   []querypb.Type
+	names   []*sqlparser.ColName
+	rows    parquet.RowReadSeekCloser
+	buffer  []parquet.Row
+	offset  int
+	length  int
+	err     error
+	close   sync.Once
+	current int
+}
+
+// parquetBound is a condition on a column that row group statistics can rule out.
+type parquetBound struct {
+	column   int
+	operator string
+	value    sqltypes.Value
+}
+
+var (
+	_ Table     = (*ParquetTable)(nil)
+	_ Describer = (*ParquetTable)(nil)
+	_ Cursor    = (*parquetCursor)(nil)
+)
+
+// NewParquetTable returns a table reading the Parquet file at path.
+func NewParquetTable(path string) *ParquetTable {
+	return &ParquetTable{path: path}
+}
+
+// Columns reads the schema of the file once.
+func (t *ParquetTable) Columns(_ context.Context) ([]Column, error) {
+	t.once.Do(func() { t.err = t.describe() })
+	return append([]Column(nil), t.columns...), t.err
+}
+
+// Indexes returns an index on each column of a primitive type, backed by the min/max statistics of row groups.
+func (t *ParquetTable) Indexes(ctx context.Context) ([]Index, error) {
+	columns, err := t.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []Index
+	for i, col := range columns {
+		if t.fields[i].leaf != nil {
+			indexes = append(indexes, Index{Name: col.Name.Name.String(), Columns: []*sqlparser.ColName{col.Name}})
+		}
+	}
+	return indexes, nil
+}
+
+// Scan reads the row groups of the file that may hold rows matching the hints, decoding only the columns they list.
+func (t *ParquetTable) Scan(ctx context.Context, hints ...ScanHint) (Cursor, error) {
+	columns, err := t.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	file, pf, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &parquetCursor{file: file, pf: pf, buffer: make([]parquet.Row, 64)}
+
+	projection := Projection(hints...)
+	for i, col := range columns {
+		if projection != nil && !slices.ContainsFunc(projection, func(name *sqlparser.ColName) bool {
+			return col.Name.Name.Equal(name.Name)
+		}) {
+			continue
+		}
+		cursor.fields = append(cursor.fields, t.fields[i])
+		cursor.types = append(cursor.types, col.Type)
+		cursor.names = append(cursor.names, col.Name)
+	}
+	if len(cursor.fields) == 0 {
+		cursor.fields = t.fields
+		for _, col := range columns {
+			cursor.types = append(cursor.types, col.Type)
+			cursor.names = append(cursor.names, col.Name)
+		}
+	}
+
+	bounds := t.bounds(hints)
+	for i := range pf.RowGroups() {
+		if !t.skip(pf.Metadata().RowGroups[i], bounds) {
+			cursor.groups = append(cursor.groups, i)
+		}
+	}
+	return cursor, nil
+}
+
+func (t *ParquetTable) open() (*os.File, *parquet.File, error) {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	pf, err := parquet.OpenFile(file, stat.Size(), parquet.SkipBloomFilters(true))
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	return file, pf, nil
+}
+
+func (t *ParquetTable) describe() error {
+	file, pf, err := t.open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, col := range pf.Root().Columns() {
+		field := parquetField{name: col.Name(), leaves: parquetLeaves(col), node: col}
+		column := Column{
+			Name:     &sqlparser.ColName{Name: sqlparser.NewColIdent(col.Name())},
+			Type:     querypb.Type_JSON,
+			Nullable: !col.Required(),
+		}
+		if col.Leaf() && !col.Repeated() {
+			field.leaf = col.Type()
+			column.Type = parquetType(col.Type())
+			if decimal := col.Type().LogicalType(); decimal != nil && decimal.Decimal != nil {
+				column.Precision = int64(decimal.Decimal.Precision)
+				column.Scale = int64(decimal.Decimal.Scale)
+			}
+		}
+		t.fields = append(t.fields, field)
+		t.columns = append(t.columns, column)
+	}
+	return nil
+}
+
+// bounds collects the conditions of hints on primitive columns.
+func (t *ParquetTable) bounds(hints []ScanHint) []parquetBound {
+	column := func(name string) int {
+		return slices.IndexFunc(t.columns, func(col Column) bool {
+			return col.Name.Name.EqualString(name)
+		})
+	}
+
+	var bounds []parquetBound
+	for _, hint := range hints {
+		if i := column(hint.Index); hint.Index != "" && i >= 0 && len(hint.Ranges) == 1 {
+			if rng := hint.Ranges[0]; rng.Min != nil {
+				bounds = append(bounds, parquetBound{column: i, operator: sqlparser.GreaterEqualStr, value: *rng.Min})
+			}
+			if rng := hint.Ranges[0]; rng.Max != nil {
+				bounds = append(bounds, parquetBound{column: i, operator: sqlparser.LessEqualStr, value: *rng.Max})
+			}
+		}
+		for _, filter := range hint.Filters {
+			if i := column(filter.Column.Name.String()); i >= 0 && len(filter.Values) == 1 {
+				bounds = append(bounds, parquetBound{column: i, operator: filter.Operator, value: filter.Values[0]})
+			}
+		}
+	}
+	return bounds
+}
+
+// skip reports whether the statistics of group rule out a row matching all bounds.
+func (t *ParquetTable) skip(group format.RowGroup, bounds []parquetBound) bool {
+	for _, bound := range bounds {
+		field := t.fields[bound.column]
+		if field.leaf == nil || bound.value.IsNull() {
+			continue
+		}
+
+		stats := group.Columns[field.leaves[0]].MetaData.Statistics
+		minimum, maximum := stats.MinValue, stats.MaxValue
+		if minimum == nil || maximum == nil {
+			continue
+		}
+		lower, err := parquetValue(field.leaf.Kind().Value(minimum), field.leaf, t.columns[bound.column].Type)
+		if err != nil {
+			continue
+		}
+		upper, err := parquetValue(field.leaf.Kind().Value(maximum), field.leaf, t.columns[bound.column].Type)
+		if err != nil {
+			continue
+		}
+
+		typ := t.columns[bound.column].Type
+		low, ok1 := parquetCompare(typ, bound.value, lower)
+		high, ok2 := parquetCompare(typ, bound.value, upper)
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		switch bound.operator {
+		case sqlparser.EqualStr:
+			if low < 0 || high > 0 {
+				return true
+			}
+		case sqlparser.LessThanStr:
+			if low <= 0 {
+				return true
+			}
+		case sqlparser.LessEqualStr:
+			if low < 0 {
+				return true
+			}
+		case sqlparser.GreaterThanStr:
+			if high >= 0 {
+				return true
+			}
+		case sqlparser.GreaterEqualStr:
+			if high > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *parquetCursor) Next() (Row, error) {
+	if c.err != nil {
+		return Row{}, c.err
+	}
+	row, err := c.next()
+	if err != nil {
+		c.err = err
+		_ = c.Close()
+	}
+	return row, err
+}
+
+func (c *parquetCursor) Close() error {
+	var err error
+	c.close.Do(func() {
+		if c.err == nil {
+			c.err = io.EOF
+		}
+		if c.rows != nil {
+			_ = c.rows.Close()
+		}
+		err = c.file.Close()
+	})
+	return err
+}
+
+func (c *parquetCursor) next() (Row, error) {
+	for c.offset == c.length {
+		if err := c.read(); err != nil {
+			return Row{}, err
+		}
+	}
+
+	row := c.buffer[c.offset]
+	c.offset++
+
+	values := make([]sqltypes.Value, len(c.fields))
+	for i, field := range c.fields {
+		var column []parquet.Value
+		for _, v := range row {
+			if v.Column() >= field.leaves[0] && v.Column() <= field.leaves[len(field.leaves)-1] {
+				column = append(column, v)
+			}
+		}
+
+		var err error
+		if field.leaf != nil {
+			values[i], err = parquetValue(column[0], field.leaf, c.types[i])
+		} else {
+			values[i], err = parquetNested(field, column)
+		}
+		if err != nil {
+			return Row{}, errors.Wrapf(err, "column %s", field.name)
+		}
+	}
+	return Row{Columns: c.names, Values: values}, nil
+}
+
+// read fills the buffer with the next rows, moving on to the next row group once the current one is read.
+func (c *parquetCursor) read() error {
+	if c.rows == nil {
+		if c.current >= len(c.groups) {
+			return io.EOF
+		}
+		chunks := c.pf.RowGroups()[c.groups[c.current]].ColumnChunks()
+		var projected []parquet.ColumnChunk
+		for _, field := range c.fields {
+			for _, leaf := range field.leaves {
+				projected = append(projected, chunks[leaf])
+			}
+		}
+		c.rows = parquet.NewColumnChunkRowReader(projected)
+		c.current++
+	}
+
+	for i := range c.buffer {
+		c.buffer[i] = c.buffer[i][:0]
+	}
+	n, err := c.rows.ReadRows(c.buffer)
+	c.offset, c.length = 0, n
+	if errors.Is(err, io.EOF) {
+		_ = c.rows.Close()
+		c.rows = nil
+		return nil
+	}
+	return err
+}
+
+func parquetLeaves(col *parquet.Column) []int {
+	if col.Leaf() {
+		return []int{col.Index()}
+	}
+	var leaves []int
+	for _, child := range col.Columns() {
+		leaves = append(leaves, parquetLeaves(child)...)
+	}
+	return leaves
+}
+
+// parquetWidth returns the number of leaves of node.
+func parquetWidth(node parquet.Node) int {
+	if node.Leaf() {
+		return 1
+	}
+	n := 0
+	for _, field := range node.Fields() {
+		n += parquetWidth(field)
+	}
+	return n
+}
+
+// parquetType returns the type of the values of a primitive Parquet type.
+func parquetType(typ parquet.Type) querypb.Type {
+	logical := typ.LogicalType()
+	switch {
+	case logical == nil:
+	case logical.Decimal != nil:
+		return querypb.Type_DECIMAL
+	case logical.Date != nil:
+		return querypb.Type_DATE
+	case logical.Time != nil:
+		return querypb.Type_TIME
+	case logical.Timestamp != nil:
+		return querypb.Type_DATETIME
+	case logical.UTF8 != nil, logical.Enum != nil, logical.UUID != nil:
+		return querypb.Type_VARCHAR
+	case logical.Json != nil:
+		return querypb.Type_JSON
+	case logical.Float16 != nil:
+		return querypb.Type_FLOAT32
+	case logical.Integer != nil:
+		switch bits, signed := logical.Integer.BitWidth, logical.Integer.IsSigned; {
+		case bits == 8 && signed:
+			return querypb.Type_INT8
+		case bits == 8:
+			return querypb.Type_UINT8
+		case bits == 16 && signed:
+			return querypb.Type_INT16
+		case bits == 16:
+			return querypb.Type_UINT16
+		case bits == 32 && signed:
+			return querypb.Type_INT32
+		case bits == 32:
+			return querypb.Type_UINT32
+		case signed:
+			return querypb.Type_INT64
+		default:
+			return querypb.Type_UINT64
+		}
+	}
+
+	switch typ.Kind() {
+	case parquet.Boolean, parquet.Int64:
+		return querypb.Type_INT64
+	case parquet.Int32:
+		return querypb.Type_INT32
+	case parquet.Int96:
+		return querypb.Type_DATETIME
+	case parquet.Float:
+		return querypb.Type_FLOAT32
+	case parquet.Double:
+		return querypb.Type_FLOAT64
+	}
+	return querypb.Type_VARBINARY
+}
+
+// parquetValue converts a value of a primitive Parquet type into a value of type typ.
+func parquetValue(value parquet.Value, ptyp parquet.Type, typ querypb.Type) (sqltypes.Value, error) {
+	if value.IsNull() {
+		return sqltypes.NULL, nil
+	}
+
+	logical := ptyp.LogicalType()
+	switch typ {
+	case querypb.Type_DECIMAL:
+		unscaled := new(big.Int)
+		switch value.Kind() {
+		case parquet.Int32:
+			unscaled.SetInt64(int64(value.Int32()))
+		case parquet.Int64:
+			unscaled.SetInt64(value.Int64())
+		default:
+			data := value.ByteArray()
+			unscaled.SetBytes(data)
+			if len(data) > 0 && data[0]&0x80 != 0 {
+				unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+			}
+		}
+		return sqltypes.MakeTrusted(sqltypes.Decimal, []byte(parquetDecimal(unscaled, int(logical.Decimal.Scale)))), nil
+	case querypb.Type_DATE:
+		date := time.Unix(int64(value.Int32())*24*60*60, 0).UTC()
+		return sqltypes.MakeTrusted(sqltypes.Date, []byte(date.Format(time.DateOnly))), nil
+	case querypb.Type_TIME:
+		n := value.Int64()
+		if value.Kind() == parquet.Int32 {
+			n = int64(value.Int32())
+		}
+		clock := time.Unix(0, 0).UTC().Add(parquetDuration(n, logical.Time.Unit))
+		return sqltypes.MakeTrusted(sqltypes.Time, []byte(clock.Format("15:04:05.999999999"))), nil
+	case querypb.Type_DATETIME:
+		if value.Kind() == parquet.Int96 {
+			i96 := value.Int96()
+			nanos := int64(uint64(i96[1])<<32 | uint64(i96[0]))
+			return Marshal(time.Unix((int64(i96[2])-2440588)*24*60*60, nanos).UTC())
+		}
+		return Marshal(time.Unix(0, 0).UTC().Add(parquetDuration(value.Int64(), logical.Timestamp.Unit)))
+	case querypb.Type_VARCHAR:
+		if logical.UUID != nil {
+			data := hex.EncodeToString(value.ByteArray())
+			if len(data) == 32 {
+				data = data[:8] + "-" + data[8:12] + "-" + data[12:16] + "-" + data[16:20] + "-" + data[20:]
+			}
+			return sqltypes.NewVarChar(data), nil
+		}
+		return sqltypes.MakeTrusted(sqltypes.VarChar, slices.Clone(value.ByteArray())), nil
+	case querypb.Type_JSON:
+		return sqltypes.MakeTrusted(sqltypes.TypeJSON, slices.Clone(value.ByteArray())), nil
+	case querypb.Type_VARBINARY:
+		return sqltypes.MakeTrusted(sqltypes.VarBinary, slices.Clone(value.ByteArray())), nil
+	}
+
+	switch value.Kind() {
+	case parquet.Boolean:
+		if value.Boolean() {
+			return sqltypes.NewInt64(1), nil
+		}
+		return sqltypes.NewInt64(0), nil
+	case parquet.Int32:
+		if sqltypes.IsUnsigned(typ) {
+			return sqltypes.MakeTrusted(typ, strconv.AppendUint(nil, uint64(value.Uint32()), 10)), nil
+		}
+		return sqltypes.MakeTrusted(typ, strconv.AppendInt(nil, int64(value.Int32()), 10)), nil
+	case parquet.Int64:
+		if sqltypes.IsUnsigned(typ) {
+			return sqltypes.MakeTrusted(typ, strconv.AppendUint(nil, value.Uint64(), 10)), nil
+		}
+		return sqltypes.MakeTrusted(typ, strconv.AppendInt(nil, value.Int64(), 10)), nil
+	case parquet.Float:
+		return sqltypes.MakeTrusted(typ, strconv.AppendFloat(nil, float64(value.Float()), 'g', -1, 32)), nil
+	case parquet.Double:
+		return sqltypes.MakeTrusted(typ, strconv.AppendFloat(nil, value.Double(), 'g', -1, 64)), nil
+	}
+	return sqltypes.NULL, errors.Errorf("unsupported parquet value %v", value)
+}
+
+// parquetNested converts the values of the leaves of a list, map or group into JSON.
+func parquetNested(field parquetField, values []parquet.Value) (sqltypes.Value, error) {
+	leaves := make([][]parquet.Value, len(field.leaves))
+	for _, v := range values {
+		i := slices.Index(field.leaves, v.Column())
+		leaves[i] = append(leaves[i], v)
+	}
+
+	value, err := parquetAssemble(field.node, leaves, 0, 0)
+	if err != nil || value == nil {
+		return sqltypes.NULL, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return sqltypes.NULL, err
+	}
+	return sqltypes.MakeTrusted(sqltypes.TypeJSON, data), nil
+}
+
+// parquetAssemble rebuilds the value of node from the values of its leaves, where def and rep are the definition and
+// repetition levels of its parent.
+func parquetAssemble(node parquet.Node, leaves [][]parquet.Value, def, rep int) (any, error) {
+	if node.Optional() || node.Repeated() {
+		def++
+	}
+	if !node.Repeated() {
+		if leaves[0][0].DefinitionLevel() < def {
+			return nil, nil
+		}
+		return parquetAssembleOne(node, leaves, def, rep)
+	}
+
+	rep++
+	items := []any{}
+	if leaves[0][0].DefinitionLevel() < def {
+		return items, nil
+	}
+
+	// A value repeated at the level of node starts a new item, and a deeper one belongs to the current item.
+	offsets := make([]int, len(leaves))
+	for offsets[0] < len(leaves[0]) {
+		item := make([][]parquet.Value, len(leaves))
+		for i, values := range leaves {
+			end := offsets[i] + 1
+			for end < len(values) && values[end].RepetitionLevel() > rep {
+				end++
+			}
+			item[i] = values[offsets[i]:end]
+			offsets[i] = end
+		}
+
+		value, err := parquetAssembleOne(node, item, def, rep)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+// parquetAssembleOne rebuilds a single defined value of node.
+func parquetAssembleOne(node parquet.Node, leaves [][]parquet.Value, def, rep int) (any, error) {
+	if node.Leaf() {
+		value, err := parquetValue(leaves[0][0], node.Type(), parquetType(node.Type()))
+		switch {
+		case err != nil:
+			return nil, err
+		case value.IsIntegral(), value.IsFloat(), value.Type() == querypb.Type_DECIMAL:
+			return json.Number(value.ToString()), nil
+		case value.Type() == querypb.Type_JSON:
+			return json.RawMessage(value.Raw()), nil
+		}
+		return value.ToString(), nil
+	}
+
+	record := map[string]any{}
+	offset := 0
+	for _, field := range node.Fields() {
+		n := parquetWidth(field)
+		value, err := parquetAssemble(field, leaves[offset:offset+n], def, rep)
+		if err != nil {
+			return nil, err
+		}
+		record[field.Name()] = value
+		offset += n
+	}
+
+	logical := node.Type().LogicalType()
+	switch {
+	case logical != nil && logical.List != nil && len(record) == 1:
+		// LIST wraps its elements in a repeated group of one field.
+		var items []any
+		for _, value := range record {
+			items, _ = value.([]any)
+		}
+		for i, item := range items {
+			if element, ok := item.(map[string]any); ok && len(element) == 1 {
+				for _, value := range element {
+					items[i] = value
+				}
+			}
+		}
+		return items, nil
+	case logical != nil && logical.Map != nil && len(record) == 1:
+		// MAP wraps its entries in a repeated group of a key and a value.
+		entries := map[string]any{}
+		for _, value := range record {
+			items, _ := value.([]any)
+			for _, item := range items {
+				if entry, ok := item.(map[string]any); ok {
+					entries[fmt.Sprint(entry["key"])] = entry["value"]
+				}
+			}
+		}
+		return entries, nil
+	}
+	return record, nil
+}
+
+func parquetDuration(n int64, unit format.TimeUnit) time.Duration {
+	switch {
+	case unit.Millis != nil:
+		return time.Duration(n) * time.Millisecond
+	case unit.Micros != nil:
+		return time.Duration(n) * time.Microsecond
+	}
+	return time.Duration(n)
+}
+
+func parquetDecimal(unscaled *big.Int, scale int) string {
+	digits := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if unscaled.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits
+}
+
+// parquetCompare compares two values of a column of type typ, reporting whether they are comparable.
+func parquetCompare(typ querypb.Type, lhs, rhs sqltypes.Value) (int, bool) {
+	switch {
+	case sqltypes.IsIntegral(typ), sqltypes.IsFloat(typ), typ == querypb.Type_DECIMAL:
+		l, err1 := strconv.ParseFloat(lhs.ToString(), 64)
+		r, err2 := strconv.ParseFloat(rhs.ToString(), 64)
+		if err1 != nil || err2 != nil {
+			return 0, false
+		}
+		switch {
+		case l < r:
+			return -1, true
+		case l > r:
+			return 1, true
+		}
+		return 0, true
+	case typ == querypb.Type_DATE || typ == querypb.Type_DATETIME:
+		l, ok1 := parquetTime(lhs.ToString())
+		r, ok2 := parquetTime(rhs.ToString())
+		if !ok1 || !ok2 {
+			return 0, false
+		}
+		return l.Compare(r), true
+	case typ == querypb.Type_VARCHAR:
+		if !lhs.IsText() && !lhs.IsQuoted() {
+			return 0, false
+		}
+		return strings.Compare(lhs.ToString(), rhs.ToString()), true
+	}
+	return 0, false
+}
+
+func parquetTime(s string) (time.Time, bool) {
+	for _, layout := range csvLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+type parquetRecord struct {
+	ID      int64             `parquet:"id"`
+	Name    *string           `parquet:"name,optional"`
+	Price   int64             `parquet:"price,decimal(2:18)"`
+	Created time.Time         `parquet:"created,timestamp(millisecond)"`
+	Tags    []string          `parquet:"tags,list"`
+	Attrs   map[string]string `parquet:"attrs"`
+}
+
+func newParquetFile(t *testing.T, records []parquetRecord) string {
+	path := filepath.Join(t.TempDir(), "records.parquet")
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	writer := parquet.NewGenericWriter[parquetRecord](file, parquet.MaxRowsPerRowGroup(2))
+	_, err = writer.Write(records)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return path
+}
+
+func TestParquetTable_Columns(t *testing.T) {
+	table := NewParquetTable(newParquetFile(t, nil))
+
+	columns, err := table.Columns(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR, Nullable: true},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("price")}, Type: querypb.Type_DECIMAL, Precision: 18, Scale: 2},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("created")}, Type: querypb.Type_DATETIME},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("tags")}, Type: querypb.Type_JSON},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("attrs")}, Type: querypb.Type_JSON},
+	}, columns)
+
+	indexes, err := table.Indexes(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, indexes, 4)
+
+	_, err = NewParquetTable(filepath.Join(t.TempDir(), "missing.parquet")).Columns(context.TODO())
+	require.Error(t, err)
+}
+
+func TestParquetTable_Scan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	foo := "foo"
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var records []parquetRecord
+	for i := range 5 {
+		records = append(records, parquetRecord{
+			ID:      int64(i),
+			Price:   int64(i*100 + 5),
+			Created: created.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	records[0].Name = &foo
+	records[0].Tags = []string{"a", "b"}
+	records[0].Attrs = map[string]string{"k": "v"}
+
+	table := NewParquetTable(newParquetFile(t, records))
+
+	t.Run("all", func(t *testing.T) {
+		cursor, err := table.Scan(ctx)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Len(t, rows, 5)
+		require.Equal(t, []sqltypes.Value{
+			sqltypes.NewInt64(0),
+			sqltypes.NewVarChar("foo"),
+			sqltypes.MakeTrusted(sqltypes.Decimal, []byte("0.05")),
+			sqltypes.MakeTrusted(sqltypes.Datetime, []byte(created.Format(time.RFC3339Nano))),
+			sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`["a","b"]`)),
+			sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"k":"v"}`)),
+		}, rows[0].Values)
+		require.Equal(t, sqltypes.NULL, rows[1].Values[1])
+		require.Equal(t, sqltypes.MakeTrusted(sqltypes.Decimal, []byte("4.05")), rows[4].Values[2])
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		cursor, err := table.Scan(ctx, ScanHint{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("name")}, {Name: sqlparser.NewColIdent("id")}}})
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Len(t, rows, 5)
+		require.Equal(t, Row{
+			Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}},
+			Values:  []sqltypes.Value{sqltypes.NewInt64(0), sqltypes.NewVarChar("foo")},
+		}, rows[0])
+
+		cursor, err = table.Scan(ctx, ScanHint{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("attrs")}, {Name: sqlparser.NewColIdent("tags")}}})
+		require.NoError(t, err)
+
+		rows, err = ReadAll(cursor)
+		require.NoError(t, err)
+		require.Len(t, rows, 5)
+		require.Equal(t, []sqltypes.Value{
+			sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[]`)),
+			sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{}`)),
+		}, rows[1].Values)
+	})
+
+	t.Run("row groups", func(t *testing.T) {
+		ids := func(rows []Row) []string {
+			var ids []string
+			for _, row := range rows {
+				ids = append(ids, row.Values[0].ToString())
+			}
+			return ids
+		}
+
+		three := sqltypes.NewInt64(3)
+		tests := []struct {
+			name   string
+			hint   ScanHint
+			expect []string
+		}{
+			{
+				name:   "range",
+				hint:   ScanHint{Index: "id", Ranges: []Range{{Min: &three, Max: &three}}},
+				expect: []string{"2", "3"},
+			},
+			{
+				name: "filter",
+				hint: ScanHint{Filters: []Filter{{
+					Column:   &sqlparser.ColName{Name: sqlparser.NewColIdent("created")},
+					Operator: sqlparser.GreaterEqualStr,
+					Values:   []sqltypes.Value{sqltypes.NewVarChar("2024-01-02 07:00:00")},
+				}}},
+				expect: []string{"4"},
+			},
+			{
+				name: "incomparable",
+				hint: ScanHint{Filters: []Filter{{
+					Column:   &sqlparser.ColName{Name: sqlparser.NewColIdent("id")},
+					Operator: sqlparser.EqualStr,
+					Values:   []sqltypes.Value{sqltypes.NewVarChar("x")},
+				}}},
+				expect: []string{"0", "1", "2", "3", "4"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.hint.Columns = []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}
+
+				cursor, err := table.Scan(ctx, tt.hint)
+				require.NoError(t, err)
+
+				rows, err := ReadAll(cursor)
+				require.NoError(t, err)
+				require.Equal(t, tt.expect, ids(rows))
+			})
+		}
+	})
+}
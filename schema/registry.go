@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"slices"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -10,6 +11,11 @@ type Registry interface {
 	Catalog(name string) (Catalog, error)
 }
 
+// CatalogLister is implemented by registries that can enumerate their catalogs.
+type CatalogLister interface {
+	Catalogs() ([]string, error)
+}
+
 type CompositeRegistry struct {
 	registries []Registry
 }
@@ -22,8 +28,10 @@ type InMemoryRegistry struct {
 var ErrCatalogNotFound = errors.New("catalog not found")
 
 var (
-	_ Registry = (*CompositeRegistry)(nil)
-	_ Registry = (*InMemoryRegistry)(nil)
+	_ Registry      = (*CompositeRegistry)(nil)
+	_ Registry      = (*InMemoryRegistry)(nil)
+	_ CatalogLister = (*CompositeRegistry)(nil)
+	_ CatalogLister = (*InMemoryRegistry)(nil)
 )
 
 func NewCompositeRegistry(registries ...Registry) *CompositeRegistry {
@@ -50,6 +58,27 @@ func (r *CompositeRegistry) Catalog(name string) (Catalog, error) {
 	return nil, errors.WithStack(ErrCatalogNotFound)
 }
 
+func (r *CompositeRegistry) Catalogs() ([]string, error) {
+	var names []string
+	for _, registry := range r.registries {
+		lister, ok := registry.(CatalogLister)
+		if !ok {
+			continue
+		}
+		catalogs, err := lister.Catalogs()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range catalogs {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
 func (r *InMemoryRegistry) Catalog(name string) (Catalog, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -60,3 +89,15 @@ func (r *InMemoryRegistry) Catalog(name string) (Catalog, error) {
 	}
 	return catalog, nil
 }
+
+func (r *InMemoryRegistry) Catalogs() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.catalogs))
+	for name := range r.catalogs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
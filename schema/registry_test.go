@@ -35,3 +35,30 @@ func TestInMemoryRegistry_Catalog(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, catalog, ctl)
 }
+
+func TestCompositeRegistry_Catalogs(t *testing.T) {
+	upper := NewInMemoryRegistry(map[string]Catalog{
+		"b": NewInMemoryCatalog(nil),
+	})
+	lower := NewInMemoryRegistry(map[string]Catalog{
+		"a": NewInMemoryCatalog(nil),
+		"b": NewInMemoryCatalog(nil),
+	})
+
+	registry := NewCompositeRegistry(upper, lower)
+
+	names, err := registry.Catalogs()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestInMemoryRegistry_Catalogs(t *testing.T) {
+	registry := NewInMemoryRegistry(map[string]Catalog{
+		"b": NewInMemoryCatalog(nil),
+		"a": NewInMemoryCatalog(nil),
+	})
+
+	names, err := registry.Catalogs()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, names)
+}
@@ -11,15 +11,33 @@ type Row struct {
 	Children []Row
 }
 
+// Match reports whether the column col is referred to by name, which may be partially qualified.
+func Match(col, name *sqlparser.ColName) bool {
+	return col.Name.Equal(name.Name) && MatchTable(col.Qualifier, name.Qualifier)
+}
+
+// MatchTable reports whether the table table is referred to by name, which may be partially qualified.
+func MatchTable(table, name sqlparser.TableName) bool {
+	if !name.Name.IsEmpty() && table.Name != name.Name {
+		return false
+	}
+	if !name.Qualifier.IsEmpty() && table.Qualifier != name.Qualifier {
+		return false
+	}
+	return true
+}
+
 func (r *Row) Get(name *sqlparser.ColName) (sqltypes.Value, bool) {
 	for i, col := range r.Columns {
-		if (name.Qualifier.IsEmpty() && col.Name.Equal(name.Name)) || (!name.Qualifier.IsEmpty() && col.Equal(name)) {
+		if Match(col, name) {
 			return r.Values[i], true
 		}
 	}
 	return sqltypes.NULL, false
 }
 
+// IsEmpty reports whether r is the zero row, which a mapping drops. A row without columns, such as the one DUAL
+// produces, is not empty if its slices are non-nil.
 func (r *Row) IsEmpty() bool {
-	return len(r.Columns) == 0 && len(r.Values) == 0 && len(r.Children) == 0
+	return r.Columns == nil && r.Values == nil && r.Children == nil
 }
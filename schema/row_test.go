@@ -25,6 +25,33 @@ func TestRow_IsEmpty(t *testing.T) {
 		Values:  []sqltypes.Value{sqltypes.NewInt64(0), sqltypes.MakeTrusted(sqltypes.VarChar, []byte("foo"))},
 	}
 	row2 := Row{}
+	row3 := Row{Columns: []*sqlparser.ColName{}, Values: []sqltypes.Value{}}
 	require.False(t, row1.IsEmpty())
 	require.True(t, row2.IsEmpty())
+	require.False(t, row3.IsEmpty())
+}
+
+func TestMatch(t *testing.T) {
+	col := &sqlparser.ColName{
+		Name:      sqlparser.NewColIdent("id"),
+		Qualifier: sqlparser.TableName{Qualifier: sqlparser.NewTableIdent("app"), Name: sqlparser.NewTableIdent("users")},
+	}
+
+	tests := []struct {
+		name  *sqlparser.ColName
+		match bool
+	}{
+		{name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, match: true},
+		{name: &sqlparser.ColName{Name: sqlparser.NewColIdent("ID"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("users")}}, match: true},
+		{name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: col.Qualifier}, match: true},
+		{name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Name: sqlparser.NewTableIdent("events")}}, match: false},
+		{name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id"), Qualifier: sqlparser.TableName{Qualifier: sqlparser.NewTableIdent("other"), Name: sqlparser.NewTableIdent("users")}}, match: false},
+		{name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(sqlparser.String(tt.name), func(t *testing.T) {
+			require.Equal(t, tt.match, Match(col, tt.name))
+		})
+	}
 }
@@ -0,0 +1,553 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// Dialect describes how to talk to a database behind database/sql: how it quotes identifiers and numbers
+// placeholders, where it lists tables and indexes, and how its type names map to types.
+type Dialect interface {
+	// Quote returns name as a quoted identifier.
+	Quote(name string) string
+	// Placeholder returns the placeholder of the n-th argument, counting from 1.
+	Placeholder(n int) string
+	// TablesQuery returns a query listing the names of the tables of the current schema.
+	TablesQuery() string
+	// TableQuery returns a query selecting the name of the table of the current schema named by its only argument.
+	TableQuery() string
+	// IndexesQuery returns a query listing the index name and column name of each column of the indexes of the
+	// table named by its only argument, in the order of the columns within each index.
+	IndexesQuery() string
+	// ParseType returns the type with the given database type name.
+	ParseType(name string) querypb.Type
+}
+
+// MySQLDialect is the dialect of MySQL and MariaDB.
+type MySQLDialect struct{}
+
+// PostgresDialect is the dialect of PostgreSQL.
+type PostgresDialect struct{}
+
+// SQLCatalog exposes the tables of a database reached through database/sql.
+type SQLCatalog struct {
+	db      *sql.DB
+	dialect Dialect
+	tables  map[string]*SQLTable
+	mu      sync.Mutex
+}
+
+// SQLTable reads a table of a database reached through database/sql. A scan becomes a single parameterized query
+// that selects the projected columns and takes over the ranges, filters and limit of the hints it can express.
+type SQLTable struct {
+	db      *sql.DB
+	dialect Dialect
+	name    string
+	columns []Column
+	indexes []Index
+	mu      sync.Mutex
+}
+
+type sqlCursor struct {
+	rows  *sql.Rows
+	names []*sqlparser.ColName
+	types []querypb.Type
+	err   error
+	close sync.Once
+}
+
+var (
+	_ Dialect     = MySQLDialect{}
+	_ Dialect     = PostgresDialect{}
+	_ Catalog     = (*SQLCatalog)(nil)
+	_ TableLister = (*SQLCatalog)(nil)
+	_ Table       = (*SQLTable)(nil)
+	_ Describer   = (*SQLTable)(nil)
+	_ Cursor      = (*sqlCursor)(nil)
+)
+
+var postgresTypes = map[string]querypb.Type{
+	"BOOL":        querypb.Type_INT8,
+	"INT2":        querypb.Type_INT16,
+	"INT4":        querypb.Type_INT32,
+	"INT8":        querypb.Type_INT64,
+	"FLOAT4":      querypb.Type_FLOAT32,
+	"FLOAT8":      querypb.Type_FLOAT64,
+	"NUMERIC":     querypb.Type_DECIMAL,
+	"BPCHAR":      querypb.Type_CHAR,
+	"VARCHAR":     querypb.Type_VARCHAR,
+	"TEXT":        querypb.Type_TEXT,
+	"BYTEA":       querypb.Type_VARBINARY,
+	"DATE":        querypb.Type_DATE,
+	"TIME":        querypb.Type_TIME,
+	"TIMESTAMP":   querypb.Type_DATETIME,
+	"TIMESTAMPTZ": querypb.Type_TIMESTAMP,
+	"JSON":        querypb.Type_JSON,
+	"JSONB":       querypb.Type_JSON,
+}
+
+// NewSQLCatalog returns a catalog with a table for each table of the current schema of db.
+func NewSQLCatalog(db *sql.DB, dialect Dialect) *SQLCatalog {
+	return &SQLCatalog{
+		db:      db,
+		dialect: dialect,
+		tables:  make(map[string]*SQLTable),
+	}
+}
+
+// NewSQLTable returns a table reading the table named name of db.
+func NewSQLTable(db *sql.DB, dialect Dialect, name string) *SQLTable {
+	return &SQLTable{db: db, dialect: dialect, name: name}
+}
+
+// Table returns the table named name, which is looked up in the database the first time it is asked for.
+func (c *SQLCatalog) Table(name string) (Table, error) {
+	c.mu.Lock()
+	table, ok := c.tables[name]
+	c.mu.Unlock()
+	if ok {
+		return table, nil
+	}
+
+	var found string
+	if err := c.db.QueryRow(c.dialect.TableQuery(), name).Scan(&found); errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.WithStack(ErrTableNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if table, ok = c.tables[name]; !ok {
+		table = NewSQLTable(c.db, c.dialect, name)
+		c.tables[name] = table
+	}
+	return table, nil
+}
+
+func (c *SQLCatalog) Tables() ([]string, error) {
+	rows, err := c.db.Query(c.dialect.TablesQuery())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Columns describes the columns of the result of a query selecting no rows from the table.
+func (t *SQLTable) Columns(ctx context.Context) ([]Column, error) {
+	if err := t.load(ctx); err != nil {
+		return nil, err
+	}
+	return append([]Column(nil), t.columns...), nil
+}
+
+func (t *SQLTable) Indexes(ctx context.Context) ([]Index, error) {
+	if err := t.load(ctx); err != nil {
+		return nil, err
+	}
+	return append([]Index(nil), t.indexes...), nil
+}
+
+// Scan queries the table, selecting only the columns listed by the hints if any. The ranges and filters of the
+// hints on known columns become the WHERE clause, and the limit becomes a LIMIT clause if no filter is left out.
+func (t *SQLTable) Scan(ctx context.Context, hints ...ScanHint) (Cursor, error) {
+	columns, err := t.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []Column
+	if projection := Projection(hints...); projection != nil {
+		for _, col := range columns {
+			if slices.ContainsFunc(projection, func(name *sqlparser.ColName) bool {
+				return col.Name.Name.Equal(name.Name)
+			}) {
+				selected = append(selected, col)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		selected = columns
+	}
+
+	query, args := t.query(selected, hints)
+
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &sqlCursor{rows: rows}
+	for _, col := range selected {
+		cursor.names = append(cursor.names, col.Name)
+		cursor.types = append(cursor.types, col.Type)
+	}
+	return cursor, nil
+}
+
+// load describes the columns and indexes of the table until it succeeds once.
+func (t *SQLTable) load(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.columns != nil {
+		return nil
+	}
+	columns, err := t.describe(ctx)
+	if err != nil {
+		return err
+	}
+	indexes, err := t.index(ctx)
+	if err != nil {
+		return err
+	}
+	t.columns, t.indexes = columns, indexes
+	return nil
+}
+
+func (t *SQLTable) describe(ctx context.Context) ([]Column, error) {
+	rows, err := t.db.QueryContext(ctx, "SELECT * FROM "+t.dialect.Quote(t.name)+" WHERE 1 = 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]Column, len(types))
+	for i, typ := range types {
+		nullable, ok := typ.Nullable()
+		columns[i] = Column{
+			Name:     &sqlparser.ColName{Name: sqlparser.NewColIdent(typ.Name())},
+			Type:     t.dialect.ParseType(typ.DatabaseTypeName()),
+			Nullable: nullable || !ok,
+		}
+		if length, ok := typ.Length(); ok {
+			columns[i].Length = length
+		}
+		if precision, scale, ok := typ.DecimalSize(); ok {
+			columns[i].Precision = precision
+			columns[i].Scale = scale
+		}
+	}
+	return columns, rows.Err()
+}
+
+func (t *SQLTable) index(ctx context.Context) ([]Index, error) {
+	rows, err := t.db.QueryContext(ctx, t.dialect.IndexesQuery(), t.name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []Index
+	for rows.Next() {
+		var name, column string
+		var unique bool
+		if err := rows.Scan(&name, &column, &unique); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, Index{Name: name, Unique: unique})
+		}
+		index := &indexes[len(indexes)-1]
+		index.Columns = append(index.Columns, &sqlparser.ColName{Name: sqlparser.NewColIdent(column)})
+	}
+	return indexes, rows.Err()
+}
+
+// query builds the query selecting columns with the conditions of hints it can express.
+func (t *SQLTable) query(columns []Column, hints []ScanHint) (string, []any) {
+	var args []any
+	arg := func(value sqltypes.Value) string {
+		args = append(args, sqlArg(value))
+		return t.dialect.Placeholder(len(args))
+	}
+	column := func(name *sqlparser.ColName) (string, bool) {
+		for _, col := range t.columns {
+			if col.Name.Name.Equal(name.Name) {
+				return t.dialect.Quote(col.Name.Name.String()), true
+			}
+		}
+		return "", false
+	}
+
+	// Backends commonly compare text case-insensitively while the engine does not, so a condition on text may match
+	// rows the engine then drops, which a pushed LIMIT would count, and a negated one may miss rows the engine keeps.
+	var conds []string
+	exact := true
+	for _, hint := range hints {
+		if hint.Index == "" {
+			continue
+		}
+		i := slices.IndexFunc(t.indexes, func(idx Index) bool { return idx.Name == hint.Index })
+		if i < 0 {
+			continue
+		}
+		for j, rng := range hint.Ranges {
+			if j >= len(t.indexes[i].Columns) {
+				break
+			}
+			name, ok := column(t.indexes[i].Columns[j])
+			if !ok {
+				continue
+			}
+			if (rng.Min != nil && rng.Min.IsText()) || (rng.Max != nil && rng.Max.IsText()) {
+				exact = false
+			}
+			if rng.Min != nil {
+				conds = append(conds, name+" >= "+arg(*rng.Min))
+			}
+			if rng.Max != nil {
+				conds = append(conds, name+" <= "+arg(*rng.Max))
+			}
+		}
+	}
+
+	for _, filter := range Filters(hints...) {
+		name, ok := column(filter.Column)
+		if !ok {
+			exact = false
+			continue
+		}
+		if slices.ContainsFunc(filter.Values, sqltypes.Value.IsText) {
+			exact = false
+			if filter.Operator == sqlparser.NotEqualStr || filter.Operator == sqlparser.NotLikeStr || filter.Operator == sqlparser.NotInStr {
+				continue
+			}
+		}
+
+		switch filter.Operator {
+		case sqlparser.EqualStr, sqlparser.LessThanStr, sqlparser.LessEqualStr, sqlparser.GreaterThanStr,
+			sqlparser.GreaterEqualStr, sqlparser.LikeStr, sqlparser.NotLikeStr:
+			if len(filter.Values) != 1 {
+				exact = false
+				continue
+			}
+			conds = append(conds, name+" "+strings.ToUpper(filter.Operator)+" "+arg(filter.Values[0]))
+		case sqlparser.NotEqualStr:
+			if len(filter.Values) != 1 {
+				exact = false
+				continue
+			}
+			conds = append(conds, name+" <> "+arg(filter.Values[0]))
+		case sqlparser.InStr, sqlparser.NotInStr:
+			if len(filter.Values) == 0 {
+				exact = false
+				continue
+			}
+			placeholders := make([]string, len(filter.Values))
+			for i, value := range filter.Values {
+				placeholders[i] = arg(value)
+			}
+			conds = append(conds, name+" "+strings.ToUpper(filter.Operator)+" ("+strings.Join(placeholders, ", ")+")")
+		case sqlparser.IsNullStr, sqlparser.IsNotNullStr:
+			conds = append(conds, name+" "+strings.ToUpper(filter.Operator))
+		default:
+			exact = false
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	for i, col := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(t.dialect.Quote(col.Name.Name.String()))
+	}
+	b.WriteString(" FROM ")
+	b.WriteString(t.dialect.Quote(t.name))
+	if len(conds) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(conds, " AND "))
+	}
+	if limit := Limit(hints...); limit > 0 && exact {
+		b.WriteString(" LIMIT ")
+		b.WriteString(strconv.Itoa(limit))
+	}
+	return b.String(), args
+}
+
+func (c *sqlCursor) Next() (Row, error) {
+	if c.err != nil {
+		return Row{}, c.err
+	}
+	row, err := c.next()
+	if err != nil {
+		c.err = err
+		_ = c.Close()
+	}
+	return row, err
+}
+
+func (c *sqlCursor) Close() error {
+	var err error
+	c.close.Do(func() {
+		if c.err == nil {
+			c.err = io.EOF
+		}
+		err = c.rows.Close()
+	})
+	return err
+}
+
+func (c *sqlCursor) next() (Row, error) {
+	if !c.rows.Next() {
+		if err := c.rows.Err(); err != nil {
+			return Row{}, err
+		}
+		return Row{}, io.EOF
+	}
+
+	dest := make([]any, len(c.names))
+	ptrs := make([]any, len(dest))
+	for i := range dest {
+		ptrs[i] = &dest[i]
+	}
+	if err := c.rows.Scan(ptrs...); err != nil {
+		return Row{}, err
+	}
+
+	values := make([]sqltypes.Value, len(dest))
+	for i, v := range dest {
+		val, err := sqlValue(v, c.types[i])
+		if err != nil {
+			return Row{}, errors.Wrapf(err, "column %s", c.names[i].Name.String())
+		}
+		values[i] = val
+	}
+	return Row{Columns: c.names, Values: values}, nil
+}
+
+func (MySQLDialect) Quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (MySQLDialect) Placeholder(_ int) string {
+	return "?"
+}
+
+func (MySQLDialect) TablesQuery() string {
+	return "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE()"
+}
+
+func (MySQLDialect) TableQuery() string {
+	return "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+}
+
+func (MySQLDialect) IndexesQuery() string {
+	return "SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE = 0 FROM information_schema.STATISTICS " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX"
+}
+
+// ParseType reads name as a MySQL type name, taking unknown ones for VARCHAR.
+func (MySQLDialect) ParseType(name string) querypb.Type {
+	if typ, ok := ParseTypeName(name); ok {
+		return typ
+	}
+	return querypb.Type_VARCHAR
+}
+
+func (PostgresDialect) Quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (PostgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (PostgresDialect) TablesQuery() string {
+	return "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema()"
+}
+
+func (PostgresDialect) TableQuery() string {
+	return "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+}
+
+func (PostgresDialect) IndexesQuery() string {
+	return "SELECT i.relname, a.attname, x.indisunique FROM pg_index x " +
+		"JOIN pg_class t ON t.oid = x.indrelid " +
+		"JOIN pg_class i ON i.oid = x.indexrelid " +
+		"JOIN pg_namespace n ON n.oid = t.relnamespace " +
+		"CROSS JOIN LATERAL unnest(x.indkey) WITH ORDINALITY AS k(attnum, ord) " +
+		"JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum " +
+		"WHERE n.nspname = current_schema() AND t.relname = $1 ORDER BY i.relname, k.ord"
+}
+
+// ParseType reads name as a PostgreSQL type name, as database/sql drivers report it, falling back to the MySQL
+// name and then to VARCHAR.
+func (PostgresDialect) ParseType(name string) querypb.Type {
+	if typ, ok := postgresTypes[strings.ToUpper(name)]; ok {
+		return typ
+	}
+	return MySQLDialect{}.ParseType(name)
+}
+
+// sqlArg converts value to an argument database/sql drivers accept.
+func sqlArg(value sqltypes.Value) any {
+	switch {
+	case value.IsNull():
+		return nil
+	case value.IsIntegral(), value.IsFloat(), value.IsBinary():
+		if v, err := Unmarshal(value); err == nil {
+			return v
+		}
+	}
+	return value.ToString()
+}
+
+// sqlValue converts a value scanned from database/sql into a value of type typ.
+func sqlValue(value any, typ querypb.Type) (sqltypes.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return sqltypes.NULL, nil
+	case []byte:
+		return sqltypes.MakeTrusted(typ, append([]byte(nil), v...)), nil
+	case string:
+		return sqltypes.MakeTrusted(typ, []byte(v)), nil
+	case bool:
+		if v {
+			return sqltypes.NewInt64(1), nil
+		}
+		return sqltypes.NewInt64(0), nil
+	case time.Time:
+		switch typ {
+		case querypb.Type_DATE:
+			return sqltypes.MakeTrusted(typ, []byte(v.Format(time.DateOnly))), nil
+		case querypb.Type_TIME:
+			return sqltypes.MakeTrusted(typ, []byte(v.Format(time.TimeOnly))), nil
+		}
+	}
+	return Marshal(value)
+}
@@ -0,0 +1,319 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+type fakeConnector struct {
+	handle  func(query string, args []any) (*fakeRows, error)
+	queries []string
+	args    [][]any
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+type fakeRows struct {
+	columns  []string
+	types    []string
+	nullable []bool
+	values   [][]driver.Value
+	offset   int
+}
+
+var (
+	_ driver.Connector                      = (*fakeConnector)(nil)
+	_ driver.QueryerContext                 = (*fakeConn)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*fakeRows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*fakeRows)(nil)
+)
+
+func (c *fakeConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return &fakeConn{connector: c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	var values []any
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	c.connector.queries = append(c.connector.queries, query)
+	c.connector.args = append(c.connector.args, values)
+	return c.connector.handle(query, values)
+}
+
+func (c *fakeConn) Prepare(_ string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.offset >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.offset])
+	r.offset++
+	return nil
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.types[index]
+}
+
+func (r *fakeRows) ColumnTypeNullable(index int) (bool, bool) {
+	return r.nullable[index], true
+}
+
+func newFakeDB(t *testing.T) (*sql.DB, *fakeConnector) {
+	connector := &fakeConnector{
+		handle: func(query string, args []any) (*fakeRows, error) {
+			switch {
+			case strings.HasPrefix(strings.ToUpper(query), "SELECT TABLE_NAME"):
+				rows := &fakeRows{columns: []string{"TABLE_NAME"}}
+				for _, name := range []string{"users", "orders"} {
+					if len(args) == 0 || args[0] == name {
+						rows.values = append(rows.values, []driver.Value{name})
+					}
+				}
+				return rows, nil
+			case strings.HasPrefix(query, "SELECT INDEX_NAME"), strings.HasPrefix(query, "SELECT i.relname"):
+				return &fakeRows{columns: []string{"INDEX_NAME", "COLUMN_NAME", "UNIQUE"}, values: [][]driver.Value{{"PRIMARY", "id", true}, {"name_age", "name", false}, {"name_age", "age", false}}}, nil
+			case strings.HasSuffix(query, "WHERE 1 = 0"):
+				return &fakeRows{
+					columns:  []string{"id", "name", "age", "active"},
+					types:    []string{"BIGINT", "VARCHAR", "UNSIGNED INT", "BOOL"},
+					nullable: []bool{false, true, true, false},
+				}, nil
+			default:
+				return &fakeRows{
+					columns: []string{"id", "name"},
+					values:  [][]driver.Value{{int64(1), []byte("foo")}, {int64(2), nil}},
+				}, nil
+			}
+		},
+	}
+
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, connector
+}
+
+func TestSQLCatalog_Table(t *testing.T) {
+	db, connector := newFakeDB(t)
+	catalog := NewSQLCatalog(db, MySQLDialect{})
+
+	names, err := catalog.Tables()
+	require.NoError(t, err)
+	require.Equal(t, []string{"orders", "users"}, names)
+
+	table, err := catalog.Table("users")
+	require.NoError(t, err)
+	require.NotNil(t, table)
+	require.Equal(t, MySQLDialect{}.TableQuery(), connector.queries[1])
+	require.Equal(t, []any{"users"}, connector.args[1])
+
+	cached, err := catalog.Table("users")
+	require.NoError(t, err)
+	require.Same(t, table, cached)
+	require.Len(t, connector.queries, 2)
+
+	_, err = catalog.Table("unknown")
+	require.ErrorIs(t, err, ErrTableNotFound)
+}
+
+func TestSQLTable_Columns(t *testing.T) {
+	db, connector := newFakeDB(t)
+	table := NewSQLTable(db, MySQLDialect{}, "users")
+
+	columns, err := table.Columns(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: querypb.Type_VARCHAR, Nullable: true},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("age")}, Type: querypb.Type_UINT32, Nullable: true},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("active")}, Type: querypb.Type_INT8},
+	}, columns)
+	require.Equal(t, "SELECT * FROM `users` WHERE 1 = 0", connector.queries[0])
+}
+
+func TestSQLTable_ColumnsError(t *testing.T) {
+	db, connector := newFakeDB(t)
+	handle := connector.handle
+	connector.handle = func(string, []any) (*fakeRows, error) {
+		return nil, errors.New("connection refused")
+	}
+	table := NewSQLTable(db, MySQLDialect{}, "users")
+
+	_, err := table.Columns(context.TODO())
+	require.Error(t, err)
+
+	connector.handle = handle
+	columns, err := table.Columns(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, columns, 4)
+}
+
+func TestSQLTable_Indexes(t *testing.T) {
+	db, connector := newFakeDB(t)
+	table := NewSQLTable(db, MySQLDialect{}, "users")
+
+	indexes, err := table.Indexes(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []Index{
+		{Name: "PRIMARY", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}, Unique: true},
+		{Name: "name_age", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("name")}, {Name: sqlparser.NewColIdent("age")}}},
+	}, indexes)
+	require.Equal(t, []any{"users"}, connector.args[1])
+}
+
+func TestSQLTable_Scan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	one := sqltypes.NewInt64(1)
+	foo := sqltypes.NewVarChar("foo")
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		hints   []ScanHint
+		query   string
+		args    []any
+	}{
+		{
+			name:    "all",
+			dialect: MySQLDialect{},
+			query:   "SELECT `id`, `name`, `age`, `active` FROM `users`",
+		},
+		{
+			name:    "projection",
+			dialect: MySQLDialect{},
+			hints:   []ScanHint{{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("NAME")}, {Name: sqlparser.NewColIdent("id")}}}},
+			query:   "SELECT `id`, `name` FROM `users`",
+		},
+		{
+			name:    "ranges",
+			dialect: PostgresDialect{},
+			hints:   []ScanHint{{Index: "name_age", Ranges: []Range{{Min: &foo, Max: &foo}, {Min: &one}}}},
+			query:   `SELECT "id", "name", "age", "active" FROM "users" WHERE "name" >= $1 AND "name" <= $2 AND "age" >= $3`,
+			args:    []any{"foo", "foo", int64(1)},
+		},
+		{
+			name:    "filters",
+			dialect: PostgresDialect{},
+			hints: []ScanHint{{
+				Filters: []Filter{
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Operator: sqlparser.NotInStr, Values: []sqltypes.Value{one, sqltypes.NewInt64(2)}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Operator: sqlparser.LikeStr, Values: []sqltypes.Value{sqltypes.NewVarChar("f%")}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("age")}, Operator: sqlparser.IsNotNullStr},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("active")}, Operator: sqlparser.NotEqualStr, Values: []sqltypes.Value{one}},
+				},
+			}},
+			query: `SELECT "id", "name", "age", "active" FROM "users" WHERE "id" NOT IN ($1, $2) AND "name" LIKE $3 AND "age" IS NOT NULL AND "active" <> $4`,
+			args:  []any{int64(1), int64(2), "f%", int64(1)},
+		},
+		{
+			name:    "limit",
+			dialect: PostgresDialect{},
+			hints: []ScanHint{{
+				Filters: []Filter{
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Operator: sqlparser.NotInStr, Values: []sqltypes.Value{one, sqltypes.NewInt64(2)}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("active")}, Operator: sqlparser.NotEqualStr, Values: []sqltypes.Value{one}},
+				},
+				Limit: 10,
+			}},
+			query: `SELECT "id", "name", "age", "active" FROM "users" WHERE "id" NOT IN ($1, $2) AND "active" <> $3 LIMIT 10`,
+			args:  []any{int64(1), int64(2), int64(1)},
+		},
+		{
+			name:    "text",
+			dialect: MySQLDialect{},
+			hints: []ScanHint{{
+				Filters: []Filter{
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Operator: sqlparser.EqualStr, Values: []sqltypes.Value{foo}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Operator: sqlparser.NotEqualStr, Values: []sqltypes.Value{sqltypes.NewVarChar("bar")}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Operator: sqlparser.NotInStr, Values: []sqltypes.Value{sqltypes.NewVarChar("baz")}},
+				},
+				Limit: 10,
+			}},
+			query: "SELECT `id`, `name`, `age`, `active` FROM `users` WHERE `name` = ?",
+			args:  []any{"foo"},
+		},
+		{
+			name:    "inexact",
+			dialect: MySQLDialect{},
+			hints: []ScanHint{{
+				Filters: []Filter{
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Operator: sqlparser.EqualStr, Values: []sqltypes.Value{one}},
+					{Column: &sqlparser.ColName{Name: sqlparser.NewColIdent("unknown")}, Operator: sqlparser.EqualStr, Values: []sqltypes.Value{one}},
+				},
+				Limit: 10,
+			}},
+			query: "SELECT `id`, `name`, `age`, `active` FROM `users` WHERE `id` = ?",
+			args:  []any{int64(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, connector := newFakeDB(t)
+			table := NewSQLTable(db, tt.dialect, "users")
+
+			cursor, err := table.Scan(ctx, tt.hints...)
+			require.NoError(t, err)
+			require.NoError(t, cursor.Close())
+
+			require.Equal(t, tt.query, connector.queries[len(connector.queries)-1])
+			require.Equal(t, tt.args, connector.args[len(connector.args)-1])
+		})
+	}
+
+	t.Run("rows", func(t *testing.T) {
+		db, _ := newFakeDB(t)
+		table := NewSQLTable(db, MySQLDialect{}, "users")
+
+		cursor, err := table.Scan(ctx, ScanHint{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}})
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+
+		columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}, {Name: sqlparser.NewColIdent("name")}}
+		require.Equal(t, []Row{
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("foo")}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NULL}},
+		}, rows)
+	})
+}
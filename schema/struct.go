@@ -0,0 +1,294 @@
+package schema
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// StructTable reads Go values of a struct type, or of pointers to one, as rows. Each exported field is a column named
+// by its `sql` tag, else its `json` tag, else the field itself, and a tag of "-" leaves it out. Fields of embedded
+// structs without a tag are columns of their own. Pointers and sql.Null types make nullable columns, and the option
+// "index" of a `sql` tag declares an index on the column, or "index=name" one shared by all fields naming it, and
+// "unique" or "unique=name" a unique one.
+type StructTable[T any] struct {
+	provider func(ctx context.Context) (iter.Seq[T], error)
+	fields   [][]int
+	columns  []Column
+	indexes  []Index
+}
+
+type structCursor[T any] struct {
+	next    func() (T, bool)
+	stop    func()
+	fields  [][]int
+	indexes []int
+	names   []*sqlparser.ColName
+	err     error
+	close   sync.Once
+}
+
+var ErrNotStruct = errors.New("not a struct")
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// NewSliceTable returns a table reading rows.
+func NewSliceTable[T any](rows []T) (*StructTable[T], error) {
+	return NewStructTable(func(_ context.Context) (iter.Seq[T], error) {
+		return slices.Values(rows), nil
+	})
+}
+
+// NewStructTable returns a table reading the values provider yields, which is called anew on every scan so that
+// the table reflects changes to the data behind it.
+func NewStructTable[T any](provider func(ctx context.Context) (iter.Seq[T], error)) (*StructTable[T], error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, errors.Wrapf(ErrNotStruct, "%v", reflect.TypeFor[T]())
+	}
+
+	t := &StructTable[T]{provider: provider}
+	t.describe(typ, nil, false)
+	return t, nil
+}
+
+func (t *StructTable[T]) Columns(_ context.Context) ([]Column, error) {
+	return append([]Column(nil), t.columns...), nil
+}
+
+func (t *StructTable[T]) Indexes(_ context.Context) ([]Index, error) {
+	return append([]Index(nil), t.indexes...), nil
+}
+
+// Scan reads the values the provider yields, reading only the columns listed by the hints if any.
+func (t *StructTable[T]) Scan(ctx context.Context, hints ...ScanHint) (Cursor, error) {
+	seq, err := t.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	next, stop := iter.Pull(seq)
+	cursor := &structCursor[T]{next: next, stop: stop, fields: t.fields}
+
+	projection := Projection(hints...)
+	for i, col := range t.columns {
+		if projection != nil && !slices.ContainsFunc(projection, func(name *sqlparser.ColName) bool {
+			return col.Name.Name.Equal(name.Name)
+		}) {
+			continue
+		}
+		cursor.indexes = append(cursor.indexes, i)
+		cursor.names = append(cursor.names, col.Name)
+	}
+	return cursor, nil
+}
+
+// describe adds the fields of typ, reached through index, as columns.
+func (t *StructTable[T]) describe(typ reflect.Type, index []int, nullable bool) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		path := append(slices.Clone(index), i)
+
+		name, options, tagged := structTag(field)
+		if name == "-" {
+			continue
+		}
+
+		ftyp := field.Type
+		if field.Anonymous && !tagged {
+			if ftyp.Kind() == reflect.Pointer {
+				ftyp = ftyp.Elem()
+			}
+			if ftyp.Kind() == reflect.Struct && ftyp != timeType {
+				t.describe(ftyp, path, nullable || field.Type.Kind() == reflect.Pointer)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		typ, null := structType(ftyp)
+		col := &sqlparser.ColName{Name: sqlparser.NewColIdent(name)}
+		t.fields = append(t.fields, path)
+		t.columns = append(t.columns, Column{Name: col, Type: typ, Nullable: nullable || null})
+
+		for _, option := range options {
+			key, value, _ := strings.Cut(option, "=")
+			if key != "index" && key != "unique" {
+				continue
+			}
+			if value == "" {
+				value = name
+			}
+			if j := slices.IndexFunc(t.indexes, func(idx Index) bool { return idx.Name == value }); j >= 0 {
+				t.indexes[j].Columns = append(t.indexes[j].Columns, col)
+				t.indexes[j].Unique = t.indexes[j].Unique || key == "unique"
+			} else {
+				t.indexes = append(t.indexes, Index{Name: value, Columns: []*sqlparser.ColName{col}, Unique: key == "unique"})
+			}
+		}
+	}
+}
+
+func (c *structCursor[T]) Next() (Row, error) {
+	if c.err != nil {
+		return Row{}, c.err
+	}
+	row, err := c.read()
+	if err != nil {
+		c.err = err
+		_ = c.Close()
+	}
+	return row, err
+}
+
+func (c *structCursor[T]) Close() error {
+	c.close.Do(func() {
+		if c.err == nil {
+			c.err = io.EOF
+		}
+		c.stop()
+	})
+	return nil
+}
+
+func (c *structCursor[T]) read() (Row, error) {
+	for {
+		item, ok := c.next()
+		if !ok {
+			return Row{}, io.EOF
+		}
+
+		value := reflect.ValueOf(&item).Elem()
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+
+		values := make([]sqltypes.Value, len(c.indexes))
+		for i, index := range c.indexes {
+			val, err := structValue(value, c.fields[index])
+			if err != nil {
+				return Row{}, errors.Wrapf(err, "column %s", c.names[i].Name.String())
+			}
+			values[i] = val
+		}
+		return Row{Columns: c.names, Values: values}, nil
+	}
+}
+
+// structTag returns the column name and options of the `sql` tag of field, or else of its `json` tag.
+func structTag(field reflect.StructField) (string, []string, bool) {
+	for _, key := range []string{"sql", "json"} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			parts := strings.Split(tag, ",")
+			if key != "sql" {
+				parts = parts[:1]
+			}
+			return parts[0], parts[1:], parts[0] != ""
+		}
+	}
+	return "", nil, false
+}
+
+// structType returns the type of the column holding values of typ, and whether it is nullable.
+func structType(typ reflect.Type) (querypb.Type, bool) {
+	nullable := false
+	if typ.Kind() == reflect.Pointer {
+		typ, nullable = typ.Elem(), true
+	}
+	if typ.Implements(valuerType) || reflect.PointerTo(typ).Implements(valuerType) {
+		if typ.Kind() == reflect.Struct && typ.NumField() == 2 {
+			if valid, ok := typ.FieldByName("Valid"); ok && valid.Type.Kind() == reflect.Bool {
+				inner, _ := structType(typ.Field(1 - valid.Index[0]).Type)
+				return inner, true
+			}
+		}
+		return querypb.Type_VARCHAR, true
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return querypb.Type_INT64, nullable
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return querypb.Type_UINT64, nullable
+	case reflect.Float32, reflect.Float64:
+		return querypb.Type_FLOAT64, nullable
+	case reflect.String:
+		return querypb.Type_VARCHAR, nullable
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return querypb.Type_VARBINARY, true
+		}
+		return querypb.Type_JSON, true
+	case reflect.Map, reflect.Interface:
+		return querypb.Type_JSON, true
+	case reflect.Struct:
+		if typ == timeType {
+			return querypb.Type_DATETIME, nullable
+		}
+	}
+	return querypb.Type_JSON, nullable
+}
+
+// structValue returns the value of the field at index within v.
+func structValue(v reflect.Value, index []int) (sqltypes.Value, error) {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return sqltypes.NULL, nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return sqltypes.NULL, nil
+		}
+		v = v.Elem()
+	case reflect.Slice, reflect.Map, reflect.Interface:
+		if v.IsNil() {
+			return sqltypes.NULL, nil
+		}
+	}
+
+	value := v.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return sqltypes.NULL, err
+		}
+	}
+	if b, ok := value.(bool); ok {
+		if b {
+			return sqltypes.NewInt64(1), nil
+		}
+		return sqltypes.NewInt64(0), nil
+	}
+	return Marshal(value)
+}
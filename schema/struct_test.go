@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+type structAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+	deleted   bool
+}
+
+type structUser struct {
+	ID      int64 `sql:"id,unique"`
+	Name    string
+	Email   *string        `json:"email,omitempty"`
+	Team    string         `sql:"team,index=team_role"`
+	Role    string         `sql:"role,index=team_role"`
+	Active  bool           `sql:"active"`
+	Nick    sql.NullString `sql:"nick"`
+	Tags    []string       `json:"tags"`
+	Secret  string         `sql:"-"`
+	private int
+	structAudit
+}
+
+func TestStructTable_Columns(t *testing.T) {
+	table, err := NewSliceTable([]structUser{})
+	require.NoError(t, err)
+
+	columns, err := table.Columns(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("Name")}, Type: querypb.Type_VARCHAR},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("email")}, Type: querypb.Type_VARCHAR, Nullable: true},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("team")}, Type: querypb.Type_VARCHAR},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("role")}, Type: querypb.Type_VARCHAR},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("active")}, Type: querypb.Type_INT64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("nick")}, Type: querypb.Type_VARCHAR, Nullable: true},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("tags")}, Type: querypb.Type_JSON, Nullable: true},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("created_at")}, Type: querypb.Type_DATETIME},
+	}, columns)
+
+	_, err = NewSliceTable([]int{1})
+	require.ErrorIs(t, err, ErrNotStruct)
+}
+
+func TestStructTable_Indexes(t *testing.T) {
+	table, err := NewSliceTable([]*structUser{})
+	require.NoError(t, err)
+
+	indexes, err := table.Indexes(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, []Index{
+		{Name: "id", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("id")}}, Unique: true},
+		{Name: "team_role", Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("team")}, {Name: sqlparser.NewColIdent("role")}}},
+	}, indexes)
+}
+
+func TestStructTable_Scan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	email := "foo@example.com"
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("slice", func(t *testing.T) {
+		table, err := NewSliceTable([]*structUser{
+			{ID: 1, Name: "foo", Email: &email, Active: true, Nick: sql.NullString{String: "f", Valid: true}, Tags: []string{"a"}, structAudit: structAudit{CreatedAt: created}},
+			nil,
+			{ID: 2, Name: "bar"},
+		})
+		require.NoError(t, err)
+
+		cursor, err := table.Scan(ctx)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		require.Equal(t, []sqltypes.Value{
+			sqltypes.NewInt64(1),
+			sqltypes.NewVarChar("foo"),
+			sqltypes.NewVarChar(email),
+			sqltypes.NewVarChar(""),
+			sqltypes.NewVarChar(""),
+			sqltypes.NewInt64(1),
+			sqltypes.NewVarChar("f"),
+			sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`["a"]`)),
+			sqltypes.MakeTrusted(sqltypes.Datetime, []byte(created.Format(time.RFC3339Nano))),
+		}, rows[0].Values)
+		require.Equal(t, sqltypes.NULL, rows[1].Values[2])
+		require.Equal(t, sqltypes.NULL, rows[1].Values[6])
+		require.Equal(t, sqltypes.NULL, rows[1].Values[7])
+	})
+
+	t.Run("provider", func(t *testing.T) {
+		var mu sync.Mutex
+		users := []structUser{{ID: 1, Name: "foo"}}
+
+		table, err := NewStructTable(func(_ context.Context) (iter.Seq[structUser], error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Values(slices.Clone(users)), nil
+		})
+		require.NoError(t, err)
+
+		hint := ScanHint{Columns: []*sqlparser.ColName{{Name: sqlparser.NewColIdent("name")}}}
+		columns := []*sqlparser.ColName{{Name: sqlparser.NewColIdent("Name")}}
+
+		cursor, err := table.Scan(ctx, hint)
+		require.NoError(t, err)
+
+		rows, err := ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []Row{{Columns: columns, Values: []sqltypes.Value{sqltypes.NewVarChar("foo")}}}, rows)
+
+		mu.Lock()
+		users = append(users, structUser{ID: 2, Name: "bar"})
+		mu.Unlock()
+
+		cursor, err = table.Scan(ctx, hint)
+		require.NoError(t, err)
+
+		rows, err = ReadAll(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []Row{
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewVarChar("foo")}},
+			{Columns: columns, Values: []sqltypes.Value{sqltypes.NewVarChar("bar")}},
+		}, rows)
+	})
+}
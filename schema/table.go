@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/xwb1989/sqlparser"
 	"github.com/xwb1989/sqlparser/dependency/sqltypes"
 )
 
@@ -12,9 +13,24 @@ type Table interface {
 	Scan(ctx context.Context, hint ...ScanHint) (Cursor, error)
 }
 
+// ScanHint either narrows the scan of an index to Ranges, or pushes down the parts of a query a table may take over:
+// the only Columns it reads, the Filters every row it reads passes, and the Limit on the number of rows it reads.
+// Tables may ignore hints and return more rows or columns than asked for, except that a table applying Limit must
+// apply all Filters.
 type ScanHint struct {
-	Index  string
-	Ranges []Range
+	Index   string
+	Ranges  []Range
+	Columns []*sqlparser.ColName
+	Filters []Filter
+	Limit   int
+}
+
+// Filter compares Column with Values by Operator, one of the comparison operators of sqlparser such as "=", "!=",
+// "<", "in", "not like" or "is null".
+type Filter struct {
+	Column   *sqlparser.ColName
+	Operator string
+	Values   []sqltypes.Value
 }
 
 type Range struct {
@@ -22,18 +38,62 @@ type Range struct {
 	Max *sqltypes.Value
 }
 
+// Projection returns the columns listed by hints, or nil if the scan has to return all of them.
+func Projection(hints ...ScanHint) []*sqlparser.ColName {
+	for _, hint := range hints {
+		if hint.Columns != nil {
+			return hint.Columns
+		}
+	}
+	return nil
+}
+
+// Filters returns the filters pushed down by hints.
+func Filters(hints ...ScanHint) []Filter {
+	var filters []Filter
+	for _, hint := range hints {
+		filters = append(filters, hint.Filters...)
+	}
+	return filters
+}
+
+// Limit returns the limit pushed down by hints, or 0 if there is none.
+func Limit(hints ...ScanHint) int {
+	for _, hint := range hints {
+		if hint.Limit > 0 {
+			return hint.Limit
+		}
+	}
+	return 0
+}
+
 type InMemoryTable struct {
+	columns []Column
 	indexes []Index
 	rows    []Row
 	mu      sync.RWMutex
 }
 
 var _ Table = (*InMemoryTable)(nil)
+var _ Describer = (*InMemoryTable)(nil)
 
 func NewInMemoryTable(rows []Row) *InMemoryTable {
 	return &InMemoryTable{rows: rows}
 }
 
+func (t *InMemoryTable) Columns(_ context.Context) ([]Column, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]Column(nil), t.columns...), nil
+}
+
+func (t *InMemoryTable) SetColumns(_ context.Context, columns []Column) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.columns = append([]Column(nil), columns...)
+	return nil
+}
+
 func (t *InMemoryTable) Indexes(_ context.Context) ([]Index, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -34,3 +34,26 @@ func TestInMemoryTable_Scan(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, rows, r)
 }
+
+func TestInMemoryTable_Columns(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	columns := []Column{
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("id")}, Type: sqltypes.Int64},
+		{Name: &sqlparser.ColName{Name: sqlparser.NewColIdent("name")}, Type: sqltypes.VarChar, Nullable: true, Length: 255},
+	}
+
+	table := NewInMemoryTable(nil)
+
+	cols, err := table.Columns(ctx)
+	require.NoError(t, err)
+	require.Nil(t, cols)
+
+	err = table.SetColumns(ctx, columns)
+	require.NoError(t, err)
+
+	cols, err = table.Columns(ctx)
+	require.NoError(t, err)
+	require.Equal(t, columns, cols)
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Viewer is implemented by catalogs that define views.
+type Viewer interface {
+	View(name string) (string, error)
+}
+
+// ViewStore is implemented by catalogs that can create and drop views.
+type ViewStore interface {
+	Viewer
+	SetView(name, query string) error
+	DeleteView(name string) error
+}
+
+// ViewCatalog adds views, stored as their defining queries, to a catalog.
+type ViewCatalog struct {
+	catalog Catalog
+	views   map[string]string
+	mu      sync.RWMutex
+}
+
+var (
+	ErrViewNotFound = errors.New("view not found")
+	ErrTableExists  = errors.New("table already exists")
+)
+
+var (
+	_ Catalog     = (*ViewCatalog)(nil)
+	_ TableLister = (*ViewCatalog)(nil)
+	_ ViewStore   = (*ViewCatalog)(nil)
+)
+
+func NewViewCatalog(catalog Catalog) *ViewCatalog {
+	if catalog == nil {
+		catalog = NewInMemoryCatalog(nil)
+	}
+	return &ViewCatalog{catalog: catalog, views: make(map[string]string)}
+}
+
+func (c *ViewCatalog) Table(name string) (Table, error) {
+	return c.catalog.Table(name)
+}
+
+func (c *ViewCatalog) Tables() ([]string, error) {
+	var names []string
+	if lister, ok := c.catalog.(TableLister); ok {
+		var err error
+		if names, err = lister.Tables(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name := range c.views {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (c *ViewCatalog) View(name string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	query, ok := c.views[name]
+	if !ok {
+		return "", errors.WithStack(ErrViewNotFound)
+	}
+	return query, nil
+}
+
+func (c *ViewCatalog) SetView(name, query string) error {
+	if _, err := c.catalog.Table(name); err == nil {
+		return errors.WithStack(ErrTableExists)
+	} else if !errors.Is(err, ErrTableNotFound) {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.views[name] = query
+	return nil
+}
+
+func (c *ViewCatalog) DeleteView(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.views[name]; !ok {
+		return errors.WithStack(ErrViewNotFound)
+	}
+	delete(c.views, name)
+	return nil
+}
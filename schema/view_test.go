@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewCatalog_View(t *testing.T) {
+	catalog := NewViewCatalog(NewInMemoryCatalog(map[string]Table{
+		"users": NewInMemoryTable(nil),
+	}))
+
+	err := catalog.SetView("active_users", "SELECT * FROM users")
+	require.NoError(t, err)
+
+	query, err := catalog.View("active_users")
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users", query)
+
+	_, err = catalog.View("users")
+	require.ErrorIs(t, err, ErrViewNotFound)
+
+	err = catalog.SetView("users", "SELECT 1")
+	require.ErrorIs(t, err, ErrTableExists)
+
+	err = catalog.DeleteView("active_users")
+	require.NoError(t, err)
+
+	_, err = catalog.View("active_users")
+	require.ErrorIs(t, err, ErrViewNotFound)
+
+	err = catalog.DeleteView("active_users")
+	require.ErrorIs(t, err, ErrViewNotFound)
+}
+
+func TestViewCatalog_Tables(t *testing.T) {
+	catalog := NewViewCatalog(NewInMemoryCatalog(map[string]Table{
+		"users": NewInMemoryTable(nil),
+	}))
+	_ = catalog.SetView("active_users", "SELECT * FROM users")
+
+	names, err := catalog.Tables()
+	require.NoError(t, err)
+	require.Equal(t, []string{"active_users", "users"}, names)
+}
@@ -0,0 +1,456 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/engine"
+	"github.com/siyul-park/sqlbridge/schema"
+)
+
+// HTTPHandler serves the registry as a JSON API:
+//
+//	POST /query                                  runs a query, answering JSON or, if asked for, NDJSON
+//	POST /explain                                returns the plan of a query
+//	GET  /catalogs                               lists the catalogs
+//	GET  /catalogs/{catalog}/tables              lists the tables of a catalog
+//	GET  /catalogs/{catalog}/tables/{table}      describes the columns and indexes of a table
+//
+// Queries take ? placeholders bound to the params of the request in order, and run on the database it names. Each
+// request has a session of its own, so a USE lasts for that request only. Statements changing the schema, such as
+// CREATE VIEW, are refused unless the handler has WithDDL. A handler without users or middleware authenticates no
+// one, so it must only be reachable by those who may query the registry.
+type HTTPHandler struct {
+	options *options
+	handler http.Handler
+}
+
+type httpRequest struct {
+	Query    string `json:"query"`
+	Params   []any  `json:"params,omitempty"`
+	Database string `json:"database,omitempty"`
+	Timeout  string `json:"timeout,omitempty"`
+}
+
+type httpColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+type httpIndex struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+// httpError is an error reported to the client with an HTTP status and a code naming its kind.
+type httpError struct {
+	status int
+	code   string
+	err    error
+}
+
+const (
+	httpNDJSON  = "application/x-ndjson"
+	httpMaxBody = 1 << 20
+)
+
+var _ http.Handler = (*HTTPHandler)(nil)
+
+// WithTimeout bounds how long a request to the HTTP handler may run its query for. Requests may ask for less.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithMiddleware wraps the HTTP handler in middleware, such as one authenticating requests by their token. The first
+// middleware given is the outermost.
+func WithMiddleware(middleware ...func(http.Handler) http.Handler) Option {
+	return func(o *options) { o.middleware = append(o.middleware, middleware...) }
+}
+
+// WithDDL allows the HTTP handler to run statements changing the schema, such as CREATE VIEW and DROP VIEW.
+func WithDDL() Option {
+	return func(o *options) { o.ddl = true }
+}
+
+// NewHTTPHandler returns a handler of the JSON API. Requests authenticate with HTTP basic authentication if the
+// handler has users.
+func NewHTTPHandler(opts ...Option) *HTTPHandler {
+	h := &HTTPHandler{options: newOptions(opts...)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /query", h.query)
+	mux.HandleFunc("POST /explain", h.explain)
+	mux.HandleFunc("GET /catalogs", h.catalogs)
+	mux.HandleFunc("GET /catalogs/{catalog}/tables", h.tables)
+	mux.HandleFunc("GET /catalogs/{catalog}/tables/{table}", h.table)
+
+	var handler http.Handler = mux
+	if len(h.options.users) > 0 {
+		handler = h.authenticate(handler)
+	}
+	for i := len(h.options.middleware) - 1; i >= 0; i-- {
+		handler = h.options.middleware[i](handler)
+	}
+	h.handler = handler
+	return h
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+func (h *HTTPHandler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || !h.options.authenticate(user, func(p string) bool {
+			return subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+		}) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="sqlbridge"`)
+			httpWriteError(w, &httpError{status: http.StatusUnauthorized, code: "unauthorized", err: errors.New("access denied")})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *HTTPHandler) query(w http.ResponseWriter, r *http.Request) {
+	req, err := httpReadRequest(w, r)
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	ctx, cancel, err := h.context(r.Context(), req)
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	defer cancel()
+
+	s, stmt, err := h.prepare(req)
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	switch stmt.plan.(type) {
+	case *engine.CreateViewPlan, *engine.DropViewPlan:
+		if !h.options.ddl {
+			httpWriteError(w, &httpError{status: http.StatusForbidden, code: "forbidden", err: errors.New("statements changing the schema are not allowed")})
+			return
+		}
+	}
+	res, err := s.run(ctx, stmt, req.Params)
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	defer res.Close()
+
+	columns := httpColumns(res.columns)
+	if !httpAccepts(r, httpNDJSON) {
+		rows := [][]any{}
+		for {
+			row, err := httpNext(res)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				httpWriteError(w, err)
+				return
+			}
+			rows = append(rows, row)
+		}
+		httpWriteJSON(w, http.StatusOK, map[string]any{"columns": columns, "rows": rows})
+		return
+	}
+
+	// Rows are streamed one per line after the columns, ending with their count or the error that cut them short.
+	w.Header().Set("Content-Type", httpNDJSON)
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(map[string]any{"columns": columns}); err != nil {
+		return
+	}
+	_ = http.NewResponseController(w).Flush()
+
+	var count int64
+	for {
+		row, err := httpNext(res)
+		if errors.Is(err, io.EOF) {
+			_ = encoder.Encode(map[string]any{"count": count})
+			return
+		}
+		if err != nil {
+			_ = encoder.Encode(map[string]any{"error": httpWrapError(err)})
+			return
+		}
+		if err := encoder.Encode(row); err != nil {
+			return
+		}
+		count++
+	}
+}
+
+func (h *HTTPHandler) explain(w http.ResponseWriter, r *http.Request) {
+	req, err := httpReadRequest(w, r)
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	_, stmt, err := h.prepare(req)
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	if stmt.plan == nil {
+		httpWriteError(w, &httpError{status: http.StatusBadRequest, code: "unsupported", err: errors.New("statement has no plan")})
+		return
+	}
+	httpWriteJSON(w, http.StatusOK, map[string]any{"plan": stmt.plan.String()})
+}
+
+func (h *HTTPHandler) catalogs(w http.ResponseWriter, _ *http.Request) {
+	var names []string
+	if lister, ok := h.options.registry.(schema.CatalogLister); ok {
+		var err error
+		if names, err = lister.Catalogs(); err != nil {
+			httpWriteError(w, err)
+			return
+		}
+	}
+	httpWriteJSON(w, http.StatusOK, map[string]any{"catalogs": append([]string{}, names...)})
+}
+
+func (h *HTTPHandler) tables(w http.ResponseWriter, r *http.Request) {
+	catalog, err := h.options.registry.Catalog(r.PathValue("catalog"))
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	var names []string
+	if lister, ok := catalog.(schema.TableLister); ok {
+		if names, err = lister.Tables(); err != nil {
+			httpWriteError(w, err)
+			return
+		}
+	}
+	httpWriteJSON(w, http.StatusOK, map[string]any{"tables": append([]string{}, names...)})
+}
+
+func (h *HTTPHandler) table(w http.ResponseWriter, r *http.Request) {
+	catalog, err := h.options.registry.Catalog(r.PathValue("catalog"))
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	table, err := catalog.Table(r.PathValue("table"))
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+
+	// The columns of tables that do not declare them are not known without reading them.
+	columns := []httpColumn{}
+	if describer, ok := table.(schema.Describer); ok {
+		cols, err := describer.Columns(r.Context())
+		if err != nil {
+			httpWriteError(w, err)
+			return
+		}
+		columns = httpColumns(cols)
+	}
+
+	idxs, err := table.Indexes(r.Context())
+	if err != nil {
+		httpWriteError(w, err)
+		return
+	}
+	indexes := []httpIndex{}
+	for _, idx := range idxs {
+		index := httpIndex{Name: idx.Name}
+		for _, col := range idx.Columns {
+			index.Columns = append(index.Columns, col.Name.String())
+		}
+		indexes = append(indexes, index)
+	}
+
+	httpWriteJSON(w, http.StatusOK, map[string]any{"name": r.PathValue("table"), "columns": columns, "indexes": indexes})
+}
+
+// context returns the context of the query of req, canceled once the request is, or the handler or the request time
+// out.
+func (h *HTTPHandler) context(ctx context.Context, req *httpRequest) (context.Context, context.CancelFunc, error) {
+	timeout := h.options.timeout
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 {
+			return nil, nil, &httpError{status: http.StatusBadRequest, code: "bad_request", err: fmt.Errorf("invalid timeout %q", req.Timeout)}
+		}
+		if timeout <= 0 || d < timeout {
+			timeout = d
+		}
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+// prepare opens a session on the database of req and plans its query.
+func (h *HTTPHandler) prepare(req *httpRequest) (*session, *statement, error) {
+	s, err := h.options.session(req.Database)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, params := engine.BindQuestionMarks(strings.TrimRight(strings.TrimSpace(req.Query), ";"))
+	if params != len(req.Params) {
+		return nil, nil, &httpError{status: http.StatusBadRequest, code: "bad_request", err: fmt.Errorf("query has %d placeholders but %d params", params, len(req.Params))}
+	}
+	stmt, err := s.prepare(query, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, stmt, nil
+}
+
+// httpReadRequest decodes the JSON body of r, turning its params into values the engine can bind.
+func httpReadRequest(w http.ResponseWriter, r *http.Request) (*httpRequest, error) {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, httpMaxBody))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+
+	var req httpRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, &httpError{status: http.StatusBadRequest, code: "bad_request", err: fmt.Errorf("invalid request: %w", err)}
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, &httpError{status: http.StatusBadRequest, code: "bad_request", err: errors.New("query is empty")}
+	}
+	for i, param := range req.Params {
+		value, err := httpParam(param)
+		if err != nil {
+			return nil, &httpError{status: http.StatusBadRequest, code: "bad_request", err: fmt.Errorf("invalid param %d: %w", i+1, err)}
+		}
+		req.Params[i] = value
+	}
+	return &req, nil
+}
+
+// httpParam converts a decoded JSON value into a bind variable value. Arrays and objects become JSON text.
+func httpParam(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case []any, map[string]any:
+		d, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(d), nil
+	}
+	return value, nil
+}
+
+func httpColumns(columns []schema.Column) []httpColumn {
+	cols := make([]httpColumn, len(columns))
+	for i, col := range columns {
+		typ := schema.TypeName(col.Type)
+		if typ == "" {
+			typ = col.Type.String()
+		}
+		cols[i] = httpColumn{Name: col.Name.Name.String(), Type: typ, Nullable: col.Nullable}
+	}
+	return cols
+}
+
+// httpNext returns the values of the next row of res as JSON values.
+func httpNext(res *result) ([]any, error) {
+	values, err := res.Next()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]any, len(values))
+	for i, value := range values {
+		if row[i], err = schema.Unmarshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// httpWrapError returns err as an httpError, classifying errors of the engine and schema.
+func httpWrapError(err error) *httpError {
+	var e *httpError
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, ErrSyntax):
+		return &httpError{status: http.StatusBadRequest, code: "syntax_error", err: err}
+	case errors.Is(err, schema.ErrCatalogNotFound), errors.Is(err, schema.ErrTableNotFound), errors.Is(err, schema.ErrViewNotFound):
+		return &httpError{status: http.StatusNotFound, code: "not_found", err: err}
+	case errors.Is(err, schema.ErrTableExists):
+		return &httpError{status: http.StatusConflict, code: "conflict", err: err}
+	case errors.Is(err, engine.ErrUnknownColumn), errors.Is(err, engine.ErrAmbiguousColumn), errors.Is(err, engine.ErrNotGrouped),
+		errors.Is(err, engine.ErrGroupFunction), errors.Is(err, engine.ErrArgumentCount), errors.Is(err, engine.ErrArgumentType),
+		errors.Is(err, engine.ErrColumnCount):
+		return &httpError{status: http.StatusBadRequest, code: "invalid_query", err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &httpError{status: http.StatusGatewayTimeout, code: "timeout", err: err}
+	case errors.Is(err, context.Canceled):
+		return &httpError{status: http.StatusServiceUnavailable, code: "canceled", err: err}
+	}
+	return &httpError{status: http.StatusInternalServerError, code: "internal", err: err}
+}
+
+func httpWriteError(w http.ResponseWriter, err error) {
+	e := httpWrapError(err)
+	httpWriteJSON(w, e.status, map[string]any{"error": e})
+}
+
+func (e *httpError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"code": e.code, "message": e.Error()})
+}
+
+func httpWriteJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// httpAccepts reports whether r accepts responses of the media type typ.
+func httpAccepts(r *http.Request, typ string) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == typ {
+			return true
+		}
+	}
+	return false
+}
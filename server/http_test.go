@@ -0,0 +1,278 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type slowTable struct{}
+
+type slowCursor struct {
+	ctx context.Context
+}
+
+var _ schema.Table = slowTable{}
+
+func (slowTable) Indexes(_ context.Context) ([]schema.Index, error) {
+	return nil, nil
+}
+
+func (slowTable) Scan(ctx context.Context, _ ...schema.ScanHint) (schema.Cursor, error) {
+	return &slowCursor{ctx: ctx}, nil
+}
+
+func (c *slowCursor) Next() (schema.Row, error) {
+	<-c.ctx.Done()
+	return schema.Row{}, c.ctx.Err()
+}
+
+func (c *slowCursor) Close() error {
+	return nil
+}
+
+func doHTTP(t *testing.T, handler http.Handler, method, path, body string, header http.Header) (*httptest.ResponseRecorder, map[string]any) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var res map[string]any
+	if rec.Header().Get("Content-Type") == "application/json" {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	}
+	return rec, res
+}
+
+func TestHTTPHandler_Query(t *testing.T) {
+	handler := NewHTTPHandler(WithRegistry(newTestRegistry(t)))
+
+	t.Run("json", func(t *testing.T) {
+		rec, res := doHTTP(t, handler, http.MethodPost, "/query", `{"query": "SELECT id, name, email, created_at FROM users WHERE id >= ? ORDER BY id", "params": [1], "database": "app"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []any{
+			map[string]any{"name": "id", "type": "BIGINT", "nullable": false},
+			map[string]any{"name": "name", "type": "VARCHAR", "nullable": false},
+			map[string]any{"name": "email", "type": "VARCHAR", "nullable": true},
+			map[string]any{"name": "created_at", "type": "DATETIME", "nullable": false},
+		}, res["columns"])
+		require.Equal(t, []any{
+			[]any{float64(1), "foo", "foo@example.com", "2024-01-02T03:04:05Z"},
+			[]any{float64(2), "bar", nil, "2024-01-02T04:04:05Z"},
+		}, res["rows"])
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		rec, _ := doHTTP(t, handler, http.MethodPost, "/query", `{"query": "SELECT name FROM app.users WHERE id IN (?, ?) ORDER BY id", "params": [1, 2]}`, http.Header{"Accept": {"application/x-ndjson"}})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+		var lines []string
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		require.Equal(t, []string{
+			`{"columns":[{"name":"name","type":"VARCHAR","nullable":false}]}`,
+			`["foo"]`,
+			`["bar"]`,
+			`{"count":2}`,
+		}, lines)
+	})
+
+	t.Run("literals", func(t *testing.T) {
+		rec, res := doHTTP(t, handler, http.MethodPost, "/query", `{"query": "SELECT name, 'a?b' AS s FROM users WHERE name <> 'what?' AND id = ?", "params": [1], "database": "app"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []any{[]any{"foo", "a?b"}}, res["rows"])
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		rec, res := doHTTP(t, handler, http.MethodPost, "/query", `{"query": "USE app"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, map[string]any{"columns": []any{}, "rows": []any{}}, res)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			body   string
+			status int
+			code   string
+		}{
+			{body: `{"query": "SELEC 1"}`, status: http.StatusBadRequest, code: "syntax_error"},
+			{body: `{"query": "SELECT * FROM missing", "database": "app"}`, status: http.StatusNotFound, code: "not_found"},
+			{body: `{"query": "SELECT * FROM users", "database": "missing"}`, status: http.StatusNotFound, code: "not_found"},
+			{body: `{"query": "SELECT missing FROM users", "database": "app"}`, status: http.StatusBadRequest, code: "invalid_query"},
+			{body: `{"query": "SELECT * FROM users WHERE id = ?", "database": "app"}`, status: http.StatusBadRequest, code: "bad_request"},
+			{body: `{"query": ""}`, status: http.StatusBadRequest, code: "bad_request"},
+			{body: `{"sql": "SELECT 1"}`, status: http.StatusBadRequest, code: "bad_request"},
+			{body: `{"query": "SELECT * FROM users", "timeout": "soon"}`, status: http.StatusBadRequest, code: "bad_request"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.body, func(t *testing.T) {
+				rec, res := doHTTP(t, handler, http.MethodPost, "/query", tt.body, nil)
+				require.Equal(t, tt.status, rec.Code)
+				require.Equal(t, tt.code, res["error"].(map[string]any)["code"])
+			})
+		}
+	})
+
+	t.Run("method", func(t *testing.T) {
+		rec, _ := doHTTP(t, handler, http.MethodGet, "/query", "", nil)
+		require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func TestHTTPHandler_Timeout(t *testing.T) {
+	registry := schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"slow": slowTable{}}),
+	})
+
+	tests := []struct {
+		opts []Option
+		body string
+	}{
+		{opts: []Option{WithTimeout(10 * time.Millisecond)}, body: `{"query": "SELECT * FROM slow", "database": "app"}`},
+		{body: `{"query": "SELECT * FROM slow", "database": "app", "timeout": "10ms"}`},
+		{opts: []Option{WithTimeout(time.Minute)}, body: `{"query": "SELECT * FROM slow", "database": "app", "timeout": "10ms"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			handler := NewHTTPHandler(append([]Option{WithRegistry(registry)}, tt.opts...)...)
+
+			rec, res := doHTTP(t, handler, http.MethodPost, "/query", tt.body, nil)
+			require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+			require.Equal(t, "timeout", res["error"].(map[string]any)["code"])
+		})
+	}
+}
+
+func TestHTTPHandler_DDL(t *testing.T) {
+	create := `{"query": "CREATE VIEW named AS SELECT name FROM users", "database": "app"}`
+	drop := `{"query": "DROP VIEW named", "database": "app"}`
+
+	t.Run("read only", func(t *testing.T) {
+		handler := NewHTTPHandler(WithRegistry(newTestRegistry(t)))
+
+		for _, body := range []string{create, drop} {
+			rec, res := doHTTP(t, handler, http.MethodPost, "/query", body, nil)
+			require.Equal(t, http.StatusForbidden, rec.Code)
+			require.Equal(t, "forbidden", res["error"].(map[string]any)["code"])
+		}
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		handler := NewHTTPHandler(WithRegistry(newTestRegistry(t)), WithDDL())
+
+		rec, _ := doHTTP(t, handler, http.MethodPost, "/query", create, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec, res := doHTTP(t, handler, http.MethodPost, "/query", `{"query": "SELECT COUNT(*) FROM named", "database": "app"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []any{[]any{float64(2)}}, res["rows"])
+
+		rec, _ = doHTTP(t, handler, http.MethodPost, "/query", drop, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestHTTPHandler_Explain(t *testing.T) {
+	handler := NewHTTPHandler(WithRegistry(newTestRegistry(t)))
+
+	rec, res := doHTTP(t, handler, http.MethodPost, "/explain", `{"query": "SELECT name FROM users WHERE id = ?", "params": [1], "database": "app"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, res["plan"], "users")
+
+	rec, res = doHTTP(t, handler, http.MethodPost, "/explain", `{"query": "BEGIN"}`, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "unsupported", res["error"].(map[string]any)["code"])
+}
+
+func TestHTTPHandler_Catalogs(t *testing.T) {
+	handler := NewHTTPHandler(WithRegistry(newTestRegistry(t)))
+
+	tests := []struct {
+		path   string
+		status int
+		body   map[string]any
+	}{
+		{
+			path:   "/catalogs",
+			status: http.StatusOK,
+			body:   map[string]any{"catalogs": []any{"app", "information_schema", "other"}},
+		},
+		{
+			path:   "/catalogs/app/tables",
+			status: http.StatusOK,
+			body:   map[string]any{"tables": []any{"users"}},
+		},
+		{
+			path:   "/catalogs/other/tables/teams",
+			status: http.StatusOK,
+			body:   map[string]any{"name": "teams", "columns": []any{}, "indexes": []any{}},
+		},
+		{
+			path:   "/catalogs/missing/tables",
+			status: http.StatusNotFound,
+		},
+		{
+			path:   "/catalogs/app/tables/missing",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec, res := doHTTP(t, handler, http.MethodGet, tt.path, "", nil)
+			require.Equal(t, tt.status, rec.Code)
+			if tt.body != nil {
+				require.Equal(t, tt.body, res)
+			}
+		})
+	}
+
+	rec, res := doHTTP(t, handler, http.MethodGet, "/catalogs/app/tables/users", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, res["columns"], 5)
+	require.Equal(t, []any{map[string]any{"name": "id", "columns": []any{"id"}}}, res["indexes"])
+}
+
+func TestHTTPHandler_Auth(t *testing.T) {
+	token := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Token") != "secret" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	handler := NewHTTPHandler(WithRegistry(newTestRegistry(t)), WithUser("root", "secret"), WithMiddleware(token))
+
+	tests := []struct {
+		header http.Header
+		status int
+	}{
+		{header: http.Header{"X-Token": {"secret"}, "Authorization": {"Basic cm9vdDpzZWNyZXQ="}}, status: http.StatusOK},
+		{header: http.Header{"X-Token": {"secret"}, "Authorization": {"Basic cm9vdDp3cm9uZw=="}}, status: http.StatusUnauthorized},
+		{header: http.Header{"X-Token": {"secret"}}, status: http.StatusUnauthorized},
+		{header: http.Header{"Authorization": {"Basic cm9vdDpzZWNyZXQ="}}, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		rec, _ := doHTTP(t, handler, http.MethodGet, "/catalogs", "", tt.header)
+		require.Equal(t, tt.status, rec.Code)
+	}
+}
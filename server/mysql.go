@@ -0,0 +1,1025 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/engine"
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+// MySQLServer serves the registry to clients speaking the MySQL client/server protocol. Each connection has a
+// session of its own, whose database is the one named on connect or by COM_INIT_DB, and which runs text queries as
+// well as prepared statements.
+type MySQLServer struct {
+	options  *options
+	acceptor *acceptor
+	id       atomic.Uint32
+}
+
+type mysqlConn struct {
+	server       *MySQLServer
+	conn         net.Conn
+	reader       *bufio.Reader
+	writer       *bufio.Writer
+	sequence     uint8
+	id           uint32
+	capabilities uint32
+	session      *session
+	stmts        map[uint32]*mysqlStmt
+	stmt         uint32
+}
+
+type mysqlStmt struct {
+	statement *statement
+	types     []byte
+	long      map[int][]byte
+	err       error
+}
+
+// mysqlError is an error reported to the client with a MySQL error code and SQLSTATE.
+type mysqlError struct {
+	code  uint16
+	state string
+	err   error
+}
+
+const (
+	mysqlServerVersion = "8.0.0-sqlbridge"
+	mysqlAuthPlugin    = "mysql_native_password"
+	mysqlMaxPacketSize = 1<<24 - 1
+)
+
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientFoundRows        = 0x00000002
+	mysqlClientLongFlag         = 0x00000004
+	mysqlClientConnectWithDB    = 0x00000008
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientTransactions     = 0x00002000
+	mysqlClientSecureConnection = 0x00008000
+	mysqlClientMultiResults     = 0x00020000
+	mysqlClientPluginAuth       = 0x00080000
+	mysqlClientConnectAttrs     = 0x00100000
+	mysqlClientPluginAuthLenenc = 0x00200000
+)
+
+const (
+	mysqlComQuit             = 0x01
+	mysqlComInitDB           = 0x02
+	mysqlComQuery            = 0x03
+	mysqlComFieldList        = 0x04
+	mysqlComPing             = 0x0e
+	mysqlComStmtPrepare      = 0x16
+	mysqlComStmtExecute      = 0x17
+	mysqlComStmtSendLongData = 0x18
+	mysqlComStmtClose        = 0x19
+	mysqlComStmtReset        = 0x1a
+	mysqlComResetConnection  = 0x1f
+)
+
+const (
+	mysqlTypeTiny         = 0x01
+	mysqlTypeShort        = 0x02
+	mysqlTypeLong         = 0x03
+	mysqlTypeFloat        = 0x04
+	mysqlTypeDouble       = 0x05
+	mysqlTypeNull         = 0x06
+	mysqlTypeTimestamp    = 0x07
+	mysqlTypeLongLong     = 0x08
+	mysqlTypeInt24        = 0x09
+	mysqlTypeDate         = 0x0a
+	mysqlTypeTime         = 0x0b
+	mysqlTypeDatetime     = 0x0c
+	mysqlTypeYear         = 0x0d
+	mysqlTypeVarString    = 0xfd
+	mysqlFlagNotNull      = 0x0001
+	mysqlFlagUnsigned     = 0x0020
+	mysqlFlagBinary       = 0x0080
+	mysqlCharsetUTF8MB4   = 45
+	mysqlCharsetBinary    = 63
+	mysqlStatusAutocommit = 0x0002
+)
+
+const mysqlCapabilities = mysqlClientLongPassword | mysqlClientFoundRows | mysqlClientLongFlag | mysqlClientConnectWithDB |
+	mysqlClientProtocol41 | mysqlClientTransactions | mysqlClientSecureConnection | mysqlClientMultiResults |
+	mysqlClientPluginAuth | mysqlClientConnectAttrs | mysqlClientPluginAuthLenenc
+
+var (
+	errMySQLPacket     = errors.New("malformed packet")
+	errMySQLPacketSize = errors.New("got a packet bigger than 'max_allowed_packet' bytes")
+)
+
+// NewMySQLServer returns a server that is not yet listening.
+func NewMySQLServer(opts ...Option) *MySQLServer {
+	return &MySQLServer{options: newOptions(opts...), acceptor: newAcceptor()}
+}
+
+// ListenAndServe listens on the TCP address addr and serves the connections it accepts.
+func (s *MySQLServer) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves the connections listener accepts until the server is closed, returning ErrServerClosed then.
+func (s *MySQLServer) Serve(listener net.Listener) error {
+	return s.acceptor.serve(listener, func(ctx context.Context, conn net.Conn) {
+		c := &mysqlConn{
+			server: s,
+			conn:   conn,
+			reader: bufio.NewReader(conn),
+			writer: bufio.NewWriter(conn),
+			id:     s.id.Add(1),
+			stmts:  map[uint32]*mysqlStmt{},
+		}
+		c.serve(ctx)
+	})
+}
+
+// Close stops the server from accepting connections, closes the open ones and waits for them to finish.
+func (s *MySQLServer) Close() error {
+	return s.acceptor.close()
+}
+
+func (e *mysqlError) Error() string {
+	return e.err.Error()
+}
+
+func (e *mysqlError) Unwrap() error {
+	return e.err
+}
+
+func (c *mysqlConn) serve(ctx context.Context) {
+	if err := c.handshake(); err != nil {
+		return
+	}
+	for {
+		packet, err := c.readPacket()
+		if errors.Is(err, errMySQLPacketSize) {
+			_ = c.writeError(err)
+		}
+		if err != nil || len(packet) == 0 {
+			return
+		}
+		if packet[0] == mysqlComQuit {
+			return
+		}
+		if err := protect(func() error { return c.dispatch(ctx, packet[0], packet[1:]) }); err != nil {
+			if errors.Is(err, errPanic) {
+				_ = c.writeError(err)
+			}
+			return
+		}
+	}
+}
+
+// handshake authenticates the client and opens its session.
+func (c *mysqlConn) handshake() error {
+	if err := c.conn.SetDeadline(time.Now().Add(c.server.options.handshake)); err != nil {
+		return err
+	}
+
+	salt := make([]byte, 20)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	for i := range salt {
+		// The scramble is printable, as MySQL generates it.
+		salt[i] = salt[i]%94 + 33
+	}
+
+	var packet []byte
+	packet = append(packet, 10)
+	packet = append(packet, mysqlServerVersion...)
+	packet = append(packet, 0)
+	packet = binary.LittleEndian.AppendUint32(packet, c.id)
+	packet = append(packet, salt[:8]...)
+	packet = append(packet, 0)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(mysqlCapabilities&0xffff))
+	packet = append(packet, mysqlCharsetUTF8MB4)
+	packet = binary.LittleEndian.AppendUint16(packet, mysqlStatusAutocommit)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(mysqlCapabilities>>16))
+	packet = append(packet, byte(len(salt)+1))
+	packet = append(packet, make([]byte, 10)...)
+	packet = append(packet, salt[8:]...)
+	packet = append(packet, 0)
+	packet = append(packet, mysqlAuthPlugin...)
+	packet = append(packet, 0)
+	if err := c.writePacket(packet); err != nil {
+		return err
+	}
+	if err := c.writer.Flush(); err != nil {
+		return err
+	}
+
+	packet, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	user, auth, database, plugin, err := c.parseHandshakeResponse(packet)
+	if err != nil {
+		return c.writeError(&mysqlError{code: 1043, state: "08S01", err: errors.New("bad handshake")})
+	}
+
+	if plugin != "" && plugin != mysqlAuthPlugin {
+		var request []byte
+		request = append(request, 0xfe)
+		request = append(request, mysqlAuthPlugin...)
+		request = append(request, 0)
+		request = append(request, salt...)
+		request = append(request, 0)
+		if err := c.writePacket(request); err != nil {
+			return err
+		}
+		if err := c.writer.Flush(); err != nil {
+			return err
+		}
+		if auth, err = c.readPacket(); err != nil {
+			return err
+		}
+	}
+
+	if !c.server.options.authenticate(user, func(password string) bool {
+		return subtle.ConstantTimeCompare(auth, mysqlScramble(salt, password)) == 1
+	}) {
+		_ = c.writeError(&mysqlError{code: 1045, state: "28000", err: fmt.Errorf("access denied for user '%s'", user)})
+		return errors.New("access denied")
+	}
+
+	if c.session, err = c.server.options.session(database); err != nil {
+		_ = c.writeError(err)
+		return err
+	}
+	if err := c.writeOK(); err != nil {
+		return err
+	}
+	return c.conn.SetDeadline(time.Time{})
+}
+
+func (c *mysqlConn) parseHandshakeResponse(packet []byte) (user string, auth []byte, database, plugin string, err error) {
+	if len(packet) < 32 {
+		return "", nil, "", "", errMySQLPacket
+	}
+	c.capabilities = binary.LittleEndian.Uint32(packet) & mysqlCapabilities
+	if c.capabilities&mysqlClientProtocol41 == 0 {
+		return "", nil, "", "", errMySQLPacket
+	}
+
+	data := packet[32:]
+	if user, data, err = mysqlReadString(data); err != nil {
+		return
+	}
+	switch {
+	case c.capabilities&mysqlClientPluginAuthLenenc != 0:
+		auth, data, err = mysqlReadLenencBytes(data)
+	case c.capabilities&mysqlClientSecureConnection != 0:
+		if len(data) < 1 || len(data) < 1+int(data[0]) {
+			return "", nil, "", "", errMySQLPacket
+		}
+		auth, data = data[1:1+int(data[0])], data[1+int(data[0]):]
+	default:
+		var s string
+		s, data, err = mysqlReadString(data)
+		auth = []byte(s)
+	}
+	if err != nil {
+		return
+	}
+	if c.capabilities&mysqlClientConnectWithDB != 0 && len(data) > 0 {
+		if database, data, err = mysqlReadString(data); err != nil {
+			return
+		}
+	}
+	if c.capabilities&mysqlClientPluginAuth != 0 && len(data) > 0 {
+		if plugin, _, err = mysqlReadString(data); err != nil {
+			return
+		}
+	}
+	return user, auth, database, plugin, nil
+}
+
+// dispatch runs a command, returning an error only if the connection is unusable.
+func (c *mysqlConn) dispatch(ctx context.Context, command byte, data []byte) error {
+	switch command {
+	case mysqlComInitDB:
+		if err := c.session.use(string(data)); err != nil {
+			return c.writeError(err)
+		}
+		return c.writeOK()
+	case mysqlComPing:
+		return c.writeOK()
+	case mysqlComQuery:
+		stmt, err := c.session.prepare(string(data), 0)
+		if err != nil {
+			return c.writeError(err)
+		}
+		res, err := c.session.run(ctx, stmt, nil)
+		if err != nil {
+			return c.writeError(err)
+		}
+		return c.writeResult(res, false)
+	case mysqlComFieldList:
+		return c.writeEOF()
+	case mysqlComStmtPrepare:
+		return c.prepare(ctx, string(data))
+	case mysqlComStmtExecute:
+		return c.execute(ctx, data)
+	case mysqlComStmtSendLongData:
+		if len(data) >= 6 {
+			if stmt, ok := c.stmts[binary.LittleEndian.Uint32(data)]; ok {
+				param := int(binary.LittleEndian.Uint16(data[4:]))
+				if len(stmt.long[param])+len(data[6:]) > c.server.options.maxPacket {
+					// The command has no response, so the error waits for the execution.
+					delete(stmt.long, param)
+					stmt.err = fmt.Errorf("parameter %d: %w", param, errMySQLPacketSize)
+					return nil
+				}
+				stmt.long[param] = append(stmt.long[param], data[6:]...)
+			}
+		}
+		return nil
+	case mysqlComStmtClose:
+		if len(data) >= 4 {
+			delete(c.stmts, binary.LittleEndian.Uint32(data))
+		}
+		return nil
+	case mysqlComStmtReset:
+		if len(data) >= 4 {
+			if stmt, ok := c.stmts[binary.LittleEndian.Uint32(data)]; ok {
+				stmt.long, stmt.err = map[int][]byte{}, nil
+			}
+		}
+		return c.writeOK()
+	case mysqlComResetConnection:
+		c.stmts = map[uint32]*mysqlStmt{}
+		return c.writeOK()
+	}
+	return c.writeError(&mysqlError{code: 1047, state: "08S01", err: fmt.Errorf("unknown command %d", command)})
+}
+
+func (c *mysqlConn) prepare(ctx context.Context, query string) error {
+	query, params := engine.BindQuestionMarks(query)
+	stmt, err := c.session.prepare(query, params)
+	if err != nil {
+		return c.writeError(err)
+	}
+
+	columns, err := c.session.describe(ctx, stmt)
+	if err != nil {
+		return c.writeError(err)
+	}
+
+	c.stmt++
+	c.stmts[c.stmt] = &mysqlStmt{statement: stmt, long: map[int][]byte{}}
+
+	var packet []byte
+	packet = append(packet, 0)
+	packet = binary.LittleEndian.AppendUint32(packet, c.stmt)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(columns)))
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(params))
+	packet = append(packet, 0, 0, 0)
+	if err := c.writePacket(packet); err != nil {
+		return err
+	}
+
+	if params > 0 {
+		for range params {
+			if err := c.writePacket(c.column("", "?", mysqlTypeVarString, 0, mysqlCharsetBinary, 0, 0)); err != nil {
+				return err
+			}
+		}
+		if err := c.writeEOF(); err != nil {
+			return err
+		}
+	}
+	if len(columns) > 0 {
+		for _, col := range columns {
+			if err := c.writePacket(c.columnDefinition(col)); err != nil {
+				return err
+			}
+		}
+		if err := c.writeEOF(); err != nil {
+			return err
+		}
+	}
+	return c.writer.Flush()
+}
+
+func (c *mysqlConn) execute(ctx context.Context, data []byte) error {
+	if len(data) < 9 {
+		return c.writeError(&mysqlError{code: 1047, state: "08S01", err: errMySQLPacket})
+	}
+	stmt, ok := c.stmts[binary.LittleEndian.Uint32(data)]
+	if !ok {
+		return c.writeError(&mysqlError{code: 1243, state: "HY000", err: errors.New("unknown prepared statement handler")})
+	}
+	if err := stmt.err; err != nil {
+		stmt.long, stmt.err = map[int][]byte{}, nil
+		return c.writeError(err)
+	}
+	data = data[9:]
+
+	params := stmt.statement.params
+	args := make([]any, params)
+	if params > 0 {
+		n := (params + 7) / 8
+		if len(data) < n+1 {
+			return c.writeError(&mysqlError{code: 1047, state: "08S01", err: errMySQLPacket})
+		}
+		nulls := data[:n]
+		bound := data[n]
+		data = data[n+1:]
+		if bound == 1 {
+			if len(data) < params*2 {
+				return c.writeError(&mysqlError{code: 1047, state: "08S01", err: errMySQLPacket})
+			}
+			stmt.types = append(stmt.types[:0], data[:params*2]...)
+			data = data[params*2:]
+		}
+		if len(stmt.types) < params*2 {
+			return c.writeError(&mysqlError{code: 1210, state: "HY000", err: errors.New("incorrect arguments to mysqld_stmt_execute")})
+		}
+
+		for i := range params {
+			if long, ok := stmt.long[i]; ok {
+				args[i] = string(long)
+				continue
+			}
+			if nulls[i/8]&(1<<(i%8)) != 0 {
+				continue
+			}
+			var err error
+			if args[i], data, err = mysqlReadParam(data, stmt.types[i*2], stmt.types[i*2+1]&0x80 != 0); err != nil {
+				return c.writeError(&mysqlError{code: 1210, state: "HY000", err: err})
+			}
+		}
+		stmt.long = map[int][]byte{}
+	}
+
+	res, err := c.session.run(ctx, stmt.statement, args)
+	if err != nil {
+		return c.writeError(err)
+	}
+	return c.writeResult(res, true)
+}
+
+// writeResult writes the rows of res as a result set, in the binary encoding of prepared statements if binary is set,
+// or an OK packet if it has no columns.
+func (c *mysqlConn) writeResult(res *result, binary bool) error {
+	if len(res.columns) == 0 {
+		if _, err := res.drain(); err != nil {
+			return c.writeError(err)
+		}
+		return c.writeOK()
+	}
+	defer res.Close()
+
+	if err := c.writePacket(mysqlAppendLenencInt(nil, uint64(len(res.columns)))); err != nil {
+		return err
+	}
+	for _, col := range res.columns {
+		if err := c.writePacket(c.columnDefinition(col)); err != nil {
+			return err
+		}
+	}
+	if err := c.writeEOF(); err != nil {
+		return err
+	}
+
+	for {
+		values, err := res.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return c.writeError(err)
+		}
+
+		var row []byte
+		if binary {
+			row, err = mysqlBinaryRow(res.columns, values)
+		} else {
+			row = mysqlTextRow(res.columns, values)
+		}
+		if err != nil {
+			return c.writeError(err)
+		}
+		if err := c.writePacket(row); err != nil {
+			return err
+		}
+	}
+	return c.writeEOF()
+}
+
+func (c *mysqlConn) columnDefinition(col schema.Column) []byte {
+	typ, flags := mysqlType(col.Type)
+	if !col.Nullable {
+		flags |= mysqlFlagNotNull
+	}
+	charset := byte(mysqlCharsetUTF8MB4)
+	if flags&mysqlFlagBinary != 0 || sqltypes.IsIntegral(col.Type) || sqltypes.IsFloat(col.Type) || col.Type == querypb.Type_DECIMAL {
+		charset = mysqlCharsetBinary
+	}
+
+	length := uint32(col.Length)
+	if length == 0 {
+		length = 255
+	}
+	decimals := byte(col.Scale)
+	if sqltypes.IsFloat(col.Type) && col.Scale == 0 {
+		decimals = 0x1f
+	}
+
+	return c.column(col.Name.Qualifier.Name.String(), col.Name.Name.String(), typ, flags, charset, length, decimals)
+}
+
+func (c *mysqlConn) column(table, name string, typ byte, flags uint16, charset byte, length uint32, decimals byte) []byte {
+	var packet []byte
+	packet = mysqlAppendLenencString(packet, "def")
+	packet = mysqlAppendLenencString(packet, c.session.database())
+	packet = mysqlAppendLenencString(packet, table)
+	packet = mysqlAppendLenencString(packet, table)
+	packet = mysqlAppendLenencString(packet, name)
+	packet = mysqlAppendLenencString(packet, name)
+	packet = append(packet, 0x0c)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(charset))
+	packet = binary.LittleEndian.AppendUint32(packet, length)
+	packet = append(packet, typ)
+	packet = binary.LittleEndian.AppendUint16(packet, flags)
+	packet = append(packet, decimals, 0, 0)
+	return packet
+}
+
+func (c *mysqlConn) writeOK() error {
+	packet := []byte{0, 0, 0}
+	packet = binary.LittleEndian.AppendUint16(packet, mysqlStatusAutocommit)
+	packet = binary.LittleEndian.AppendUint16(packet, 0)
+	if err := c.writePacket(packet); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+func (c *mysqlConn) writeEOF() error {
+	packet := []byte{0xfe, 0, 0}
+	packet = binary.LittleEndian.AppendUint16(packet, mysqlStatusAutocommit)
+	if err := c.writePacket(packet); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+func (c *mysqlConn) writeError(err error) error {
+	var e *mysqlError
+	if !errors.As(err, &e) {
+		e = &mysqlError{code: 1105, state: "HY000", err: err}
+		switch {
+		case errors.Is(err, errMySQLPacketSize):
+			e.code, e.state = 1153, "08S01"
+		case errors.Is(err, ErrSyntax):
+			e.code, e.state = 1064, "42000"
+		case errors.Is(err, schema.ErrCatalogNotFound):
+			e.code, e.state = 1049, "42000"
+		case errors.Is(err, schema.ErrTableNotFound), errors.Is(err, schema.ErrViewNotFound):
+			e.code, e.state = 1146, "42S02"
+		case errors.Is(err, schema.ErrTableExists):
+			e.code, e.state = 1050, "42S01"
+		case errors.Is(err, engine.ErrUnknownColumn):
+			e.code, e.state = 1054, "42S22"
+		case errors.Is(err, engine.ErrAmbiguousColumn):
+			e.code, e.state = 1052, "23000"
+		case errors.Is(err, engine.ErrNotGrouped):
+			e.code, e.state = 1055, "42000"
+		case errors.Is(err, engine.ErrGroupFunction):
+			e.code, e.state = 1111, "HY000"
+		case errors.Is(err, engine.ErrArgumentCount):
+			e.code, e.state = 1582, "42000"
+		case errors.Is(err, engine.ErrColumnCount):
+			e.code, e.state = 1222, "21000"
+		case errors.Is(err, engine.ErrUnknownVariable):
+			e.code, e.state = 1193, "HY000"
+		}
+	}
+
+	packet := []byte{0xff}
+	packet = binary.LittleEndian.AppendUint16(packet, e.code)
+	packet = append(packet, '#')
+	packet = append(packet, e.state...)
+	packet = append(packet, e.Error()...)
+	if err := c.writePacket(packet); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// readPacket reads the payload of a packet, joining the packets it is split into if it exceeds their maximum size,
+// up to max_allowed_packet bytes.
+func (c *mysqlConn) readPacket() ([]byte, error) {
+	var payload []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+			return nil, err
+		}
+		size := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+		c.sequence = header[3] + 1
+		if len(payload)+size > c.server.options.maxPacket {
+			return nil, errMySQLPacketSize
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		payload = append(payload, data...)
+		if size < mysqlMaxPacketSize {
+			return payload, nil
+		}
+	}
+}
+
+// writePacket buffers payload, split into packets of the maximum size.
+func (c *mysqlConn) writePacket(payload []byte) error {
+	for {
+		size := min(len(payload), mysqlMaxPacketSize)
+		header := []byte{byte(size), byte(size >> 8), byte(size >> 16), c.sequence}
+		c.sequence++
+		if _, err := c.writer.Write(header); err != nil {
+			return err
+		}
+		if _, err := c.writer.Write(payload[:size]); err != nil {
+			return err
+		}
+		payload = payload[size:]
+		if size < mysqlMaxPacketSize {
+			return nil
+		}
+	}
+}
+
+// mysqlScramble returns the mysql_native_password response to salt for password.
+func mysqlScramble(salt []byte, password string) []byte {
+	if password == "" {
+		return []byte{}
+	}
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+
+	h := sha1.New()
+	h.Write(salt)
+	h.Write(stage2[:])
+	scramble := h.Sum(nil)
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble
+}
+
+// mysqlType returns the MySQL column type and flags of typ, sending types MySQL lacks as strings.
+func mysqlType(typ querypb.Type) (byte, uint16) {
+	t, flags := sqltypes.TypeToMySQL(typ)
+	if t == 0 || t == mysqlTypeNull {
+		return mysqlTypeVarString, 0
+	}
+	return byte(t), uint16(flags)
+}
+
+func mysqlTextRow(columns []schema.Column, values []sqltypes.Value) []byte {
+	var row []byte
+	for i, value := range values {
+		if value.IsNull() {
+			row = append(row, 0xfb)
+			continue
+		}
+		row = mysqlAppendLenencString(row, mysqlText(columns[i].Type, value))
+	}
+	return row
+}
+
+// mysqlText returns value as MySQL formats a value of type typ, turning the dates and times tables hold in other
+// layouts into its own.
+func mysqlText(typ querypb.Type, value sqltypes.Value) string {
+	switch typ {
+	case querypb.Type_DATETIME, querypb.Type_TIMESTAMP:
+		if t, ok := parseTime(value.ToString()); ok {
+			return t.Format("2006-01-02 15:04:05.999999")
+		}
+	case querypb.Type_DATE:
+		if t, ok := parseTime(value.ToString()); ok {
+			return t.Format(time.DateOnly)
+		}
+	}
+	return value.ToString()
+}
+
+func mysqlBinaryRow(columns []schema.Column, values []sqltypes.Value) ([]byte, error) {
+	row := []byte{0}
+	nulls := make([]byte, (len(values)+7+2)/8)
+	row = append(row, nulls...)
+
+	for i, value := range values {
+		if value.IsNull() {
+			row[1+(i+2)/8] |= 1 << ((i + 2) % 8)
+			continue
+		}
+
+		typ, flags := mysqlType(columns[i].Type)
+		s := mysqlText(columns[i].Type, value)
+		switch typ {
+		case mysqlTypeTiny, mysqlTypeShort, mysqlTypeYear, mysqlTypeLong, mysqlTypeInt24, mysqlTypeLongLong:
+			var n uint64
+			if flags&mysqlFlagUnsigned != 0 {
+				u, err := strconv.ParseUint(s, 10, 64)
+				if err != nil {
+					f, ferr := strconv.ParseFloat(s, 64)
+					if ferr != nil {
+						return nil, err
+					}
+					u = uint64(f)
+				}
+				n = u
+			} else {
+				v, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					f, ferr := strconv.ParseFloat(s, 64)
+					if ferr != nil {
+						return nil, err
+					}
+					v = int64(f)
+				}
+				n = uint64(v)
+			}
+			switch typ {
+			case mysqlTypeTiny:
+				row = append(row, byte(n))
+			case mysqlTypeShort, mysqlTypeYear:
+				row = binary.LittleEndian.AppendUint16(row, uint16(n))
+			case mysqlTypeLong, mysqlTypeInt24:
+				row = binary.LittleEndian.AppendUint32(row, uint32(n))
+			default:
+				row = binary.LittleEndian.AppendUint64(row, n)
+			}
+		case mysqlTypeFloat, mysqlTypeDouble:
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, err
+			}
+			if typ == mysqlTypeFloat {
+				row = binary.LittleEndian.AppendUint32(row, math.Float32bits(float32(f)))
+			} else {
+				row = binary.LittleEndian.AppendUint64(row, math.Float64bits(f))
+			}
+		case mysqlTypeDate, mysqlTypeDatetime, mysqlTypeTimestamp:
+			t, ok := parseTime(value.ToString())
+			if !ok {
+				return nil, fmt.Errorf("invalid datetime value %q", value.ToString())
+			}
+			row = mysqlAppendDatetime(row, t, typ == mysqlTypeDate)
+		case mysqlTypeTime:
+			d, err := parseDuration(s)
+			if err != nil {
+				return nil, err
+			}
+			row = mysqlAppendTime(row, d)
+		default:
+			row = mysqlAppendLenencString(row, s)
+		}
+	}
+	return row, nil
+}
+
+func mysqlAppendDatetime(b []byte, t time.Time, date bool) []byte {
+	switch {
+	case date || (t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0):
+		b = append(b, 4)
+	case t.Nanosecond() == 0:
+		b = append(b, 7)
+	default:
+		b = append(b, 11)
+	}
+	length := b[len(b)-1]
+	b = binary.LittleEndian.AppendUint16(b, uint16(t.Year()))
+	b = append(b, byte(t.Month()), byte(t.Day()))
+	if length > 4 {
+		b = append(b, byte(t.Hour()), byte(t.Minute()), byte(t.Second()))
+	}
+	if length > 7 {
+		b = binary.LittleEndian.AppendUint32(b, uint32(t.Nanosecond()/1000))
+	}
+	return b
+}
+
+func mysqlAppendTime(b []byte, d time.Duration) []byte {
+	if d == 0 {
+		return append(b, 0)
+	}
+	var negative byte
+	if d < 0 {
+		negative, d = 1, -d
+	}
+	micros := (d % time.Second) / time.Microsecond
+	if micros == 0 {
+		b = append(b, 8)
+	} else {
+		b = append(b, 12)
+	}
+	b = append(b, negative)
+	b = binary.LittleEndian.AppendUint32(b, uint32(d/(24*time.Hour)))
+	b = append(b, byte(d/time.Hour%24), byte(d/time.Minute%60), byte(d/time.Second%60))
+	if micros != 0 {
+		b = binary.LittleEndian.AppendUint32(b, uint32(micros))
+	}
+	return b
+}
+
+// mysqlReadParam reads a parameter of a prepared statement in the binary encoding of typ.
+func mysqlReadParam(data []byte, typ byte, unsigned bool) (any, []byte, error) {
+	need := func(n int) error {
+		if len(data) < n {
+			return errMySQLPacket
+		}
+		return nil
+	}
+
+	switch typ {
+	case mysqlTypeNull:
+		return nil, data, nil
+	case mysqlTypeTiny:
+		if err := need(1); err != nil {
+			return nil, nil, err
+		}
+		if unsigned {
+			return uint64(data[0]), data[1:], nil
+		}
+		return int64(int8(data[0])), data[1:], nil
+	case mysqlTypeShort, mysqlTypeYear:
+		if err := need(2); err != nil {
+			return nil, nil, err
+		}
+		v := binary.LittleEndian.Uint16(data)
+		if unsigned {
+			return uint64(v), data[2:], nil
+		}
+		return int64(int16(v)), data[2:], nil
+	case mysqlTypeLong, mysqlTypeInt24:
+		if err := need(4); err != nil {
+			return nil, nil, err
+		}
+		v := binary.LittleEndian.Uint32(data)
+		if unsigned {
+			return uint64(v), data[4:], nil
+		}
+		return int64(int32(v)), data[4:], nil
+	case mysqlTypeLongLong:
+		if err := need(8); err != nil {
+			return nil, nil, err
+		}
+		v := binary.LittleEndian.Uint64(data)
+		if unsigned {
+			return v, data[8:], nil
+		}
+		return int64(v), data[8:], nil
+	case mysqlTypeFloat:
+		if err := need(4); err != nil {
+			return nil, nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), data[4:], nil
+	case mysqlTypeDouble:
+		if err := need(8); err != nil {
+			return nil, nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), data[8:], nil
+	case mysqlTypeDate, mysqlTypeDatetime, mysqlTypeTimestamp:
+		if err := need(1); err != nil {
+			return nil, nil, err
+		}
+		n := int(data[0])
+		if err := need(1 + n); err != nil {
+			return nil, nil, err
+		}
+		b := data[1 : 1+n]
+		var year, month, day, hour, minute, second, micro int
+		if n >= 4 {
+			year, month, day = int(binary.LittleEndian.Uint16(b)), int(b[2]), int(b[3])
+		}
+		if n >= 7 {
+			hour, minute, second = int(b[4]), int(b[5]), int(b[6])
+		}
+		if n >= 11 {
+			micro = int(binary.LittleEndian.Uint32(b[7:]))
+		}
+		t := time.Date(year, time.Month(month), day, hour, minute, second, micro*1000, time.UTC)
+		if typ == mysqlTypeDate {
+			return t.Format(time.DateOnly), data[1+n:], nil
+		}
+		return t.Format("2006-01-02 15:04:05.999999"), data[1+n:], nil
+	case mysqlTypeTime:
+		if err := need(1); err != nil {
+			return nil, nil, err
+		}
+		n := int(data[0])
+		if err := need(1 + n); err != nil {
+			return nil, nil, err
+		}
+		b := data[1 : 1+n]
+		var d time.Duration
+		if n >= 8 {
+			d = time.Duration(binary.LittleEndian.Uint32(b[1:]))*24*time.Hour + time.Duration(b[5])*time.Hour + time.Duration(b[6])*time.Minute + time.Duration(b[7])*time.Second
+		}
+		if n >= 12 {
+			d += time.Duration(binary.LittleEndian.Uint32(b[8:])) * time.Microsecond
+		}
+		sign := ""
+		if n >= 8 && b[0] == 1 {
+			sign = "-"
+		}
+		s := fmt.Sprintf("%s%02d:%02d:%02d", sign, int(d/time.Hour), int(d/time.Minute%60), int(d/time.Second%60))
+		if micros := d % time.Second / time.Microsecond; micros != 0 {
+			s += fmt.Sprintf(".%06d", micros)
+		}
+		return s, data[1+n:], nil
+	}
+
+	value, rest, err := mysqlReadLenencBytes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return string(value), rest, nil
+}
+
+func mysqlReadString(data []byte) (string, []byte, error) {
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i]), data[i+1:], nil
+		}
+	}
+	return "", nil, errMySQLPacket
+}
+
+func mysqlReadLenencInt(data []byte) (uint64, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, errMySQLPacket
+	}
+	switch data[0] {
+	case 0xfc:
+		if len(data) < 3 {
+			return 0, nil, errMySQLPacket
+		}
+		return uint64(binary.LittleEndian.Uint16(data[1:])), data[3:], nil
+	case 0xfd:
+		if len(data) < 4 {
+			return 0, nil, errMySQLPacket
+		}
+		return uint64(data[1]) | uint64(data[2])<<8 | uint64(data[3])<<16, data[4:], nil
+	case 0xfe:
+		if len(data) < 9 {
+			return 0, nil, errMySQLPacket
+		}
+		return binary.LittleEndian.Uint64(data[1:]), data[9:], nil
+	}
+	return uint64(data[0]), data[1:], nil
+}
+
+func mysqlReadLenencBytes(data []byte) ([]byte, []byte, error) {
+	n, data, err := mysqlReadLenencInt(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if uint64(len(data)) < n {
+		return nil, nil, errMySQLPacket
+	}
+	return data[:n], data[n:], nil
+}
+
+func mysqlAppendLenencInt(b []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return binary.LittleEndian.AppendUint16(append(b, 0xfc), uint16(n))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+	return binary.LittleEndian.AppendUint64(append(b, 0xfe), n)
+}
+
+func mysqlAppendLenencString(b []byte, s string) []byte {
+	return append(mysqlAppendLenencInt(b, uint64(len(s))), s...)
+}
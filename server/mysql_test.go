@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/siyul-park/sqlbridge/engine"
+	"github.com/stretchr/testify/require"
+)
+
+func newMySQLServer(t *testing.T, opts ...Option) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewMySQLServer(append([]Option{WithRegistry(newTestRegistry(t))}, opts...)...)
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+		require.ErrorIs(t, <-done, ErrServerClosed)
+	})
+	return listener.Addr().String()
+}
+
+func openMySQL(t *testing.T, dsn string) *sql.DB {
+	db, err := sql.Open("mysql", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestMySQLServer_Query(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newMySQLServer(t, WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn(), engine.WithString())))
+	db := openMySQL(t, "root@tcp("+addr+")/app?parseTime=true")
+
+	t.Run("text", func(t *testing.T) {
+		rows, err := db.QueryContext(ctx, "SELECT id, name, email, score, created_at FROM users ORDER BY id")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		types, err := rows.ColumnTypes()
+		require.NoError(t, err)
+		require.Equal(t, "BIGINT", types[0].DatabaseTypeName())
+		require.Equal(t, "VARCHAR", types[1].DatabaseTypeName())
+		require.Equal(t, "DATETIME", types[4].DatabaseTypeName())
+
+		var users []testUser
+		for rows.Next() {
+			var user testUser
+			var email sql.NullString
+			require.NoError(t, rows.Scan(&user.ID, &user.Name, &email, &user.Score, &user.Created))
+			if email.Valid {
+				user.Email = &email.String
+			}
+			users = append(users, user)
+		}
+		require.NoError(t, rows.Err())
+		require.Len(t, users, 2)
+		require.Equal(t, "foo@example.com", *users[0].Email)
+		require.Nil(t, users[1].Email)
+		require.Equal(t, 2.5, users[1].Score)
+		require.Equal(t, testCreated, users[0].Created)
+	})
+
+	t.Run("prepared", func(t *testing.T) {
+		stmt, err := db.PrepareContext(ctx, "SELECT id, name, email, score, created_at FROM users WHERE id = ? AND name = ?")
+		require.NoError(t, err)
+		defer stmt.Close()
+
+		var user testUser
+		var email sql.NullString
+		err = stmt.QueryRowContext(ctx, 2, "bar").Scan(&user.ID, &user.Name, &email, &user.Score, &user.Created)
+		require.NoError(t, err)
+		require.Equal(t, testUser{ID: 2, Name: "bar", Score: 2.5, Created: testCreated.Add(time.Hour)}, user)
+		require.False(t, email.Valid)
+
+		err = stmt.QueryRowContext(ctx, 1, "bar").Scan(&user.ID, &user.Name, &email, &user.Score, &user.Created)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("expressions", func(t *testing.T) {
+		var sum int64
+		var text string
+		var null sql.NullString
+		err := db.QueryRowContext(ctx, "SELECT id + ?, CONCAT(name, ?), NULL FROM users WHERE id = 1", 2, "b").Scan(&sum, &text, &null)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), sum)
+		require.Equal(t, "foob", text)
+		require.False(t, null.Valid)
+	})
+
+	t.Run("literals", func(t *testing.T) {
+		var names []string
+		rows, err := db.QueryContext(ctx, "SELECT CONCAT(name, '?') FROM users WHERE name <> 'what?' ORDER BY id")
+		require.NoError(t, err)
+		for rows.Next() {
+			var name string
+			require.NoError(t, rows.Scan(&name))
+			names = append(names, name)
+		}
+		require.NoError(t, rows.Close())
+		require.Equal(t, []string{"foo?", "bar?"}, names)
+
+		var name string
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT CONCAT(name, '?') /* ? */ FROM users WHERE id = ?", 1).Scan(&name))
+		require.Equal(t, "foo?", name)
+	})
+
+	t.Run("bootstrap", func(t *testing.T) {
+		tests := []struct {
+			query  string
+			values []string
+		}{
+			{query: "select @@version_comment limit 1", values: []string{"sqlbridge"}},
+			{query: "SELECT DATABASE()", values: []string{"app"}},
+			{query: "SELECT @@session.transaction_isolation", values: []string{"REPEATABLE-READ"}},
+			{query: "SELECT 1", values: []string{"1"}},
+			{query: "SHOW VARIABLES LIKE 'max_allowed_packet'", values: []string{"max_allowed_packet", "67108864"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				values := make([]string, len(tt.values))
+				dest := make([]any, len(values))
+				for i := range values {
+					dest[i] = &values[i]
+				}
+				require.NoError(t, db.QueryRowContext(ctx, tt.query).Scan(dest...))
+				require.Equal(t, tt.values, values)
+			})
+		}
+
+		rows, err := db.QueryContext(ctx, "SHOW VARIABLES")
+		require.NoError(t, err)
+		variables := map[string]string{}
+		for rows.Next() {
+			var name, value string
+			require.NoError(t, rows.Scan(&name, &value))
+			variables[name] = value
+		}
+		require.NoError(t, rows.Close())
+		require.Equal(t, "8.0.0-sqlbridge", variables["version"])
+		require.Equal(t, "utf8mb4", variables["character_set_server"])
+	})
+
+	t.Run("use", func(t *testing.T) {
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.ExecContext(ctx, "USE other")
+		require.NoError(t, err)
+
+		var count int64
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM teams").Scan(&count))
+		require.Zero(t, count)
+
+		_, err = conn.ExecContext(ctx, "USE app")
+		require.NoError(t, err)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, "SET NAMES utf8mb4")
+		require.NoError(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			query string
+			code  uint16
+		}{
+			{query: "SELEC 1", code: 1064},
+			{query: "SELECT * FROM missing", code: 1146},
+			{query: "SELECT missing FROM users", code: 1054},
+			{query: "SELECT @@missing", code: 1193},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				_, err := db.QueryContext(ctx, tt.query)
+
+				var e *mysql.MySQLError
+				require.True(t, errors.As(err, &e), "%v", err)
+				require.Equal(t, tt.code, e.Number)
+			})
+		}
+	})
+}
+
+func TestMySQLServer_Panic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newMySQLServer(t, WithRegistry(newPanicRegistry()))
+	db := openMySQL(t, "root@tcp("+addr+")/app")
+
+	stmt, err := db.PrepareContext(ctx, "SELECT * FROM boom WHERE id = ?")
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	_, err = stmt.QueryContext(ctx, 1)
+	var e *mysql.MySQLError
+	require.True(t, errors.As(err, &e), "%v", err)
+	require.Equal(t, uint16(1105), e.Number)
+
+	require.NoError(t, db.PingContext(ctx))
+}
+
+func TestMySQLServer_Auth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newMySQLServer(t, WithUser("root", "secret"), WithUser("guest", ""))
+
+	tests := []struct {
+		dsn  string
+		code uint16
+	}{
+		{dsn: "root:secret@tcp(" + addr + ")/app"},
+		{dsn: "guest@tcp(" + addr + ")/"},
+		{dsn: "root:wrong@tcp(" + addr + ")/app", code: 1045},
+		{dsn: "nobody@tcp(" + addr + ")/app", code: 1045},
+		{dsn: "root:secret@tcp(" + addr + ")/missing", code: 1049},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			err := openMySQL(t, tt.dsn).PingContext(ctx)
+			if tt.code == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var e *mysql.MySQLError
+			require.True(t, errors.As(err, &e), "%v", err)
+			require.Equal(t, tt.code, e.Number)
+		})
+	}
+}
+
+func TestMySQLServer_Limits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newMySQLServer(t, WithMaxPacketSize(1024), WithHandshakeTimeout(100*time.Millisecond))
+
+	t.Run("oversized", func(t *testing.T) {
+		db := openMySQL(t, "root@tcp("+addr+")/app")
+
+		_, err := db.QueryContext(ctx, "SELECT '"+strings.Repeat("a", 2048)+"'")
+
+		var e *mysql.MySQLError
+		require.True(t, errors.As(err, &e), "%v", err)
+		require.Equal(t, uint16(1153), e.Number)
+	})
+
+	t.Run("long data", func(t *testing.T) {
+		// The driver sends arguments of half the packet size or more as long data.
+		db := openMySQL(t, "root@tcp("+addr+")/app?maxAllowedPacket=1024")
+
+		var value string
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT ?", strings.Repeat("a", 600)).Scan(&value))
+		require.Len(t, value, 600)
+
+		_, err := db.QueryContext(ctx, "SELECT ?", strings.Repeat("a", 2048))
+
+		var e *mysql.MySQLError
+		require.True(t, errors.As(err, &e), "%v", err)
+		require.Equal(t, uint16(1153), e.Number)
+
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT ?", "b").Scan(&value))
+		require.Equal(t, "b", value)
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+		// The server greets the client, then hangs up.
+		_, err = io.ReadAll(conn)
+		require.NoError(t, err)
+	})
+}
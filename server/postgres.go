@@ -0,0 +1,1173 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/engine"
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+)
+
+// PostgresServer serves the registry to clients speaking version 3 of the PostgreSQL frontend/backend protocol. Each
+// connection has a session of its own on the database named on startup, and runs simple queries as well as extended
+// ones that parse, bind and execute statements through portals. Queries are parsed as the engine's SQL, with $1, $2
+// and so on as placeholders.
+type PostgresServer struct {
+	options  *options
+	acceptor *acceptor
+	conns    sync.Map
+	id       atomic.Uint32
+}
+
+type pgConn struct {
+	server  *PostgresServer
+	conn    net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+	pid     uint32
+	secret  uint32
+	session *session
+	stmts   map[string]*pgStmt
+	portals map[string]*pgPortal
+	failed  bool
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+}
+
+type pgStmt struct {
+	statement *statement
+	query     string
+	oids      []uint32
+}
+
+type pgPortal struct {
+	stmt    *pgStmt
+	args    []any
+	formats []int16
+	result  *result
+	cancel  context.CancelFunc
+	rows    int64
+	done    bool
+}
+
+// pgError is an error reported to the client with a severity and SQLSTATE.
+type pgError struct {
+	severity string
+	code     string
+	err      error
+}
+
+const (
+	pgProtocolVersion = 196608
+	pgSSLRequest      = 80877103
+	pgGSSENCRequest   = 80877104
+	pgCancelRequest   = 80877102
+	pgServerVersion   = "14.0"
+)
+
+const (
+	pgOIDBool        = 16
+	pgOIDBytea       = 17
+	pgOIDName        = 19
+	pgOIDInt8        = 20
+	pgOIDInt2        = 21
+	pgOIDInt4        = 23
+	pgOIDText        = 25
+	pgOIDJSON        = 114
+	pgOIDFloat4      = 700
+	pgOIDFloat8      = 701
+	pgOIDUnknown     = 705
+	pgOIDBpchar      = 1042
+	pgOIDVarchar     = 1043
+	pgOIDDate        = 1082
+	pgOIDTime        = 1083
+	pgOIDTimestamp   = 1114
+	pgOIDTimestamptz = 1184
+	pgOIDNumeric     = 1700
+	pgOIDUUID        = 2950
+	pgOIDJSONB       = 3802
+)
+
+var errPGMessage = errors.New("malformed message")
+
+// pgEpoch is the origin of binary dates and timestamps.
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NewPostgresServer returns a server that is not yet listening.
+func NewPostgresServer(opts ...Option) *PostgresServer {
+	return &PostgresServer{options: newOptions(opts...), acceptor: newAcceptor()}
+}
+
+func (s *PostgresServer) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+func (s *PostgresServer) Serve(listener net.Listener) error {
+	return s.acceptor.serve(listener, func(ctx context.Context, conn net.Conn) {
+		c := &pgConn{
+			server:  s,
+			conn:    conn,
+			reader:  bufio.NewReader(conn),
+			writer:  bufio.NewWriter(conn),
+			pid:     s.id.Add(1),
+			stmts:   map[string]*pgStmt{},
+			portals: map[string]*pgPortal{},
+		}
+		c.serve(ctx)
+	})
+}
+
+func (s *PostgresServer) Close() error {
+	return s.acceptor.close()
+}
+
+func (e *pgError) Error() string {
+	return e.err.Error()
+}
+
+func (e *pgError) Unwrap() error {
+	return e.err
+}
+
+func (c *pgConn) serve(ctx context.Context) {
+	if err := c.startup(); err != nil {
+		return
+	}
+
+	c.server.conns.Store(c.pid, c)
+	defer c.server.conns.Delete(c.pid)
+	defer func() {
+		for _, portal := range c.portals {
+			portal.close()
+		}
+	}()
+
+	for {
+		typ, data, err := c.readMessage()
+		if err != nil || typ == 'X' {
+			return
+		}
+		if c.failed && typ != 'S' {
+			// The messages of a failed extended query are discarded up to the next Sync.
+			continue
+		}
+		if err := protect(func() error { return c.dispatch(ctx, typ, data) }); err != nil {
+			if errors.Is(err, errPanic) {
+				_ = c.writeError(err)
+			}
+			return
+		}
+	}
+}
+
+// startup negotiates the protocol, authenticates the client and opens its session.
+func (c *pgConn) startup() error {
+	if err := c.conn.SetDeadline(time.Now().Add(c.server.options.handshake)); err != nil {
+		return err
+	}
+
+	var params map[string]string
+	for params == nil {
+		var size [4]byte
+		if _, err := io.ReadFull(c.reader, size[:]); err != nil {
+			return err
+		}
+		n := int(binary.BigEndian.Uint32(size[:]))
+		if n < 8 || n > 1<<16 {
+			return errPGMessage
+		}
+		data := make([]byte, n-4)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return err
+		}
+
+		switch code := binary.BigEndian.Uint32(data); code {
+		case pgSSLRequest, pgGSSENCRequest:
+			if _, err := c.conn.Write([]byte{'N'}); err != nil {
+				return err
+			}
+		case pgCancelRequest:
+			if len(data) >= 12 {
+				c.server.cancelRequest(binary.BigEndian.Uint32(data[4:]), binary.BigEndian.Uint32(data[8:]))
+			}
+			return io.EOF
+		case pgProtocolVersion:
+			params = map[string]string{}
+			fields := strings.Split(string(data[4:]), "\x00")
+			for i := 0; i+1 < len(fields); i += 2 {
+				if fields[i] != "" {
+					params[fields[i]] = fields[i+1]
+				}
+			}
+		default:
+			_ = c.writeError(&pgError{severity: "FATAL", code: "0A000", err: fmt.Errorf("unsupported frontend protocol %d.%d", code>>16, code&0xffff)})
+			return errPGMessage
+		}
+	}
+
+	user := params["user"]
+	if len(c.server.options.users) > 0 {
+		salt := make([]byte, 4)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		if err := c.writeMessage('R', append(binary.BigEndian.AppendUint32(nil, 5), salt...)); err != nil {
+			return err
+		}
+		if err := c.writer.Flush(); err != nil {
+			return err
+		}
+
+		typ, data, err := c.readMessage()
+		if err != nil {
+			return err
+		}
+		response, _, _ := strings.Cut(string(data), "\x00")
+		if typ != 'p' || !c.server.options.authenticate(user, func(password string) bool {
+			return subtle.ConstantTimeCompare([]byte(response), []byte(pgMD5(user, password, salt))) == 1
+		}) {
+			_ = c.writeError(&pgError{severity: "FATAL", code: "28P01", err: fmt.Errorf("password authentication failed for user %q", user)})
+			return errors.New("access denied")
+		}
+	}
+
+	var err error
+	if c.session, err = c.server.options.session(params["database"]); err != nil {
+		_ = c.writeError(&pgError{severity: "FATAL", code: pgErrorCode(err), err: err})
+		return err
+	}
+
+	secret := make([]byte, 4)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	c.secret = binary.BigEndian.Uint32(secret)
+
+	if err := c.writeMessage('R', binary.BigEndian.AppendUint32(nil, 0)); err != nil {
+		return err
+	}
+	for _, param := range [][2]string{
+		{"server_version", pgServerVersion},
+		{"server_encoding", "UTF8"},
+		{"client_encoding", "UTF8"},
+		{"DateStyle", "ISO, MDY"},
+		{"TimeZone", c.server.options.location.String()},
+		{"integer_datetimes", "on"},
+		{"standard_conforming_strings", "on"},
+		{"application_name", params["application_name"]},
+	} {
+		if err := c.writeMessage('S', []byte(param[0]+"\x00"+param[1]+"\x00")); err != nil {
+			return err
+		}
+	}
+	if err := c.writeMessage('K', binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, c.pid), c.secret)); err != nil {
+		return err
+	}
+	if err := c.writeReady(); err != nil {
+		return err
+	}
+	return c.conn.SetDeadline(time.Time{})
+}
+
+// cancelRequest cancels the query the connection pid runs, if secret is its key.
+func (s *PostgresServer) cancelRequest(pid, secret uint32) {
+	value, ok := s.conns.Load(pid)
+	if !ok {
+		return
+	}
+	c := value.(*pgConn)
+	if c.secret != secret {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// dispatch handles a message, returning an error only if the connection is unusable.
+func (c *pgConn) dispatch(ctx context.Context, typ byte, data []byte) error {
+	switch typ {
+	case 'Q':
+		query, _, _ := strings.Cut(string(data), "\x00")
+		if err := c.query(ctx, query); err != nil {
+			return err
+		}
+		return c.writeReady()
+	case 'P':
+		return c.parse(data)
+	case 'B':
+		return c.bind(data)
+	case 'D':
+		return c.describe(ctx, data)
+	case 'E':
+		return c.execute(ctx, data)
+	case 'C':
+		if len(data) < 1 {
+			return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+		}
+		name, _, _ := strings.Cut(string(data[1:]), "\x00")
+		if data[0] == 'S' {
+			delete(c.stmts, name)
+		} else if portal, ok := c.portals[name]; ok {
+			portal.close()
+			delete(c.portals, name)
+		}
+		return c.writeMessage('3', nil)
+	case 'S':
+		c.failed = false
+		for name, portal := range c.portals {
+			portal.close()
+			delete(c.portals, name)
+		}
+		return c.writeReady()
+	case 'H':
+		return c.writer.Flush()
+	}
+	return c.fail(&pgError{severity: "ERROR", code: "08P01", err: fmt.Errorf("unsupported message type %q", typ)})
+}
+
+// query runs a simple query, whose rows are sent in text format.
+func (c *pgConn) query(ctx context.Context, query string) error {
+	query = strings.TrimRight(pgStripComments(query), "; \t\r\n")
+	if query == "" {
+		return c.writeMessage('I', nil)
+	}
+
+	stmt, err := c.session.prepare(query, 0)
+	if err != nil {
+		return c.writeError(err)
+	}
+
+	ctx, cancel := c.begin(ctx)
+	defer cancel()
+
+	res, err := c.session.run(ctx, stmt, nil)
+	if err != nil {
+		return c.writeError(err)
+	}
+	portal := &pgPortal{stmt: &pgStmt{statement: stmt, query: query}, result: res}
+	if len(res.columns) > 0 {
+		if err := c.writeRowDescription(res.columns, nil); err != nil {
+			return err
+		}
+	}
+	_, err = c.run(portal, 0)
+	return err
+}
+
+func (c *pgConn) parse(data []byte) error {
+	name, data, err1 := pgReadString(data)
+	query, data, err2 := pgReadString(data)
+	if err := errors.Join(err1, err2); err != nil || len(data) < 2 {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+4*n {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+	}
+
+	if _, ok := c.stmts[name]; ok && name != "" {
+		return c.fail(&pgError{severity: "ERROR", code: "42P05", err: fmt.Errorf("prepared statement %q already exists", name)})
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	bound, params := pgBindParams(query)
+	stmt, err := c.session.prepare(bound, params)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	oids := make([]uint32, max(params, n))
+	for i := range n {
+		oids[i] = binary.BigEndian.Uint32(data[2+4*i:])
+	}
+	c.stmts[name] = &pgStmt{statement: stmt, query: query, oids: oids}
+	return c.writeMessage('1', nil)
+}
+
+func (c *pgConn) bind(data []byte) error {
+	name, data, err1 := pgReadString(data)
+	stmtName, data, err2 := pgReadString(data)
+	if err := errors.Join(err1, err2); err != nil {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+	}
+	stmt, ok := c.stmts[stmtName]
+	if !ok {
+		return c.fail(&pgError{severity: "ERROR", code: "26000", err: fmt.Errorf("prepared statement %q does not exist", stmtName)})
+	}
+
+	formats, data, err := pgReadInt16s(data)
+	if err != nil || len(data) < 2 {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	if n != len(stmt.oids) {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: fmt.Errorf("bind message supplies %d parameters, but prepared statement requires %d", n, len(stmt.oids))})
+	}
+
+	args := make([]any, n)
+	for i := range n {
+		if len(data) < 4 {
+			return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+		}
+		size := int32(binary.BigEndian.Uint32(data))
+		data = data[4:]
+		if size < 0 {
+			continue
+		}
+		if len(data) < int(size) {
+			return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+		}
+		if args[i], err = pgDecodeParam(stmt.oids[i], pgFormat(formats, i), data[:size]); err != nil {
+			return c.fail(err)
+		}
+		data = data[size:]
+	}
+
+	results, _, err := pgReadInt16s(data)
+	if err != nil {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+	}
+
+	if portal, ok := c.portals[name]; ok {
+		portal.close()
+	}
+	c.portals[name] = &pgPortal{stmt: stmt, args: args, formats: results}
+	return c.writeMessage('2', nil)
+}
+
+func (c *pgConn) describe(ctx context.Context, data []byte) error {
+	if len(data) < 1 {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+	}
+	name, _, _ := strings.Cut(string(data[1:]), "\x00")
+
+	if data[0] == 'S' {
+		stmt, ok := c.stmts[name]
+		if !ok {
+			return c.fail(&pgError{severity: "ERROR", code: "26000", err: fmt.Errorf("prepared statement %q does not exist", name)})
+		}
+		columns, err := c.session.describe(ctx, stmt.statement)
+		if err != nil {
+			return c.fail(err)
+		}
+
+		description := binary.BigEndian.AppendUint16(nil, uint16(len(stmt.oids)))
+		for _, oid := range stmt.oids {
+			description = binary.BigEndian.AppendUint32(description, oid)
+		}
+		if err := c.writeMessage('t', description); err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			return c.writeMessage('n', nil)
+		}
+		return c.writeRowDescription(columns, nil)
+	}
+
+	portal, ok := c.portals[name]
+	if !ok {
+		return c.fail(&pgError{severity: "ERROR", code: "34000", err: fmt.Errorf("portal %q does not exist", name)})
+	}
+	if err := c.start(ctx, portal); err != nil {
+		return c.fail(err)
+	}
+	if len(portal.result.columns) == 0 {
+		return c.writeMessage('n', nil)
+	}
+	return c.writeRowDescription(portal.result.columns, portal.formats)
+}
+
+func (c *pgConn) execute(ctx context.Context, data []byte) error {
+	name, data, err := pgReadString(data)
+	if err != nil || len(data) < 4 {
+		return c.fail(&pgError{severity: "ERROR", code: "08P01", err: errPGMessage})
+	}
+	limit := int64(int32(binary.BigEndian.Uint32(data)))
+
+	portal, ok := c.portals[name]
+	if !ok {
+		return c.fail(&pgError{severity: "ERROR", code: "34000", err: fmt.Errorf("portal %q does not exist", name)})
+	}
+
+	if err := c.start(ctx, portal); err != nil {
+		return c.fail(err)
+	}
+	if portal.cancel != nil {
+		defer c.interrupt(portal.cancel)()
+	}
+	ok, err = c.run(portal, limit)
+	if err == nil && !ok {
+		c.failed = true
+	}
+	return err
+}
+
+// start runs the statement of portal unless it already is, under a context canceled once the portal is closed, as
+// its rows may be fetched over several executions.
+func (c *pgConn) start(ctx context.Context, portal *pgPortal) error {
+	if portal.result != nil || portal.done {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	release := c.interrupt(cancel)
+	res, err := c.session.run(ctx, portal.stmt.statement, portal.args)
+	release()
+	if err != nil {
+		cancel()
+		return err
+	}
+	portal.result, portal.cancel = res, cancel
+	return nil
+}
+
+// run sends up to limit rows of portal, or all of them if limit is not positive, reporting whether it succeeded.
+func (c *pgConn) run(portal *pgPortal, limit int64) (bool, error) {
+	if portal.done {
+		return true, c.writeMessage('C', []byte(pgCommandTag(portal.stmt.query, portal.rows, true)+"\x00"))
+	}
+
+	res := portal.result
+	if len(res.columns) == 0 {
+		portal.done = true
+		if _, err := res.drain(); err != nil {
+			return false, c.writeError(err)
+		}
+		return true, c.writeMessage('C', []byte(pgCommandTag(portal.stmt.query, 0, false)+"\x00"))
+	}
+
+	for sent := int64(0); limit <= 0 || sent < limit; sent++ {
+		values, err := res.Next()
+		if errors.Is(err, io.EOF) {
+			portal.close()
+			portal.done = true
+			return true, c.writeMessage('C', []byte(pgCommandTag(portal.stmt.query, portal.rows, true)+"\x00"))
+		}
+		if err != nil {
+			portal.close()
+			portal.done = true
+			return false, c.writeError(err)
+		}
+
+		row := binary.BigEndian.AppendUint16(nil, uint16(len(values)))
+		for i, value := range values {
+			if value.IsNull() {
+				row = binary.BigEndian.AppendUint32(row, math.MaxUint32)
+				continue
+			}
+			data, err := pgEncode(res.columns[i].Type, value.ToString(), pgFormat(portal.formats, i))
+			if err != nil {
+				portal.close()
+				portal.done = true
+				return false, c.writeError(err)
+			}
+			row = binary.BigEndian.AppendUint32(row, uint32(len(data)))
+			row = append(row, data...)
+		}
+		if err := c.writeMessage('D', row); err != nil {
+			return false, err
+		}
+		portal.rows++
+	}
+	return true, c.writeMessage('s', nil)
+}
+
+// begin returns a context of the query about to run, which a cancel request for the connection cancels.
+func (c *pgConn) begin(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	release := c.interrupt(cancel)
+	return ctx, func() {
+		release()
+		cancel()
+	}
+}
+
+// interrupt makes a cancel request for the connection call cancel until the returned function is called.
+func (c *pgConn) interrupt(cancel context.CancelFunc) func() {
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		c.cancel = nil
+		c.mu.Unlock()
+	}
+}
+
+func (c *pgConn) writeRowDescription(columns []schema.Column, formats []int16) error {
+	data := binary.BigEndian.AppendUint16(nil, uint16(len(columns)))
+	for i, col := range columns {
+		oid, size := pgType(col.Type)
+		modifier := int32(-1)
+		if oid == pgOIDNumeric && col.Precision > 0 {
+			modifier = int32(col.Precision<<16|col.Scale) + 4
+		}
+
+		data = append(data, col.Name.Name.String()...)
+		data = append(data, 0)
+		data = binary.BigEndian.AppendUint32(data, 0)
+		data = binary.BigEndian.AppendUint16(data, 0)
+		data = binary.BigEndian.AppendUint32(data, oid)
+		data = binary.BigEndian.AppendUint16(data, uint16(size))
+		data = binary.BigEndian.AppendUint32(data, uint32(modifier))
+		data = binary.BigEndian.AppendUint16(data, uint16(pgFormat(formats, i)))
+	}
+	return c.writeMessage('T', data)
+}
+
+func (c *pgConn) writeReady() error {
+	if err := c.writeMessage('Z', []byte{'I'}); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// fail reports err, discarding the messages of the extended query up to the next Sync.
+func (c *pgConn) fail(err error) error {
+	c.failed = true
+	return c.writeError(err)
+}
+
+func (c *pgConn) writeError(err error) error {
+	var e *pgError
+	if !errors.As(err, &e) {
+		e = &pgError{severity: "ERROR", code: pgErrorCode(err), err: err}
+	}
+
+	var data []byte
+	for _, field := range []struct {
+		typ   byte
+		value string
+	}{
+		{'S', e.severity},
+		{'V', e.severity},
+		{'C', e.code},
+		{'M', e.Error()},
+	} {
+		data = append(data, field.typ)
+		data = append(data, field.value...)
+		data = append(data, 0)
+	}
+	data = append(data, 0)
+	if err := c.writeMessage('E', data); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+func (c *pgConn) readMessage() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+	n := int(binary.BigEndian.Uint32(header[1:]))
+	if n < 4 || n-4 > c.server.options.maxPacket {
+		return 0, nil, errPGMessage
+	}
+	data := make([]byte, n-4)
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return 0, nil, err
+	}
+	return header[0], data, nil
+}
+
+func (c *pgConn) writeMessage(typ byte, data []byte) error {
+	header := []byte{typ}
+	header = binary.BigEndian.AppendUint32(header, uint32(len(data)+4))
+	if _, err := c.writer.Write(header); err != nil {
+		return err
+	}
+	_, err := c.writer.Write(data)
+	return err
+}
+
+func (p *pgPortal) close() {
+	if p.result != nil {
+		_ = p.result.Close()
+		p.result = nil
+	}
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+// pgErrorCode returns the SQLSTATE of err.
+func pgErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrSyntax), errors.Is(err, engine.ErrColumnCount):
+		return "42601"
+	case errors.Is(err, schema.ErrCatalogNotFound):
+		return "3D000"
+	case errors.Is(err, schema.ErrTableNotFound), errors.Is(err, schema.ErrViewNotFound):
+		return "42P01"
+	case errors.Is(err, schema.ErrTableExists):
+		return "42P07"
+	case errors.Is(err, engine.ErrUnknownColumn):
+		return "42703"
+	case errors.Is(err, engine.ErrAmbiguousColumn):
+		return "42702"
+	case errors.Is(err, engine.ErrNotGrouped), errors.Is(err, engine.ErrGroupFunction):
+		return "42803"
+	case errors.Is(err, engine.ErrArgumentCount):
+		return "42883"
+	case errors.Is(err, engine.ErrArgumentType):
+		return "42804"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "57014"
+	}
+	return "XX000"
+}
+
+// pgType returns the OID and size of the PostgreSQL type holding values of typ.
+func pgType(typ querypb.Type) (uint32, int16) {
+	switch typ {
+	case querypb.Type_INT8, querypb.Type_UINT8, querypb.Type_INT16, querypb.Type_YEAR:
+		return pgOIDInt2, 2
+	case querypb.Type_UINT16, querypb.Type_INT24, querypb.Type_UINT24, querypb.Type_INT32:
+		return pgOIDInt4, 4
+	case querypb.Type_UINT32, querypb.Type_INT64:
+		return pgOIDInt8, 8
+	case querypb.Type_UINT64, querypb.Type_DECIMAL:
+		return pgOIDNumeric, -1
+	case querypb.Type_FLOAT32:
+		return pgOIDFloat4, 4
+	case querypb.Type_FLOAT64:
+		return pgOIDFloat8, 8
+	case querypb.Type_DATE:
+		return pgOIDDate, 4
+	case querypb.Type_TIME:
+		return pgOIDTime, 8
+	case querypb.Type_DATETIME:
+		return pgOIDTimestamp, 8
+	case querypb.Type_TIMESTAMP:
+		return pgOIDTimestamptz, 8
+	case querypb.Type_VARCHAR:
+		return pgOIDVarchar, -1
+	case querypb.Type_BLOB, querypb.Type_VARBINARY, querypb.Type_BINARY, querypb.Type_BIT:
+		return pgOIDBytea, -1
+	case querypb.Type_JSON:
+		return pgOIDJSON, -1
+	}
+	return pgOIDText, -1
+}
+
+// pgEncode encodes the text of a value of typ in format, 0 for text and 1 for binary.
+func pgEncode(typ querypb.Type, s string, format int16) ([]byte, error) {
+	oid, _ := pgType(typ)
+
+	switch oid {
+	case pgOIDDate, pgOIDTimestamp, pgOIDTimestamptz:
+		t, ok := parseTime(s)
+		if !ok {
+			return []byte(s), nil
+		}
+		switch {
+		case format == 1 && oid == pgOIDDate:
+			return binary.BigEndian.AppendUint32(nil, uint32(int32(math.Floor(t.Sub(pgEpoch).Hours()/24)))), nil
+		case format == 1:
+			return binary.BigEndian.AppendUint64(nil, uint64(t.Sub(pgEpoch).Microseconds())), nil
+		case oid == pgOIDDate:
+			return []byte(t.Format(time.DateOnly)), nil
+		case oid == pgOIDTimestamp:
+			return []byte(t.Format("2006-01-02 15:04:05.999999")), nil
+		}
+		return []byte(t.Format("2006-01-02 15:04:05.999999-07")), nil
+	case pgOIDBytea:
+		if format == 1 {
+			return []byte(s), nil
+		}
+		return []byte(`\x` + hex.EncodeToString([]byte(s))), nil
+	}
+	if format != 1 {
+		return []byte(s), nil
+	}
+
+	switch oid {
+	case pgOIDInt2, pgOIDInt4, pgOIDInt8:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil {
+				return nil, err
+			}
+			n = int64(f)
+		}
+		switch oid {
+		case pgOIDInt2:
+			return binary.BigEndian.AppendUint16(nil, uint16(n)), nil
+		case pgOIDInt4:
+			return binary.BigEndian.AppendUint32(nil, uint32(n)), nil
+		}
+		return binary.BigEndian.AppendUint64(nil, uint64(n)), nil
+	case pgOIDFloat4, pgOIDFloat8:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		if oid == pgOIDFloat4 {
+			return binary.BigEndian.AppendUint32(nil, math.Float32bits(float32(f))), nil
+		}
+		return binary.BigEndian.AppendUint64(nil, math.Float64bits(f)), nil
+	case pgOIDNumeric:
+		return pgEncodeNumeric(s)
+	case pgOIDTime:
+		d, err := parseDuration(s)
+		if err != nil {
+			return nil, err
+		}
+		return binary.BigEndian.AppendUint64(nil, uint64(d.Microseconds())), nil
+	}
+	return []byte(s), nil
+}
+
+// pgDecodeParam decodes a parameter of the type oid sent in format, 0 for text and 1 for binary.
+func pgDecodeParam(oid uint32, format int16, data []byte) (any, error) {
+	invalid := func(err error) error {
+		return &pgError{severity: "ERROR", code: "22P02", err: err}
+	}
+
+	if format != 1 {
+		s := string(data)
+		switch oid {
+		case pgOIDInt2, pgOIDInt4, pgOIDInt8:
+			n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				return nil, invalid(err)
+			}
+			return n, nil
+		case pgOIDFloat4, pgOIDFloat8:
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, invalid(err)
+			}
+			return f, nil
+		case pgOIDBool:
+			b, err := strconv.ParseBool(strings.TrimSpace(s))
+			if err != nil {
+				return nil, invalid(err)
+			}
+			if b {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case pgOIDBytea:
+			if strings.HasPrefix(s, `\x`) {
+				b, err := hex.DecodeString(s[2:])
+				if err != nil {
+					return nil, invalid(err)
+				}
+				return b, nil
+			}
+			return data, nil
+		}
+		return s, nil
+	}
+
+	fixed := func(n int) error {
+		if len(data) != n {
+			return invalid(fmt.Errorf("incorrect binary data format for parameter of type %d", oid))
+		}
+		return nil
+	}
+
+	switch oid {
+	case pgOIDInt2:
+		if err := fixed(2); err != nil {
+			return nil, err
+		}
+		return int64(int16(binary.BigEndian.Uint16(data))), nil
+	case pgOIDInt4:
+		if err := fixed(4); err != nil {
+			return nil, err
+		}
+		return int64(int32(binary.BigEndian.Uint32(data))), nil
+	case pgOIDInt8:
+		if err := fixed(8); err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(data)), nil
+	case pgOIDFloat4:
+		if err := fixed(4); err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case pgOIDFloat8:
+		if err := fixed(8); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case pgOIDBool:
+		if err := fixed(1); err != nil {
+			return nil, err
+		}
+		return int64(data[0]), nil
+	case pgOIDBytea:
+		return data, nil
+	case pgOIDDate:
+		if err := fixed(4); err != nil {
+			return nil, err
+		}
+		return pgEpoch.AddDate(0, 0, int(int32(binary.BigEndian.Uint32(data)))).Format(time.DateOnly), nil
+	case pgOIDTime:
+		if err := fixed(8); err != nil {
+			return nil, err
+		}
+		return time.UnixMicro(int64(binary.BigEndian.Uint64(data))).UTC().Format("15:04:05.999999"), nil
+	case pgOIDTimestamp, pgOIDTimestamptz:
+		if err := fixed(8); err != nil {
+			return nil, err
+		}
+		return pgEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(data))) * time.Microsecond).Format("2006-01-02 15:04:05.999999"), nil
+	case pgOIDNumeric:
+		s, err := pgDecodeNumeric(data)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		return s, nil
+	case pgOIDUUID:
+		if err := fixed(16); err != nil {
+			return nil, err
+		}
+		s := hex.EncodeToString(data)
+		return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+	case pgOIDJSONB:
+		if len(data) < 1 {
+			return nil, invalid(errPGMessage)
+		}
+		return string(data[1:]), nil
+	case pgOIDText, pgOIDVarchar, pgOIDBpchar, pgOIDName, pgOIDJSON, pgOIDUnknown, 0:
+		return string(data), nil
+	}
+	return nil, &pgError{severity: "ERROR", code: "22P03", err: fmt.Errorf("unsupported binary format for parameter of type %d", oid)}
+}
+
+// pgEncodeNumeric encodes a decimal in the binary format of numeric, as groups of four digits.
+func pgEncodeNumeric(s string) ([]byte, error) {
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimLeft(s, "+-")
+	integer, fraction, _ := strings.Cut(digits, ".")
+	if strings.Trim(integer+fraction, "0123456789") != "" || integer+fraction == "" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("invalid numeric value %q", s)
+		}
+		return pgEncodeNumeric(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+
+	scale := len(fraction)
+	integer = strings.TrimLeft(integer, "0")
+	if r := len(integer) % 4; r != 0 {
+		integer = strings.Repeat("0", 4-r) + integer
+	}
+	if r := len(fraction) % 4; r != 0 {
+		fraction += strings.Repeat("0", 4-r)
+	}
+
+	var groups []uint16
+	for i := 0; i < len(integer+fraction); i += 4 {
+		n, _ := strconv.Atoi((integer + fraction)[i : i+4])
+		groups = append(groups, uint16(n))
+	}
+	weight := len(integer)/4 - 1
+	for len(groups) > 0 && groups[0] == 0 {
+		groups = groups[1:]
+		weight--
+	}
+	for len(groups) > 0 && groups[len(groups)-1] == 0 {
+		groups = groups[:len(groups)-1]
+	}
+
+	var sign uint16
+	switch {
+	case len(groups) == 0:
+		weight = 0
+	case negative:
+		sign = 0x4000
+	}
+
+	data := binary.BigEndian.AppendUint16(nil, uint16(len(groups)))
+	data = binary.BigEndian.AppendUint16(data, uint16(int16(weight)))
+	data = binary.BigEndian.AppendUint16(data, sign)
+	data = binary.BigEndian.AppendUint16(data, uint16(scale))
+	for _, group := range groups {
+		data = binary.BigEndian.AppendUint16(data, group)
+	}
+	return data, nil
+}
+
+// pgDecodeNumeric decodes the binary format of numeric into a decimal.
+func pgDecodeNumeric(data []byte) (string, error) {
+	if len(data) < 8 {
+		return "", errPGMessage
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	weight := int(int16(binary.BigEndian.Uint16(data[2:])))
+	sign := binary.BigEndian.Uint16(data[4:])
+	scale := int(binary.BigEndian.Uint16(data[6:]))
+	if sign == 0xc000 {
+		return "NaN", nil
+	}
+	if len(data) < 8+2*n {
+		return "", errPGMessage
+	}
+	group := func(i int) int {
+		if i < 0 || i >= n {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(data[8+2*i:]))
+	}
+
+	var b strings.Builder
+	if sign == 0x4000 {
+		b.WriteByte('-')
+	}
+	if weight < 0 {
+		b.WriteByte('0')
+	}
+	for i := 0; i <= weight; i++ {
+		if i == 0 {
+			fmt.Fprintf(&b, "%d", group(i))
+		} else {
+			fmt.Fprintf(&b, "%04d", group(i))
+		}
+	}
+	if scale > 0 {
+		var fraction strings.Builder
+		for i := weight + 1; fraction.Len() < scale; i++ {
+			fmt.Fprintf(&fraction, "%04d", group(i))
+		}
+		b.WriteByte('.')
+		b.WriteString(fraction.String()[:scale])
+	}
+	return b.String(), nil
+}
+
+// pgCommandTag returns the tag completing query, which returned n rows if it returns rows at all.
+func pgCommandTag(query string, n int64, rows bool) string {
+	if rows {
+		return fmt.Sprintf("SELECT %d", n)
+	}
+	words := strings.Fields(strings.ToUpper(query))
+	switch {
+	case len(words) == 0:
+		return ""
+	case len(words) > 1 && (words[0] == "CREATE" || words[0] == "DROP"):
+		if words[1] == "OR" && len(words) > 3 {
+			return words[0] + " " + words[3]
+		}
+		return words[0] + " " + words[1]
+	}
+	return words[0]
+}
+
+// pgBindParams rewrites the $1, $2 and so on placeholders of query outside of quotes and comments into bind
+// variables, returning how many parameters there are.
+func pgBindParams(query string) (string, int) {
+	var n int
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		if j := engine.SkipLiteral(query, i); j > i {
+			b.WriteString(query[i:j])
+			i = j - 1
+			continue
+		}
+		if query[i] != '$' || i+1 == len(query) || query[i+1] < '0' || query[i+1] > '9' {
+			b.WriteByte(query[i])
+			continue
+		}
+		j := i + 1
+		for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+			j++
+		}
+		param, _ := strconv.Atoi(query[i+1 : j])
+		n = max(n, param)
+		b.WriteString(fmt.Sprintf(":v%d", param))
+		i = j - 1
+	}
+	return b.String(), n
+}
+
+// pgStripComments removes the comments leading query, which clients send on their own to check the connection.
+func pgStripComments(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			_, query, _ = strings.Cut(query, "\n")
+		case strings.HasPrefix(query, "/*") && strings.Contains(query, "*/"):
+			_, query, _ = strings.Cut(query, "*/")
+		default:
+			return query
+		}
+	}
+}
+
+// pgMD5 returns the response to an MD5 password challenge with salt.
+func pgMD5(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+	return "md5" + hex.EncodeToString(outer[:])
+}
+
+// pgFormat returns the format of the i-th value, given as none for all text, one for all, or one per value.
+func pgFormat(formats []int16, i int) int16 {
+	switch {
+	case len(formats) == 0:
+		return 0
+	case len(formats) == 1:
+		return formats[0]
+	case i < len(formats):
+		return formats[i]
+	}
+	return 0
+}
+
+func pgReadString(data []byte) (string, []byte, error) {
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i]), data[i+1:], nil
+		}
+	}
+	return "", nil, errPGMessage
+}
+
+func pgReadInt16s(data []byte) ([]int16, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, errPGMessage
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	if len(data) < 2*n {
+		return nil, nil, errPGMessage
+	}
+	values := make([]int16, n)
+	for i := range values {
+		values[i] = int16(binary.BigEndian.Uint16(data[2*i:]))
+	}
+	return values, data[2*n:], nil
+}
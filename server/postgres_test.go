@@ -0,0 +1,354 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/siyul-park/sqlbridge/engine"
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func newPostgresServer(t *testing.T, opts ...Option) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewPostgresServer(append([]Option{WithRegistry(newTestRegistry(t))}, opts...)...)
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+		require.ErrorIs(t, <-done, ErrServerClosed)
+	})
+	return listener.Addr().String()
+}
+
+func connectPostgres(ctx context.Context, t *testing.T, dsn string) *pgx.Conn {
+	conn, err := pgx.Connect(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close(context.Background()) })
+	return conn
+}
+
+func TestPostgresServer_Query(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newPostgresServer(t, WithDispatcher(engine.NewDispatcher(engine.WithBuiltIn(), engine.WithString())))
+
+	t.Run("expressions", func(t *testing.T) {
+		db := connectPostgres(ctx, t, "postgres://postgres@"+addr+"/app?sslmode=disable")
+
+		var sum int64
+		var text string
+		var null *string
+		err := db.QueryRow(ctx, "SELECT id + 2, CONCAT(name, $1), NULL FROM users WHERE id = $2", "b", 1).Scan(&sum, &text, &null)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), sum)
+		require.Equal(t, "foob", text)
+		require.Nil(t, null)
+	})
+
+	for _, mode := range []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeSimpleProtocol} {
+		t.Run(mode.String(), func(t *testing.T) {
+			config, err := pgx.ParseConfig("postgres://postgres@" + addr + "/app?sslmode=disable")
+			require.NoError(t, err)
+			config.DefaultQueryExecMode = mode
+
+			conn, err := pgx.ConnectConfig(ctx, config)
+			require.NoError(t, err)
+			defer conn.Close(context.Background())
+
+			t.Run("rows", func(t *testing.T) {
+				rows, err := conn.Query(ctx, "SELECT id, name, email, score, created_at FROM users ORDER BY id")
+				require.NoError(t, err)
+
+				fields := rows.FieldDescriptions()
+				require.Len(t, fields, 5)
+				require.Equal(t, "id", fields[0].Name)
+				require.Equal(t, uint32(pgtype.Int8OID), fields[0].DataTypeOID)
+				require.Equal(t, uint32(pgtype.VarcharOID), fields[1].DataTypeOID)
+				require.Equal(t, uint32(pgtype.TimestampOID), fields[4].DataTypeOID)
+
+				users, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (testUser, error) {
+					var user testUser
+					err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Score, &user.Created)
+					return user, err
+				})
+				require.NoError(t, err)
+				require.Len(t, users, 2)
+				require.Equal(t, "foo@example.com", *users[0].Email)
+				require.Nil(t, users[1].Email)
+				require.Equal(t, 2.5, users[1].Score)
+				require.Equal(t, testCreated, users[0].Created)
+			})
+
+			t.Run("params", func(t *testing.T) {
+				var user testUser
+				err := conn.QueryRow(ctx, "SELECT id, name, score FROM users WHERE id = $1 AND name = $2", 2, "bar").Scan(&user.ID, &user.Name, &user.Score)
+				require.NoError(t, err)
+				require.Equal(t, testUser{ID: 2, Name: "bar", Score: 2.5}, user)
+
+				err = conn.QueryRow(ctx, "SELECT id FROM users WHERE id = $1 AND name = $2", 1, "bar").Scan(&user.ID)
+				require.ErrorIs(t, err, pgx.ErrNoRows)
+			})
+
+			t.Run("use", func(t *testing.T) {
+				_, err := conn.Exec(ctx, "USE other")
+				require.NoError(t, err)
+
+				var count int64
+				require.NoError(t, conn.QueryRow(ctx, "SELECT COUNT(*) FROM teams").Scan(&count))
+				require.Zero(t, count)
+
+				tag, err := conn.Exec(ctx, "USE app")
+				require.NoError(t, err)
+				require.Equal(t, "USE", tag.String())
+			})
+
+			t.Run("unsupported", func(t *testing.T) {
+				_, err := conn.Exec(ctx, "BEGIN")
+				require.NoError(t, err)
+			})
+
+			t.Run("errors", func(t *testing.T) {
+				tests := []struct {
+					query string
+					code  string
+				}{
+					{query: "SELEC 1", code: "42601"},
+					{query: "SELECT * FROM missing", code: "42P01"},
+					{query: "SELECT missing FROM users", code: "42703"},
+				}
+
+				for _, tt := range tests {
+					t.Run(tt.query, func(t *testing.T) {
+						_, err := conn.Exec(ctx, tt.query)
+
+						var e *pgconn.PgError
+						require.True(t, errors.As(err, &e), "%v", err)
+						require.Equal(t, tt.code, e.Code)
+
+						require.NoError(t, conn.Ping(ctx))
+					})
+				}
+			})
+		})
+	}
+}
+
+func TestPostgresServer_Portal(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newPostgresServer(t)
+	conn := connectPostgres(ctx, t, "postgres://postgres@"+addr+"/app?sslmode=disable").PgConn()
+
+	sd, err := conn.Prepare(ctx, "users", "SELECT id, name FROM users WHERE id >= $1 ORDER BY id", nil)
+	require.NoError(t, err)
+	require.Len(t, sd.ParamOIDs, 1)
+	require.Len(t, sd.Fields, 2)
+
+	res := conn.ExecPrepared(ctx, "users", [][]byte{[]byte("1")}, nil, []int16{1, 0}).Read()
+	require.NoError(t, res.Err)
+	require.Equal(t, [][][]byte{{{0, 0, 0, 0, 0, 0, 0, 1}, []byte("foo")}, {{0, 0, 0, 0, 0, 0, 0, 2}, []byte("bar")}}, res.Rows)
+	require.Equal(t, "SELECT 2", res.CommandTag.String())
+}
+
+// contextTable scans cursors that fail once the context of the scan is done.
+type contextTable struct {
+	schema.Table
+	schema.Describer
+}
+
+type contextCursor struct {
+	schema.Cursor
+	ctx context.Context
+}
+
+func (t *contextTable) Scan(ctx context.Context, hints ...schema.ScanHint) (schema.Cursor, error) {
+	cursor, err := t.Table.Scan(ctx, hints...)
+	if err != nil {
+		return nil, err
+	}
+	return &contextCursor{Cursor: cursor, ctx: ctx}, nil
+}
+
+func (c *contextCursor) Next() (schema.Row, error) {
+	if err := c.ctx.Err(); err != nil {
+		return schema.Row{}, err
+	}
+	return c.Cursor.Next()
+}
+
+func TestPostgresServer_PortalBatches(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	users, err := newTestRegistry(t).Catalog("app")
+	require.NoError(t, err)
+	table, err := users.Table("users")
+	require.NoError(t, err)
+
+	addr := newPostgresServer(t, WithRegistry(schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"users": &contextTable{Table: table, Describer: table.(schema.Describer)}}),
+	})))
+	conn := connectPostgres(ctx, t, "postgres://postgres@"+addr+"/app?sslmode=disable").PgConn()
+	frontend := conn.Frontend()
+
+	receive := func() pgproto3.BackendMessage {
+		require.NoError(t, conn.Conn().SetReadDeadline(time.Now().Add(5*time.Second)))
+		msg, err := frontend.Receive()
+		require.NoError(t, err)
+		return msg
+	}
+
+	frontend.SendParse(&pgproto3.Parse{Query: "SELECT id, name FROM users WHERE id >= $1"})
+	frontend.SendBind(&pgproto3.Bind{DestinationPortal: "p", Parameters: [][]byte{[]byte("1")}})
+
+	var rows []string
+	for {
+		frontend.SendExecute(&pgproto3.Execute{Portal: "p", MaxRows: 1})
+		frontend.Send(&pgproto3.Flush{})
+		require.NoError(t, frontend.Flush())
+
+		done := false
+		for suspended := false; !suspended && !done; {
+			switch msg := receive().(type) {
+			case *pgproto3.DataRow:
+				rows = append(rows, string(msg.Values[1]))
+			case *pgproto3.PortalSuspended:
+				suspended = true
+			case *pgproto3.CommandComplete:
+				require.Equal(t, "SELECT 2", string(msg.CommandTag))
+				done = true
+			case *pgproto3.ErrorResponse:
+				require.FailNow(t, msg.Message)
+			}
+		}
+		if done {
+			break
+		}
+	}
+	require.Equal(t, []string{"foo", "bar"}, rows)
+
+	frontend.SendSync(&pgproto3.Sync{})
+	require.NoError(t, frontend.Flush())
+	for {
+		if _, ok := receive().(*pgproto3.ReadyForQuery); ok {
+			break
+		}
+	}
+}
+
+func TestPostgresServer_Panic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newPostgresServer(t, WithRegistry(newPanicRegistry()))
+	conn := connectPostgres(ctx, t, "postgres://postgres@"+addr+"/app?sslmode=disable").PgConn()
+
+	sd, err := conn.Prepare(ctx, "boom", "SELECT * FROM boom WHERE id = $1", nil)
+	require.NoError(t, err)
+	require.Empty(t, sd.Fields)
+
+	res := conn.ExecPrepared(ctx, "boom", [][]byte{[]byte("1")}, nil, nil).Read()
+	var e *pgconn.PgError
+	require.True(t, errors.As(res.Err, &e), "%v", res.Err)
+	require.Equal(t, "XX000", e.Code)
+
+	other := connectPostgres(ctx, t, "postgres://postgres@"+addr+"/app?sslmode=disable")
+	require.NoError(t, other.Ping(ctx))
+}
+
+func TestPostgresServer_Auth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	addr := newPostgresServer(t, WithUser("root", "secret"))
+
+	tests := []struct {
+		dsn  string
+		code string
+	}{
+		{dsn: "postgres://root:secret@" + addr + "/app?sslmode=disable"},
+		{dsn: "postgres://root:secret@" + addr + "/?sslmode=prefer"},
+		{dsn: "postgres://root:wrong@" + addr + "/app?sslmode=disable", code: "28P01"},
+		{dsn: "postgres://nobody@" + addr + "/app?sslmode=disable", code: "28P01"},
+		{dsn: "postgres://root:secret@" + addr + "/missing?sslmode=disable", code: "3D000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			conn, err := pgx.Connect(ctx, tt.dsn)
+			if tt.code == "" {
+				require.NoError(t, err)
+				require.NoError(t, conn.Ping(ctx))
+				require.NoError(t, conn.Close(ctx))
+				return
+			}
+
+			var e *pgconn.PgError
+			require.True(t, errors.As(err, &e), "%v", err)
+			require.Equal(t, tt.code, e.Code)
+		})
+	}
+}
+
+func TestPostgresServer_Limits(t *testing.T) {
+	addr := newPostgresServer(t, WithUser("root", "secret"), WithMaxPacketSize(1024), WithHandshakeTimeout(100*time.Millisecond))
+
+	startup := binary.BigEndian.AppendUint32(nil, pgProtocolVersion)
+	startup = append(startup, "user\x00root\x00database\x00app\x00\x00"...)
+	startup = append(binary.BigEndian.AppendUint32(nil, uint32(len(startup)+4)), startup...)
+
+	tests := []struct {
+		name string
+		send []byte
+	}{
+		{name: "oversized", send: append(slices.Clone(startup), 'p', 0xff, 0xff, 0xff, 0xff)},
+		{name: "idle", send: startup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", addr)
+			require.NoError(t, err)
+			defer conn.Close()
+			require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+			_, err = conn.Write(tt.send)
+			require.NoError(t, err)
+
+			// The server asks for a password, then hangs up.
+			_, err = io.ReadAll(conn)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPGNumeric(t *testing.T) {
+	tests := []string{"0", "1", "-1", "12345.678", "0.0001", "-0.05", "100000000", "3.14159265358979"}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			data, err := pgEncodeNumeric(tt)
+			require.NoError(t, err)
+
+			s, err := pgDecodeNumeric(data)
+			require.NoError(t, err)
+			require.Equal(t, tt, s)
+		})
+	}
+}
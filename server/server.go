@@ -0,0 +1,397 @@
+package server
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/engine"
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/xwb1989/sqlparser"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+type options struct {
+	registry   schema.Registry
+	dispatcher *engine.Dispatcher
+	location   *time.Location
+	maxDepth   int
+	parallel   int
+	users      map[string]string
+	timeout    time.Duration
+	middleware []func(http.Handler) http.Handler
+	ddl        bool
+	maxPacket  int
+	handshake  time.Duration
+}
+
+type Option func(*options)
+
+const (
+	// DefaultMaxPacketSize matches MySQL's max_allowed_packet.
+	DefaultMaxPacketSize = 64 << 20
+	// DefaultHandshakeTimeout matches MySQL's connect_timeout.
+	DefaultHandshakeTimeout = 10 * time.Second
+)
+
+type acceptor struct {
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+}
+
+type session struct {
+	planner *engine.Planner
+}
+
+// statement runs as a no-op if plan is nil.
+type statement struct {
+	plan   engine.Plan
+	params int
+}
+
+type result struct {
+	columns []schema.Column
+	cursor  schema.Cursor
+}
+
+var (
+	ErrServerClosed = errors.New("server closed")
+	ErrSyntax       = errors.New("syntax error")
+
+	errPanic = errors.New("internal error")
+)
+
+func WithRegistry(registry schema.Registry) Option {
+	return func(o *options) { o.registry = registry }
+}
+
+func WithDispatcher(dispatcher *engine.Dispatcher) Option {
+	return func(o *options) { o.dispatcher = dispatcher }
+}
+
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) { o.location = loc }
+}
+
+func WithMaxRecursionDepth(depth int) Option {
+	return func(o *options) { o.maxDepth = depth }
+}
+
+func WithParallelism(workers int) Option {
+	return func(o *options) { o.parallel = workers }
+}
+
+// WithUser adds a user. A server without users accepts anyone.
+func WithUser(name, password string) Option {
+	return func(o *options) {
+		if o.users == nil {
+			o.users = map[string]string{}
+		}
+		o.users[name] = password
+	}
+}
+
+// WithMaxPacketSize disconnects wire protocol clients sending larger messages.
+func WithMaxPacketSize(size int) Option {
+	return func(o *options) { o.maxPacket = size }
+}
+
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.handshake = timeout }
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		registry:   schema.NewInMemoryRegistry(nil),
+		dispatcher: engine.NewDispatcher(engine.WithBuiltIn()),
+		location:   time.UTC,
+		maxDepth:   engine.DefaultMaxRecursionDepth,
+		maxPacket:  DefaultMaxPacketSize,
+		handshake:  DefaultHandshakeTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.registry = schema.NewCompositeRegistry(o.registry, schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		schema.InformationSchemaName: schema.NewInformationSchema(o.registry),
+	}))
+	return o
+}
+
+func newAcceptor() *acceptor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &acceptor{
+		listeners: map[net.Listener]struct{}{},
+		conns:     map[net.Conn]struct{}{},
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+func (a *acceptor) serve(listener net.Listener, handle func(ctx context.Context, conn net.Conn)) error {
+	a.mu.Lock()
+	if a.ctx.Err() != nil {
+		a.mu.Unlock()
+		_ = listener.Close()
+		return ErrServerClosed
+	}
+	a.listeners[listener] = struct{}{}
+	a.mu.Unlock()
+
+	defer func() {
+		a.mu.Lock()
+		delete(a.listeners, listener)
+		a.mu.Unlock()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if a.ctx.Err() != nil {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		a.mu.Lock()
+		if a.ctx.Err() != nil {
+			a.mu.Unlock()
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+		a.conns[conn] = struct{}{}
+		a.wg.Add(1)
+		a.mu.Unlock()
+
+		go func() {
+			defer a.wg.Done()
+			defer func() {
+				a.mu.Lock()
+				delete(a.conns, conn)
+				a.mu.Unlock()
+				_ = conn.Close()
+			}()
+			defer func() {
+				// A connection that panics is dropped, not the server.
+				_ = recover()
+			}()
+			handle(a.ctx, conn)
+		}()
+	}
+}
+
+func (a *acceptor) close() error {
+	a.mu.Lock()
+	a.cancel()
+	for listener := range a.listeners {
+		_ = listener.Close()
+	}
+	for conn := range a.conns {
+		_ = conn.Close()
+	}
+	a.mu.Unlock()
+
+	a.wg.Wait()
+	return nil
+}
+
+// protect turns a panic of fn into an error wrapping errPanic.
+func protect(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", errPanic, r)
+		}
+	}()
+	return fn()
+}
+
+func (o *options) authenticate(user string, verify func(password string) bool) bool {
+	if len(o.users) == 0 {
+		return true
+	}
+	password, ok := o.users[user]
+	return ok && verify(password)
+}
+
+func (o *options) session(database string) (*session, error) {
+	var catalog schema.Catalog = schema.NewInMemoryCatalog(nil)
+	if database != "" {
+		var err error
+		if catalog, err = o.registry.Catalog(database); err != nil {
+			return nil, err
+		}
+	}
+	vars := map[string]sqltypes.Value{
+		"version":            sqltypes.NewVarChar(mysqlServerVersion),
+		"max_allowed_packet": sqltypes.NewInt64(int64(o.maxPacket)),
+	}
+	return &session{planner: engine.NewPlanner(catalog, o.dispatcher, engine.WithRegistry(o.registry), engine.WithDatabase(database), engine.WithLocation(o.location), engine.WithMaxRecursionDepth(o.maxDepth), engine.WithParallelism(o.parallel), engine.WithVariables(vars))}, nil
+}
+
+func (s *session) database() string {
+	return s.planner.Database()
+}
+
+func (s *session) use(database string) error {
+	return s.planner.Use(database)
+}
+
+// prepare expects placeholders rewritten into the bind variables v1, v2, ...
+func (s *session) prepare(query string, params int) (*statement, error) {
+	stmt, err := engine.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+	}
+
+	plan, err := s.planner.Plan(stmt)
+	if errors.Is(err, driver.ErrSkip) {
+		return &statement{params: params}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &statement{plan: plan, params: params}, nil
+}
+
+func (s *session) run(ctx context.Context, stmt *statement, args []any) (*result, error) {
+	if stmt.plan == nil {
+		return &result{columns: []schema.Column{}, cursor: schema.NewInMemoryCursor(nil)}, nil
+	}
+
+	values := make(map[string]any, len(args))
+	for i, arg := range args {
+		values[fmt.Sprintf("v%d", i+1)] = arg
+	}
+	binds, err := sqltypes.BuildBindVariables(values)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = engine.ContextWithLocation(ctx, s.planner.Location())
+	columns, err := stmt.plan.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := stmt.plan.Run(ctx, binds)
+	if err != nil {
+		return nil, err
+	}
+	if columns != nil && !slices.ContainsFunc(columns, func(col schema.Column) bool { return col.Type == querypb.Type_NULL_TYPE }) {
+		return &result{columns: columns, cursor: cursor}, nil
+	}
+
+	// Some column types are only known once the rows are read.
+	rows, err := schema.ReadAll(cursor)
+	if err != nil {
+		return nil, err
+	}
+	known := columns != nil
+	columns = append([]schema.Column{}, columns...)
+	for _, row := range rows {
+		for i, col := range row.Columns {
+			j := i
+			if !known || len(row.Columns) != len(columns) {
+				j = slices.IndexFunc(columns, func(c schema.Column) bool { return c.Name.Name.Equal(col.Name) })
+			}
+			if j < 0 {
+				if known {
+					continue
+				}
+				columns = append(columns, schema.Column{Name: &sqlparser.ColName{Name: col.Name}, Type: querypb.Type_NULL_TYPE, Nullable: true})
+				j = len(columns) - 1
+			}
+			if columns[j].Type == querypb.Type_NULL_TYPE && !row.Values[i].IsNull() {
+				columns[j].Type = row.Values[i].Type()
+			}
+		}
+	}
+	return &result{columns: columns, cursor: schema.NewInMemoryCursor(rows)}, nil
+}
+
+// describe returns nil if the columns are only known at run time.
+func (s *session) describe(ctx context.Context, stmt *statement) ([]schema.Column, error) {
+	if stmt.plan == nil {
+		return []schema.Column{}, nil
+	}
+	return stmt.plan.Schema(engine.ContextWithLocation(ctx, s.planner.Location()))
+}
+
+func (r *result) Next() ([]sqltypes.Value, error) {
+	row, err := r.cursor.Next()
+	if err != nil {
+		return nil, err
+	}
+	if len(row.Columns) == len(r.columns) {
+		return row.Values, nil
+	}
+
+	values := make([]sqltypes.Value, len(r.columns))
+	for i, col := range r.columns {
+		if j := slices.IndexFunc(row.Columns, func(c *sqlparser.ColName) bool { return c.Name.Equal(col.Name.Name) }); j >= 0 {
+			values[i] = row.Values[j]
+		}
+	}
+	return values, nil
+}
+
+func (r *result) Close() error {
+	return r.cursor.Close()
+}
+
+func (r *result) drain() (int64, error) {
+	var n int64
+	for {
+		if _, err := r.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, r.Close()
+			}
+			_ = r.Close()
+			return n, err
+		}
+		n++
+	}
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999", time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseDuration parses a TIME value of the form [-]HH:MM:SS[.ffffff].
+func parseDuration(s string) (time.Duration, error) {
+	negative := strings.HasPrefix(s, "-")
+	parts := strings.Split(strings.TrimPrefix(s, "-"), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time value %q", s)
+	}
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return 0, fmt.Errorf("invalid time value %q", s)
+	}
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second))
+	if negative {
+		d = -d
+	}
+	return d.Round(time.Microsecond), nil
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/siyul-park/sqlbridge/engine"
+	"github.com/siyul-park/sqlbridge/schema"
+	"github.com/stretchr/testify/require"
+	"github.com/xwb1989/sqlparser/dependency/querypb"
+	"github.com/xwb1989/sqlparser/dependency/sqltypes"
+)
+
+type testUser struct {
+	ID      int64     `sql:"id,index"`
+	Name    string    `sql:"name"`
+	Email   *string   `sql:"email"`
+	Score   float64   `sql:"score"`
+	Created time.Time `sql:"created_at"`
+}
+
+var testCreated = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestRegistry(t *testing.T) schema.Registry {
+	email := "foo@example.com"
+	users, err := schema.NewSliceTable([]testUser{
+		{ID: 1, Name: "foo", Email: &email, Score: 1.5, Created: testCreated},
+		{ID: 2, Name: "bar", Score: 2.5, Created: testCreated.Add(time.Hour)},
+	})
+	require.NoError(t, err)
+
+	return schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app":   schema.NewViewCatalog(schema.NewInMemoryCatalog(map[string]schema.Table{"users": users})),
+		"other": schema.NewInMemoryCatalog(map[string]schema.Table{"teams": schema.NewInMemoryTable(nil)}),
+	})
+}
+
+// panicTable panics when scanned and has no known columns, so describing a query on it must not run it.
+type panicTable struct {
+	schema.Table
+}
+
+func (t *panicTable) Scan(context.Context, ...schema.ScanHint) (schema.Cursor, error) {
+	panic("scan")
+}
+
+func newPanicRegistry() schema.Registry {
+	return schema.NewInMemoryRegistry(map[string]schema.Catalog{
+		"app": schema.NewInMemoryCatalog(map[string]schema.Table{"boom": &panicTable{Table: schema.NewInMemoryTable(nil)}}),
+	})
+}
+
+func TestSession_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	s, err := newOptions(WithRegistry(newTestRegistry(t))).session("app")
+	require.NoError(t, err)
+
+	t.Run("query", func(t *testing.T) {
+		query, params := engine.BindQuestionMarks("SELECT id, name FROM users WHERE id = ?")
+		require.Equal(t, 1, params)
+
+		stmt, err := s.prepare(query, params)
+		require.NoError(t, err)
+
+		res, err := s.run(ctx, stmt, []any{int64(2)})
+		require.NoError(t, err)
+		require.Len(t, res.columns, 2)
+		require.Equal(t, querypb.Type_INT64, res.columns[0].Type)
+
+		values, err := res.Next()
+		require.NoError(t, err)
+		require.Equal(t, []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarChar("bar")}, values)
+
+		_, err = res.Next()
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		stmt, err := s.prepare("BEGIN", 0)
+		require.NoError(t, err)
+
+		res, err := s.run(ctx, stmt, nil)
+		require.NoError(t, err)
+		require.Empty(t, res.columns)
+	})
+
+	t.Run("use", func(t *testing.T) {
+		stmt, err := s.prepare("USE other", 0)
+		require.NoError(t, err)
+
+		res, err := s.run(ctx, stmt, nil)
+		require.NoError(t, err)
+		_, err = res.drain()
+		require.NoError(t, err)
+		require.Equal(t, "other", s.database())
+	})
+
+	t.Run("syntax", func(t *testing.T) {
+		_, err := s.prepare("SELEC 1", 0)
+		require.ErrorIs(t, err, ErrSyntax)
+	})
+
+	_, err = newOptions(WithRegistry(newTestRegistry(t))).session("missing")
+	require.ErrorIs(t, err, schema.ErrCatalogNotFound)
+}